@@ -0,0 +1,91 @@
+package rz
+
+import "unicode/utf8"
+
+// appendStripANSI appends src to dst, removing ANSI escape sequences (CSI, OSC, DCS and
+// two-character ESC sequences). If stripControl is true, the remaining C0 and C1 control
+// characters are removed too, except tabulations and line breaks.
+func appendStripANSI(dst, src []byte, stripControl bool) []byte {
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == 0x1b: // ESC
+			i = skipEscape(src, i+1)
+			continue
+		case c < 0x80:
+			if !stripControl || c >= 0x20 && c != 0x7f || c == '\t' || c == '\n' || c == '\r' {
+				dst = append(dst, c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && size == 1 && c == 0x9b {
+			// 8-bit C1 CSI, in non UTF-8 streams
+			r = 0x9b
+		}
+		switch {
+		case r == 0x9b: // C1 CSI
+			i = skipCSI(src, i+size)
+			continue
+		case r == 0x9d: // C1 OSC
+			i = skipString(src, i+size)
+			continue
+		case stripControl && r >= 0x80 && r < 0xa0:
+		default:
+			dst = append(dst, src[i:i+size]...)
+		}
+		i += size
+	}
+	return dst
+}
+
+// skipEscape returns the index following the escape sequence starting at i, right after ESC.
+func skipEscape(src []byte, i int) int {
+	if i >= len(src) {
+		return i
+	}
+	switch src[i] {
+	case '[':
+		return skipCSI(src, i+1)
+	case ']', 'P', 'X', '^', '_': // OSC, DCS, SOS, PM, APC
+		return skipString(src, i+1)
+	}
+	// intermediate bytes then a final byte
+	for i < len(src) && src[i] >= 0x20 && src[i] <= 0x2f {
+		i++
+	}
+	if i < len(src) {
+		i++
+	}
+	return i
+}
+
+// skipCSI returns the index following the control sequence starting at i.
+func skipCSI(src []byte, i int) int {
+	for i < len(src) && src[i] >= 0x20 && src[i] <= 0x3f {
+		i++
+	}
+	if i < len(src) && src[i] >= 0x40 && src[i] <= 0x7e {
+		i++
+	}
+	return i
+}
+
+// skipString returns the index following the control string starting at i, terminated
+// by BEL or ST.
+func skipString(src []byte, i int) int {
+	for i < len(src) {
+		switch {
+		case src[i] == 0x07:
+			return i + 1
+		case src[i] == 0x1b && i+1 < len(src) && src[i+1] == '\\':
+			return i + 2
+		case src[i] == 0xc2 && i+1 < len(src) && src[i+1] == 0x9c: // C1 ST
+			return i + 2
+		}
+		i++
+	}
+	return i
+}
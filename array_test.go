@@ -1,6 +1,7 @@
 package rz
 
 import (
+	"bytes"
 	"net"
 	"testing"
 	"time"
@@ -33,3 +34,26 @@ func TestArray(t *testing.T) {
 		t.Errorf("Array.write()\ngot:  %s\nwant: %s", got, want)
 	}
 }
+
+type testTeams []*testTeam
+
+func (teams testTeams) MarshalRzArray(a *LogArray) {
+	for _, team := range teams {
+		a.Object(team)
+	}
+}
+
+func TestArrayOfObjects(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	log.Info("hello",
+		Array("teams", testTeams{{name: "core"}, nil, {name: "web"}}),
+		Objects("points", []point{{1, 2}, {3, 4}}),
+		Objects("empty", []point(nil)),
+		Array("nil", nil),
+	)
+	want := `{"level":"info","teams":[{"name":"core"},null,{"name":"web"}],"points":[{"x":1,"y":2},{"x":3,"y":4}],"empty":[],"nil":null,"message":"hello"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+package rz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PartitionRange is the range of offsets of a partition consumed by a batch.
+type PartitionRange struct {
+	Partition   int32
+	FirstOffset int64
+	LastOffset  int64
+	// HighWatermark is the offset of the next message to be produced to the partition, used to
+	// estimate the lag. It's ignored if <= 0.
+	HighWatermark int64
+}
+
+// Messages returns the number of messages of the range.
+func (r PartitionRange) Messages() int64 {
+	if r.LastOffset < r.FirstOffset {
+		return 0
+	}
+	return r.LastOffset - r.FirstOffset + 1
+}
+
+// Lag returns the number of messages of the partition after the range, or -1 if the high
+// watermark is unknown.
+func (r PartitionRange) Lag() int64 {
+	if r.HighWatermark <= 0 {
+		return -1
+	}
+	if lag := r.HighWatermark - r.LastOffset - 1; lag > 0 {
+		return lag
+	}
+	return 0
+}
+
+// MarshalRzObject implements LogObjectMarshaler.
+func (r PartitionRange) MarshalRzObject(e *Event) {
+	e.Append(Int32("partition", r.Partition), Int64("first_offset", r.FirstOffset), Int64("last_offset", r.LastOffset))
+	if lag := r.Lag(); lag >= 0 {
+		e.Append(Int64("lag", lag))
+	}
+}
+
+// ConsumerBatch is a batch of messages of a topic (or queue) consumed by a consumer group.
+type ConsumerBatch struct {
+	Topic      string
+	Group      string
+	Partitions []PartitionRange
+}
+
+// BatchLogger logs the processing of a ConsumerBatch with a consistent schema: all its events
+// have the fields "topic" and "consumer_group" (omitted if empty), and Run logs a summary of the
+// batch.
+//
+//     batch := logger.ForBatch(rz.ConsumerBatch{Topic: "orders", Group: "billing", Partitions: ranges})
+//     err := batch.Run(ctx, func(ctx context.Context) error {
+//         for _, p := range partitions {
+//             if err := process(ctx, p); err != nil {
+//                 batch.FailPartition(p.Partition, err)
+//             }
+//         }
+//         return nil
+//     })
+type BatchLogger struct {
+	Logger
+	batch ConsumerBatch
+
+	mu     sync.Mutex
+	failed map[int32]error
+}
+
+type partitionRanges []PartitionRange
+
+func (ranges partitionRanges) MarshalRzArray(a *LogArray) {
+	for _, r := range ranges {
+		a.Object(r)
+	}
+}
+
+type partitionErrors []partitionError
+
+type partitionError struct {
+	partition int32
+	err       error
+}
+
+func (errs partitionErrors) MarshalRzArray(a *LogArray) {
+	for _, pe := range errs {
+		a.Object(pe)
+	}
+}
+
+func (pe partitionError) MarshalRzObject(e *Event) {
+	e.Append(Int32("partition", pe.partition), Error("error", pe.err))
+}
+
+// ForBatch returns a BatchLogger for batch, logging with a copy of the logger with the batch
+// fields.
+func (l Logger) ForBatch(batch ConsumerBatch) *BatchLogger {
+	fields := []Field{String("topic", batch.Topic)}
+	if batch.Group != "" {
+		fields = append(fields, String("consumer_group", batch.Group))
+	}
+	return &BatchLogger{Logger: l.With(Fields(fields...)), batch: batch}
+}
+
+// Batch returns the batch logged.
+func (b *BatchLogger) Batch() ConsumerBatch {
+	return b.batch
+}
+
+// FailPartition records that the messages of partition failed to be processed with err. It's
+// safe for concurrent use.
+func (b *BatchLogger) FailPartition(partition int32, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failed == nil {
+		b.failed = map[int32]error{}
+	}
+	b.failed[partition] = err
+}
+
+// Run runs fn with a copy of ctx holding the logger of the batch (see FromCtx), and logs "batch
+// processed" with the fields "messages", "partitions" (the partition, first_offset, last_offset
+// and lag of each range), "lag" (the total lag, if known), "duration" and "status":
+//   - "ok" at info level if fn returns nil and no partition failed;
+//   - "partial" at warn level with "error_partitions" (the partition and error of each failed
+//     partition, see FailPartition) if fn returns nil but partitions failed;
+//   - "error" at error level with the error if fn returns an error;
+//   - "panic" at error level with the field "panic" if fn panics. The panic is recovered, and
+//     Run returns an error wrapping ErrJobPanicked so the consumer keeps running.
+//
+// Run returns the error of fn.
+func (b *BatchLogger) Run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	start := time.Now()
+	defer func() {
+		fields := b.summaryFields()
+		fields = append(fields, Duration("duration", time.Since(start)))
+		errs := b.failedPartitions()
+		level, status := InfoLevel, "ok"
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanicked, r)
+			level, status = ErrorLevel, "panic"
+			fields = append(fields, String("panic", fmt.Sprint(r)))
+		} else if err != nil {
+			level, status = ErrorLevel, "error"
+		} else if len(errs) > 0 {
+			level, status = WarnLevel, "partial"
+		}
+		fields = append(fields, String("status", status))
+		if len(errs) > 0 {
+			fields = append(fields, func(e *Event) {
+				e.array("error_partitions", errs)
+			})
+		}
+		fields = append(fields, Err(err))
+		b.LogWithLevel(level, "batch processed", fields...)
+	}()
+	return fn(b.Logger.ToCtx(ctx))
+}
+
+func (b *BatchLogger) summaryFields() []Field {
+	var messages, lag int64
+	lagKnown := false
+	for _, r := range b.batch.Partitions {
+		messages += r.Messages()
+		if partitionLag := r.Lag(); partitionLag >= 0 {
+			lag += partitionLag
+			lagKnown = true
+		}
+	}
+	fields := []Field{
+		Int64("messages", messages),
+		func(e *Event) {
+			e.array("partitions", partitionRanges(b.batch.Partitions))
+		},
+	}
+	if lagKnown {
+		fields = append(fields, Int64("lag", lag))
+	}
+	return fields
+}
+
+// failedPartitions returns the failed partitions sorted by partition.
+func (b *BatchLogger) failedPartitions() partitionErrors {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	errs := make(partitionErrors, 0, len(b.failed))
+	for partition, err := range b.failed {
+		errs = append(errs, partitionError{partition: partition, err: err})
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].partition < errs[j].partition
+	})
+	return errs
+}
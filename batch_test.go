@@ -0,0 +1,93 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBatchLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	batch := log.ForBatch(ConsumerBatch{
+		Topic: "orders",
+		Group: "billing",
+		Partitions: []PartitionRange{
+			{Partition: 0, FirstOffset: 10, LastOffset: 19, HighWatermark: 25},
+			{Partition: 3, FirstOffset: 5, LastOffset: 6},
+		},
+	})
+
+	err := batch.Run(context.Background(), func(ctx context.Context) error {
+		FromCtx(ctx).Debug("processing")
+		batch.FailPartition(3, errors.New("invalid order"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("invalid number of events: %d\n%s", len(lines), out.String())
+	}
+	if want := `{"level":"debug","topic":"orders","consumer_group":"billing","message":"processing"}`; lines[0] != want {
+		t.Errorf("invalid event:\ngot:  %v\nwant: %v", lines[0], want)
+	}
+	summary := regexp.MustCompile(`"duration":[0-9.e-]+,`).ReplaceAllString(lines[1], "")
+	want := `{"level":"warning","topic":"orders","consumer_group":"billing","messages":12,` +
+		`"partitions":[{"partition":0,"first_offset":10,"last_offset":19,"lag":5},{"partition":3,"first_offset":5,"last_offset":6}],` +
+		`"lag":5,"status":"partial","error_partitions":[{"partition":3,"error":"invalid order"}],"message":"batch processed"}`
+	if summary != want {
+		t.Errorf("invalid summary:\ngot:  %v\nwant: %v", summary, want)
+	}
+}
+
+func TestBatchLoggerOutcomes(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	batch := log.ForBatch(ConsumerBatch{Topic: "orders"})
+
+	batch.Run(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	if summary := out.String(); !strings.Contains(summary, `"level":"info"`) || !strings.Contains(summary, `"status":"ok"`) ||
+		strings.Contains(summary, "consumer_group") || strings.Contains(summary, `"lag"`) {
+		t.Errorf("invalid summary: %s", summary)
+	}
+	out.Reset()
+
+	err := batch.Run(context.Background(), func(ctx context.Context) error {
+		return errors.New("broker unavailable")
+	})
+	if err == nil {
+		t.Error("Run() = nil")
+	}
+	if summary := out.String(); !strings.Contains(summary, `"level":"error"`) || !strings.Contains(summary, `"status":"error"`) ||
+		!strings.Contains(summary, `"error":"broker unavailable"`) {
+		t.Errorf("invalid summary: %s", summary)
+	}
+	out.Reset()
+
+	err = batch.Run(context.Background(), func(ctx context.Context) error {
+		panic("nil map")
+	})
+	if !errors.Is(err, ErrJobPanicked) {
+		t.Errorf("Run() = %v", err)
+	}
+	if summary := out.String(); !strings.Contains(summary, `"status":"panic"`) || !strings.Contains(summary, `"panic":"nil map"`) {
+		t.Errorf("invalid summary: %s", summary)
+	}
+}
+
+func TestPartitionRange(t *testing.T) {
+	r := PartitionRange{FirstOffset: 100, LastOffset: 149, HighWatermark: 150}
+	if r.Messages() != 50 || r.Lag() != 0 {
+		t.Errorf("Messages() = %d, Lag() = %d", r.Messages(), r.Lag())
+	}
+	if r = (PartitionRange{FirstOffset: 1, LastOffset: 0}); r.Messages() != 0 || r.Lag() != -1 {
+		t.Errorf("Messages() = %d, Lag() = %d", r.Messages(), r.Lag())
+	}
+}
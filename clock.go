@@ -0,0 +1,80 @@
+package rz
+
+import (
+	"sync"
+	"time"
+)
+
+// processStart is the reference of the monotonic clock readings.
+var processStart = time.Now()
+
+// monotonicNow returns the time elapsed since the process start, read from the monotonic clock,
+// which is not affected by the changes of the wall clock.
+func monotonicNow() time.Duration {
+	return time.Since(processStart)
+}
+
+type monotonicTimestamps struct {
+	fieldName string
+}
+
+// Process implements the LogProcessor interface.
+func (p monotonicTimestamps) Process(e *Event, level LogLevel, message string) {
+	e.int64(p.fieldName, int64(monotonicNow()))
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (p monotonicTimestamps) DescribeConfig() string {
+	return "monotonic_timestamps(" + p.fieldName + ")"
+}
+
+// MonotonicTimestamps adds to each event, in addition to the wall clock timestamp, the field
+// fieldName with the nanoseconds elapsed since the process start according to the monotonic
+// clock. Unlike timestamps, the differences between these values are exact durations, even when
+// the wall clock is adjusted. The processor is appended to the enrich stage of logger's pipeline.
+func MonotonicTimestamps(fieldName string) LoggerOption {
+	return AddProcessor(EnrichStage, monotonicTimestamps{fieldName: fieldName})
+}
+
+// ClockJumpFieldName is the field name used for the duration of a wall clock jump.
+const ClockJumpFieldName = "clock_jump"
+
+type clockJumpDetector struct {
+	threshold time.Duration
+
+	mu       sync.Mutex
+	lastWall time.Time
+	lastMono time.Duration
+}
+
+// Process implements the LogProcessor interface.
+func (d *clockJumpDetector) Process(e *Event, level LogLevel, message string) {
+	wall, mono := e.timestampFunc(), monotonicNow()
+	d.mu.Lock()
+	lastWall, lastMono := d.lastWall, d.lastMono
+	d.lastWall, d.lastMono = wall, mono
+	d.mu.Unlock()
+	if lastWall.IsZero() {
+		return
+	}
+	// the drift between the wall clock and the monotonic clock since the last event
+	if drift := wall.Sub(lastWall) - (mono - lastMono); drift < -d.threshold {
+		warning := e.derive(WarnLevel)
+		warning.duration(ClockJumpFieldName, -drift)
+		writeEvent(warning, "wall clock jumped backwards", nil, false)
+	}
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (d *clockJumpDetector) DescribeConfig() string {
+	return "clock_jump_detector(" + d.threshold.String() + ")"
+}
+
+// DetectClockJumps logs a "wall clock jumped backwards" event with warning level, with the
+// duration of the jump in the "clock_jump" field, before the first event whose timestamp went
+// backwards by more than threshold compared to the monotonic clock. It's checked on each event,
+// so timestamps in the logs can be trusted (or not) when investigating an incident.
+// The processor is appended to the enrich stage of logger's pipeline.
+func DetectClockJumps(threshold time.Duration) LoggerOption {
+	return AddProcessor(EnrichStage, &clockJumpDetector{threshold: threshold})
+}
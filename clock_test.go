@@ -0,0 +1,40 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonotonicTimestamps(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), MonotonicTimestamps("mono"))
+	log.Info("a")
+	if !strings.HasPrefix(out.String(), `{"level":"info","mono":`) {
+		t.Errorf("invalid log output: %s", out.String())
+	}
+}
+
+func TestDetectClockJumps(t *testing.T) {
+	out := &bytes.Buffer{}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := New(Writer(out), Fields(Timestamp(false)), TimestampFunc(func() time.Time { return now }), DetectClockJumps(time.Minute))
+	log.Info("a")
+	now = now.Add(-30 * time.Second)
+	log.Info("b")
+	now = now.Add(-time.Hour)
+	log.Info("c")
+	log.Info("d")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("invalid number of events:\n%s", out.String())
+	}
+	if !strings.HasPrefix(lines[2], `{"level":"warning","clock_jump":3600`) || !strings.HasSuffix(lines[2], `"message":"wall clock jumped backwards"}`) {
+		t.Errorf("invalid warning event: %s", lines[2])
+	}
+	if lines[3] != `{"level":"info","message":"c"}` || lines[4] != `{"level":"info","message":"d"}` {
+		t.Errorf("invalid events:\n%s", out.String())
+	}
+}
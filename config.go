@@ -27,6 +27,7 @@ func Writer(writer io.Writer) LoggerOption {
 func Level(lvl LogLevel) LoggerOption {
 	return func(logger *Logger) {
 		logger.level = lvl
+		logger.atomicLevel = nil
 	}
 }
 
@@ -69,12 +70,24 @@ func Fields(fields ...Field) LoggerOption {
 		if e.timestamp != logger.timestamp {
 			logger.timestamp = e.timestamp
 		}
+		if e.confirm != logger.confirm {
+			logger.confirm = e.confirm
+		}
 		if e.buf != nil {
-			logger.context = enc.AppendObjectData(logger.context, e.buf)
+			logger.context = e.encoder.AppendObjectData(logger.context, e.buf)
 		}
 	}
 }
 
+// SafeMode enables or disables logger's safe mode. In safe mode, if a field panics, the fields
+// it added are removed and the panic is recorded in the "fields_panic" and "fields_panic_stack"
+// fields of the event, which is then logged normally.
+func SafeMode(enable bool) LoggerOption {
+	return func(logger *Logger) {
+		logger.safeMode = enable
+	}
+}
+
 // Formatter update logger's formatter.
 func Formatter(formatter LogFormatter) LoggerOption {
 	return func(logger *Logger) {
@@ -131,7 +144,8 @@ func ErrorStackFieldName(errorStackFieldName string) LoggerOption {
 	}
 }
 
-// TimeFieldFormat update logger's timeFieldFormat.
+// TimeFieldFormat update logger's timeFieldFormat. It's either a time layout or the name of a
+// format registered with RegisterTimeFormat (e.g. TimeFormatUnixMs).
 func TimeFieldFormat(timeFieldFormat string) LoggerOption {
 	return func(logger *Logger) {
 		logger.timeFieldFormat = timeFieldFormat
@@ -145,6 +159,20 @@ func TimestampFunc(timestampFunc func() time.Time) LoggerOption {
 	}
 }
 
+// UseEncoder update logger's encoder, used to encode events instead of the default JSONEncoder.
+// As context fields are encoded when they are added, it must be applied before the options
+// adding context fields. Processors and writers parsing the encoded events (redaction, queries,
+// relays...) expect JSON, and don't support other encodings; LogfmtEncoder builds the events as
+// JSON to be compatible with them.
+func UseEncoder(encoder Encoder) LoggerOption {
+	return func(logger *Logger) {
+		if encoder == nil {
+			encoder = JSONEncoder{}
+		}
+		logger.encoder = encoder
+	}
+}
+
 var (
 	// DurationFieldUnit defines the unit for time.Duration type fields added
 	// using the Duration method.
@@ -0,0 +1,119 @@
+package rz
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ConsoleStyle is the output stream and the color of a level on the console.
+type ConsoleStyle struct {
+	Writer io.Writer
+	// Color is an ANSI color (SGR code), e.g. 33 for yellow. It's used only if Writer is a
+	// terminal, 0 disables the color.
+	Color int
+}
+
+// DefaultConsoleStyles returns the common CLI conventions: debug and info events to stdout,
+// warnings to stderr in yellow and errors (and above) to stderr in red. Events without level
+// go to stdout.
+func DefaultConsoleStyles() map[LogLevel]ConsoleStyle {
+	return map[LogLevel]ConsoleStyle{
+		DebugLevel: {Writer: os.Stdout, Color: cMagenta},
+		InfoLevel:  {Writer: os.Stdout, Color: cCyan},
+		WarnLevel:  {Writer: os.Stderr, Color: cYellow},
+		ErrorLevel: {Writer: os.Stderr, Color: cRed},
+		FatalLevel: {Writer: os.Stderr, Color: cRed},
+		PanicLevel: {Writer: os.Stderr, Color: cRed},
+		NoLevel:    {Writer: os.Stdout},
+	}
+}
+
+// ConsoleStyles sets the logger's writer and formatter so that each level is written with the
+// console formatter to its stream, with its color. The levels without style are written to
+// os.Stdout without color.
+func ConsoleStyles(styles map[LogLevel]ConsoleStyle) LoggerOption {
+	writers := make(map[LogLevel]io.Writer, len(styles))
+	colors := make(map[LogLevel]int, len(styles))
+	for level, style := range styles {
+		writers[level] = style.Writer
+		if style.Color != 0 && IsTerminal(style.Writer) {
+			colors[level] = style.Color
+		}
+	}
+	router := LevelRouter(writers, os.Stdout)
+	formatter := FormatterConsoleColors(colors)
+	return func(logger *Logger) {
+		Writer(router)(logger)
+		logger.formatter = formatter
+	}
+}
+
+type levelRouter struct {
+	writers  map[LogLevel]LevelWriter
+	fallback LevelWriter
+}
+
+// LevelRouter returns a LevelWriter writing each event to the writer of its level, or to
+// fallback if its level has no writer.
+func LevelRouter(writers map[LogLevel]io.Writer, fallback io.Writer) LevelWriter {
+	toLevelWriter := func(w io.Writer) LevelWriter {
+		if lw, ok := w.(LevelWriter); ok {
+			return lw
+		}
+		return levelWriterAdapter{w}
+	}
+	r := levelRouter{writers: make(map[LogLevel]LevelWriter, len(writers)), fallback: toLevelWriter(fallback)}
+	for level, w := range writers {
+		if w != nil {
+			r.writers[level] = toLevelWriter(w)
+		}
+	}
+	return r
+}
+
+func (r levelRouter) writer(level LogLevel) LevelWriter {
+	if w, ok := r.writers[level]; ok {
+		return w
+	}
+	return r.fallback
+}
+
+// Write implements the io.Writer interface.
+func (r levelRouter) Write(p []byte) (n int, err error) {
+	return r.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (r levelRouter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	return r.writer(level).WriteLevel(level, p)
+}
+
+// Flush implements the Flusher interface.
+func (r levelRouter) Flush() (err error) {
+	if flushErr := flush(r.fallback); flushErr != nil {
+		err = flushErr
+	}
+	for _, w := range r.writers {
+		if flushErr := flush(w); flushErr != nil {
+			err = flushErr
+		}
+	}
+	return err
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (r levelRouter) DescribeConfig() string {
+	routes := make([]string, 0, len(r.writers)+1)
+	for _, level := range []LogLevel{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel, NoLevel} {
+		if w, ok := r.writers[level]; ok {
+			name := level.String()
+			if level == NoLevel {
+				name = "none"
+			}
+			routes = append(routes, name+": "+describe(w))
+		}
+	}
+	routes = append(routes, "default: "+describe(r.fallback))
+	return "level_router(" + strings.Join(routes, ", ") + ")"
+}
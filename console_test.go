@@ -0,0 +1,50 @@
+package rz
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestConsoleStyles(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(ConsoleStyles(map[LogLevel]ConsoleStyle{
+		InfoLevel: {Writer: stdout, Color: cCyan},
+		WarnLevel: {Writer: stderr, Color: cYellow},
+	}), Fields(Timestamp(false)))
+	log.Info("hello", String("foo", "bar"))
+	log.Warn("careful")
+
+	// buffers are not terminals: no colors
+	if got, want := stdout.String(), "                     |INFO| hello foo=bar\n"; got != want {
+		t.Errorf("invalid stdout:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := stderr.String(), "                     |WARN| careful\n"; got != want {
+		t.Errorf("invalid stderr:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestFormatterConsoleColors(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Formatter(FormatterConsoleColors(map[LogLevel]int{ErrorLevel: cRed})), Fields(Timestamp(false)))
+	log.Error("failed")
+	log.Info("ok")
+	lines := strings.Split(out.String(), "\n")
+	if got, want := lines[0], "                     |"+colorize("ERRO", cRed)+"| failed"; got != want {
+		t.Errorf("invalid colored line:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := lines[1], "                     |INFO| ok"; got != want {
+		t.Errorf("invalid line:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestLevelRouter(t *testing.T) {
+	errs, others := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Writer(LevelRouter(map[LogLevel]io.Writer{ErrorLevel: errs}, others)), Fields(Timestamp(false)))
+	log.Error("a")
+	log.Info("b")
+	if errs.String() != `{"level":"error","message":"a"}`+"\n" || others.String() != `{"level":"info","message":"b"}`+"\n" {
+		t.Errorf("invalid routing: %q, %q", errs.String(), others.String())
+	}
+}
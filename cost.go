@@ -0,0 +1,193 @@
+package rz
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CostOtherComponent is the component of the events without component field, or exceeding the
+// maximum number of components of a CostAccounter.
+const CostOtherComponent = "other"
+
+// CostBucket holds the volume of a set of events.
+type CostBucket struct {
+	Events uint64
+	Bytes  uint64
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface.
+func (b CostBucket) MarshalRzObject(e *Event) {
+	e.uint64("events", b.Events)
+	e.uint64("bytes", b.Bytes)
+}
+
+// CostStats holds the volume of the events accounted by a CostAccounter.
+type CostStats struct {
+	Total       CostBucket
+	ByLevel     map[LogLevel]CostBucket
+	ByComponent map[string]CostBucket
+}
+
+// CostAccounter is a LogProcessor accounting the encoded bytes of events bucketed by level and
+// by the value of a designated component field, so teams can attribute log storage cost.
+// Add it to a logger with the AccountCost option. It's safe for concurrent use.
+//
+// The bytes are the size of the encoded events, before any formatter is applied. The component is
+// the first string value of the component field found in the event. As the accounter runs once
+// the events are encoded, the component is only read with JSONEncoder: with the other encoders
+// (including LogfmtEncoder), all the events are accounted to the "other" component.
+type CostAccounter struct {
+	mu             sync.Mutex
+	componentField string
+	componentKey   []byte
+	maxComponents  int
+	total          CostBucket
+	byLevel        map[LogLevel]CostBucket
+	byComponent    map[string]CostBucket
+}
+
+// NewCostAccounter returns a CostAccounter bucketing events by the value of componentField,
+// tracking at most maxComponents (unbounded if <= 0) distinct components.
+func NewCostAccounter(componentField string, maxComponents int) *CostAccounter {
+	return &CostAccounter{
+		componentField: componentField,
+		componentKey:   fieldKey(componentField),
+		maxComponents:  maxComponents,
+		byLevel:        map[LogLevel]CostBucket{},
+		byComponent:    map[string]CostBucket{},
+	}
+}
+
+// AccountCost appends accounter to the encode stage of logger's pipeline.
+func AccountCost(accounter *CostAccounter) LoggerOption {
+	return AddProcessor(EncodeStage, accounter)
+}
+
+// Process implements the LogProcessor interface.
+func (c *CostAccounter) Process(e *Event, level LogLevel, message string) {
+	size := uint64(len(e.buf))
+	component := CostOtherComponent
+	if jsonEncoded(e.encoder) {
+		component = c.component(e.buf)
+	}
+
+	c.mu.Lock()
+	c.total.add(size)
+	b := c.byLevel[level]
+	b.add(size)
+	c.byLevel[level] = b
+	if _, ok := c.byComponent[component]; !ok && c.maxComponents > 0 {
+		components := len(c.byComponent)
+		if _, ok := c.byComponent[CostOtherComponent]; ok {
+			components--
+		}
+		if components >= c.maxComponents {
+			component = CostOtherComponent
+		}
+	}
+	b = c.byComponent[component]
+	b.add(size)
+	c.byComponent[component] = b
+	c.mu.Unlock()
+}
+
+func (b *CostBucket) add(size uint64) {
+	b.Events++
+	b.Bytes += size
+}
+
+// component extracts the value of the component field from the encoded event.
+func (c *CostAccounter) component(buf []byte) string {
+	if value, ok := stringFieldValue(buf, c.componentKey); ok {
+		return value
+	}
+	return CostOtherComponent
+}
+
+// Stats returns the volume of the accounted events.
+func (c *CostAccounter) Stats() CostStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := CostStats{
+		Total:       c.total,
+		ByLevel:     make(map[LogLevel]CostBucket, len(c.byLevel)),
+		ByComponent: make(map[string]CostBucket, len(c.byComponent)),
+	}
+	for level, b := range c.byLevel {
+		stats.ByLevel[level] = b
+	}
+	for component, b := range c.byComponent {
+		stats.ByComponent[component] = b
+	}
+	return stats
+}
+
+// Reset resets the accounted volumes.
+func (c *CostAccounter) Reset() {
+	c.swap()
+}
+
+// swap resets the accounted volumes and returns their previous values, so that no event is
+// lost between reading and resetting them.
+func (c *CostAccounter) swap() CostStats {
+	c.mu.Lock()
+	stats := CostStats{Total: c.total, ByLevel: c.byLevel, ByComponent: c.byComponent}
+	c.total = CostBucket{}
+	c.byLevel = map[LogLevel]CostBucket{}
+	c.byComponent = map[string]CostBucket{}
+	c.mu.Unlock()
+	return stats
+}
+
+// LogStats logs a summary of the accounted volumes with info level, under the "log_cost" key.
+func (c *CostAccounter) LogStats(logger *Logger) {
+	logCostStats(logger, c.Stats())
+}
+
+func logCostStats(logger *Logger, stats CostStats) {
+	logger.Info("log cost", func(e *Event) {
+		e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "log_cost"))
+		e.object("total", stats.Total)
+
+		levels := make([]int, 0, len(stats.ByLevel))
+		for level := range stats.ByLevel {
+			levels = append(levels, int(level))
+		}
+		sort.Ints(levels)
+		e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "by_level"))
+		for _, level := range levels {
+			name := LogLevel(level).String()
+			if name == "" {
+				name = "none"
+			}
+			e.object(name, stats.ByLevel[LogLevel(level)])
+		}
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+
+		components := make([]string, 0, len(stats.ByComponent))
+		for component := range stats.ByComponent {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+		e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "by_component"))
+		for _, component := range components {
+			e.object(component, stats.ByComponent[component])
+		}
+		e.buf = e.encoder.AppendEndMarker(e.encoder.AppendEndMarker(e.buf))
+	})
+}
+
+// Report logs a summary of the accounted volumes like LogStats every interval, and resets them
+// after each report. The returned function stops the reports.
+func (c *CostAccounter) Report(logger Logger, interval time.Duration) (stop func()) {
+	return report(interval, func() {
+		logCostStats(&logger, c.swap())
+	})
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (c *CostAccounter) DescribeConfig() string {
+	return "cost(" + strconv.Quote(c.componentField) + ")"
+}
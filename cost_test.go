@@ -0,0 +1,84 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCostAccounter(t *testing.T) {
+	accounter := NewCostAccounter("component", 2)
+	log := New(Writer(&bytes.Buffer{}), Fields(Timestamp(false)), AccountCost(accounter))
+
+	db := log.With(Fields(String("component", "db")))
+	db.Info("query")
+	// the nested component is ignored
+	db.Error("failed", Dict("ctx", log.NewDict(String("component", "nested"))))
+	log.Warn("no component", String("x_component", "x"))
+	log.Info("escaped", String("component", `a"b`))
+	log.Info("overflow", String("component", "c"))
+
+	stats := accounter.Stats()
+	if stats.Total.Events != 5 {
+		t.Errorf("invalid total: %+v", stats.Total)
+	}
+	if got := stats.ByLevel[InfoLevel].Events; got != 3 {
+		t.Errorf("invalid info events: %d", got)
+	}
+	if got, want := stats.ByComponent["db"], (CostBucket{Events: 2, Bytes: uint64(len(`{"level":"info","component":"db","message":"query"}`+"\n") + len(`{"level":"error","component":"db","ctx":{"component":"nested"},"message":"failed"}`+"\n"))}); got != want {
+		t.Errorf("invalid db bucket: got %+v, want %+v", got, want)
+	}
+	if got := stats.ByComponent[`a"b`].Events; got != 1 {
+		t.Errorf("invalid escaped component events: %d", got)
+	}
+	if got := stats.ByComponent[CostOtherComponent].Events; got != 2 {
+		t.Errorf("invalid other component events: %d", got)
+	}
+
+	out := &bytes.Buffer{}
+	accounter.Reset()
+	db.Info("query")
+	l := New(Writer(out), Fields(Timestamp(false)))
+	accounter.LogStats(&l)
+	want := `{"level":"info","log_cost":{"total":{"events":1,"bytes":52},"by_level":{"info":{"events":1,"bytes":52}},"by_component":{"db":{"events":1,"bytes":52}}},"message":"log cost"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestCostAccounterReport(t *testing.T) {
+	accounter := NewCostAccounter("component", 0)
+	log := New(Writer(&bytes.Buffer{}), Fields(Timestamp(false)), AccountCost(accounter))
+	out := &syncBuffer{}
+	stop := accounter.Report(New(Writer(out), Fields(Timestamp(false))), time.Millisecond)
+
+	const events = 2000
+	for i := 0; i < events; i++ {
+		log.Info("query")
+		if i%100 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	stop()
+	stop()
+
+	total := accounter.Stats().Total.Events
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var report struct {
+			LogCost struct {
+				Total struct {
+					Events uint64 `json:"events"`
+				} `json:"total"`
+			} `json:"log_cost"`
+		}
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			t.Fatalf("invalid report %q: %v", line, err)
+		}
+		total += report.LogCost.Total.Events
+	}
+	if total != events {
+		t.Errorf("reported and remaining events = %d, want %d", total, events)
+	}
+}
@@ -14,15 +14,46 @@ func (l *Logger) ToCtx(ctx context.Context) context.Context {
 			// Do not store same logger.
 			return ctx
 		}
-	} else if l.level == Disabled {
+	} else if l.currentLevel() == Disabled {
 		// Do not store disabled logger.
 		return ctx
 	}
 	return context.WithValue(ctx, ctxKey{}, l)
 }
 
+// ToCtx returns a copy of ctx with logger associated, like logger.ToCtx(ctx).
+func ToCtx(ctx context.Context, logger *Logger) context.Context {
+	return logger.ToCtx(ctx)
+}
+
+// WithCtxFields returns a copy of ctx associated with a copy of its logger with the fields added
+// to its context, so the request-scoped fields (user ID, tenant...) are added once and logged by
+// all the functions using FromCtx down the call stack:
+//
+//     ctx = rz.WithCtxFields(ctx, rz.String("user_id", userID))
+//     rz.FromCtx(ctx).Info("order created")
+//
+// ctx is returned as is if it has no logger.
+func WithCtxFields(ctx context.Context, fields ...Field) context.Context {
+	l, ok := LoggerFromCtx(ctx)
+	if !ok || len(fields) == 0 {
+		return ctx
+	}
+	logger := l.With(Fields(fields...))
+	return logger.ToCtx(ctx)
+}
+
+// LoggerFromCtx returns the Logger associated with ctx, or false if there is none, unlike
+// FromCtx which returns a fallback logger. The level override of ctx is not applied.
+func LoggerFromCtx(ctx context.Context) (*Logger, bool) {
+	l, ok := ctx.Value(ctxKey{}).(*Logger)
+	return l, ok
+}
+
 // FromCtx returns the Logger associated with the ctx. If no logger
 // is associated, a New() logger is returned with a addedfield "rz.FromCtx": "error".
+// If ctx holds a level override (see WithLevelOverride), a copy of the logger applying it is
+// returned.
 //
 // For example, to add a field to an existing logger in the context, use this
 // notation:
@@ -32,8 +63,12 @@ func (l *Logger) ToCtx(ctx context.Context) context.Context {
 //     l.With(...)
 func FromCtx(ctx context.Context) *Logger {
 	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		if _, override := LevelOverride(ctx); override {
+			logger := l.ForCtx(ctx)
+			return &logger
+		}
 		return l
 	}
-	logger := New().With(Fields(String("rz.FromCtx", "error")))
+	logger := New().With(Fields(String("rz.FromCtx", "error"))).ForCtx(ctx)
 	return &logger
 }
@@ -0,0 +1,35 @@
+package rz
+
+import (
+	"context"
+)
+
+type levelOverrideCtxKey struct{}
+
+// WithLevelOverride returns a copy of ctx overriding the level of the loggers retrieved with
+// FromCtx or Logger.ForCtx. It's intended to debug a single request in production: a middleware
+// validating a debug flag of the request (see rzhttp.DebugOverride) stores DebugLevel in the
+// request context, and the request then logs at debug level regardless of the configured level.
+func WithLevelOverride(ctx context.Context, level LogLevel) context.Context {
+	return context.WithValue(ctx, levelOverrideCtxKey{}, level)
+}
+
+// LevelOverride returns the level override stored in ctx by WithLevelOverride.
+func LevelOverride(ctx context.Context) (LogLevel, bool) {
+	level, ok := ctx.Value(levelOverrideCtxKey{}).(LogLevel)
+	return level, ok
+}
+
+// ForCtx returns a copy of the logger applying the level override of ctx, if any. The sampler of
+// the returned logger is removed so no event of the overridden request is dropped.
+// Disabled loggers stay disabled.
+func (l Logger) ForCtx(ctx context.Context) Logger {
+	level, ok := LevelOverride(ctx)
+	if !ok || l.currentLevel() == Disabled {
+		return l
+	}
+	l.level = level
+	l.atomicLevel = nil
+	l.sampler = nil
+	return l
+}
@@ -1,6 +1,7 @@
 package rz
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
 	"testing"
@@ -55,3 +56,61 @@ func TestFromCtxDisabled(t *testing.T) {
 		t.Error("ToCtx did not overide logger with a disabled logger")
 	}
 }
+
+func TestWithCtxFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false), String("service", "api")))
+	ctx := ToCtx(context.Background(), &log)
+	if FromCtx(ctx) != &log {
+		t.Error("ToCtx did not store logger")
+	}
+
+	ctx = WithCtxFields(ctx, String("user_id", "42"))
+	ctx = WithCtxFields(ctx, String("tenant", "acme"))
+	FromCtx(ctx).Info("order created")
+	log.Info("unchanged")
+
+	want := `{"level":"info","service":"api","user_id":"42","tenant":"acme","message":"order created"}` + "\n" +
+		`{"level":"info","service":"api","message":"unchanged"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if _, ok := LoggerFromCtx(context.Background()); ok {
+		t.Error("LoggerFromCtx found a logger in an empty context")
+	}
+	if l, ok := LoggerFromCtx(ctx); !ok || l == &log {
+		t.Error("LoggerFromCtx did not return the copied logger")
+	}
+	if WithCtxFields(context.Background(), String("user_id", "42")) != context.Background() {
+		t.Error("WithCtxFields updated a context without logger")
+	}
+}
+
+func TestLevelOverride(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Level(WarnLevel), Sampler(&SamplerBasic{N: 1000}), Fields(Timestamp(false)))
+	ctx := log.ToCtx(context.Background())
+
+	FromCtx(ctx).Debug("dropped")
+	debugCtx := WithLevelOverride(ctx, DebugLevel)
+	if level, ok := LevelOverride(debugCtx); !ok || level != DebugLevel {
+		t.Errorf("invalid level override: %v %v", level, ok)
+	}
+	FromCtx(debugCtx).Debug("debug")
+	FromCtx(debugCtx).Debug("sampled")
+	debugLog := log.ForCtx(debugCtx)
+	debugLog.Info("info")
+	log.Info("dropped")
+
+	if got, want := out.String(), `{"level":"debug","message":"debug"}`+"\n"+
+		`{"level":"debug","message":"sampled"}`+"\n"+
+		`{"level":"info","message":"info"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	nop := Nop().ForCtx(debugCtx)
+	if nop.GetLevel() != Disabled {
+		t.Error("ForCtx enabled a disabled logger")
+	}
+}
@@ -0,0 +1,142 @@
+package rz
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RepeatedFieldName is the field name used for the number of events suppressed by a Deduplicator.
+const RepeatedFieldName = "repeated"
+
+// Deduplicator is a LogProcessor suppressing the identical events (same level, message and
+// fields) logged within a window after a first occurrence, so a crash loop or a failing
+// dependency doesn't flood the storage. The first occurrence is written, then once the window
+// ends, a copy of it with the number of suppressed events in the "repeated" field is written if
+// any was suppressed. Add it to a logger with the Deduplicate option. It's safe for concurrent use.
+//
+// The timestamps are not compared: they are added once the pipeline ran. The events are compared
+// with a FNV-1a hash of their payload: the keys never leave the process, so HashFunc is not used.
+type Deduplicator struct {
+	window  time.Duration
+	maxKeys int
+
+	mu        sync.Mutex
+	events    map[uint64]*dedupEntry
+	lastSweep time.Time
+
+	now func() time.Time // for the tests
+}
+
+type dedupEntry struct {
+	expiresAt time.Time
+	repeated  uint64
+	summary   *Event // copy of the first occurrence, nil until an event is suppressed
+	message   string
+	timer     *time.Timer
+}
+
+// NewDeduplicator returns a Deduplicator suppressing the identical events within window,
+// tracking at most maxKeys distinct events (unbounded if <= 0). Other events are not deduplicated.
+// The expired events are forgotten once per window.
+func NewDeduplicator(window time.Duration, maxKeys int) *Deduplicator {
+	return &Deduplicator{window: window, maxKeys: maxKeys, events: map[uint64]*dedupEntry{}}
+}
+
+// Deduplicate appends deduplicator to the sample stage of logger's pipeline.
+func Deduplicate(deduplicator *Deduplicator) LoggerOption {
+	return AddProcessor(SampleStage, deduplicator)
+}
+
+// Process implements the LogProcessor interface.
+func (d *Deduplicator) Process(e *Event, level LogLevel, message string) {
+	h := fnv.New64a()
+	h.Write(e.buf)
+	h.Write([]byte{0})
+	h.Write([]byte(message))
+	key := h.Sum64()
+	now := time.Now()
+	if d.now != nil {
+		now = d.now()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if now.Sub(d.lastSweep) >= d.window {
+		d.removeExpired(now)
+		d.lastSweep = now
+	}
+	entry, ok := d.events[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		if ok && entry.summary != nil {
+			// the timer of the summary is firing
+			return
+		}
+		if !ok && d.maxKeys > 0 && len(d.events) >= d.maxKeys {
+			return
+		}
+		d.events[key] = &dedupEntry{expiresAt: now.Add(d.window)}
+		return
+	}
+
+	entry.repeated++
+	if entry.summary == nil {
+		summary := e.derive(level)
+		summary.buf = append(summary.buf[:0], e.buf...)
+		summary.levelStart, summary.levelEnd = e.levelStart, e.levelEnd
+		// the summary is written after the window, once the context of the event is usually done
+		summary.ctx = nil
+		entry.summary = summary
+		entry.message = message
+		entry.timer = time.AfterFunc(entry.expiresAt.Sub(now), func() {
+			d.writeSummary(key, entry)
+		})
+	}
+	e.discard()
+}
+
+// removeExpired removes the expired entries without suppressed events.
+func (d *Deduplicator) removeExpired(now time.Time) {
+	for key, entry := range d.events {
+		if entry.summary == nil && !now.Before(entry.expiresAt) {
+			delete(d.events, key)
+		}
+	}
+}
+
+func (d *Deduplicator) writeSummary(key uint64, entry *dedupEntry) {
+	d.mu.Lock()
+	if d.events[key] != entry {
+		// already written by Flush
+		d.mu.Unlock()
+		return
+	}
+	delete(d.events, key)
+	d.mu.Unlock()
+	entry.summary.uint64(RepeatedFieldName, entry.repeated)
+	writeEvent(entry.summary, entry.message, nil, false)
+}
+
+// Flush writes the summaries of the events suppressed in the current windows, e.g. before the
+// program exits.
+func (d *Deduplicator) Flush() {
+	d.mu.Lock()
+	var pending []*dedupEntry
+	for key, entry := range d.events {
+		if entry.summary != nil && entry.timer.Stop() {
+			pending = append(pending, entry)
+			delete(d.events, key)
+		}
+	}
+	d.mu.Unlock()
+	for _, entry := range pending {
+		entry.summary.uint64(RepeatedFieldName, entry.repeated)
+		writeEvent(entry.summary, entry.message, nil, false)
+	}
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (d *Deduplicator) DescribeConfig() string {
+	return "deduplicator(" + d.window.String() + ", " + strconv.Itoa(d.maxKeys) + ")"
+}
@@ -0,0 +1,116 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDeduplicator(t *testing.T) {
+	out := &syncBuffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), Deduplicate(NewDeduplicator(50*time.Millisecond, 0)))
+
+	for i := 0; i < 5; i++ {
+		log.Error("connection refused", String("host", "db"))
+	}
+	log.Error("connection refused", String("host", "cache"))
+	log.Warn("connection refused", String("host", "db"))
+	time.Sleep(100 * time.Millisecond)
+	log.Error("connection refused", String("host", "db"))
+
+	want := `{"level":"error","host":"db","message":"connection refused"}` + "\n" +
+		`{"level":"error","host":"cache","message":"connection refused"}` + "\n" +
+		`{"level":"warning","host":"db","message":"connection refused"}` + "\n" +
+		`{"level":"error","host":"db","repeated":4,"message":"connection refused"}` + "\n" +
+		`{"level":"error","host":"db","message":"connection refused"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDeduplicatorFlush(t *testing.T) {
+	out := &syncBuffer{}
+	dedup := NewDeduplicator(time.Hour, 1)
+	log := New(Writer(out), Fields(Timestamp(false)), Deduplicate(dedup))
+
+	log.Info("tick")
+	log.Info("tick")
+	log.Info("tock")
+	log.Info("tock")
+	dedup.Flush()
+	dedup.Flush()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		`{"level":"info","message":"tick"}`,
+		// maxKeys reached: not deduplicated
+		`{"level":"info","message":"tock"}`,
+		`{"level":"info","message":"tock"}`,
+		`{"level":"info","repeated":1,"message":"tick"}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", lines, want)
+	}
+	if got, want := dedup.DescribeConfig(), "deduplicator(1h0m0s, 1)"; got != want {
+		t.Errorf("DescribeConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestDeduplicatorEviction(t *testing.T) {
+	now := time.Now()
+	dedup := NewDeduplicator(time.Minute, 0)
+	dedup.now = func() time.Time { return now }
+	log := New(Writer(&syncBuffer{}), Deduplicate(dedup))
+
+	log.Info("a")
+	log.Info("b")
+	log.Info("c")
+	if got := len(dedup.events); got != 3 {
+		t.Fatalf("len(events) = %d, want 3", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	log.Info("d")
+	if got := len(dedup.events); got != 1 {
+		t.Errorf("len(events) = %d after the window, want 1", got)
+	}
+}
+
+func TestDeduplicatorContext(t *testing.T) {
+	out := &ctxWriter{}
+	dedup := NewDeduplicator(time.Hour, 0)
+	log := New(Writer(out), Fields(Timestamp(false)), Deduplicate(dedup))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	log.Info("tick", Ctx(ctx))
+	log.Info("tick", Ctx(ctx))
+	// the request is over when the summary is written
+	cancel()
+	dedup.Flush()
+
+	want := `{"level":"info","message":"tick"}` + "\n" +
+		`{"level":"info","repeated":1,"message":"tick"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -24,6 +24,9 @@ const (
 	// DefaultErrorStackFieldName is the default field name used for error stacks.
 	DefaultErrorStackFieldName = "stack"
 
+	// DefaultModuleFieldName is the default field name used for the module field added by ForModule.
+	DefaultModuleFieldName = "module"
+
 	// DefaultTimeFieldFormat defines the time format of the Time field type.
 	// If set to an empty string, the time is formatted as an UNIX timestamp
 	// as integer.
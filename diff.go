@@ -0,0 +1,140 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// diffChange is a changed path of a diff.
+type diffChange struct {
+	path     string
+	old, new interface{}
+	hasOld   bool
+	hasNew   bool
+}
+
+// Diff adds the field key with a compact structural diff between old and new, as an array of
+// changed paths with their old and new values:
+//
+//     [{"path":"limits.cpu","old":1,"new":2},{"path":"tags[1]","new":"beta"}]
+//
+// "old" is omitted for added paths and "new" for removed ones. Values are compared using their
+// JSON representation, so old and new don't need to be of the same type.
+// It's intended for config-change and state-transition logging, instead of dumping both
+// full objects.
+func Diff(key string, old, new interface{}) Field {
+	return func(e *Event) {
+		e.diff(key, old, new)
+	}
+}
+
+func (e *Event) diff(key string, old, new interface{}) {
+	oldValue, err := toJSONValue(old)
+	if err != nil {
+		e.error(key, err)
+		return
+	}
+	newValue, err := toJSONValue(new)
+	if err != nil {
+		e.error(key, err)
+		return
+	}
+
+	changes := diffValues(nil, "", oldValue, newValue, true, true)
+	e.buf = e.encoder.AppendArrayStart(e.encoder.AppendKey(e.buf, key))
+	for i, change := range changes {
+		if i > 0 {
+			e.buf = e.encoder.AppendArrayDelim(e.buf)
+		}
+		e.buf = e.encoder.AppendBeginMarker(e.buf)
+		e.buf = e.encoder.AppendString(e.encoder.AppendKey(e.buf, "path"), change.path)
+		if change.hasOld {
+			e.buf = e.encoder.AppendInterface(e.encoder.AppendKey(e.buf, "old"), change.old)
+		}
+		if change.hasNew {
+			e.buf = e.encoder.AppendInterface(e.encoder.AppendKey(e.buf, "new"), change.new)
+		}
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+	}
+	e.buf = e.encoder.AppendArrayEnd(e.buf)
+}
+
+// toJSONValue converts v to its generic JSON representation.
+func toJSONValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var ret interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&ret)
+	return ret, err
+}
+
+func diffValues(changes []diffChange, path string, old, new interface{}, hasOld, hasNew bool) []diffChange {
+	if hasOld && hasNew {
+		switch o := old.(type) {
+		case map[string]interface{}:
+			if n, ok := new.(map[string]interface{}); ok {
+				return diffMaps(changes, path, o, n)
+			}
+		case []interface{}:
+			if n, ok := new.([]interface{}); ok {
+				return diffSlices(changes, path, o, n)
+			}
+		default:
+			if o == new {
+				return changes
+			}
+		}
+	}
+	if path == "" {
+		path = "."
+	}
+	return append(changes, diffChange{path: path, old: old, new: new, hasOld: hasOld, hasNew: hasNew})
+}
+
+func diffMaps(changes []diffChange, path string, old, new map[string]interface{}) []diffChange {
+	keys := make([]string, 0, len(old)+len(new))
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		o, hasOld := old[key]
+		n, hasNew := new[key]
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		changes = diffValues(changes, keyPath, o, n, hasOld, hasNew)
+	}
+	return changes
+}
+
+func diffSlices(changes []diffChange, path string, old, new []interface{}) []diffChange {
+	length := len(old)
+	if len(new) > length {
+		length = len(new)
+	}
+	for i := 0; i < length; i++ {
+		var o, n interface{}
+		hasOld, hasNew := i < len(old), i < len(new)
+		if hasOld {
+			o = old[i]
+		}
+		if hasNew {
+			n = new[i]
+		}
+		changes = diffValues(changes, path+"["+strconv.Itoa(i)+"]", o, n, hasOld, hasNew)
+	}
+	return changes
+}
@@ -0,0 +1,30 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type limits struct {
+		CPU    int `json:"cpu"`
+		Memory int `json:"memory"`
+	}
+	type config struct {
+		Name   string            `json:"name"`
+		Limits limits            `json:"limits"`
+		Tags   []string          `json:"tags"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+	old := config{Name: "api", Limits: limits{CPU: 1, Memory: 512}, Tags: []string{"a", "b"}, Labels: map[string]string{"team": "core"}}
+	new := config{Name: "api", Limits: limits{CPU: 2, Memory: 512}, Tags: []string{"a", "c", "d"}}
+
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	log.Log("", Diff("diff", old, new), Diff("same", 1, 1), Diff("root", "a", 2))
+
+	want := `{"diff":[{"path":"labels","old":{"team":"core"}},{"path":"limits.cpu","old":1,"new":2},{"path":"tags[1]","old":"b","new":"c"},{"path":"tags[2]","new":"d"}],"same":[],"root":[{"path":".","old":"a","new":2}]}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
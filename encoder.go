@@ -5,7 +5,14 @@ import (
 	"time"
 )
 
-// Encoder is used to serialize an object to be logged
+// Encoder is used to serialize an object to be logged. Each method appends the encoding of
+// its arguments to dst and returns the extended buffer. Implement it to log with another
+// encoding than JSON (see UseEncoder and JSONEncoder); it's a stable API.
+//
+// An event is encoded as AppendBeginMarker, then AppendKey followed by a value method for each
+// field, and AppendEndMarker and AppendLineBreak once complete. AppendKey is responsible for the
+// separators between fields, and AppendArrayDelim for the ones between array elements.
+// AppendObjectData appends the already encoded fields o (e.g. the logger's context) to an object.
 type Encoder interface {
 	AppendArrayDelim(dst []byte) []byte
 	AppendArrayEnd(dst []byte) []byte
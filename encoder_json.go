@@ -4,17 +4,47 @@ package rz
 // JSON encoded byte stream.
 
 import (
+	stdjson "encoding/json"
+
 	"github.com/skerkour/rz/internal/json"
 )
 
+// JSONEncoder is the default Encoder of loggers, encoding events as JSON. Custom encoders can
+// embed it to only override some of its methods.
+type JSONEncoder = json.Encoder
+
 var (
 	_ Encoder = (*json.Encoder)(nil)
 
 	enc = json.Encoder{}
 )
 
-func appendJSON(dst []byte, j []byte) []byte {
-	return append(dst, j...)
+// jsonBased returns true if encoder builds JSON, into which JSON values can be appended as is,
+// and whose fields can be read and rewritten by the hooks and processors (Event.SetLevel,
+// Event.Has...). LogfmtEncoder builds JSON up to AppendLineBreak, where it's converted to
+// logfmt: from the EncodeStage, use jsonEncoded.
+func jsonBased(encoder Encoder) bool {
+	switch encoder.(type) {
+	case json.Encoder, LogfmtEncoder:
+		return true
+	}
+	return false
+}
+
+// jsonEncoded returns true if the complete events of encoder, as seen from the EncodeStage of the
+// pipeline, are JSON.
+func jsonEncoded(encoder Encoder) bool {
+	_, ok := encoder.(json.Encoder)
+	return ok
+}
+
+// appendJSON appends the JSON encoded value j with encoder: as is with the JSON based encoders,
+// through AppendInterface with the other ones.
+func appendJSON(encoder Encoder, dst []byte, j []byte) []byte {
+	if jsonBased(encoder) {
+		return append(dst, j...)
+	}
+	return encoder.AppendInterface(dst, stdjson.RawMessage(j))
 }
 
 func decodeIfBinaryToString(in []byte) string {
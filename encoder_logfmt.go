@@ -0,0 +1,222 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// LogfmtEncoder is an Encoder writing the events as logfmt lines (key=value pairs separated by
+// spaces), for the pipelines consuming logfmt (Heroku, Grafana agent...):
+//
+//     log := rz.New(rz.UseEncoder(rz.LogfmtEncoder{}))
+//     log.Info("hello", rz.Int("status", 200), rz.Dict("user", log.NewDict(rz.String("name", "John Doe"))))
+//     // level=info status=200 user.name="John Doe" timestamp=2019-02-07T09:45:33Z message=hello
+//
+// Events are built as JSON and converted once complete, by AppendLineBreak, so the hooks and
+// processors up to the transform stage handle them as usual; the encode stage processors,
+// formatters and writers get logfmt. Fields keep their order, nested objects are flattened with
+// dotted keys and arrays are written as quoted JSON. Strings are quoted when they contain spaces,
+// '=', quotes, backslashes or control characters.
+type LogfmtEncoder struct {
+	JSONEncoder
+}
+
+var logfmtPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 500)
+		return &b
+	},
+}
+
+// AppendLineBreak converts the JSON encoded event dst to logfmt and appends a line break. If dst
+// is not a valid JSON object (e.g. after an invalid RawJSON field), it's left unchanged.
+func (e LogfmtEncoder) AppendLineBreak(dst []byte) []byte {
+	buf := logfmtPool.Get().(*[]byte)
+	out, i, ok := appendLogfmtObject((*buf)[:0], dst, skipSpaces(dst, 0), nil)
+	if ok && skipSpaces(dst, i) == len(dst) {
+		dst = append(dst[:0], out...)
+	}
+	if cap(out) <= 1<<16 {
+		*buf = out
+		logfmtPool.Put(buf)
+	}
+	return append(dst, '\n')
+}
+
+// appendLogfmtObject appends the fields of the JSON object starting at src[i] to dst, their keys
+// prefixed with prefix. It returns the index following the object.
+func appendLogfmtObject(dst, src []byte, i int, prefix []byte) ([]byte, int, bool) {
+	if i >= len(src) || src[i] != '{' {
+		return dst, i, false
+	}
+	i = skipSpaces(src, i+1)
+	if i < len(src) && src[i] == '}' {
+		return dst, i + 1, true
+	}
+	for i < len(src) {
+		if src[i] != '"' {
+			return dst, i, false
+		}
+		end, ok := skipJSONValue(src, i)
+		if !ok {
+			return dst, i, false
+		}
+		key, ok := jsonString(src[i:end])
+		if !ok {
+			return dst, i, false
+		}
+		i = skipSpaces(src, end)
+		if i >= len(src) || src[i] != ':' {
+			return dst, i, false
+		}
+		i = skipSpaces(src, i+1)
+		if i >= len(src) {
+			return dst, i, false
+		}
+
+		if src[i] == '{' {
+			nested := append(appendLogfmtKey(prefix, key), '.')
+			if dst, i, ok = appendLogfmtObject(dst, src, i, nested); !ok {
+				return dst, i, false
+			}
+		} else {
+			if end, ok = skipJSONValue(src, i); !ok {
+				return dst, i, false
+			}
+			if len(dst) > 0 {
+				dst = append(dst, ' ')
+			}
+			dst = appendLogfmtKey(append(dst, prefix...), key)
+			dst = append(dst, '=')
+			if src[i] == '"' {
+				value, ok := jsonString(src[i:end])
+				if !ok {
+					return dst, i, false
+				}
+				dst = appendLogfmtValue(dst, value)
+			} else if src[i] == '[' {
+				dst = appendLogfmtValue(dst, src[i:end])
+			} else {
+				dst = append(dst, src[i:end]...)
+			}
+			i = end
+		}
+
+		i = skipSpaces(src, i)
+		if i < len(src) && src[i] == ',' {
+			i = skipSpaces(src, i+1)
+			continue
+		}
+		if i < len(src) && src[i] == '}' {
+			return dst, i + 1, true
+		}
+		return dst, i, false
+	}
+	return dst, i, false
+}
+
+// appendLogfmtKey appends key, replacing the characters not allowed in logfmt keys with '_'.
+func appendLogfmtKey(dst, key []byte) []byte {
+	if len(key) == 0 {
+		return append(dst, '_')
+	}
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendLogfmtValue appends value, quoted if needed.
+func appendLogfmtValue(dst, value []byte) []byte {
+	if len(value) == 0 {
+		return append(dst, '"', '"')
+	}
+	if !utf8.Valid(value) {
+		return strconv.AppendQuote(dst, string(value))
+	}
+	for _, c := range value {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return strconv.AppendQuote(dst, string(value))
+		}
+	}
+	return append(dst, value...)
+}
+
+// jsonString decodes the JSON string s.
+func jsonString(s []byte) ([]byte, bool) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return nil, false
+	}
+	if bytes.IndexByte(s, '\\') < 0 {
+		return s[1 : len(s)-1], true
+	}
+	var decoded string
+	if err := json.Unmarshal(s, &decoded); err != nil {
+		return nil, false
+	}
+	return []byte(decoded), true
+}
+
+// skipJSONValue returns the index following the JSON value starting at src[i].
+func skipJSONValue(src []byte, i int) (int, bool) {
+	if i >= len(src) {
+		return i, false
+	}
+	switch src[i] {
+	case '"':
+		for i++; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, true
+			}
+		}
+		return i, false
+	case '{', '[':
+		depth := 0
+		for i < len(src) {
+			switch src[i] {
+			case '"':
+				end, ok := skipJSONValue(src, i)
+				if !ok {
+					return end, false
+				}
+				i = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, true
+				}
+			}
+			i++
+		}
+		return i, false
+	}
+	start := i
+	for i < len(src) && isJSONScalarByte(src[i]) {
+		i++
+	}
+	return i, i > start
+}
+
+func isJSONScalarByte(c byte) bool {
+	return c != '"' && c != '{' && c != '[' && c != '}' && c != ']' && c != ',' && c != ':' &&
+		c != ' ' && c != '\t' && c != '\n' && c != '\r'
+}
+
+func skipSpaces(src []byte, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,62 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLogfmtEncoder(t *testing.T) {
+	out := &bytes.Buffer{}
+	ts := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	log := New(Writer(out), UseEncoder(LogfmtEncoder{}), TimestampFunc(func() time.Time { return ts }),
+		Fields(String("service", "api")))
+	log.Info("hello world",
+		Int("status", 200),
+		Bool("ok", true),
+		String("empty", ""),
+		String("query", `a=b "c"`),
+		String("bad key", "é"),
+		Object("point", point{1, 2}),
+		Dict("user", log.NewDict(String("name", "John"), Dict("address", log.NewDict(String("city", "Paris"))))),
+		Strings("tags", []string{"a", "b c"}),
+		Any("nil", nil),
+	)
+
+	want := `level=info service=api status=200 ok=true empty="" query="a=b \"c\"" bad_key=é point.x=1 point.y=2 ` +
+		`user.name=John user.address.city=Paris tags="[\"a\",\"b c\"]" nil=null timestamp=2001-02-03T04:05:06Z ` +
+		`message="hello world"` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestLogfmtEncoderInvalidJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), UseEncoder(LogfmtEncoder{}), Fields(Timestamp(false)))
+	log.Info("hello", RawJSON("raw", []byte(`{"unterminated`)))
+
+	want := `{"level":"info","raw":{"unterminated,"message":"hello"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestLogfmtEncoderProcessors(t *testing.T) {
+	out := &bytes.Buffer{}
+	accounter := NewCostAccounter("component", 0)
+	esc := NewEscalator(EscalationRule{Fields: map[string]string{"component": "db"}, Level: ErrorLevel})
+	log := New(Writer(out), UseEncoder(LogfmtEncoder{}), Fields(Timestamp(false)), EscalateLevels(esc),
+		AccountCost(accounter))
+	log.Info("failed", String("component", "db"))
+
+	// the events are JSON up to the encode stage
+	want := "level=error component=db original_level=info message=failed\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	// then logfmt
+	if got := accounter.Stats().ByComponent[CostOtherComponent].Events; got != 1 {
+		t.Errorf("invalid other component events: %d", got)
+	}
+}
@@ -0,0 +1,50 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upperKeysEncoder is a custom encoder uppercasing the keys of the JSON encoder.
+type upperKeysEncoder struct {
+	JSONEncoder
+}
+
+func (e upperKeysEncoder) AppendKey(dst []byte, key string) []byte {
+	return e.JSONEncoder.AppendKey(dst, strings.ToUpper(key))
+}
+
+type point struct{ x, y int }
+
+func (p point) MarshalRzObject(e *Event) {
+	e.int("x", p.x)
+	e.int("y", p.y)
+}
+
+func TestUseEncoder(t *testing.T) {
+	out := &bytes.Buffer{}
+	ts := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	log := New(Writer(out), UseEncoder(upperKeysEncoder{}), TimestampFunc(func() time.Time { return ts }),
+		Fields(String("service", "api")))
+	log.Info("hello",
+		Object("point", point{1, 2}),
+		Dict("dict", log.NewDict(Int("n", 1))),
+		Slice("points", []point{{3, 4}}),
+		Map(map[string]interface{}{"map": point{5, 6}}),
+	)
+
+	want := `{"LEVEL":"info","SERVICE":"api","POINT":{"X":1,"Y":2},"DICT":{"N":1},"POINTS":[{"X":3,"Y":4}],` +
+		`"MAP":{"X":5,"Y":6},"TIMESTAMP":"2001-02-03T04:05:06Z","MESSAGE":"hello"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = New(Writer(out), UseEncoder(nil), Fields(Timestamp(false)))
+	log.Info("hello")
+	if got, want := out.String(), `{"level":"info","message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
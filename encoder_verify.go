@@ -0,0 +1,187 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync/atomic"
+)
+
+// EncoderMismatchFieldName is the field name used for the mismatches found by an EncoderVerifier.
+const EncoderMismatchFieldName = "encoder_mismatch"
+
+// EncoderMismatch describes a value encoded differently by the candidate encoder of an
+// EncoderVerifier.
+type EncoderMismatch struct {
+	// Method is the name of the Encoder method.
+	Method string
+	// Want is the output of the reference encoder, Got the one of the candidate encoder.
+	Want []byte
+	Got  []byte
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface.
+func (m EncoderMismatch) MarshalRzObject(e *Event) {
+	e.string("method", m.Method)
+	e.string("want", string(m.Want))
+	e.string("got", string(m.Got))
+}
+
+// EncoderVerifier is a LogProcessor verifying a candidate Encoder (e.g. an optimized rewrite of the
+// JSON encoder) against rz's encoder on live traffic before rolling it out: each sampled event is
+// decoded, then each of its keys and values is encoded with both encoders and the outputs are
+// compared. Add it to a logger with the VerifyEncoder option. It's safe for concurrent use if the
+// candidate encoder is.
+//
+// When an event is encoded differently, an "encoder mismatch" event with warning level is logged
+// before it, with the first mismatch under the "encoder_mismatch" key and the number of
+// mismatching values of the event in the "mismatches" field.
+type EncoderVerifier struct {
+	candidate Encoder
+	sampler   LogSampler
+
+	events     uint64
+	mismatches uint64
+}
+
+// NewEncoderVerifier returns an EncoderVerifier for candidate. If sampler is nil, all the events
+// are verified.
+func NewEncoderVerifier(candidate Encoder, sampler LogSampler) *EncoderVerifier {
+	return &EncoderVerifier{candidate: candidate, sampler: sampler}
+}
+
+// VerifyEncoder appends verifier to the encode stage of logger's pipeline.
+func VerifyEncoder(verifier *EncoderVerifier) LoggerOption {
+	return AddProcessor(EncodeStage, verifier)
+}
+
+// Process implements the LogProcessor interface.
+func (v *EncoderVerifier) Process(e *Event, level LogLevel, message string) {
+	if v.sampler != nil && !v.sampler.Sample(level) {
+		return
+	}
+	mismatches, err := v.Verify(e.buf)
+	if err != nil {
+		return
+	}
+	atomic.AddUint64(&v.events, 1)
+	if len(mismatches) == 0 {
+		return
+	}
+	atomic.AddUint64(&v.mismatches, 1)
+	warning := e.derive(WarnLevel)
+	warning.object(EncoderMismatchFieldName, mismatches[0])
+	warning.int("mismatches", len(mismatches))
+	writeEvent(warning, "encoder mismatch", nil, false)
+}
+
+// Verify encodes the keys and values of the JSON encoded event with both encoders and returns
+// the values encoded differently. An error is returned if event is not valid JSON.
+// It can be used to verify a candidate encoder offline against captured logs.
+func (v *EncoderVerifier) Verify(event []byte) ([]EncoderMismatch, error) {
+	decoder := json.NewDecoder(bytes.NewReader(event))
+	decoder.UseNumber()
+
+	var want, got []byte
+	var mismatches []EncoderMismatch
+	check := func(method string, wantLen, gotLen int) {
+		if !bytes.Equal(want[wantLen:], got[gotLen:]) {
+			mismatches = append(mismatches, EncoderMismatch{
+				Method: method,
+				Want:   append([]byte(nil), want[wantLen:]...),
+				Got:    append([]byte(nil), got[gotLen:]...),
+			})
+			// resynchronize the candidate output so the next values are compared independently
+			got = append(got[:0], want...)
+		}
+	}
+
+	// containers holds the open objects and arrays, with the number of keys and values written in them
+	type container struct {
+		object bool
+		values int
+	}
+	containers := []container{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF && len(containers) == 0 {
+			return mismatches, nil
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		wantLen, gotLen := len(want), len(got)
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			containers = containers[:len(containers)-1]
+			if delim == '}' {
+				want, got = enc.AppendEndMarker(want), v.candidate.AppendEndMarker(got)
+				check("AppendEndMarker", wantLen, gotLen)
+			} else {
+				want, got = enc.AppendArrayEnd(want), v.candidate.AppendArrayEnd(got)
+				check("AppendArrayEnd", wantLen, gotLen)
+			}
+			continue
+		}
+
+		if n := len(containers); n > 0 {
+			current := &containers[n-1]
+			if current.object && current.values%2 == 0 {
+				key, _ := token.(string)
+				want, got = enc.AppendKey(want, key), v.candidate.AppendKey(got, key)
+				check("AppendKey", wantLen, gotLen)
+				current.values++
+				continue
+			}
+			if !current.object && current.values > 0 {
+				want, got = enc.AppendArrayDelim(want), v.candidate.AppendArrayDelim(got)
+				check("AppendArrayDelim", wantLen, gotLen)
+				wantLen, gotLen = len(want), len(got)
+			}
+			current.values++
+		}
+
+		switch value := token.(type) {
+		case json.Delim:
+			if value == '{' {
+				want, got = enc.AppendBeginMarker(want), v.candidate.AppendBeginMarker(got)
+				check("AppendBeginMarker", wantLen, gotLen)
+			} else {
+				want, got = enc.AppendArrayStart(want), v.candidate.AppendArrayStart(got)
+				check("AppendArrayStart", wantLen, gotLen)
+			}
+			containers = append(containers, container{object: value == '{'})
+		case string:
+			want, got = enc.AppendString(want, value), v.candidate.AppendString(got, value)
+			check("AppendString", wantLen, gotLen)
+		case json.Number:
+			if i, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+				want, got = enc.AppendInt64(want, i), v.candidate.AppendInt64(got, i)
+				check("AppendInt64", wantLen, gotLen)
+			} else if f, err := strconv.ParseFloat(string(value), 64); err == nil {
+				want, got = enc.AppendFloat64(want, f), v.candidate.AppendFloat64(got, f)
+				check("AppendFloat64", wantLen, gotLen)
+			}
+		case bool:
+			want, got = enc.AppendBool(want, value), v.candidate.AppendBool(got, value)
+			check("AppendBool", wantLen, gotLen)
+		case nil:
+			want, got = enc.AppendNil(want), v.candidate.AppendNil(got)
+			check("AppendNil", wantLen, gotLen)
+		}
+	}
+}
+
+// Stats returns the number of verified events, and of events encoded differently.
+func (v *EncoderVerifier) Stats() (events, mismatches uint64) {
+	return atomic.LoadUint64(&v.events), atomic.LoadUint64(&v.mismatches)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (v *EncoderVerifier) DescribeConfig() string {
+	return "verify_encoder(" + describe(v.candidate) + ")"
+}
@@ -0,0 +1,55 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/skerkour/rz/internal/json"
+)
+
+// asciiOnlyEncoder is a broken candidate encoder escaping all the non ASCII characters.
+type asciiOnlyEncoder struct {
+	json.Encoder
+}
+
+func (e asciiOnlyEncoder) AppendString(dst []byte, s string) []byte {
+	if strings.IndexFunc(s, func(r rune) bool { return r > 127 }) < 0 {
+		return e.Encoder.AppendString(dst, s)
+	}
+	dst = append(dst, '"')
+	for _, r := range s {
+		if r > 127 {
+			dst = append(dst, '?')
+		} else {
+			dst = append(dst, byte(r))
+		}
+	}
+	return append(dst, '"')
+}
+
+func TestEncoderVerifier(t *testing.T) {
+	out := &bytes.Buffer{}
+	verifier := NewEncoderVerifier(asciiOnlyEncoder{}, nil)
+	log := New(Writer(out), VerifyEncoder(verifier), Fields(Timestamp(false)))
+
+	log.Info("hello", Int("n", 42), Float64("f", 1.5), Strings("tags", []string{"a", "b"}), Dict("d", log.NewDict(Bool("ok", true))))
+	if got, want := out.String(), `{"level":"info","n":42,"f":1.5,"tags":["a","b"],"d":{"ok":true},"message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log.Info("héllo", String("name", "zoé"), Any("nil", nil))
+	want := `{"level":"warning","encoder_mismatch":{"method":"AppendString","want":"\"zoé\"","got":"\"zo?\""},"mismatches":2,"message":"encoder mismatch"}` + "\n" +
+		`{"level":"info","name":"zoé","nil":null,"message":"héllo"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if events, mismatches := verifier.Stats(); events != 2 || mismatches != 1 {
+		t.Errorf("invalid stats: %d %d", events, mismatches)
+	}
+	if _, err := verifier.Verify([]byte(`{"a":`)); err == nil {
+		t.Error("invalid JSON is verified")
+	}
+}
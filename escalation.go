@@ -0,0 +1,138 @@
+package rz
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// OriginalLevelFieldName is the field name used by Escalator for the level of an event before it
+// was changed by a rule.
+const OriginalLevelFieldName = "original_level"
+
+// EscalationRule changes the level of the matching events. All the conditions of a rule must
+// match for the rule to apply.
+type EscalationRule struct {
+	// Message matches the events whose message contains it. If empty, all messages match.
+	Message string
+	// Fields matches the events having all these string fields with the given values.
+	Fields map[string]string
+	// Levels matches the events with one of these levels. If empty, all levels match.
+	Levels []LogLevel
+	// Match is an optional custom condition.
+	Match func(e *Event, level LogLevel, message string) bool
+	// Count is the number of matching events within Window from which the rule applies, e.g.
+	// the third "connection refused" within a minute. If <= 1, the rule applies to every matching
+	// event. If Window is <= 0, the matching events are counted without time limit.
+	Count  int
+	Window time.Duration
+	// Level is the new level of the events.
+	Level LogLevel
+}
+
+type escalationRule struct {
+	EscalationRule
+	fields map[string][]byte
+
+	mu   sync.Mutex
+	hits []time.Time // ring of the last Count matches
+	next int
+	n    int
+}
+
+// Escalator is a LogProcessor escalating or demoting the level of events before they are
+// written, according to a list of rules, so noisy levels can be tuned in one place.
+// Add it to a logger with the EscalateLevels option. It's safe for concurrent use.
+//
+// The first applicable rule wins. The field "original_level" is added to the events whose level
+// was changed. Hooks see the original level, and since the logger's level is checked before,
+// a demoted event may be below it.
+//
+// The Fields conditions and the level field rewrite require a JSON based encoder (JSONEncoder or
+// LogfmtEncoder): with the other encoders, rules with Fields never match, and only the level
+// passed to the writers changes.
+type Escalator struct {
+	rules []*escalationRule
+	now   func() time.Time
+}
+
+// NewEscalator returns an Escalator applying rules, in order.
+func NewEscalator(rules ...EscalationRule) *Escalator {
+	esc := &Escalator{now: time.Now}
+	for _, rule := range rules {
+		r := &escalationRule{EscalationRule: rule, fields: make(map[string][]byte, len(rule.Fields))}
+		for key := range rule.Fields {
+			r.fields[key] = fieldKey(key)
+		}
+		if rule.Count > 1 {
+			r.hits = make([]time.Time, rule.Count)
+		}
+		esc.rules = append(esc.rules, r)
+	}
+	return esc
+}
+
+// EscalateLevels appends esc to the transform stage of logger's pipeline.
+func EscalateLevels(esc *Escalator) LoggerOption {
+	return AddProcessor(TransformStage, esc)
+}
+
+// Process implements the LogProcessor interface.
+func (esc *Escalator) Process(e *Event, level LogLevel, message string) {
+	for _, rule := range esc.rules {
+		if rule.match(e, level, message) && rule.hit(esc.now()) {
+			if rule.Level != level {
+				levelName := level.String()
+				if level == NoLevel {
+					levelName = "none"
+				}
+				e.string(OriginalLevelFieldName, levelName)
+				e.SetLevel(rule.Level)
+			}
+			return
+		}
+	}
+}
+
+func (r *escalationRule) match(e *Event, level LogLevel, message string) bool {
+	if r.Message != "" && !strings.Contains(message, r.Message) {
+		return false
+	}
+	if len(r.Levels) > 0 {
+		found := false
+		for _, l := range r.Levels {
+			if l == level {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for key, want := range r.Fields {
+		if value, ok := e.stringField(r.fields[key]); !ok || value != want {
+			return false
+		}
+	}
+	return r.Match == nil || r.Match(e, level, message)
+}
+
+// hit records a match and returns true if the rule applies.
+func (r *escalationRule) hit(now time.Time) bool {
+	if r.hits == nil {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hits[r.next] = now
+	r.next = (r.next + 1) % len(r.hits)
+	if r.n < len(r.hits) {
+		r.n++
+	}
+	if r.n < len(r.hits) {
+		return false
+	}
+	// r.hits[r.next] is the oldest of the last Count matches
+	return r.Window <= 0 || now.Sub(r.hits[r.next]) <= r.Window
+}
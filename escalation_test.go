@@ -0,0 +1,83 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEscalator(t *testing.T) {
+	out := &bytes.Buffer{}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	esc := NewEscalator(
+		EscalationRule{Message: "connection refused", Count: 3, Window: time.Minute, Level: ErrorLevel},
+		EscalationRule{Fields: map[string]string{"component": "health"}, Levels: []LogLevel{InfoLevel}, Level: DebugLevel},
+		EscalationRule{Message: "started", Level: NoLevel},
+	)
+	esc.now = func() time.Time { return now }
+	log := New(Writer(out), Fields(Timestamp(false)), Level(DebugLevel), EscalateLevels(esc))
+
+	log.Warn("connection refused")
+	now = now.Add(20 * time.Second)
+	log.Warn("connection refused")
+	now = now.Add(20 * time.Second)
+	log.Warn("connection refused")
+	now = now.Add(50 * time.Second)
+	log.Warn("connection refused")
+	log.Info("ping", String("component", "health"))
+	log.Warn("ping", String("component", "health"))
+	log.Info("started", String("component", "api"))
+	log.Log("none")
+
+	want := `{"level":"warning","message":"connection refused"}
+{"level":"warning","message":"connection refused"}
+{"level":"error","original_level":"warning","message":"connection refused"}
+{"level":"warning","message":"connection refused"}
+{"level":"debug","component":"health","original_level":"info","message":"ping"}
+{"level":"warning","component":"health","message":"ping"}
+{"component":"api","original_level":"info","message":"started"}
+{"message":"none"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEventSetLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	var levels []LogLevel
+	log := New(Writer(out), Fields(Timestamp(false)), AddProcessor(TransformStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+		e.SetLevel(ErrorLevel)
+		levels = append(levels, e.Level())
+	})))
+	log.Log("a", String("foo", "bar"))
+	log.Info("b")
+
+	want := `{"level":"error","foo":"bar","message":"a"}
+{"level":"error","message":"b"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if len(levels) != 2 || levels[0] != ErrorLevel {
+		t.Errorf("invalid levels: %v", levels)
+	}
+}
+
+func TestEventSetLevelOtherEncoder(t *testing.T) {
+	out := &bytes.Buffer{}
+	esc := NewEscalator(
+		EscalationRule{Fields: map[string]string{"component": "db"}, Level: ErrorLevel},
+		EscalationRule{Message: "timeout", Level: WarnLevel},
+	)
+	log := New(Writer(out), UseEncoder(testEncoder{name: "other"}), Fields(Timestamp(false)), EscalateLevels(esc))
+	log.Info("failed", String("component", "db"))
+	log.Info("timeout")
+
+	// the payload is left as is, the fields can't be read
+	want := `{"level":"info","component":"db","message":"failed"}` + "\n" +
+		`{"level":"info","original_level":"info","message":"timeout"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -2,9 +2,14 @@ package rz
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net"
+	"reflect"
+	"runtime/debug"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -26,6 +31,7 @@ type Event struct {
 	stack                bool      // enable error stack trace
 	caller               bool      // enable caller field
 	timestamp            bool      // enable timestamp
+	confirm              bool      // flush the writer after writing the event
 	ch                   []LogHook // hooks from context
 	timestampFieldName   string
 	levelFieldName       string
@@ -37,7 +43,14 @@ type Event struct {
 	callerSkipFrameCount int
 	formatter            LogFormatter
 	timestampFunc        func() time.Time
+	eventTime            time.Time // timestamp set with EventTime, zero for the current time
 	encoder              Encoder
+	message              string
+	processors           *processorPipeline
+	ctx                  context.Context
+	levelStart           int // offsets of the level field in buf, -1 if unknown
+	levelEnd             int
+	module               string // set by ForModule
 }
 
 func putEvent(e *Event) {
@@ -56,20 +69,43 @@ func putEvent(e *Event) {
 
 // LogObjectMarshaler provides a strongly-typed and encoding-agnostic interface
 // to be implemented by types used with Event/Context's Object methods.
+// Domain types control their own structured representation without reflection,
+// also when logged with Any:
+//
+//     func (u User) MarshalRzObject(e *rz.Event) {
+//         e.Append(rz.String("id", u.ID), rz.String("role", u.Role))
+//         e.Object("team", u.Team)
+//     }
 type LogObjectMarshaler interface {
 	MarshalRzObject(*Event)
 }
 
 // LogArrayMarshaler provides a strongly-typed and encoding-agnostic interface
-// to be implemented by types used with Event/Context's Array methods.
-type logArrayMarshaler interface {
-	MarshalRzArray(*array)
+// to be implemented by types used with Event/Context's Array methods, e.g.
+// collections of domain objects:
+//
+//     type Users []User
+//
+//     func (users Users) MarshalRzArray(a *rz.LogArray) {
+//         for _, u := range users {
+//             a.Object(u)
+//         }
+//     }
+type LogArrayMarshaler interface {
+	MarshalRzArray(*LogArray)
 }
 
 func newEvent(w LevelWriter, level LogLevel) *Event {
 	e := eventPool.Get().(*Event)
 	e.buf = e.buf[:0]
 	e.ch = nil
+	e.processors = nil
+	e.message = ""
+	e.ctx = nil
+	e.eventTime = time.Time{}
+	e.module = ""
+	e.levelStart, e.levelEnd = -1, -1
+	e.encoder = enc
 	e.buf = enc.AppendBeginMarker(e.buf)
 	e.w = w
 	e.level = level
@@ -82,6 +118,135 @@ func (e *Event) Enabled() bool {
 	return e.level != Disabled
 }
 
+// Level returns the level of the event.
+func (e *Event) Level() LogLevel {
+	return e.level
+}
+
+// Message returns the message of the event.
+func (e *Event) Message() string {
+	return e.message
+}
+
+// Module returns the module of the logger of the event, set by ForModule, or "".
+func (e *Event) Module() string {
+	return e.module
+}
+
+// derive returns a new event with level, written to the same writer with the same
+// configuration as e, but without hooks, processors nor fields.
+func (e *Event) derive(level LogLevel) *Event {
+	d := newEvent(e.w, level)
+	d.stack = e.stack
+	d.timestamp = e.timestamp
+	d.confirm = e.confirm
+	d.timestampFieldName = e.timestampFieldName
+	d.levelFieldName = e.levelFieldName
+	d.messageFieldName = e.messageFieldName
+	d.errorFieldName = e.errorFieldName
+	d.callerFieldName = e.callerFieldName
+	d.timeFieldFormat = e.timeFieldFormat
+	d.errorStackFieldName = e.errorStackFieldName
+	d.formatter = e.formatter
+	d.timestampFunc = e.timestampFunc
+	d.setEncoder(e.encoder)
+	d.ctx = e.ctx
+	d.levelStart = len(d.buf)
+	if level != NoLevel {
+		d.string(d.levelFieldName, level.String())
+	}
+	d.levelEnd = len(d.buf)
+	return d
+}
+
+// SetLevel changes the level of the event and rewrites its level field. It's intended to be used
+// by processors before the EncodeStage of the pipeline. Setting the Disabled level discards the event.
+// The level field is only rewritten with the JSON based encoders (JSONEncoder and LogfmtEncoder):
+// with the other ones, only the level passed to the writers changes.
+func (e *Event) SetLevel(level LogLevel) {
+	if e.levelStart >= 0 && e.level != Disabled && level != Disabled && jsonBased(e.encoder) {
+		tail := append([]byte(nil), e.buf[e.levelEnd:]...)
+		e.buf = e.buf[:e.levelStart]
+		if level != NoLevel {
+			e.string(e.levelFieldName, level.String())
+		}
+		e.levelEnd = len(e.buf)
+		if len(tail) > 0 {
+			last := e.buf[len(e.buf)-1]
+			if tail[0] == ',' && last == '{' {
+				tail = tail[1:]
+			} else if tail[0] != ',' && tail[0] != '}' && last != '{' {
+				e.buf = append(e.buf, ',')
+			}
+		}
+		e.buf = append(e.buf, tail...)
+	}
+	e.level = level
+}
+
+// SetMessage replaces the message of the event. It's intended to be used by processors.
+func (e *Event) SetMessage(message string) {
+	e.message = message
+}
+
+// Context returns the context attached to the event with the Ctx field, or
+// context.Background() if none.
+func (e *Event) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// Buffer returns the encoded payload of the event. Before the EncodeStage of the pipeline,
+// the payload is incomplete.
+// The returned slice is only valid until the event is written.
+func (e *Event) Buffer() []byte {
+	return e.buf
+}
+
+// fieldKey returns the encoded key of the field name, as searched by stringFieldValue.
+func fieldKey(name string) []byte {
+	return append(enc.AppendString(nil, name), ':')
+}
+
+// stringField returns the first string value of the field with the encoded key in the payload of
+// the event, before the EncodeStage. It's only supported by the JSON based encoders, false is
+// returned with the other ones.
+func (e *Event) stringField(key []byte) (string, bool) {
+	if !jsonBased(e.encoder) {
+		return "", false
+	}
+	return stringFieldValue(e.buf, key)
+}
+
+// stringFieldValue returns the first string value of the field with the encoded key in buf.
+func stringFieldValue(buf []byte, key []byte) (string, bool) {
+	for i := 0; ; {
+		j := bytes.Index(buf[i:], key)
+		if j < 0 {
+			return "", false
+		}
+		i += j
+		// the key must start a field and its value must be a string
+		if i > 0 && (buf[i-1] == ',' || buf[i-1] == '{') {
+			start := i + len(key)
+			if start < len(buf) && buf[start] == '"' {
+				for end := start + 1; end < len(buf); end++ {
+					switch buf[end] {
+					case '\\':
+						end++
+					case '"':
+						value, err := strconv.Unquote(string(buf[start : end+1]))
+						return value, err == nil
+					}
+				}
+			}
+		}
+		i += len(key)
+	}
+}
+
 // Append the given fields to the event
 func (e *Event) Append(fields ...Field) {
 	for i := range fields {
@@ -89,6 +254,20 @@ func (e *Event) Append(fields ...Field) {
 	}
 }
 
+// appendSafe appends field to the event and recovers from its panics. In case of panic, the
+// partially added field is removed and the panic is recorded instead.
+func (e *Event) appendSafe(field Field) {
+	n := len(e.buf)
+	defer func() {
+		if r := recover(); r != nil {
+			e.buf = e.buf[:n]
+			e.string("fields_panic", fmt.Sprint(r))
+			e.string("fields_panic_stack", string(debug.Stack()))
+		}
+	}()
+	field(e)
+}
+
 // Fields returns the fields from the event.
 // Note that this call is very expensive and should be used sparingly.
 func (e *Event) Fields() (map[string]interface{}, error) {
@@ -118,8 +297,8 @@ func (e *Event) fields(fields map[string]interface{}) {
 // Dict adds the field key with a dict to the event context.
 // Use rz.Dict() to create the dictionary.
 func (e *Event) dict(key string, dict *Event) {
-	dict.buf = enc.AppendEndMarker(dict.buf)
-	e.buf = append(enc.AppendKey(e.buf, key), dict.buf...)
+	dict.buf = e.encoder.AppendEndMarker(dict.buf)
+	e.buf = append(e.encoder.AppendKey(e.buf, key), dict.buf...)
 	putEvent(dict)
 }
 
@@ -127,13 +306,40 @@ func newDict() *Event {
 	return newEvent(nil, 0)
 }
 
-// Array adds the field key with an array to the event context.
-// Use Event.Arr() to create the array or pass a type that
-// implement the LogArrayMarshaler interface.
-func (e *Event) array(key string, arr logArrayMarshaler) {
-	e.buf = enc.AppendKey(e.buf, key)
-	var a *array
-	if aa, ok := arr.(*array); ok {
+// child returns a new event without writer using the encoder of e, to encode nested objects.
+func (e *Event) child() *Event {
+	c := newEvent(nil, 0)
+	c.setEncoder(e.encoder)
+	return c
+}
+
+// setEncoder sets the encoder of a new event, re-encoding its begin marker if it was written.
+func (e *Event) setEncoder(encoder Encoder) {
+	if encoder == nil {
+		encoder = enc
+	}
+	began := len(e.buf) > 0
+	e.encoder = encoder
+	if began {
+		e.buf = encoder.AppendBeginMarker(e.buf[:0])
+	}
+}
+
+// Array adds the field key with arr marshaled as an array to the event, for the
+// MarshalRzObject methods of LogObjectMarshaler.
+func (e *Event) Array(key string, arr LogArrayMarshaler) {
+	e.array(key, arr)
+}
+
+// array adds the field key with an array to the event context.
+func (e *Event) array(key string, arr LogArrayMarshaler) {
+	e.buf = e.encoder.AppendKey(e.buf, key)
+	if arr == nil {
+		e.buf = e.encoder.AppendInterface(e.buf, nil)
+		return
+	}
+	var a *LogArray
+	if aa, ok := arr.(*LogArray); ok {
 		a = aa
 	} else {
 		a = e.arr()
@@ -143,14 +349,24 @@ func (e *Event) array(key string, arr logArrayMarshaler) {
 }
 
 func (e *Event) appendObject(obj LogObjectMarshaler) {
-	e.buf = enc.AppendBeginMarker(e.buf)
+	e.buf = e.encoder.AppendBeginMarker(e.buf)
 	obj.MarshalRzObject(e)
-	e.buf = enc.AppendEndMarker(e.buf)
+	e.buf = e.encoder.AppendEndMarker(e.buf)
+}
+
+// Object adds the field key with obj marshaled as a nested object to the event, for the
+// MarshalRzObject methods of LogObjectMarshaler. A nil obj is logged as null.
+func (e *Event) Object(key string, obj LogObjectMarshaler) {
+	e.object(key, obj)
 }
 
 // Object marshals an object that implement the LogObjectMarshaler interface.
 func (e *Event) object(key string, obj LogObjectMarshaler) {
-	e.buf = enc.AppendKey(e.buf, key)
+	e.buf = e.encoder.AppendKey(e.buf, key)
+	if isNil(obj) {
+		e.buf = e.encoder.AppendInterface(e.buf, nil)
+		return
+	}
 	e.appendObject(obj)
 }
 
@@ -161,12 +377,12 @@ func (e *Event) embedObject(obj LogObjectMarshaler) {
 
 // String adds the field key with val as a string to the *Event context.
 func (e *Event) string(key, val string) {
-	e.buf = enc.AppendString(enc.AppendKey(e.buf, key), val)
+	e.buf = e.encoder.AppendString(e.encoder.AppendKey(e.buf, key), val)
 }
 
 // Strings adds the field key with vals as a []string to the *Event context.
 func (e *Event) strings(key string, vals []string) {
-	e.buf = enc.AppendStrings(enc.AppendKey(e.buf, key), vals)
+	e.buf = e.encoder.AppendStrings(e.encoder.AppendKey(e.buf, key), vals)
 }
 
 // Bytes adds the field key with val as a string to the *Event context.
@@ -174,12 +390,12 @@ func (e *Event) strings(key string, vals []string) {
 // Runes outside of normal ASCII ranges will be hex-encoded in the resulting
 // JSON.
 func (e *Event) bytes(key string, val []byte) {
-	e.buf = enc.AppendBytes(enc.AppendKey(e.buf, key), val)
+	e.buf = e.encoder.AppendBytes(e.encoder.AppendKey(e.buf, key), val)
 }
 
 // Hex adds the field key with val as a hex string to the *Event context.
 func (e *Event) hex(key string, val []byte) {
-	e.buf = enc.AppendHex(enc.AppendKey(e.buf, key), val)
+	e.buf = e.encoder.AppendHex(e.encoder.AppendKey(e.buf, key), val)
 }
 
 // RawJSON adds already encoded JSON to the log line under key.
@@ -187,7 +403,7 @@ func (e *Event) hex(key string, val []byte) {
 // No sanity check is performed on b; it must not contain carriage returns and
 // be valid JSON.
 func (e *Event) rawJSON(key string, b []byte) {
-	e.buf = appendJSON(enc.AppendKey(e.buf, key), b)
+	e.buf = appendJSON(e.encoder, e.encoder.AppendKey(e.buf, key), b)
 }
 
 // Error adds the field key with serialized err to the *Event context.
@@ -259,139 +475,139 @@ func (e *Event) enableStack(enable bool) {
 
 // Bool adds the field key with val as a bool to the *Event context.
 func (e *Event) bool(key string, b bool) {
-	e.buf = enc.AppendBool(enc.AppendKey(e.buf, key), b)
+	e.buf = e.encoder.AppendBool(e.encoder.AppendKey(e.buf, key), b)
 }
 
 // Bools adds the field key with val as a []bool to the *Event context.
 func (e *Event) bools(key string, b []bool) {
-	e.buf = enc.AppendBools(enc.AppendKey(e.buf, key), b)
+	e.buf = e.encoder.AppendBools(e.encoder.AppendKey(e.buf, key), b)
 }
 
 // Int adds the field key with i as a int to the *Event context.
 func (e *Event) int(key string, i int) {
-	e.buf = enc.AppendInt(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInt(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Ints adds the field key with i as a []int to the *Event context.
 func (e *Event) ints(key string, i []int) {
-	e.buf = enc.AppendInts(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInts(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Int8 adds the field key with i as a int8 to the *Event context.
 func (e *Event) int8(key string, i int8) {
-	e.buf = enc.AppendInt8(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInt8(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Ints8 adds the field key with i as a []int8 to the *Event context.
 func (e *Event) ints8(key string, i []int8) {
-	e.buf = enc.AppendInts8(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInts8(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Int16 adds the field key with i as a int16 to the *Event context.
 func (e *Event) int16(key string, i int16) {
-	e.buf = enc.AppendInt16(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInt16(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Ints16 adds the field key with i as a []int16 to the *Event context.
 func (e *Event) ints16(key string, i []int16) {
-	e.buf = enc.AppendInts16(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInts16(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Int32 adds the field key with i as a int32 to the *Event context.
 func (e *Event) int32(key string, i int32) {
-	e.buf = enc.AppendInt32(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInt32(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Ints32 adds the field key with i as a []int32 to the *Event context.
 func (e *Event) ints32(key string, i []int32) {
-	e.buf = enc.AppendInts32(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInts32(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Int64 adds the field key with i as a int64 to the *Event context.
 func (e *Event) int64(key string, i int64) {
-	e.buf = enc.AppendInt64(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInt64(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Ints64 adds the field key with i as a []int64 to the *Event context.
 func (e *Event) ints64(key string, i []int64) {
-	e.buf = enc.AppendInts64(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInts64(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uint adds the field key with i as a uint to the *Event context.
 func (e *Event) uint(key string, i uint) {
-	e.buf = enc.AppendUint(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUint(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uints adds the field key with i as a []int to the *Event context.
 func (e *Event) uints(key string, i []uint) {
-	e.buf = enc.AppendUints(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUints(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uint8 adds the field key with i as a uint8 to the *Event context.
 func (e *Event) uint8(key string, i uint8) {
-	e.buf = enc.AppendUint8(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUint8(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uints8 adds the field key with i as a []int8 to the *Event context.
 func (e *Event) uints8(key string, i []uint8) {
-	e.buf = enc.AppendUints8(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUints8(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uint16 adds the field key with i as a uint16 to the *Event context.
 func (e *Event) uint16(key string, i uint16) {
-	e.buf = enc.AppendUint16(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUint16(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uints16 adds the field key with i as a []int16 to the *Event context.
 func (e *Event) uints16(key string, i []uint16) {
-	e.buf = enc.AppendUints16(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUints16(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uint32 adds the field key with i as a uint32 to the *Event context.
 func (e *Event) uint32(key string, i uint32) {
-	e.buf = enc.AppendUint32(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUint32(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uints32 adds the field key with i as a []int32 to the *Event context.
 func (e *Event) uints32(key string, i []uint32) {
-	e.buf = enc.AppendUints32(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUints32(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uint64 adds the field key with i as a uint64 to the *Event context.
 func (e *Event) uint64(key string, i uint64) {
-	e.buf = enc.AppendUint64(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUint64(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Uints64 adds the field key with i as a []int64 to the *Event context.
 func (e *Event) uints64(key string, i []uint64) {
-	e.buf = enc.AppendUints64(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendUints64(e.encoder.AppendKey(e.buf, key), i)
 }
 
 // Float32 adds the field key with f as a float32 to the *Event context.
 func (e *Event) float32(key string, f float32) {
-	e.buf = enc.AppendFloat32(enc.AppendKey(e.buf, key), f)
+	e.buf = e.encoder.AppendFloat32(e.encoder.AppendKey(e.buf, key), f)
 }
 
 // Floats32 adds the field key with f as a []float32 to the *Event context.
 func (e *Event) floats32(key string, f []float32) {
-	e.buf = enc.AppendFloats32(enc.AppendKey(e.buf, key), f)
+	e.buf = e.encoder.AppendFloats32(e.encoder.AppendKey(e.buf, key), f)
 }
 
 // Float64 adds the field key with f as a float64 to the *Event context.
 func (e *Event) float64(key string, f float64) {
-	e.buf = enc.AppendFloat64(enc.AppendKey(e.buf, key), f)
+	e.buf = e.encoder.AppendFloat64(e.encoder.AppendKey(e.buf, key), f)
 }
 
 // Floats64 adds the field key with f as a []float64 to the *Event context.
 func (e *Event) floats64(key string, f []float64) {
-	e.buf = enc.AppendFloats64(enc.AppendKey(e.buf, key), f)
+	e.buf = e.encoder.AppendFloats64(e.encoder.AppendKey(e.buf, key), f)
 }
 
 // Timestamp adds the current local time as UNIX timestamp to the *Event context with the
 // logger.TimestampFieldName key.
 // func (e *Event) Timestamp() {
 // 	e.timestamp = false
-// 	e.buf = enc.AppendTime(enc.AppendKey(e.buf, e.timestampFieldName), e.timestampFunc(), e.timeFieldFormat)
+// 	e.buf = e.encoder.AppendTime(e.encoder.AppendKey(e.buf, e.timestampFieldName), e.timestampFunc(), e.timeFieldFormat)
 // 	return e
 // }
 func (e *Event) enableTimestamp(enable bool) {
@@ -400,34 +616,40 @@ func (e *Event) enableTimestamp(enable bool) {
 
 // Time adds the field key with t formated as string using rz.TimeFieldFormat.
 func (e *Event) time(key string, t time.Time) {
-	e.buf = enc.AppendTime(enc.AppendKey(e.buf, key), t, e.timeFieldFormat)
+	e.buf = appendTime(e.encoder, e.encoder.AppendKey(e.buf, key), t, e.timeFieldFormat)
 }
 
 // Times adds the field key with t formated as string using rz.TimeFieldFormat.
 func (e *Event) times(key string, t []time.Time) {
-	e.buf = enc.AppendTimes(enc.AppendKey(e.buf, key), t, e.timeFieldFormat)
+	e.buf = appendTimes(e.encoder, e.encoder.AppendKey(e.buf, key), t, e.timeFieldFormat)
 }
 
 // Duration adds the field key with duration d stored as rz.DurationFieldUnit.
 // If rz.DurationFieldInteger is true, durations are rendered as integer
 // instead of float.
 func (e *Event) duration(key string, d time.Duration) {
-	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger)
+	e.buf = e.encoder.AppendDuration(e.encoder.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger)
 }
 
 // Durations adds the field key with duration d stored as rz.DurationFieldUnit.
 // If rz.DurationFieldInteger is true, durations are rendered as integer
 // instead of float.
 func (e *Event) durations(key string, d []time.Duration) {
-	e.buf = enc.AppendDurations(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger)
+	e.buf = e.encoder.AppendDurations(e.encoder.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger)
 }
 
 // Interface adds the field key with i marshaled using reflection.
 func (e *Event) iinterface(key string, i interface{}) {
 	if obj, ok := i.(LogObjectMarshaler); ok {
 		e.object(key, obj)
+		return
 	}
-	e.buf = enc.AppendInterface(enc.AppendKey(e.buf, key), i)
+	e.buf = e.encoder.AppendInterface(e.encoder.AppendKey(e.buf, key), i)
+}
+
+// enableConfirm enables the flush of the writer after writing the event.
+func (e *Event) enableConfirm(enable bool) {
+	e.confirm = enable
 }
 
 // enableCaller adds the file:line of the caller with the rz.CallerFieldName key.
@@ -437,15 +659,24 @@ func (e *Event) enableCaller(enable bool) {
 
 // ip adds IPv4 or IPv6 Address to the event
 func (e *Event) ip(key string, ip net.IP) {
-	e.buf = enc.AppendIPAddr(enc.AppendKey(e.buf, key), ip)
+	e.buf = e.encoder.AppendIPAddr(e.encoder.AppendKey(e.buf, key), ip)
 }
 
 // ipNet adds IPv4 or IPv6 Prefix (address and mask) to the event
 func (e *Event) ipNet(key string, pfx net.IPNet) {
-	e.buf = enc.AppendIPPrefix(enc.AppendKey(e.buf, key), pfx)
+	e.buf = e.encoder.AppendIPPrefix(e.encoder.AppendKey(e.buf, key), pfx)
 }
 
 // hardwareAddr adds MAC address to the event
 func (e *Event) hardwareAddr(key string, ha net.HardwareAddr) {
-	e.buf = enc.AppendMACAddr(enc.AppendKey(e.buf, key), ha)
+	e.buf = e.encoder.AppendMACAddr(e.encoder.AppendKey(e.buf, key), ha)
+}
+
+// isNil returns true if obj is nil or a nil pointer.
+func isNil(obj LogObjectMarshaler) bool {
+	if obj == nil {
+		return true
+	}
+	v := reflect.ValueOf(obj)
+	return v.Kind() == reflect.Ptr && v.IsNil()
 }
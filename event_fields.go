@@ -0,0 +1,114 @@
+package rz
+
+import "strconv"
+
+// fieldSpan is the position of a top level field in the payload of an event.
+type fieldSpan struct {
+	key        string
+	start, end int
+}
+
+// Has returns true if the event has a top level field named key, including the logger's
+// context fields. It's only supported by the JSON based encoders, false is returned with the
+// other ones.
+func (e *Event) Has(key string) bool {
+	if !jsonBased(e.encoder) {
+		return false
+	}
+	for _, span := range jsonFieldSpans(e.buf) {
+		if span.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the top level fields named keys from the event, including the logger's
+// context fields, so hooks and processors can enforce policies on the fields of the events. A
+// field is modified by removing it, then appending the new value. It's only supported by the
+// JSON based encoders, it does nothing with the other ones.
+//
+//     rz.HookFunc(func(e *rz.Event, level rz.LogLevel, message string) {
+//         if e.Has("email") {
+//             e.Remove("email")
+//             e.Append(rz.Hashed("email_hash", email))
+//         }
+//     })
+func (e *Event) Remove(keys ...string) {
+	if !jsonBased(e.encoder) || len(keys) == 0 {
+		return
+	}
+	spans := jsonFieldSpans(e.buf)
+	for i := len(spans) - 1; i >= 0; i-- {
+		span := spans[i]
+		if !containsString(keys, span.key) {
+			continue
+		}
+		start, end := span.start, span.end
+		if start > 0 && e.buf[start-1] == ',' {
+			start--
+		} else if end < len(e.buf) && e.buf[end] == ',' {
+			end++
+		}
+		switch {
+		case e.levelStart >= start && e.levelStart < end:
+			e.levelStart, e.levelEnd = -1, -1
+		case e.levelStart >= end:
+			e.levelStart -= end - start
+			e.levelEnd -= end - start
+		}
+		e.buf = append(e.buf[:start], e.buf[end:]...)
+	}
+}
+
+// Discard drops the event: the pipeline stops and it's not written. It's the same as
+// e.Append(Discard()), to veto events in hooks and processors.
+func (e *Event) Discard() {
+	e.discard()
+}
+
+// jsonFieldSpans returns the top level fields of the, possibly incomplete, JSON object buf.
+func jsonFieldSpans(buf []byte) []fieldSpan {
+	var spans []fieldSpan
+	i := 0
+	for i < len(buf) && buf[i] != '{' {
+		i++
+	}
+	i++
+	for i < len(buf) {
+		i = skipJSONSpaces(buf, i)
+		if i >= len(buf) || buf[i] == '}' {
+			break
+		}
+		if buf[i] == ',' {
+			i++
+			continue
+		}
+		if buf[i] != '"' {
+			break
+		}
+		start := i
+		i, _ = skipJSONValue(buf, i)
+		key, err := strconv.Unquote(string(buf[start:i]))
+		if err != nil {
+			break
+		}
+		i = skipJSONSpaces(buf, i)
+		if i >= len(buf) || buf[i] != ':' {
+			break
+		}
+		var ok bool
+		if i, ok = skipJSONValue(buf, skipJSONSpaces(buf, i+1)); !ok {
+			break
+		}
+		spans = append(spans, fieldSpan{key: key, start: start, end: i})
+	}
+	return spans
+}
+
+func skipJSONSpaces(buf []byte, i int) int {
+	for i < len(buf) && (buf[i] == ' ' || buf[i] == '\t' || buf[i] == '\n' || buf[i] == '\r') {
+		i++
+	}
+	return i
+}
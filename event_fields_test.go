@@ -0,0 +1,46 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEventRemove(t *testing.T) {
+	out := &bytes.Buffer{}
+	policy := HookFunc(func(e *Event, level LogLevel, message string) {
+		if strings.HasPrefix(message, "debug") {
+			e.Discard()
+			return
+		}
+		if e.Has("password") {
+			e.Remove("password", "token")
+			e.Append(Bool("redacted", true))
+		}
+		e.Remove("status")
+		e.Append(String("status", "ok"))
+	})
+	log := New(Writer(out), Fields(Timestamp(false), String("service", "api"), String("token", "t")), Hooks(policy))
+
+	log.Info("login", String("user", "bob"), String("password", `p",{"a":[`), Dict("session", log.NewDict(String("status", "open"))), Int("status", 200))
+	log.Info("debug dump", String("password", "p"))
+	log.Warn("no secret")
+
+	want := `{"level":"info","service":"api","user":"bob","session":{"status":"open"},"redacted":true,"status":"ok","message":"login"}` + "\n" +
+		`{"level":"warning","service":"api","token":"t","status":"ok","message":"no secret"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEventRemoveLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), Hooks(HookFunc(func(e *Event, level LogLevel, message string) {
+		e.Remove("level")
+		e.SetLevel(ErrorLevel)
+	})))
+	log.Info("hello", Int("n", 1))
+	if got, want := out.String(), `{"n":1,"message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package rz
+
+// eventIDs stamps each event with a ULID.
+type eventIDs struct {
+	fieldName string
+	key       []byte
+}
+
+// Process implements the LogProcessor interface.
+func (p eventIDs) Process(e *Event, level LogLevel, message string) {
+	if _, ok := e.stringField(p.key); ok {
+		return
+	}
+	e.string(p.fieldName, NewULID(e.timestampFunc()))
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (p eventIDs) DescribeConfig() string {
+	return "event_ids(" + p.fieldName + ")"
+}
+
+// EventIDs stamps each event with a unique, time sortable ID (a ULID) in the field fieldName, so
+// idempotent backends can deduplicate the events replayed by at-least-once deliveries.
+// The ID is part of the encoded event: remote writers send it with the event, and can read it
+// with EventID, e.g. the EventID options of rzclickhouse and rzloki make their inserts and pushes
+// idempotent. The events which already have the field (e.g. the events of an Operation, sharing
+// its ID) are not stamped again; this is only detected with the JSON based encoders. The processor
+// is appended to the enrich stage of logger's pipeline.
+func EventIDs(fieldName string) LoggerOption {
+	return AddProcessor(EnrichStage, eventIDs{fieldName: fieldName, key: fieldKey(fieldName)})
+}
+
+// EventID returns the ID stamped by EventIDs in the encoded event p.
+func EventID(p []byte, fieldName string) (string, bool) {
+	return stringFieldValue(p, fieldKey(fieldName))
+}
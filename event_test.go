@@ -1,6 +1,7 @@
 package rz
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 )
@@ -24,3 +25,29 @@ func TestEvent_Fields(t *testing.T) {
 		t.Errorf("Event.Fields() returned %+v, want %+v", got, fields)
 	}
 }
+
+type testTeam struct{ name string }
+
+func (t *testTeam) MarshalRzObject(e *Event) {
+	e.Append(String("name", t.name))
+}
+
+type testUser struct {
+	id   string
+	team *testTeam
+}
+
+func (u testUser) MarshalRzObject(e *Event) {
+	e.Append(String("id", u.id))
+	e.Object("team", u.team)
+}
+
+func TestEvent_Object(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	log.Info("hello", Object("user", testUser{id: "1", team: &testTeam{name: "core"}}), Any("other", testUser{id: "2"}))
+	want := `{"level":"info","user":{"id":"1","team":{"name":"core"}},"other":{"id":"2","team":null},"message":"hello"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -29,8 +29,9 @@ func main() {
 
 	router := chi.NewRouter()
 
-	// replace size field name by latency and disable userAgent logging
-	loggingMiddleware := rzhttp.Handler(log.Logger(), rzhttp.Duration("latency"), rzhttp.UserAgent(""))
+	// replace size field name by latency, disable userAgent logging and log the chi route patterns
+	loggingMiddleware := rzhttp.Handler(log.Logger(), rzhttp.Duration("latency"), rzhttp.UserAgent(""),
+		rzhttp.RoutePatterns(rzhttp.ContextRoutePattern(chi.RouteCtxKey)))
 
 	// here the order matters, otherwise loggingMiddleware won't see the request ID
 	router.Use(requestIDMiddleware)
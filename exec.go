@@ -0,0 +1,123 @@
+package rz
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// ExecOutputLimit is the maximum number of bytes of stdout and stderr logged by Logger.Exec.
+var ExecOutputLimit = 4096
+
+// limitedBuffer keeps the first limit bytes written to it.
+type limitedBuffer struct {
+	buf   []byte
+	limit int
+	total int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if n := b.limit - len(b.buf); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		b.buf = append(b.buf, p[:n]...)
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) MarshalRzObject(e *Event) {
+	e.string("data", string(b.buf))
+	e.int("bytes", b.total)
+	e.bool("truncated", b.total > len(b.buf))
+}
+
+// Exec runs cmd and logs its execution: a "command started" event with debug level, then a
+// "command finished" event with the duration, exit code, the first ExecOutputLimit bytes of
+// stdout and stderr, and the resource usage where available, under the "exec" key.
+// The event is logged with info level if the command succeeded, with error level otherwise.
+// If cmd.Stdout or cmd.Stderr are set, the output is also written to them. If they are the same
+// writer, it's not written concurrently (as with os/exec), and the interleaved output is logged
+// under the "output" key instead. cmd.Stdout and cmd.Stderr are restored once the command exits.
+// The error returned is the one of cmd.Run.
+func (l *Logger) Exec(cmd *exec.Cmd, fields ...Field) error {
+	stdout := &limitedBuffer{limit: ExecOutputLimit}
+	stderr := &limitedBuffer{limit: ExecOutputLimit}
+	combined := cmd.Stdout != nil && interfaceEqual(cmd.Stdout, cmd.Stderr)
+	origStdout, origStderr := cmd.Stdout, cmd.Stderr
+	defer func() {
+		cmd.Stdout, cmd.Stderr = origStdout, origStderr
+	}()
+	cmd.Stdout = teeWriter(cmd.Stdout, stdout)
+	if combined {
+		cmd.Stderr = cmd.Stdout
+	} else {
+		cmd.Stderr = teeWriter(cmd.Stderr, stderr)
+	}
+	command := func(e *Event) {
+		e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "exec"))
+		e.string("path", cmd.Path)
+		e.strings("args", cmd.Args)
+		if cmd.Dir != "" {
+			e.string("dir", cmd.Dir)
+		}
+	}
+	started := func(e *Event) {
+		command(e)
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+	}
+
+	l.logEvent(DebugLevel, "command started", nil, append([]Field{started}, fields...), false)
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start)
+
+	level := InfoLevel
+	if err != nil {
+		level = ErrorLevel
+	}
+	result := func(e *Event) {
+		command(e)
+		e.duration("duration", duration)
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if cmd.ProcessState != nil {
+			exitCode = cmd.ProcessState.ExitCode()
+		} else if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		e.int("exit_code", exitCode)
+		if combined {
+			e.object("output", stdout)
+		} else {
+			e.object("stdout", stdout)
+			e.object("stderr", stderr)
+		}
+		if cmd.ProcessState != nil {
+			e.duration("user_time", cmd.ProcessState.UserTime())
+			e.duration("system_time", cmd.ProcessState.SystemTime())
+			appendRusage(e, cmd.ProcessState)
+		}
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+	}
+	l.logEvent(level, "command finished", nil, append([]Field{result, Err(err)}, fields...), false)
+	return err
+}
+
+// teeWriter returns a writer writing to w, if not nil, and buf.
+func teeWriter(w io.Writer, buf *limitedBuffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
+
+// interfaceEqual protects against panics from comparing uncomparable types, as os/exec does.
+func interfaceEqual(a, b interface{}) bool {
+	defer func() {
+		recover()
+	}()
+	return a == b
+}
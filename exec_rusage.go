@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package rz
+
+import (
+	"os"
+	"syscall"
+)
+
+// appendRusage appends the resource usage of the exited process to the event.
+func appendRusage(e *Event, state *os.ProcessState) {
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		e.int64("max_rss", int64(rusage.Maxrss))
+		e.int64("minor_page_faults", int64(rusage.Minflt))
+		e.int64("major_page_faults", int64(rusage.Majflt))
+		e.int64("voluntary_context_switches", int64(rusage.Nvcsw))
+		e.int64("involuntary_context_switches", int64(rusage.Nivcsw))
+	}
+}
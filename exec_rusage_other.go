@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package rz
+
+import (
+	"os"
+)
+
+// appendRusage is a no-op: the resource usage is not available on this platform.
+func appendRusage(e *Event, state *os.ProcessState) {
+}
@@ -0,0 +1,103 @@
+//go:build !windows
+// +build !windows
+
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Level(DebugLevel), Fields(Timestamp(false)))
+	stdout := &bytes.Buffer{}
+	cmd := exec.Command("sh", "-c", "echo hello; echo oops >&2; exit 3")
+	cmd.Stdout = stdout
+	err := log.Exec(cmd, String("job", "test"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("stdout not passed through: %q", stdout.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("invalid number of events: %d", len(lines))
+	}
+	if got, want := lines[0], `{"level":"debug","exec":{"path":"`+cmd.Path+`","args":["sh","-c","echo hello; echo oops >&2; exit 3"]},"job":"test","message":"command started"}`; got != want {
+		t.Errorf("invalid start event:\ngot:  %v\nwant: %v", got, want)
+	}
+	var finished struct {
+		Level   string
+		Message string
+		Error   string
+		Job     string
+		Exec    struct {
+			ExitCode int `json:"exit_code"`
+			Duration *float64
+			Stdout   struct {
+				Data      string
+				Bytes     int
+				Truncated bool
+			}
+			Stderr struct {
+				Data string
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &finished); err != nil {
+		t.Fatal(err)
+	}
+	if finished.Level != "error" || finished.Message != "command finished" || finished.Error == "" || finished.Job != "test" ||
+		finished.Exec.ExitCode != 3 || finished.Exec.Duration == nil || finished.Exec.Stdout.Data != "hello\n" ||
+		finished.Exec.Stdout.Bytes != 6 || finished.Exec.Stdout.Truncated || finished.Exec.Stderr.Data != "oops\n" {
+		t.Errorf("invalid finish event: %s", lines[1])
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 4}
+	b.Write([]byte("ab"))
+	b.Write([]byte("cdef"))
+	if string(b.buf) != "abcd" || b.total != 6 {
+		t.Errorf("invalid buffer: %q, %d", b.buf, b.total)
+	}
+}
+
+func TestExecCombinedOutput(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Level(InfoLevel), Fields(Timestamp(false)))
+	// a bytes.Buffer is not safe for concurrent use: -race reports concurrent writes
+	output := &bytes.Buffer{}
+	cmd := exec.Command("sh", "-c", "for i in 1 2 3; do echo out; echo err >&2; done")
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := log.Exec(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Stdout != output || cmd.Stderr != output {
+		t.Error("cmd.Stdout and cmd.Stderr are not restored")
+	}
+	want := strings.Repeat("out\nerr\n", 3)
+	if got := output.String(); got != want {
+		t.Errorf("invalid output: got %q, want %q", got, want)
+	}
+	var finished struct {
+		Exec struct {
+			Output struct {
+				Data string
+			}
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &finished); err != nil {
+		t.Fatal(err)
+	}
+	if finished.Exec.Output.Data != want {
+		t.Errorf("invalid finish event: %s", out.String())
+	}
+}
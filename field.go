@@ -1,6 +1,7 @@
 package rz
 
 import (
+	"context"
 	"net"
 	"time"
 )
@@ -8,6 +9,24 @@ import (
 // Field functions are used to add fields to events
 type Field func(e *Event)
 
+// Compose returns a Field adding all the fields, so reusable field-set builders can return a
+// single Field and be combined at call sites:
+//
+//     func httpFields(r *http.Request) rz.Field {
+//         return rz.Compose(rz.String("method", r.Method), rz.String("path", r.URL.Path))
+//     }
+//
+//     log.Info("user created", httpFields(r), userFields(u))
+func Compose(fields ...Field) Field {
+	return func(e *Event) {
+		for i := range fields {
+			if fields[i] != nil {
+				fields[i](e)
+			}
+		}
+	}
+}
+
 // Discard disables the event
 func Discard() Field {
 	return func(e *Event) {
@@ -15,14 +34,12 @@ func Discard() Field {
 	}
 }
 
-// // Array adds the field key with an array to the event context.
-// // Use Event.Arr() to create the array or pass a type that
-// // implement the LogArrayMarshaler interface.
-// func Array(key string, arr logArrayMarshaler) Field {
-// 	return func(e *Event) {
-// 		e.array(key, arr)
-// 	}
-// }
+// Array adds the field key with arr marshaled as an array to the *Event context.
+func Array(key string, arr LogArrayMarshaler) Field {
+	return func(e *Event) {
+		e.array(key, arr)
+	}
+}
 
 // Stack enables stack trace printing for the error passed to Err().
 //
@@ -40,6 +57,24 @@ func Caller(enable bool) Field {
 	}
 }
 
+// Confirm makes the logging call wait for the event to be written through buffering writers
+// (asynchronous or in batches) before returning, by flushing the logger's writer if it implements
+// the Flusher interface. It's intended for audit-critical events.
+// Used with rz.Fields, it enables (or disables) the confirm mode for all the events of the logger.
+func Confirm(enable bool) Field {
+	return func(e *Event) {
+		e.enableConfirm(enable)
+	}
+}
+
+// Ctx attaches ctx to the event. It's available to hooks and processors with Event.Context,
+// and bounds the write of the event if the logger's writer implements ContextWriter.
+func Ctx(ctx context.Context) Field {
+	return func(e *Event) {
+		e.ctx = ctx
+	}
+}
+
 // Map is a helper function to use a map to set fields using type assertion.
 func Map(fields map[string]interface{}) Field {
 	return func(e *Event) {
@@ -175,6 +210,14 @@ func Timestamp(enable bool) Field {
 	}
 }
 
+// EventTime sets the timestamp of the event to t instead of the current time, e.g. to log
+// events received from another logging system with their original time. Ignored if t is zero.
+func EventTime(t time.Time) Field {
+	return func(e *Event) {
+		e.eventTime = t
+	}
+}
+
 // Error adds the field key with serialized err to the *Event context.
 // If err is nil, no field is added.
 func Error(key string, value error) Field {
@@ -0,0 +1,126 @@
+package rz
+
+import (
+	"time"
+)
+
+// Val adds the field key with value to the *Event context. The encoding is selected from
+// the type of value: common types (strings, numbers, bools, times and durations) use the same
+// encoding as their dedicated field function, without boxing value on the heap. Other types
+// (errors, IPs, LogObjectMarshaler, pointers, slices...) are encoded like with Any, which may
+// allocate: use Slice for slices.
+func Val[T any](key string, value T) Field {
+	return func(e *Event) {
+		// the interface value doesn't escape: the common types are not boxed on the heap
+		switch val := interface{}(value).(type) {
+		case string:
+			e.string(key, val)
+		case bool:
+			e.buf = e.encoder.AppendBool(e.encoder.AppendKey(e.buf, key), val)
+		case int:
+			e.buf = e.encoder.AppendInt(e.encoder.AppendKey(e.buf, key), val)
+		case int8:
+			e.buf = e.encoder.AppendInt8(e.encoder.AppendKey(e.buf, key), val)
+		case int16:
+			e.buf = e.encoder.AppendInt16(e.encoder.AppendKey(e.buf, key), val)
+		case int32:
+			e.buf = e.encoder.AppendInt32(e.encoder.AppendKey(e.buf, key), val)
+		case int64:
+			e.buf = e.encoder.AppendInt64(e.encoder.AppendKey(e.buf, key), val)
+		case uint:
+			e.buf = e.encoder.AppendUint(e.encoder.AppendKey(e.buf, key), val)
+		case uint8:
+			e.buf = e.encoder.AppendUint8(e.encoder.AppendKey(e.buf, key), val)
+		case uint16:
+			e.buf = e.encoder.AppendUint16(e.encoder.AppendKey(e.buf, key), val)
+		case uint32:
+			e.buf = e.encoder.AppendUint32(e.encoder.AppendKey(e.buf, key), val)
+		case uint64:
+			e.buf = e.encoder.AppendUint64(e.encoder.AppendKey(e.buf, key), val)
+		case float32:
+			e.buf = e.encoder.AppendFloat32(e.encoder.AppendKey(e.buf, key), val)
+		case float64:
+			e.buf = e.encoder.AppendFloat64(e.encoder.AppendKey(e.buf, key), val)
+		case time.Duration:
+			e.buf = e.encoder.AppendDuration(e.encoder.AppendKey(e.buf, key), val, DurationFieldUnit, DurationFieldInteger)
+		case time.Time:
+			e.time(key, val)
+		case []time.Time:
+			e.times(key, val)
+		default:
+			e.buf = e.appendValue(e.encoder.AppendKey(e.buf, key), interface{}(value))
+		}
+	}
+}
+
+// Slice adds the field key with values as an array to the *Event context.
+// Slices of common types use the same encoding as their dedicated field function,
+// elements of other slices are encoded like with Val.
+//
+// Note that unlike Bytes, a []byte is encoded as an array of numbers.
+func Slice[T any](key string, values []T) Field {
+	return func(e *Event) {
+		e.buf = e.encoder.AppendKey(e.buf, key)
+		// the interface value doesn't escape: the common types are not boxed on the heap
+		switch vals := interface{}(values).(type) {
+		case []uint8:
+			e.buf = e.encoder.AppendUints8(e.buf, vals)
+		case []string:
+			e.buf = e.encoder.AppendStrings(e.buf, vals)
+		case []bool:
+			e.buf = e.encoder.AppendBools(e.buf, vals)
+		case []int:
+			e.buf = e.encoder.AppendInts(e.buf, vals)
+		case []int8:
+			e.buf = e.encoder.AppendInts8(e.buf, vals)
+		case []int16:
+			e.buf = e.encoder.AppendInts16(e.buf, vals)
+		case []int32:
+			e.buf = e.encoder.AppendInts32(e.buf, vals)
+		case []int64:
+			e.buf = e.encoder.AppendInts64(e.buf, vals)
+		case []uint:
+			e.buf = e.encoder.AppendUints(e.buf, vals)
+		case []uint16:
+			e.buf = e.encoder.AppendUints16(e.buf, vals)
+		case []uint32:
+			e.buf = e.encoder.AppendUints32(e.buf, vals)
+		case []uint64:
+			e.buf = e.encoder.AppendUints64(e.buf, vals)
+		case []float32:
+			e.buf = e.encoder.AppendFloats32(e.buf, vals)
+		case []float64:
+			e.buf = e.encoder.AppendFloats64(e.buf, vals)
+		case []time.Duration:
+			e.buf = e.encoder.AppendDurations(e.buf, vals, DurationFieldUnit, DurationFieldInteger)
+		case []time.Time:
+			e.buf = appendTimes(e.encoder, e.buf, vals, e.timeFieldFormat)
+		default:
+			e.buf = e.encoder.AppendArrayStart(e.buf)
+			for i := range values {
+				if i > 0 {
+					e.buf = e.encoder.AppendArrayDelim(e.buf)
+				}
+				e.buf = e.appendValue(e.buf, values[i])
+			}
+			e.buf = e.encoder.AppendArrayEnd(e.buf)
+		}
+	}
+}
+
+// objectArray is a LogArrayMarshaler marshaling its elements as objects.
+type objectArray[T LogObjectMarshaler] []T
+
+func (objs objectArray[T]) MarshalRzArray(a *LogArray) {
+	for i := range objs {
+		a.Object(objs[i])
+	}
+}
+
+// Objects adds the field key with objs marshaled as an array of objects to the *Event
+// context, without reflection nor a LogArrayMarshaler type for the collection.
+func Objects[T LogObjectMarshaler](key string, objs []T) Field {
+	return func(e *Event) {
+		e.array(key, objectArray[T](objs))
+	}
+}
@@ -0,0 +1,81 @@
+package rz
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+type genericPoint struct {
+	X, Y int
+}
+
+func TestVal(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	str := "pointer"
+	log.Log("",
+		Val("string", "foo"),
+		Val("int", 1),
+		Val("uint8", uint8(2)),
+		Val("float64", 3.5),
+		Val("bool", true),
+		Val("error", errors.New("some error")),
+		Val("pointer", &str),
+		Val("duration", time.Second),
+		Val("time", time.Time{}),
+		Val("ip", net.IP{192, 168, 0, 1}),
+		Val("object", obj{"a", "b", 1}),
+		Val("struct", genericPoint{1, 2}),
+	)
+	want := `{"string":"foo","int":1,"uint8":2,"float64":3.5,"bool":true,"error":"some error","pointer":"pointer","duration":1000,"time":"0001-01-01T00:00:00Z","ip":"192.168.0.1","object":{"Pub":"a","Tag":"b","priv":1},"struct":{"X":1,"Y":2}}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	log.Log("",
+		Slice("strings", []string{"a", "b"}),
+		Slice("ints", []int{1, 2}),
+		Slice("bytes", []byte{1, 2}),
+		Slice("empty", []float64{}),
+		Slice("errors", []error{errors.New("a"), errors.New("b")}),
+		Slice("structs", []genericPoint{{1, 2}, {3, 4}}),
+	)
+	want := `{"strings":["a","b"],"ints":[1,2],"bytes":[1,2],"empty":[],"errors":["a","b"],"structs":[{"X":1,"Y":2},{"X":3,"Y":4}]}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestGenericAllocs(t *testing.T) {
+	log := New(Writer(ioutil.Discard), Fields(Timestamp(false)))
+	str, n, f, d, ts := "foo", 123456, 3.5, time.Second, time.Now()
+	ints, strs := []int{n}, []string{str}
+	tests := []struct {
+		name           string
+		generic, typed func()
+	}{
+		{"string", func() { log.Log("", Val("k", str)) }, func() { log.Log("", String("k", str)) }},
+		{"int", func() { log.Log("", Val("k", n)) }, func() { log.Log("", Int("k", n)) }},
+		{"float64", func() { log.Log("", Val("k", f)) }, func() { log.Log("", Float64("k", f)) }},
+		{"bool", func() { log.Log("", Val("k", true)) }, func() { log.Log("", Bool("k", true)) }},
+		{"duration", func() { log.Log("", Val("k", d)) }, func() { log.Log("", Duration("k", d)) }},
+		{"time", func() { log.Log("", Val("k", ts)) }, func() { log.Log("", Time("k", ts)) }},
+		{"ints", func() { log.Log("", Slice("k", ints)) }, func() { log.Log("", Ints("k", ints)) }},
+		{"strings", func() { log.Log("", Slice("k", strs)) }, func() { log.Log("", Strings("k", strs)) }},
+	}
+	for _, tt := range tests {
+		generic := testing.AllocsPerRun(100, tt.generic)
+		typed := testing.AllocsPerRun(100, tt.typed)
+		if generic > typed {
+			t.Errorf("%s: %v allocations, %v with the dedicated field", tt.name, generic, typed)
+		}
+	}
+}
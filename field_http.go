@@ -0,0 +1,84 @@
+package rz
+
+import (
+	"net/http"
+	"time"
+)
+
+type httpRequest struct {
+	r *http.Request
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface.
+func (req httpRequest) MarshalRzObject(e *Event) {
+	r := req.r
+	e.string("method", r.Method)
+	url := r.RequestURI
+	if url == "" && r.URL != nil {
+		// client requests
+		url = r.URL.String()
+	}
+	e.string("url", url)
+	if r.Host != "" {
+		e.string("host", r.Host)
+	} else if r.URL != nil && r.URL.Host != "" {
+		e.string("host", r.URL.Host)
+	}
+	scheme := "http"
+	if r.URL != nil && r.URL.Scheme != "" {
+		scheme = r.URL.Scheme
+	} else if r.TLS != nil {
+		scheme = "https"
+	}
+	e.string("scheme", scheme)
+	e.string("proto", r.Proto)
+	if r.RemoteAddr != "" {
+		e.string("remote_addr", r.RemoteAddr)
+	}
+	if userAgent := r.UserAgent(); userAgent != "" {
+		e.string("user_agent", userAgent)
+	}
+	if r.ContentLength > 0 {
+		e.int64("size", r.ContentLength)
+	}
+}
+
+type httpResponse struct {
+	status   int
+	size     int64
+	duration time.Duration
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface.
+func (res httpResponse) MarshalRzObject(e *Event) {
+	e.int("status", res.status)
+	if res.size >= 0 {
+		e.int64("size", res.size)
+	}
+	e.duration("duration", res.duration)
+}
+
+// HTTPRequest adds the field key with a summary of the HTTP request r as a nested object:
+// "method", "url", "host", "scheme", "proto", "remote_addr", "user_agent" and "size" (the
+// content length), the empty ones being omitted. It works with server and client requests,
+// e.g. to log the calls of HTTP clients or in tests, with the same schema as HTTPResponse:
+//
+//     log.Info("api call", rz.HTTPRequest("http_request", req),
+//         rz.HTTPResponse("http_response", res.StatusCode, res.ContentLength, time.Since(start)))
+func HTTPRequest(key string, r *http.Request) Field {
+	return func(e *Event) {
+		if r == nil {
+			e.object(key, nil)
+			return
+		}
+		e.object(key, httpRequest{r: r})
+	}
+}
+
+// HTTPResponse adds the field key with a summary of an HTTP response as a nested object:
+// "status", "size" (omitted if < 0, i.e. unknown) and "duration", stored as rz.DurationFieldUnit.
+func HTTPResponse(key string, status int, size int64, duration time.Duration) Field {
+	return func(e *Event) {
+		e.object(key, httpResponse{status: status, size: size, duration: duration})
+	}
+}
@@ -0,0 +1,30 @@
+package rz
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+
+	server := httptest.NewRequest("POST", "/users?page=2", strings.NewReader("{}"))
+	server.Header.Set("User-Agent", "curl/8.0")
+	client, err := http.NewRequest("GET", "https://api.example.com/v1/orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("served", HTTPRequest("http_request", server), HTTPResponse("http_response", 201, 12, 2*time.Second))
+	log.Info("called", HTTPRequest("http_request", client), HTTPResponse("http_response", 200, -1, 0), HTTPRequest("nil", nil))
+
+	want := `{"level":"info","http_request":{"method":"POST","url":"/users?page=2","host":"example.com","scheme":"http","proto":"HTTP/1.1","remote_addr":"192.0.2.1:1234","user_agent":"curl/8.0","size":2},"http_response":{"status":201,"size":12,"duration":2000},"message":"served"}` + "\n" +
+		`{"level":"info","http_request":{"method":"GET","url":"https://api.example.com/v1/orders","host":"api.example.com","scheme":"https","proto":"HTTP/1.1"},"http_response":{"status":200,"duration":0},"nil":null,"message":"called"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
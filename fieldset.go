@@ -0,0 +1,65 @@
+package rz
+
+import "reflect"
+
+// FieldSet is a group of fields encoded once, attached cheaply to many events or loggers with
+// Field, e.g. the fields describing a host or a deployment logged by every event of hot paths.
+// It's immutable and safe for concurrent use.
+//
+//     deployment := log.NewFieldSet(rz.String("region", region), rz.String("version", version))
+//     log.Info("request served", deployment.Field(), rz.Int("status", status))
+//
+// The fields changing the options of the events (Caller, Stack, Timestamp...) have no effect.
+type FieldSet struct {
+	encoder Encoder
+	buf     []byte
+	fields  []Field
+}
+
+// NewFieldSet encodes the fields with the encoder of the logger.
+func (l Logger) NewFieldSet(fields ...Field) *FieldSet {
+	e := newEvent(nil, l.level)
+	e.buf = nil
+	copyInternalLoggerFieldsToEvent(&l, e)
+	for i := range fields {
+		fields[i](e)
+	}
+	fs := &FieldSet{
+		encoder: e.encoder,
+		buf:     append([]byte(nil), e.buf...),
+		fields:  fields,
+	}
+	putEvent(e)
+	return fs
+}
+
+// Field returns a Field adding the fields of the set. The pre-encoded fields are copied as is
+// into the events using the same encoding as the set, and encoded again for the other ones.
+func (fs *FieldSet) Field() Field {
+	return fs.append
+}
+
+func (fs *FieldSet) append(e *Event) {
+	if len(fs.buf) == 0 {
+		return
+	}
+	if sameEncoding(e.encoder, fs.encoder) {
+		e.buf = e.encoder.AppendObjectData(e.buf, fs.buf)
+		return
+	}
+	for i := range fs.fields {
+		fs.fields[i](e)
+	}
+}
+
+// sameEncoding returns true if the data encoded by a can be appended as is by b.
+func sameEncoding(a, b Encoder) bool {
+	if jsonBased(a) && jsonBased(b) {
+		return true
+	}
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	return !ta.Comparable() || a == b
+}
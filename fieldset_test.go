@@ -0,0 +1,64 @@
+package rz
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestFieldSet(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	deployment := log.NewFieldSet(String("region", "eu-west-1"), Int("replica", 3))
+
+	log.Info("first", deployment.Field(), Int("status", 200))
+	child := log.With(Fields(deployment.Field()))
+	child.Info("second", log.NewFieldSet().Field())
+	logfmt := New(Writer(out), Fields(Timestamp(false)), UseEncoder(LogfmtEncoder{}))
+	logfmt.Info("third", deployment.Field())
+
+	want := `{"level":"info","region":"eu-west-1","replica":3,"status":200,"message":"first"}` + "\n" +
+		`{"level":"info","region":"eu-west-1","replica":3,"message":"second"}` + "\n" +
+		`level=info region=eu-west-1 replica=3 message=third` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type testEncoder struct {
+	JSONEncoder
+	name string
+}
+
+func TestFieldSetOtherEncoder(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	fs := log.NewFieldSet(String("region", "eu-west-1"))
+	other := New(Writer(out), Fields(Timestamp(false)), UseEncoder(testEncoder{name: "other"}))
+
+	e := newEvent(nil, InfoLevel)
+	e.setEncoder(testEncoder{name: "other"})
+	if sameEncoding(e.encoder, fs.encoder) {
+		t.Error("sameEncoding() = true for different encoders")
+	}
+	other.Info("hello", fs.Field())
+	if got, want := out.String(), `{"level":"info","region":"eu-west-1","message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func BenchmarkFieldSet(b *testing.B) {
+	log := New(Writer(ioutil.Discard))
+	fields := []Field{String("region", "eu-west-1"), String("version", "v1.2.3"), Int("replica", 3), Bool("canary", false)}
+	b.Run("Fields", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			log.Info("hello", fields...)
+		}
+	})
+	fs := log.NewFieldSet(fields...)
+	b.Run("FieldSet", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			log.Info("hello", fs.Field())
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package rz
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	// FlowIDFieldName is the field name used for the flow ID by Logger.Flow.
+	FlowIDFieldName = "flow_id"
+	// FlowStepFieldName is the field name used for the flow step by Logger.Flow.
+	FlowStepFieldName = "flow_step"
+)
+
+type flowStep struct {
+	id   string
+	step *uint64
+}
+
+// Process implements the LogProcessor interface.
+func (f flowStep) Process(e *Event, level LogLevel, message string) {
+	e.uint64(FlowStepFieldName, atomic.AddUint64(f.step, 1))
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (f flowStep) DescribeConfig() string {
+	return "flow(" + strconv.Quote(f.id) + ")"
+}
+
+// Flow returns a child logger for the workflow identified by id. Each event logged by the child
+// logger (and its own children) is stamped with the flow_id field and a flow_step field, starting
+// at 1 and atomically incremented, making it trivial to reconstruct the ordered narrative of a
+// single workflow in aggregated logs.
+func (l Logger) Flow(id string, options ...LoggerOption) Logger {
+	step := flowStep{id: id, step: new(uint64)}
+	options = append([]LoggerOption{
+		Fields(String(FlowIDFieldName, id)),
+		AddProcessor(EnrichStage, step),
+	}, options...)
+	return l.With(options...)
+}
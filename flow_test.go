@@ -0,0 +1,26 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFlow(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), Level(InfoLevel))
+	flow := log.Flow("checkout-42")
+	flow.Info("started")
+	flow.Debug("filtered out")
+	sub := flow.With(Fields(String("sub", "payment")))
+	sub.Info("paid")
+	log.Info("outside")
+	flow.Info("done")
+
+	want := `{"level":"info","flow_id":"checkout-42","flow_step":1,"message":"started"}` + "\n" +
+		`{"level":"info","flow_id":"checkout-42","sub":"payment","flow_step":2,"message":"paid"}` + "\n" +
+		`{"level":"info","message":"outside"}` + "\n" +
+		`{"level":"info","flow_id":"checkout-42","flow_step":3,"message":"done"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -24,6 +24,25 @@ const (
 
 // FormatterConsole prettify output for human cosumption
 func FormatterConsole() LogFormatter {
+	return formatterConsole(func(level string) (int, bool) {
+		return levelColor(level), true
+	})
+}
+
+// FormatterConsoleColors is FormatterConsole with the given ANSI colors (SGR codes, e.g. 33 for
+// yellow) for each level. The levels without color are not colorized.
+func FormatterConsoleColors(colors map[LogLevel]int) LogFormatter {
+	return formatterConsole(func(level string) (int, bool) {
+		l, err := ParseLevel(level)
+		if err != nil {
+			return cReset, false
+		}
+		color, ok := colors[l]
+		return color, ok
+	})
+}
+
+func formatterConsole(color func(level string) (int, bool)) LogFormatter {
 	return func(ev *Event) ([]byte, error) {
 		var event map[string]interface{}
 		var ret = new(bytes.Buffer)
@@ -35,12 +54,18 @@ func FormatterConsole() LogFormatter {
 			return ret.Bytes(), err
 		}
 
-		lvlColor := cReset
+		lvlColor, colored := color("")
 		level := "????"
 		if l, ok := event[DefaultLevelFieldName].(string); ok {
-			lvlColor = levelColor(l)
+			lvlColor, colored = color(l)
 			level = strings.ToUpper(l)[0:4]
 		}
+		paint := func(s string) string {
+			if colored {
+				return colorize(s, lvlColor)
+			}
+			return s
+		}
 
 		message := ""
 		if m, ok := event[DefaultMessageFieldName].(string); ok {
@@ -54,7 +79,7 @@ func FormatterConsole() LogFormatter {
 
 		ret.WriteString(fmt.Sprintf("%-20s |%-4s|",
 			timestamp,
-			colorize(level, lvlColor),
+			paint(level),
 		))
 		if message != "" {
 			ret.WriteString(" " + message)
@@ -75,7 +100,7 @@ func FormatterConsole() LogFormatter {
 			if needsQuote(field) {
 				field = strconv.Quote(field)
 			}
-			fmt.Fprintf(ret, " %s=", colorize(field, lvlColor))
+			fmt.Fprintf(ret, " %s=", paint(field))
 
 			switch value := event[field].(type) {
 			case string:
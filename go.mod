@@ -1,3 +1,10 @@
 module github.com/skerkour/rz
 
-go 1.16
+go 1.18
+
+require (
+	github.com/go-chi/chi v1.5.5
+	github.com/go-logr/logr v1.4.2
+	github.com/google/uuid v1.6.0
+	github.com/pkg/errors v0.9.1
+)
@@ -0,0 +1,43 @@
+package rz
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+)
+
+// HashFunc creates the hash used by the features hashing data (pseudonymized fields, hash based
+// sampling, request bodies...). Only cryptographic hashes approved by FIPS 140 are provided,
+// so FIPS bound deployments can comply by choosing one of them. Keys that never leave the
+// process, like the deduplication keys of Deduplicator, use FNV-1a instead.
+type HashFunc func() hash.Hash
+
+// DefaultHash is the HashFunc used when none is configured.
+var DefaultHash HashFunc = SHA256
+
+// SHA256 returns a new SHA-256 hash.
+func SHA256() hash.Hash {
+	return sha256.New()
+}
+
+// SHA512 returns a new SHA-512 hash.
+func SHA512() hash.Hash {
+	return sha512.New()
+}
+
+// Hashed adds the field key with the hex encoded DefaultHash of value to the *Event context,
+// e.g. to pseudonymize an identifier while keeping events correlated.
+func Hashed(key, value string) Field {
+	return HashedWith(DefaultHash, key, value)
+}
+
+// HashedWith adds the field key with the hex encoded hash of value, computed with hashFunc, to
+// the *Event context.
+func HashedWith(hashFunc HashFunc, key, value string) Field {
+	return func(e *Event) {
+		h := hashFunc()
+		h.Write([]byte(value))
+		e.string(key, hex.EncodeToString(h.Sum(nil)))
+	}
+}
@@ -0,0 +1,19 @@
+//go:build go1.24
+// +build go1.24
+
+package rz
+
+import (
+	"crypto/sha3"
+	"hash"
+)
+
+// SHA3_256 returns a new SHA3-256 hash.
+func SHA3_256() hash.Hash {
+	return sha3.New256()
+}
+
+// SHA3_512 returns a new SHA3-512 hash.
+func SHA3_512() hash.Hash {
+	return sha3.New512()
+}
@@ -0,0 +1,18 @@
+//go:build go1.24
+// +build go1.24
+
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHashedSHA3(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	log.Log("", HashedWith(SHA3_256, "user", "alice"))
+	if got, want := out.String(), `{"user":"a7dcef9aef26202fce82a7c7d6672afb3a149db207d90a07e437d5abc7fc99ed"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHashed(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+		want  string
+	}{
+		{"Default", Hashed("user", "alice"), `{"user":"2bd806c97f0e00af1a1fc3328fa763a9269723c8db8fac4f93af71db186d6e90"}` + "\n"},
+		{"SHA512", HashedWith(SHA512, "user", "alice"), `{"user":"408b27d3097eea5a46bf2ab6433a7234a33d5e49957b13ec7acc2ca08e1a13c75272c90c8d3385d47ede5420a7a9623aad817d9f8a70bd100a0acea7400daa59"}` + "\n"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			log := New(Writer(out), Fields(Timestamp(false)))
+			log.Log("", tt.field)
+			if got := out.String(); got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
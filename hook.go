@@ -1,6 +1,8 @@
 package rz
 
-// LogHook defines an interface to a log hook.
+// LogHook defines an interface to a log hook. Hooks run before the processors, and can enrich
+// the event (Event.Append), modify or remove its fields (Event.Remove), change its level and
+// message (Event.SetLevel and Event.SetMessage) or veto it (Event.Discard).
 type LogHook interface {
 	// Run runs the hook with the event.
 	Run(e *Event, level LogLevel, message string)
@@ -0,0 +1,128 @@
+package rz
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Priorities of the hooks of a HookRegistry, ordering them by purpose: enrichment hooks run
+// before the redaction ones, so the redaction also applies to the fields added by enrichment.
+// Any other value can be used to order hooks within them.
+const (
+	HookPriorityEarly     = -100
+	HookPriorityEnrich    = 0
+	HookPriorityRedact    = 100
+	HookPriorityTransform = 200
+	HookPriorityLate      = 300
+)
+
+// RegisteredHook describes a hook of a HookRegistry.
+type RegisteredHook struct {
+	ID       string
+	Priority int
+	Hook     LogHook
+}
+
+// HookRegistry is a LogHook running a set of hooks in a defined order: by ascending priority,
+// then by registration order. Hooks can be registered and removed by id at runtime, e.g. by
+// several packages of an application, and the changes apply to all the loggers the registry
+// was added to with AddHook. It's safe for concurrent use; running the hooks takes no lock.
+type HookRegistry struct {
+	mu    sync.Mutex
+	seq   uint64
+	hooks atomic.Value // []registeredHook, sorted
+}
+
+type registeredHook struct {
+	RegisteredHook
+	seq uint64
+}
+
+// NewHookRegistry creates an empty HookRegistry.
+func NewHookRegistry() *HookRegistry {
+	r := &HookRegistry{}
+	r.hooks.Store([]registeredHook{})
+	return r
+}
+
+func (r *HookRegistry) load() []registeredHook {
+	hooks, _ := r.hooks.Load().([]registeredHook)
+	return hooks
+}
+
+// Register registers hook with the given id and priority. A hook already registered with id is
+// replaced, and ordered as a new registration.
+func (r *HookRegistry) Register(id string, priority int, hook LogHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	hooks := r.without(id)
+	hooks = append(hooks, registeredHook{
+		RegisteredHook: RegisteredHook{ID: id, Priority: priority, Hook: hook},
+		seq:            r.seq,
+	})
+	sort.Slice(hooks, func(i, j int) bool {
+		if hooks[i].Priority != hooks[j].Priority {
+			return hooks[i].Priority < hooks[j].Priority
+		}
+		return hooks[i].seq < hooks[j].seq
+	})
+	r.hooks.Store(hooks)
+}
+
+// Remove removes the hook registered with id, and returns false if there is none.
+func (r *HookRegistry) Remove(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hooks := r.without(id)
+	if len(hooks) == len(r.load()) {
+		return false
+	}
+	r.hooks.Store(hooks)
+	return true
+}
+
+// without returns a copy of the hooks without the one registered with id. r.mu must be held.
+func (r *HookRegistry) without(id string) []registeredHook {
+	current := r.load()
+	hooks := make([]registeredHook, 0, len(current)+1)
+	for _, hook := range current {
+		if hook.ID != id {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// Hooks returns the registered hooks, in execution order.
+func (r *HookRegistry) Hooks() []RegisteredHook {
+	current := r.load()
+	hooks := make([]RegisteredHook, len(current))
+	for i := range current {
+		hooks[i] = current[i].RegisteredHook
+	}
+	return hooks
+}
+
+// Run implements the LogHook interface. The hooks after one disabling the event are not run.
+func (r *HookRegistry) Run(e *Event, level LogLevel, message string) {
+	for _, hook := range r.load() {
+		if e.level == Disabled {
+			return
+		}
+		hook.Hook.Run(e, level, message)
+	}
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (r *HookRegistry) DescribeConfig() string {
+	hooks := r.load()
+	ids := make([]string, len(hooks))
+	for i, hook := range hooks {
+		ids[i] = hook.ID + "=" + strconv.Itoa(hook.Priority)
+	}
+	return "hook_registry(" + strings.Join(ids, ", ") + ")"
+}
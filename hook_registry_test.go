@@ -0,0 +1,53 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHookRegistry(t *testing.T) {
+	registry := NewHookRegistry()
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), AddHook(registry))
+
+	stringHook := func(key, value string) LogHook {
+		return HookFunc(func(e *Event, level LogLevel, message string) {
+			e.Append(String(key, value))
+		})
+	}
+	registry.Register("redact", HookPriorityRedact, stringHook("b", "redact"))
+	registry.Register("enrich", HookPriorityEnrich, stringHook("a", "enrich"))
+	registry.Register("enrich2", HookPriorityEnrich, stringHook("c", "enrich2"))
+	log.Info("hello")
+	if got, want := out.String(), `{"level":"info","a":"enrich","c":"enrich2","b":"redact","message":"hello"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	out.Reset()
+	if !registry.Remove("enrich") {
+		t.Error("Remove must return true for a registered hook")
+	}
+	if registry.Remove("missing") {
+		t.Error("Remove must return false for an unknown hook")
+	}
+	registry.Register("redact", HookPriorityEarly, stringHook("b", "early"))
+	log.Info("hello")
+	if got, want := out.String(), `{"level":"info","b":"early","c":"enrich2","message":"hello"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	hooks := registry.Hooks()
+	if len(hooks) != 2 || hooks[0].ID != "redact" || hooks[1].ID != "enrich2" {
+		t.Errorf("unexpected hooks: %v", hooks)
+	}
+	if got, want := registry.DescribeConfig(), "hook_registry(redact=-100, enrich2=0)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	out.Reset()
+	registry.Register("discard", HookPriorityEarly-1, discardHook)
+	log.Info("hello")
+	if out.Len() != 0 {
+		t.Errorf("the event must be discarded, got %q", out.String())
+	}
+}
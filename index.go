@@ -0,0 +1,199 @@
+package rz
+
+import (
+	"hash/fnv"
+	"io"
+	"math"
+	"sync"
+)
+
+// BloomFilter is a probabilistic set of strings: MayContain never returns false for an added
+// value, and returns true for other values with the configured false positive rate.
+// It uses FNV-1a, which is not a cryptographic hash.
+type BloomFilter struct {
+	Bits   []byte `json:"bits"`
+	Hashes uint32 `json:"hashes"`
+}
+
+// NewBloomFilter returns a BloomFilter sized for n values with the false positive rate fpRate.
+func NewBloomFilter(n int, fpRate float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &BloomFilter{Bits: make([]byte, (int(m)+7)/8), Hashes: uint32(k)}
+}
+
+// positions calls f with the bit positions of value, using double hashing.
+func (b *BloomFilter) positions(value string, f func(bit uint64) bool) bool {
+	h := fnv.New64a()
+	io.WriteString(h, value)
+	h1 := h.Sum64()
+	h2 := h1>>33 | h1<<31 | 1
+	size := uint64(len(b.Bits)) * 8
+	for i := uint64(0); i < uint64(b.Hashes); i++ {
+		if !f((h1 + i*h2) % size) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds value to the filter.
+func (b *BloomFilter) Add(value string) {
+	if len(b.Bits) == 0 {
+		return
+	}
+	b.positions(value, func(bit uint64) bool {
+		b.Bits[bit/8] |= 1 << (bit % 8)
+		return true
+	})
+}
+
+// MayContain returns false if value was definitely not added to the filter.
+func (b *BloomFilter) MayContain(value string) bool {
+	if len(b.Bits) == 0 {
+		return false
+	}
+	return b.positions(value, func(bit uint64) bool {
+		return b.Bits[bit/8]&(1<<(bit%8)) != 0
+	})
+}
+
+// FieldIndex indexes the values of a field in a set of events.
+type FieldIndex struct {
+	Bloom *BloomFilter `json:"bloom"`
+	// Min and Max are the lowest and highest values, compared as strings.
+	Min   string `json:"min"`
+	Max   string `json:"max"`
+	Count uint64 `json:"count"`
+}
+
+// MayContain returns false if value is definitely not a value of the field.
+func (ix *FieldIndex) MayContain(value string) bool {
+	return ix.Count > 0 && value >= ix.Min && value <= ix.Max && ix.Bloom.MayContain(value)
+}
+
+// ObjectIndex is a sidecar index of an archived log object (e.g. a file in an object storage):
+// a bloom filter and the min/max of the values of selected fields, so searches can skip the
+// objects which can't contain a value. It's serializable with encoding/json.
+type ObjectIndex struct {
+	Events uint64                 `json:"events"`
+	Fields map[string]*FieldIndex `json:"fields"`
+}
+
+// MayContain returns false if no event of the object has the value for field. It returns true
+// if the field is not indexed.
+func (ix *ObjectIndex) MayContain(field, value string) bool {
+	f, ok := ix.Fields[field]
+	return !ok || f.MayContain(value)
+}
+
+// Indexer builds the ObjectIndex of the events written to an archived object. A writer
+// archiving objects calls Add for each event and Rotate when an object is complete, like
+// RotatingFileWriter with the RotateIndex option.
+// Only string values are indexed. It's safe for concurrent use.
+type Indexer struct {
+	fields         []string
+	keys           [][]byte
+	expectedValues int
+	fpRate         float64
+
+	mu    sync.Mutex
+	index *ObjectIndex
+}
+
+// NewIndexer returns an Indexer of the given fields, with bloom filters sized for expectedValues
+// values per object with the false positive rate fpRate.
+func NewIndexer(expectedValues int, fpRate float64, fields ...string) *Indexer {
+	ix := &Indexer{fields: fields, expectedValues: expectedValues, fpRate: fpRate}
+	for _, field := range fields {
+		ix.keys = append(ix.keys, fieldKey(field))
+	}
+	ix.index = ix.newIndex()
+	return ix
+}
+
+func (ix *Indexer) newIndex() *ObjectIndex {
+	index := &ObjectIndex{Fields: make(map[string]*FieldIndex, len(ix.fields))}
+	for _, field := range ix.fields {
+		index.Fields[field] = &FieldIndex{Bloom: NewBloomFilter(ix.expectedValues, ix.fpRate)}
+	}
+	return index
+}
+
+// Add indexes the encoded (JSON) event.
+func (ix *Indexer) Add(event []byte) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.index.Events++
+	for i, field := range ix.fields {
+		value, ok := stringFieldValue(event, ix.keys[i])
+		if !ok {
+			continue
+		}
+		f := ix.index.Fields[field]
+		if f.Count == 0 || value < f.Min {
+			f.Min = value
+		}
+		if f.Count == 0 || value > f.Max {
+			f.Max = value
+		}
+		f.Count++
+		f.Bloom.Add(value)
+	}
+}
+
+// Rotate returns the index of the events added since the last rotation, and starts a new one.
+func (ix *Indexer) Rotate() *ObjectIndex {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	index := ix.index
+	ix.index = ix.newIndex()
+	return index
+}
+
+type indexWriter struct {
+	lw      LevelWriter
+	indexer *Indexer
+}
+
+// IndexWriter wraps w so that the events written to it are added to indexer.
+func IndexWriter(w io.Writer, indexer *Indexer) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return indexWriter{lw: lw, indexer: indexer}
+}
+
+// Write implements the io.Writer interface.
+func (w indexWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. Only the events successfully written
+// are indexed.
+func (w indexWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	if n, err = w.lw.WriteLevel(level, p); err == nil {
+		w.indexer.Add(p)
+	}
+	return
+}
+
+// Flush implements the Flusher interface.
+func (w indexWriter) Flush() error {
+	return flush(w.lw)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w indexWriter) DescribeConfig() string {
+	return "index(" + describe(w.lw) + ")"
+}
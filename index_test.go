@@ -0,0 +1,72 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	b := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		b.Add("value-" + strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !b.MayContain("value-" + strconv.Itoa(i)) {
+			t.Fatalf("false negative for value-%d", i)
+		}
+	}
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if b.MayContain("other-" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("too many false positives: %d/10000", falsePositives)
+	}
+}
+
+func TestIndexWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	indexer := NewIndexer(100, 0.01, "trace_id", "user_id")
+	log := New(Writer(IndexWriter(out, indexer)), Fields(Timestamp(false)))
+	log.Info("a", String("trace_id", "t2"), String("user_id", "42"))
+	log.Info("b", String("trace_id", "t1"), Int("user_id", 43))
+	log.Info("c")
+
+	index := indexer.Rotate()
+	if index.Events != 3 || index.Fields["trace_id"].Min != "t1" || index.Fields["trace_id"].Max != "t2" || index.Fields["user_id"].Count != 1 {
+		t.Errorf("invalid index: %+v", index)
+	}
+
+	// the index survives serialization
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index = &ObjectIndex{}
+	if err = json.Unmarshal(data, index); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field, value string
+		want         bool
+	}{
+		{"trace_id", "t1", true},
+		{"trace_id", "t3", false},
+		{"trace_id", "t0", false},
+		{"user_id", "42", true},
+		{"user_id", "43", false},
+		{"message", "a", true},
+	}
+	for _, tt := range tests {
+		if got := index.MayContain(tt.field, tt.value); got != tt.want {
+			t.Errorf("%s=%s: got %v, want %v", tt.field, tt.value, got, tt.want)
+		}
+	}
+	if next := indexer.Rotate(); next.Events != 0 {
+		t.Errorf("index not rotated: %+v", next)
+	}
+}
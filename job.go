@@ -0,0 +1,83 @@
+package rz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ErrJobPanicked is wrapped by the error returned by JobLogger.Run when the job panics.
+var ErrJobPanicked = errors.New("rz: job panicked")
+
+// Job identifies a run of a background job.
+type Job struct {
+	ID   string
+	Type string
+	// Attempt is the number of the attempt, starting at 1. It's omitted if <= 0.
+	Attempt int
+}
+
+// JobLogger logs a run of a background job with a consistent schema: all its events have the
+// fields "job_id", "job_type" and "attempt", and Run logs its start and its outcome.
+//
+//     err := logger.ForJob(rz.Job{ID: msg.ID, Type: "send_email", Attempt: msg.Attempts}).
+//         Run(ctx, func(ctx context.Context) error {
+//             rz.FromCtx(ctx).Debug("rendering template")
+//             return send(ctx, msg)
+//         })
+type JobLogger struct {
+	Logger
+	job Job
+}
+
+// ForJob returns a JobLogger for job, logging with a copy of the logger with the job fields.
+func (l Logger) ForJob(job Job) *JobLogger {
+	fields := []Field{String("job_id", job.ID), String("job_type", job.Type)}
+	if job.Attempt > 0 {
+		fields = append(fields, Int("attempt", job.Attempt))
+	}
+	return &JobLogger{Logger: l.With(Fields(fields...)), job: job}
+}
+
+// Job returns the job logged.
+func (j *JobLogger) Job() Job {
+	return j.job
+}
+
+// Run runs fn with a copy of ctx holding the logger of the job (see FromCtx). It logs "job
+// started" at debug level, then "job finished" with the fields "status" and "duration":
+//   - "ok" at info level if fn returns nil;
+//   - "canceled" at warn level if ctx is done and fn returns its error;
+//   - "error" at error level with the error if fn returns another error;
+//   - "panic" at error level with the fields "panic" and "stack" if fn panics. The panic is
+//     recovered, and Run returns an error wrapping ErrJobPanicked so the worker keeps running.
+//
+// Run returns the error of fn.
+func (j *JobLogger) Run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	start := time.Now()
+	j.Debug("job started")
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanicked, r)
+			j.Error("job finished",
+				String("status", "panic"),
+				Duration("duration", time.Since(start)),
+				String("panic", fmt.Sprint(r)),
+				String("stack", string(debug.Stack())),
+			)
+			return
+		}
+		level, status := InfoLevel, "ok"
+		switch {
+		case err == nil:
+		case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+			level, status = WarnLevel, "canceled"
+		default:
+			level, status = ErrorLevel, "error"
+		}
+		j.LogWithLevel(level, "job finished", String("status", status), Duration("duration", time.Since(start)), Err(err))
+	}()
+	return fn(j.Logger.ToCtx(ctx))
+}
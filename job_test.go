@@ -0,0 +1,93 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func decodeJobEvents(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		event := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	out.Reset()
+	return events
+}
+
+func TestJobLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	job := log.ForJob(Job{ID: "42", Type: "send_email", Attempt: 2})
+
+	err := job.Run(context.Background(), func(ctx context.Context) error {
+		FromCtx(ctx).Info("sending")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := decodeJobEvents(t, out)
+	if len(events) != 3 {
+		t.Fatalf("invalid number of events: %d", len(events))
+	}
+	for _, event := range events {
+		if event["job_id"] != "42" || event["job_type"] != "send_email" || event["attempt"] != 2.0 {
+			t.Errorf("event without the job fields: %v", event)
+		}
+	}
+	if events[0]["message"] != "job started" || events[0]["level"] != "debug" || events[1]["message"] != "sending" {
+		t.Errorf("invalid events: %v", events)
+	}
+	if events[2]["message"] != "job finished" || events[2]["level"] != "info" || events[2]["status"] != "ok" ||
+		events[2]["duration"] == nil || events[2]["error"] != nil {
+		t.Errorf("invalid outcome event: %v", events[2])
+	}
+}
+
+func TestJobLoggerOutcomes(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	job := log.ForJob(Job{ID: "1", Type: "export"})
+
+	err := job.Run(context.Background(), func(ctx context.Context) error {
+		return errors.New("disk full")
+	})
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Run() = %v", err)
+	}
+	if outcome := decodeJobEvents(t, out)[1]; outcome["level"] != "error" || outcome["status"] != "error" || outcome["error"] != "disk full" || outcome["attempt"] != nil {
+		t.Errorf("invalid outcome event: %v", outcome)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = job.Run(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() = %v", err)
+	}
+	if outcome := decodeJobEvents(t, out)[1]; outcome["level"] != "warning" || outcome["status"] != "canceled" {
+		t.Errorf("invalid outcome event: %v", outcome)
+	}
+
+	err = job.Run(context.Background(), func(ctx context.Context) error {
+		panic("nil map")
+	})
+	if !errors.Is(err, ErrJobPanicked) {
+		t.Errorf("Run() = %v", err)
+	}
+	if outcome := decodeJobEvents(t, out)[1]; outcome["level"] != "error" || outcome["status"] != "panic" || outcome["panic"] != "nil map" ||
+		!strings.Contains(outcome["stack"].(string), "job_test.go") {
+		t.Errorf("invalid outcome event: %v", outcome)
+	}
+}
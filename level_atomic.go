@@ -0,0 +1,50 @@
+package rz
+
+import "sync/atomic"
+
+// AtomicLevel is a log level which can be changed while the loggers using it are logging, e.g.
+// to switch a production service to debug level without restarting it (see LevelHandler).
+// The zero value is DebugLevel. It's safe for concurrent use.
+//
+//     level := rz.NewAtomicLevel(rz.InfoLevel)
+//     log := rz.New(rz.DynamicLevel(level))
+//     level.SetLevel(rz.DebugLevel)
+type AtomicLevel struct {
+	level uint32
+}
+
+// NewAtomicLevel returns an AtomicLevel set to level.
+func NewAtomicLevel(level LogLevel) *AtomicLevel {
+	return &AtomicLevel{level: uint32(level)}
+}
+
+// Level returns the current level.
+func (a *AtomicLevel) Level() LogLevel {
+	return LogLevel(atomic.LoadUint32(&a.level))
+}
+
+// SetLevel changes the level of all the loggers using a.
+func (a *AtomicLevel) SetLevel(level LogLevel) {
+	atomic.StoreUint32(&a.level, uint32(level))
+}
+
+// String returns the name of the current level.
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// DynamicLevel makes logger and the loggers derived from it use the current level of level,
+// instead of a fixed one. It's replaced by the Level option and by the level overrides of ForCtx.
+func DynamicLevel(level *AtomicLevel) LoggerOption {
+	return func(logger *Logger) {
+		logger.atomicLevel = level
+	}
+}
+
+// currentLevel returns the level of the logger, the one of its AtomicLevel if it has one.
+func (l *Logger) currentLevel() LogLevel {
+	if l.atomicLevel != nil {
+		return l.atomicLevel.Level()
+	}
+	return l.level
+}
@@ -0,0 +1,81 @@
+package rz
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// LevelHandler is an http.Handler reading and changing the level of loggers at runtime, so
+// operators can switch a service to debug level without redeploying it. GET returns the current
+// level as {"level":"info"}, PUT sets it from a {"level":"debug"} body or a level query
+// parameter, and returns the new one. Level names are case insensitive and warn is accepted for
+// warning. The logger query parameter selects a named level of Loggers, Level is used without
+// it. Mount it on an internal or authenticated route only:
+//
+//     level := rz.NewAtomicLevel(rz.InfoLevel)
+//     log.SetLogger(rz.New(rz.DynamicLevel(level)))
+//     http.Handle("/debug/level", rz.LevelHandler{Level: level})
+//
+//     curl -X PUT -d '{"level":"debug"}' localhost:8080/debug/level
+type LevelHandler struct {
+	// Level is the level used without the logger query parameter, e.g. the one of the global
+	// logger.
+	Level *AtomicLevel
+	// Loggers are the named levels, e.g. by module.
+	Loggers map[string]*AtomicLevel
+}
+
+type levelPayload struct {
+	Logger string `json:"logger,omitempty"`
+	Level  string `json:"level,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("logger")
+	level := h.Level
+	if name != "" {
+		level = h.Loggers[name]
+	}
+	if level == nil {
+		writeLevelPayload(w, http.StatusNotFound, levelPayload{Logger: name, Error: "unknown logger"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		levelStr := r.URL.Query().Get("level")
+		if levelStr == "" {
+			var payload levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeLevelPayload(w, http.StatusBadRequest, levelPayload{Logger: name, Error: "invalid body: " + err.Error()})
+				return
+			}
+			levelStr = payload.Level
+		}
+		normalized := strings.ToLower(levelStr)
+		if normalized == "warn" {
+			normalized = WarnLevel.String()
+		}
+		lvl, err := ParseLevel(normalized)
+		if err != nil || lvl == NoLevel {
+			writeLevelPayload(w, http.StatusBadRequest, levelPayload{Logger: name, Error: "invalid level: " + levelStr})
+			return
+		}
+		level.SetLevel(lvl)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelPayload(w, http.StatusMethodNotAllowed, levelPayload{Logger: name, Error: "method not allowed"})
+		return
+	}
+	writeLevelPayload(w, http.StatusOK, levelPayload{Logger: name, Level: level.String()})
+}
+
+func writeLevelPayload(w http.ResponseWriter, status int, payload levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
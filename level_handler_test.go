@@ -0,0 +1,73 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAtomicLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	level := NewAtomicLevel(InfoLevel)
+	log := New(Writer(out), Fields(Timestamp(false)), DynamicLevel(level))
+	child := log.With(Fields(String("component", "db")))
+
+	child.Debug("dropped")
+	level.SetLevel(DebugLevel)
+	child.Debug("kept")
+	if got := log.GetLevel(); got != DebugLevel {
+		t.Errorf("GetLevel() = %v, want %v", got, DebugLevel)
+	}
+	fixed := log.With(Level(ErrorLevel))
+	fixed.Warn("dropped")
+	overridden := fixed.ForCtx(WithLevelOverride(context.Background(), WarnLevel))
+	overridden.Warn("override")
+
+	want := `{"level":"debug","component":"db","message":"kept"}` + "\n" +
+		`{"level":"warning","message":"override"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	global := NewAtomicLevel(InfoLevel)
+	db := NewAtomicLevel(WarnLevel)
+	handler := LevelHandler{Level: global, Loggers: map[string]*AtomicLevel{"db": db}}
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		response             string
+	}{
+		{"GET", "/", "", http.StatusOK, `{"level":"info"}`},
+		{"PUT", "/", `{"level":"debug"}`, http.StatusOK, `{"level":"debug"}`},
+		{"GET", "/?logger=db", "", http.StatusOK, `{"logger":"db","level":"warning"}`},
+		{"PUT", "/?logger=db&level=ERROR", "", http.StatusOK, `{"logger":"db","level":"error"}`},
+		{"PUT", "/", `{"level":"warn"}`, http.StatusOK, `{"level":"warning"}`},
+		{"PUT", "/?logger=db&level=WARN", "", http.StatusOK, `{"logger":"db","level":"warning"}`},
+		{"PUT", "/", `{"level":"verbose"}`, http.StatusBadRequest, `{"error":"invalid level: verbose"}`},
+		{"PUT", "/", `{}`, http.StatusBadRequest, `{"error":"invalid level: "}`},
+		{"GET", "/?logger=cache", "", http.StatusNotFound, `{"logger":"cache","error":"unknown logger"}`},
+		{"POST", "/", "", http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, rec.Code, tt.status)
+		}
+		if got := strings.TrimSpace(rec.Body.String()); got != tt.response {
+			t.Errorf("%s %s: response = %s, want %s", tt.method, tt.target, got, tt.response)
+		}
+	}
+	if got := global.Level(); got != WarnLevel {
+		t.Errorf("global level = %v, want %v", got, WarnLevel)
+	}
+	if got := db.Level(); got != WarnLevel {
+		t.Errorf("db level = %v, want %v", got, WarnLevel)
+	}
+}
@@ -0,0 +1,112 @@
+package rz
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var libraries = struct {
+	sync.Mutex
+	loggers map[string]*LibraryLogger
+	configs map[string][]LoggerOption // by name prefix
+}{
+	loggers: map[string]*LibraryLogger{},
+	configs: map[string][]LoggerOption{},
+}
+
+// LibraryLogger is the logger of a library, silent until the host application enables it with
+// EnableLibraryLogger, so libraries can log with rz without writing anything for the
+// applications which didn't opt in:
+//
+//     var log = rz.NewLibraryLogger("github.com/org/lib")
+//
+//     func Connect() {
+//         log.Logger().Info("connecting")
+//     }
+//
+// The events of an enabled library logger have the module field set to the name of the library,
+// and are filterable with ModuleFilter.
+type LibraryLogger struct {
+	name   string
+	logger atomic.Value // *Logger
+}
+
+// NewLibraryLogger returns the logger of the library name, usually its module path. It's
+// disabled unless EnableLibraryLogger was called with name or a parent path of name. The same
+// logger is returned for the same name.
+func NewLibraryLogger(name string) *LibraryLogger {
+	libraries.Lock()
+	defer libraries.Unlock()
+	if l, ok := libraries.loggers[name]; ok {
+		return l
+	}
+	l := &LibraryLogger{name: name}
+	l.configure()
+	libraries.loggers[name] = l
+	return l
+}
+
+// configure applies the options of the longest enabled prefix of the name of l, or disables it.
+// libraries must be locked.
+func (l *LibraryLogger) configure() {
+	prefix, found := "", false
+	for path := range libraries.configs {
+		if hasPathPrefix(l.name, path) && (!found || len(path) > len(prefix)) {
+			prefix, found = path, true
+		}
+	}
+	logger := Nop()
+	if found {
+		logger = New(libraries.configs[prefix]...)
+		logger.module = l.name
+		Fields(String(DefaultModuleFieldName, l.name))(&logger)
+	}
+	l.logger.Store(&logger)
+}
+
+// Name returns the name of the library.
+func (l *LibraryLogger) Name() string {
+	return l.name
+}
+
+// Logger returns the current logger of the library, a disabled logger if it's not enabled.
+// It must not be modified.
+func (l *LibraryLogger) Logger() *Logger {
+	return l.logger.Load().(*Logger)
+}
+
+// EnableLibraryLogger enables the loggers of the library name and of its sub paths (e.g.
+// "github.com/org" enables "github.com/org/lib"), created with options, replacing the options of
+// a previous call with name. It applies to the library loggers created later too.
+func EnableLibraryLogger(name string, options ...LoggerOption) {
+	libraries.Lock()
+	defer libraries.Unlock()
+	libraries.configs[name] = options
+	for _, l := range libraries.loggers {
+		l.configure()
+	}
+}
+
+// DisableLibraryLogger reverts EnableLibraryLogger for name: the loggers it enabled are disabled,
+// unless a parent path of their name is enabled.
+func DisableLibraryLogger(name string) {
+	libraries.Lock()
+	defer libraries.Unlock()
+	delete(libraries.configs, name)
+	for _, l := range libraries.loggers {
+		l.configure()
+	}
+}
+
+// LibraryLoggers returns the names of the library loggers created with NewLibraryLogger, sorted.
+func LibraryLoggers() []string {
+	libraries.Lock()
+	defer libraries.Unlock()
+	names := make([]string, 0, len(libraries.loggers))
+	for name := range libraries.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
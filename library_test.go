@@ -0,0 +1,49 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLibraryLogger(t *testing.T) {
+	lib := NewLibraryLogger("github.com/org/lib")
+	if NewLibraryLogger("github.com/org/lib") != lib {
+		t.Error("the same logger must be returned for the same name")
+	}
+	if lib.Logger().GetLevel() != Disabled {
+		t.Error("library loggers must be disabled by default")
+	}
+	lib.Logger().Info("silent")
+
+	out := &bytes.Buffer{}
+	EnableLibraryLogger("github.com/org", Writer(out), Level(InfoLevel), Fields(Timestamp(false)))
+	defer DisableLibraryLogger("github.com/org")
+	lib.Logger().Debug("filtered")
+	lib.Logger().Info("hello")
+	other := NewLibraryLogger("github.com/org/other")
+	other.Logger().Info("world")
+	NewLibraryLogger("github.com/organization/lib").Logger().Info("silent")
+
+	want := `{"level":"info","module":"github.com/org/lib","message":"hello"}
+{"level":"info","module":"github.com/org/other","message":"world"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	names := LibraryLoggers()
+	found := 0
+	for _, name := range names {
+		if name == "github.com/org/lib" || name == "github.com/org/other" {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("unexpected library loggers: %v", names)
+	}
+
+	DisableLibraryLogger("github.com/org")
+	if lib.Logger().GetLevel() != Disabled {
+		t.Error("the library logger must be disabled")
+	}
+}
@@ -0,0 +1,120 @@
+package rz
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// lifecycleFlushTimeout bounds the time spent flushing the writer of the logger in
+// Lifecycle.Exiting, so a stuck writer doesn't prevent the process from exiting.
+const lifecycleFlushTimeout = 5 * time.Second
+
+// Lifecycle logs the lifecycle of a process with a consistent schema: the signals received
+// ("signal received" with the field "signal"), the start of the shutdown ("shutdown started"
+// with "reason" and "uptime") and the exit ("process exiting" with "exit_code", "uptime" and
+// "shutdown_duration" once the shutdown started). It's safe for concurrent use.
+//
+//     lifecycle := logger.Lifecycle()
+//     defer lifecycle.Exiting(0)
+//     ctx, stop := lifecycle.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//     defer stop()
+//     <-ctx.Done()
+//     server.Shutdown(context.Background())
+type Lifecycle struct {
+	logger Logger
+	start  time.Time
+
+	mu         sync.Mutex
+	shutdownAt time.Time
+	exitOnce   sync.Once
+}
+
+// Lifecycle returns a Lifecycle logging with the logger. The uptime is measured from this call.
+func (l Logger) Lifecycle() *Lifecycle {
+	return &Lifecycle{logger: l, start: time.Now()}
+}
+
+// NotifyContext returns a copy of parent canceled when one of signals (all the incoming signals
+// if none is given) is received, like signal.NotifyContext. Each received signal is logged at
+// warn level, and the first one starts the shutdown (see ShutdownStarted). The stop function
+// unregisters the signals.
+func (lc *Lifecycle) NotifyContext(parent context.Context, signals ...os.Signal) (ctx context.Context, stop context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				lc.logger.Warn("signal received", String("signal", sig.String()))
+				lc.ShutdownStarted("signal: " + sig.String())
+				cancel()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// ShutdownStarted logs "shutdown started" at info level with the reason, and starts measuring
+// the shutdown duration. Only the first call is logged.
+func (lc *Lifecycle) ShutdownStarted(reason string) {
+	lc.mu.Lock()
+	if !lc.shutdownAt.IsZero() {
+		lc.mu.Unlock()
+		return
+	}
+	lc.shutdownAt = time.Now()
+	lc.mu.Unlock()
+	lc.logger.Info("shutdown started", String("reason", reason), Duration("uptime", time.Since(lc.start)))
+}
+
+// Exiting logs the final "process exiting" event, at info level if code is 0 or error level
+// otherwise, then flushes the writer of the logger (see Flusher), waiting at most 5 seconds, so
+// the event and the buffered ones are written before the process exits. Defer it in main, or
+// call it before os.Exit (see Exit). Only the first call has an effect.
+func (lc *Lifecycle) Exiting(code int) {
+	lc.exitOnce.Do(func() {
+		fields := []Field{Int("exit_code", code), Duration("uptime", time.Since(lc.start))}
+		lc.mu.Lock()
+		if !lc.shutdownAt.IsZero() {
+			fields = append(fields, Duration("shutdown_duration", time.Since(lc.shutdownAt)))
+		}
+		lc.mu.Unlock()
+		level := InfoLevel
+		if code != 0 {
+			level = ErrorLevel
+		}
+		lc.logger.LogWithLevel(level, "process exiting", fields...)
+
+		if lc.logger.writer != nil {
+			done := make(chan struct{})
+			go func() {
+				flush(lc.logger.writer)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(lifecycleFlushTimeout):
+			}
+		}
+	})
+}
+
+// Exit calls Exiting, then os.Exit with code.
+func (lc *Lifecycle) Exit(code int) {
+	lc.Exiting(code)
+	os.Exit(code)
+}
@@ -0,0 +1,77 @@
+package rz
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeLifecycleEvents(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		event := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestLifecycle(t *testing.T) {
+	out := &syncBuffer{}
+	async := NewAsyncWriter(out)
+	log := New(Writer(async), Fields(Timestamp(false)))
+	lifecycle := log.Lifecycle()
+
+	lifecycle.ShutdownStarted("deploy")
+	lifecycle.ShutdownStarted("again")
+	lifecycle.Exiting(2)
+	lifecycle.Exiting(0)
+
+	// Exiting flushed the asynchronous writer
+	events := decodeLifecycleEvents(t, out.String())
+	if len(events) != 2 {
+		t.Fatalf("invalid number of events: %d\n%s", len(events), out.String())
+	}
+	if events[0]["message"] != "shutdown started" || events[0]["reason"] != "deploy" || events[0]["uptime"] == nil {
+		t.Errorf("invalid shutdown event: %v", events[0])
+	}
+	if events[1]["message"] != "process exiting" || events[1]["level"] != "error" || events[1]["exit_code"] != 2.0 ||
+		events[1]["uptime"] == nil || events[1]["shutdown_duration"] == nil {
+		t.Errorf("invalid exit event: %v", events[1])
+	}
+}
+
+func TestLifecycleNotifyContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can't be sent to the process on windows")
+	}
+	out := &syncBuffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	lifecycle := log.Lifecycle()
+	ctx, stop := lifecycle.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	process, _ := os.FindProcess(os.Getpid())
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the context was not canceled")
+	}
+	stop()
+
+	events := decodeLifecycleEvents(t, out.String())
+	if len(events) != 2 || events[0]["message"] != "signal received" || events[0]["signal"] != "interrupt" ||
+		events[0]["level"] != "warning" || events[1]["reason"] != "signal: interrupt" {
+		t.Errorf("invalid events: %v", events)
+	}
+}
@@ -67,6 +67,36 @@ func Log(message string, fields ...rz.Field) {
 	logger.Log(message, fields...)
 }
 
+// LogWithLevelE logs a new message with the given level and returns the error of the writer.
+func LogWithLevelE(level rz.LogLevel, message string, fields ...rz.Field) error {
+	return logger.LogWithLevelE(level, message, fields...)
+}
+
+// DebugE logs a new message with debug level and returns the error of the writer.
+func DebugE(message string, fields ...rz.Field) error {
+	return logger.DebugE(message, fields...)
+}
+
+// InfoE logs a new message with info level and returns the error of the writer.
+func InfoE(message string, fields ...rz.Field) error {
+	return logger.InfoE(message, fields...)
+}
+
+// WarnE logs a new message with warn level and returns the error of the writer.
+func WarnE(message string, fields ...rz.Field) error {
+	return logger.WarnE(message, fields...)
+}
+
+// ErrorE logs a message with error level and returns the error of the writer.
+func ErrorE(message string, fields ...rz.Field) error {
+	return logger.ErrorE(message, fields...)
+}
+
+// LogE logs a new message with no level and returns the error of the writer.
+func LogE(message string, fields ...rz.Field) error {
+	return logger.LogE(message, fields...)
+}
+
 // Append the fields to the internal logger's context.
 // It does not create a new copy of the logger and rely on a mutex to enable thread safety,
 // so `Config(With(fields...))` often is preferable.
@@ -0,0 +1,82 @@
+package rz
+
+import (
+	"fmt"
+	"os"
+)
+
+// LogConfig logs a single event describing the logger's effective configuration
+// (level, writer, sampler, hooks and processors) under the "config" key.
+// The event is logged without level, and bypasses the logger's sampler.
+//
+// Writers, samplers, hooks and processors implementing the ConfigDescriber interface are
+// described using their DescribeConfig method, otherwise their type name is used.
+func (l *Logger) LogConfig() {
+	logger := *l
+	logger.sampler = nil
+
+	hooks := make([]string, 0, len(l.hooks))
+	for _, hook := range l.hooks {
+		hooks = append(hooks, describe(hook))
+	}
+
+	processors := newDict()
+	if l.processors != nil {
+		for stage := range l.processors {
+			if len(l.processors[stage]) == 0 {
+				continue
+			}
+			stageProcessors := make([]string, 0, len(l.processors[stage]))
+			for _, processor := range l.processors[stage] {
+				stageProcessors = append(stageProcessors, describe(processor))
+			}
+			processors.strings(ProcessorStage(stage).String(), stageProcessors)
+		}
+	}
+
+	sampler := "none"
+	if l.sampler != nil {
+		sampler = describe(l.sampler)
+	}
+
+	config := newDict()
+	config.Append(
+		String("level", l.currentLevel().String()),
+		String("writer", describe(l.writer)),
+		String("sampler", sampler),
+		Strings("hooks", hooks),
+		Dict("processors", processors),
+		Bool("formatter", l.formatter != nil),
+		Bool("caller", l.caller),
+		Bool("stack", l.stack),
+		Bool("timestamp", l.timestamp),
+		Bool("confirm", l.confirm),
+		Bool("safe_mode", l.safeMode),
+		String("time_field_format", l.timeFieldFormat),
+	)
+	if l.targeted != nil {
+		config.Append(String("targeted_sampler", describe(l.targeted)))
+	}
+
+	logger.logEvent(NoLevel, "logger configuration", nil, []Field{Dict("config", config)}, false)
+}
+
+// ConfigDescriber can be implemented by writers, samplers, hooks and processors
+// to describe themselves in the event logged by Logger.LogConfig.
+type ConfigDescriber interface {
+	DescribeConfig() string
+}
+
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case levelWriterAdapter:
+		return describe(v.Writer)
+	case *os.File:
+		return v.Name()
+	case ConfigDescriber:
+		return v.DescribeConfig()
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,24 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLogConfig(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(
+		Writer(SyncWriter(out)),
+		Level(WarnLevel),
+		Sampler(SamplerRandom(0)),
+		Fields(Timestamp(false)),
+		AddHook(nopHook),
+		AddProcessor(RedactStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {})),
+	)
+	log.LogConfig()
+
+	want := `{"config":{"level":"warning","writer":"sync(*bytes.Buffer)","sampler":"rz.SamplerRandom","hooks":["rz.HookFunc"],"processors":{"redact":["rz.ProcessorFunc"]},"formatter":false,"caller":false,"stack":false,"timestamp":false,"confirm":false,"safe_mode":false,"time_field_format":"2006-01-02T15:04:05Z07:00"},"message":"logger configuration"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
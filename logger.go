@@ -21,7 +21,10 @@ type Logger struct {
 	stack                bool
 	caller               bool
 	timestamp            bool
+	confirm              bool
+	safeMode             bool
 	level                LogLevel
+	atomicLevel          *AtomicLevel
 	sampler              LogSampler
 	context              []byte
 	hooks                []LogHook
@@ -37,6 +40,10 @@ type Logger struct {
 	timestampFunc        func() time.Time
 	contextMutex         *sync.Mutex
 	encoder              Encoder
+	processors           *processorPipeline
+	transitions          *TransitionTable
+	targeted             *TargetedSampler
+	module               string
 }
 
 // New creates a root logger with given options. If the output writer implements
@@ -87,50 +94,88 @@ func (l Logger) With(options ...LoggerOption) Logger {
 
 // GetLevel returns the current log level.
 func (l *Logger) GetLevel() LogLevel {
-	return l.level
+	return l.currentLevel()
 }
 
 // LogWithLevel logs a new message with the given level.
 func (l *Logger) LogWithLevel(level LogLevel, message string, fields ...Field) {
-	l.logEvent(level, message, nil, fields)
+	l.logEvent(level, message, nil, fields, false)
 }
 
 // Debug logs a new message with debug level.
 func (l *Logger) Debug(message string, fields ...Field) {
-	l.logEvent(DebugLevel, message, nil, fields)
+	l.logEvent(DebugLevel, message, nil, fields, false)
 }
 
 // Info logs a new message with info level.
 func (l *Logger) Info(message string, fields ...Field) {
-	l.logEvent(InfoLevel, message, nil, fields)
+	l.logEvent(InfoLevel, message, nil, fields, false)
 }
 
 // Warn logs a new message with warn level.
 func (l *Logger) Warn(message string, fields ...Field) {
-	l.logEvent(WarnLevel, message, nil, fields)
+	l.logEvent(WarnLevel, message, nil, fields, false)
 }
 
 // Error logs a message with error level.
 func (l *Logger) Error(message string, fields ...Field) {
-	l.logEvent(ErrorLevel, message, nil, fields)
+	l.logEvent(ErrorLevel, message, nil, fields, false)
 }
 
 // Fatal logs a new message with fatal level. The os.Exit(1) function
 // is then called, which terminates the program immediately.
 func (l *Logger) Fatal(message string, fields ...Field) {
-	l.logEvent(FatalLevel, message, func(msg string) { os.Exit(1) }, fields)
+	l.logEvent(FatalLevel, message, func(msg string) { os.Exit(1) }, fields, false)
 }
 
 // Panic logs a new message with panic level. The panic() function
 // is then called, which stops the ordinary flow of a goroutine.
 func (l *Logger) Panic(message string, fields ...Field) {
-	l.logEvent(PanicLevel, message, func(msg string) { panic(msg) }, fields)
+	l.logEvent(PanicLevel, message, func(msg string) { panic(msg) }, fields, false)
 }
 
 // Log logs a new message with no level. Setting GlobalLevel to Disabled
 // will still disable events produced by this method.
 func (l *Logger) Log(message string, fields ...Field) {
-	l.logEvent(NoLevel, message, nil, fields)
+	l.logEvent(NoLevel, message, nil, fields, false)
+}
+
+// LogWithLevelE logs a new message with the given level and returns the error of the writer
+// instead of passing it to rz.ErrorHandler. It's intended for audit paths where the application
+// must know that the event was persisted.
+// A nil error is returned if the event was filtered out.
+func (l *Logger) LogWithLevelE(level LogLevel, message string, fields ...Field) error {
+	return l.logEvent(level, message, nil, fields, true)
+}
+
+// DebugE logs a new message with debug level and returns the error of the writer.
+// See LogWithLevelE.
+func (l *Logger) DebugE(message string, fields ...Field) error {
+	return l.logEvent(DebugLevel, message, nil, fields, true)
+}
+
+// InfoE logs a new message with info level and returns the error of the writer.
+// See LogWithLevelE.
+func (l *Logger) InfoE(message string, fields ...Field) error {
+	return l.logEvent(InfoLevel, message, nil, fields, true)
+}
+
+// WarnE logs a new message with warn level and returns the error of the writer.
+// See LogWithLevelE.
+func (l *Logger) WarnE(message string, fields ...Field) error {
+	return l.logEvent(WarnLevel, message, nil, fields, true)
+}
+
+// ErrorE logs a message with error level and returns the error of the writer.
+// See LogWithLevelE.
+func (l *Logger) ErrorE(message string, fields ...Field) error {
+	return l.logEvent(ErrorLevel, message, nil, fields, true)
+}
+
+// LogE logs a new message with no level and returns the error of the writer.
+// See LogWithLevelE.
+func (l *Logger) LogE(message string, fields ...Field) error {
+	return l.logEvent(NoLevel, message, nil, fields, true)
 }
 
 // NewDict creates an Event to be used with the Dict method.
@@ -144,7 +189,7 @@ func (l *Logger) NewDict(fields ...Field) *Event {
 }
 
 // Write implements the io.Writer interface. This is useful to set as a writer
-// for the standard library log.
+// for the standard library log. Use StdLogger to write at a given level.
 //
 //    log := rz.New()
 //    stdlog.SetFlags(0)
@@ -160,29 +205,52 @@ func (l Logger) Write(p []byte) (n int, err error) {
 	return
 }
 
-func (l *Logger) logEvent(level LogLevel, message string, done func(string), fields []Field) {
+func (l *Logger) logEvent(level LogLevel, message string, done func(string), fields []Field, returnErr bool) error {
 	enabled := l.should(level)
-	if !enabled {
-		return
+	if !enabled && (l.targeted == nil || l.currentLevel() == Disabled || level == Disabled) {
+		return nil
 	}
 	e := newEvent(l.writer, level)
 	e.ch = l.hooks
+	e.processors = l.processors
 	copyInternalLoggerFieldsToEvent(l, e)
+	e.levelStart = len(e.buf)
 	if level != NoLevel {
 		e.string(e.levelFieldName, level.String())
 	}
+	e.levelEnd = len(e.buf)
 	if l.context != nil && len(l.context) > 0 {
-		e.buf = enc.AppendObjectData(e.buf, l.context)
+		e.buf = e.encoder.AppendObjectData(e.buf, l.context)
 	}
 
-	for i := range fields {
-		fields[i](e)
+	if l.safeMode {
+		for i := range fields {
+			e.appendSafe(fields[i])
+		}
+	} else {
+		for i := range fields {
+			fields[i](e)
+		}
 	}
 
-	writeEvent(e, message, done)
+	if !enabled && !l.targeted.allow(e) {
+		putEvent(e)
+		return nil
+	}
+	if reporter, ok := l.sampler.(droppedReporter); ok && enabled {
+		if dropped := reporter.takeDropped(); dropped > 0 {
+			e.Append(Uint64("sampled_dropped", dropped))
+		}
+	}
+
+	return writeEvent(e, message, done, returnErr)
 }
 
-func writeEvent(e *Event, msg string, done func(string)) {
+// writeEvent writes the event. If returnErr is true, the write error is returned instead of
+// being passed to rz.ErrorHandler.
+func writeEvent(e *Event, msg string, done func(string), returnErr bool) (err error) {
+	e.message = msg
+
 	// run hooks
 	if len(e.ch) > 0 {
 		e.ch[0].Run(e, e.level, msg)
@@ -197,50 +265,66 @@ func writeEvent(e *Event, msg string, done func(string)) {
 		defer done(msg)
 	}
 
-	// if hooks didn't disabled our event, continue
-	if e.level != Disabled {
-		var err error
+	// if hooks and processors didn't disabled our event, continue
+	if e.processors.run(EnrichStage, e) &&
+		e.processors.run(RedactStage, e) &&
+		e.processors.run(TransformStage, e) &&
+		e.processors.run(SampleStage, e) {
 
 		if e.timestamp {
-			e.buf = enc.AppendTime(enc.AppendKey(e.buf, e.timestampFieldName), e.timestampFunc(), e.timeFieldFormat)
+			t := e.eventTime
+			if t.IsZero() {
+				t = e.timestampFunc()
+			}
+			e.buf = appendTime(e.encoder, e.encoder.AppendKey(e.buf, e.timestampFieldName), t, e.timeFieldFormat)
 		}
 
-		if msg != "" {
-			e.buf = enc.AppendString(enc.AppendKey(e.buf, e.messageFieldName), msg)
+		if e.message != "" {
+			e.buf = e.encoder.AppendString(e.encoder.AppendKey(e.buf, e.messageFieldName), e.message)
 		}
 		if e.caller {
 			_, file, line, ok := runtime.Caller(e.callerSkipFrameCount)
 			if ok {
-				e.buf = enc.AppendString(enc.AppendKey(e.buf, e.callerFieldName), file+":"+strconv.Itoa(line))
+				e.buf = e.encoder.AppendString(e.encoder.AppendKey(e.buf, e.callerFieldName), file+":"+strconv.Itoa(line))
 			}
 		}
 
 		// end json payload
-		e.buf = enc.AppendEndMarker(e.buf)
-		e.buf = enc.AppendLineBreak(e.buf)
-		if e.formatter != nil {
-			e.buf, err = e.formatter(e)
-		}
-		if e.w != nil {
-			_, err = e.w.WriteLevel(e.level, e.buf)
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+		e.buf = e.encoder.AppendLineBreak(e.buf)
+		if e.processors.run(EncodeStage, e) {
+			if e.formatter != nil {
+				e.buf, err = e.formatter(e)
+			}
+			if e.processors.run(WriteStage, e) && e.w != nil {
+				if e.ctx != nil {
+					_, err = writeContext(e.ctx, e.w, e.level, e.buf)
+				} else {
+					_, err = e.w.WriteLevel(e.level, e.buf)
+				}
+				if err == nil && e.confirm {
+					err = flush(e.w)
+				}
+			}
 		}
 
-		putEvent(e)
-
-		if err != nil {
+		if err != nil && !returnErr {
 			if ErrorHandler != nil {
 				ErrorHandler(err)
 			} else {
 				fmt.Fprintf(os.Stderr, "rz: could not write event: %v\n", err)
 			}
+			err = nil
 		}
 	}
 
+	putEvent(e)
+	return err
 }
 
 // should returns true if the log event should be logged.
 func (l *Logger) should(lvl LogLevel) bool {
-	if lvl < l.level {
+	if lvl < l.currentLevel() {
 		return false
 	}
 	if l.sampler != nil {
@@ -269,8 +353,11 @@ func (l *Logger) Append(fields ...Field) {
 	if e.timestamp != l.timestamp {
 		l.timestamp = e.timestamp
 	}
+	if e.confirm != l.confirm {
+		l.confirm = e.confirm
+	}
 	if e.buf != nil {
-		l.context = enc.AppendObjectData(l.context, e.buf)
+		l.context = e.encoder.AppendObjectData(l.context, e.buf)
 	}
 	l.contextMutex.Unlock()
 }
@@ -279,6 +366,7 @@ func copyInternalLoggerFieldsToEvent(l *Logger, e *Event) {
 	e.stack = l.stack
 	e.caller = l.caller
 	e.timestamp = l.timestamp
+	e.confirm = l.confirm
 	e.timestampFieldName = l.timestampFieldName
 	e.levelFieldName = l.levelFieldName
 	e.messageFieldName = l.messageFieldName
@@ -289,5 +377,6 @@ func copyInternalLoggerFieldsToEvent(l *Logger, e *Event) {
 	e.callerSkipFrameCount = l.callerSkipFrameCount
 	e.formatter = l.formatter
 	e.timestampFunc = l.timestampFunc
-	e.encoder = l.encoder
+	e.module = l.module
+	e.setEncoder(l.encoder)
 }
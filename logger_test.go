@@ -2,11 +2,13 @@ package rz
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -106,6 +108,18 @@ func TestFields(t *testing.T) {
 	}
 }
 
+func TestCompose(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	requestFields := func(method string) Field {
+		return Compose(String("method", method), String("path", "/users"))
+	}
+	log.Info("created", requestFields("POST"), Compose(Int("id", 42), nil, Compose()))
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","method":"POST","path":"/users","id":42,"message":"created"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestFieldsMap(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(Writer(out), Fields(Timestamp(false)))
@@ -603,6 +617,99 @@ func TestErrorHandler(t *testing.T) {
 	}
 }
 
+func TestErrorReturn(t *testing.T) {
+	var handled error
+	want := errors.New("write error")
+	ErrorHandler = func(err error) {
+		handled = err
+	}
+	defer func() { ErrorHandler = nil }()
+
+	log := New(Writer(errWriter{want}), Level(InfoLevel))
+	if got := log.InfoE("test"); got != want {
+		t.Errorf("InfoE err = %#v, want %#v", got, want)
+	}
+	if got := log.DebugE("filtered out"); got != nil {
+		t.Errorf("DebugE err = %#v, want nil", got)
+	}
+	if handled != nil {
+		t.Errorf("ErrorHandler called with %#v", handled)
+	}
+
+	out := &bytes.Buffer{}
+	log = New(Writer(out))
+	if got := log.ErrorE("test"); got != nil {
+		t.Errorf("ErrorE err = %#v, want nil", got)
+	}
+}
+
+type flushWriter struct {
+	bytes.Buffer
+	buffered []byte
+	err      error
+}
+
+func (w *flushWriter) Write(p []byte) (n int, err error) {
+	w.buffered = append(w.buffered, p...)
+	return len(p), nil
+}
+
+func (w *flushWriter) Flush() error {
+	w.Buffer.Write(w.buffered)
+	w.buffered = nil
+	return w.err
+}
+
+func TestConfirm(t *testing.T) {
+	out := &flushWriter{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+
+	log.Info("buffered")
+	if got := out.String(); got != "" {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, "")
+	}
+	log.Info("confirmed", Confirm(true))
+	if got, want := out.String(), `{"level":"info","message":"buffered"}`+"\n"+`{"level":"info","message":"confirmed"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.err = errors.New("flush error")
+	log = log.With(Writer(MultiLevelWriter(out)), Fields(Confirm(true)))
+	if got := log.InfoE("confirmed"); got != out.err {
+		t.Errorf("InfoE err = %#v, want %#v", got, out.err)
+	}
+}
+
+func TestSafeMode(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), SafeMode(true))
+	log.Info("hello",
+		String("before", "ok"),
+		func(e *Event) {
+			e.Append(String("partial", "value"))
+			panic("boom")
+		},
+		String("after", "ok"),
+	)
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &fields); err != nil {
+		t.Fatalf("invalid JSON output: %v: %s", err, out.String())
+	}
+	if fields["before"] != "ok" || fields["after"] != "ok" || fields["message"] != "hello" {
+		t.Errorf("invalid fields: %v", fields)
+	}
+	if _, ok := fields["partial"]; ok {
+		t.Errorf("partial field was not removed: %v", fields)
+	}
+	if fields["fields_panic"] != "boom" {
+		t.Errorf("fields_panic = %v, want boom", fields["fields_panic"])
+	}
+	if stack, _ := fields["fields_panic_stack"].(string); !strings.Contains(stack, "TestSafeMode") {
+		t.Errorf("invalid fields_panic_stack: %v", stack)
+	}
+}
+
 func TestWrite(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(Writer(out), Fields(Timestamp(false)))
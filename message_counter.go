@@ -0,0 +1,136 @@
+package rz
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MessageCount is the number of events logged with a message.
+type MessageCount struct {
+	Message string
+	Count   uint64
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface.
+func (c MessageCount) MarshalRzObject(e *Event) {
+	e.string("message", c.Message)
+	e.uint64("count", c.Count)
+}
+
+// MessageCounter is a LogProcessor counting the written events per message, to find the
+// noisiest messages and guide log-volume cleanup efforts. Add it to a logger with the
+// CountMessages option. It's safe for concurrent use.
+type MessageCounter struct {
+	mu          sync.Mutex
+	counts      map[string]uint64
+	maxMessages int
+	overflow    uint64
+}
+
+// NewMessageCounter returns a MessageCounter tracking at most maxMessages distinct messages
+// (unbounded if <= 0). Events with other messages are only counted in Overflow.
+func NewMessageCounter(maxMessages int) *MessageCounter {
+	return &MessageCounter{counts: map[string]uint64{}, maxMessages: maxMessages}
+}
+
+// CountMessages appends counter to the write stage of logger's pipeline.
+func CountMessages(counter *MessageCounter) LoggerOption {
+	return AddProcessor(WriteStage, counter)
+}
+
+// Process implements the LogProcessor interface.
+func (c *MessageCounter) Process(e *Event, level LogLevel, message string) {
+	c.mu.Lock()
+	if _, ok := c.counts[message]; ok || c.maxMessages <= 0 || len(c.counts) < c.maxMessages {
+		c.counts[message]++
+	} else {
+		c.overflow++
+	}
+	c.mu.Unlock()
+}
+
+// TopK returns the k messages with the most events, sorted by decreasing count.
+func (c *MessageCounter) TopK(k int) []MessageCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return topMessages(c.counts, k)
+}
+
+// topMessages returns the k messages of counts with the most events, sorted by decreasing count.
+func topMessages(counts map[string]uint64, k int) []MessageCount {
+	ret := make([]MessageCount, 0, len(counts))
+	for message, count := range counts {
+		ret = append(ret, MessageCount{Message: message, Count: count})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].Message < ret[j].Message
+	})
+	if k >= 0 && len(ret) > k {
+		ret = ret[:k]
+	}
+	return ret
+}
+
+// Overflow returns the number of events not tracked because the maximum number of distinct
+// messages was reached.
+func (c *MessageCounter) Overflow() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.overflow
+}
+
+// Reset resets all the counts.
+func (c *MessageCounter) Reset() {
+	c.swap()
+}
+
+// swap resets the counts and returns their previous values, so that no event is lost between
+// reading and resetting them.
+func (c *MessageCounter) swap() (counts map[string]uint64, overflow uint64) {
+	c.mu.Lock()
+	counts, overflow = c.counts, c.overflow
+	c.counts = map[string]uint64{}
+	c.overflow = 0
+	c.mu.Unlock()
+	return counts, overflow
+}
+
+// LogTopK logs the k noisiest messages with info level, under the "top_messages" key.
+func (c *MessageCounter) LogTopK(logger *Logger, k int) {
+	c.mu.Lock()
+	top, overflow := topMessages(c.counts, k), c.overflow
+	c.mu.Unlock()
+	logTopMessages(logger, top, overflow)
+}
+
+func logTopMessages(logger *Logger, top []MessageCount, overflow uint64) {
+	logger.Info("top messages", func(e *Event) {
+		arr := e.arr()
+		for _, count := range top {
+			arr = arr.Object(count)
+		}
+		e.array("top_messages", arr)
+		if overflow > 0 {
+			e.uint64("overflow", overflow)
+		}
+	})
+}
+
+// Report logs the k noisiest messages like LogTopK every interval, and resets the counts after
+// each report. The returned function stops the reports.
+func (c *MessageCounter) Report(logger Logger, k int, interval time.Duration) (stop func()) {
+	return report(interval, func() {
+		counts, overflow := c.swap()
+		logTopMessages(&logger, topMessages(counts, k), overflow)
+	})
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (c *MessageCounter) DescribeConfig() string {
+	return "message_counter(" + strconv.Itoa(c.maxMessages) + ")"
+}
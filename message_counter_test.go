@@ -0,0 +1,79 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageCounter(t *testing.T) {
+	out := &bytes.Buffer{}
+	counter := NewMessageCounter(3)
+	log := New(Writer(out), Fields(Timestamp(false)), Level(InfoLevel), CountMessages(counter))
+	for i := 0; i < 5; i++ {
+		log.Info("noisy")
+	}
+	log.Info("rare")
+	log.Warn("medium")
+	log.Warn("medium")
+	log.Debug("filtered out")
+	log.Info("overflow")
+
+	top := counter.TopK(2)
+	if len(top) != 2 || top[0] != (MessageCount{"noisy", 5}) || top[1] != (MessageCount{"medium", 2}) {
+		t.Errorf("invalid TopK(2): %v", top)
+	}
+	if got := counter.Overflow(); got != 1 {
+		t.Errorf("Overflow() = %d, want 1", got)
+	}
+
+	out.Reset()
+	counter.LogTopK(&log, 2)
+	want := `{"level":"info","top_messages":[{"message":"noisy","count":5},{"message":"medium","count":2}],"overflow":1,"message":"top messages"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	counter.Reset()
+	if top := counter.TopK(10); len(top) != 0 {
+		t.Errorf("invalid TopK after Reset: %v", top)
+	}
+}
+
+func TestMessageCounterReport(t *testing.T) {
+	counter := NewMessageCounter(0)
+	log := New(Writer(&bytes.Buffer{}), Fields(Timestamp(false)), CountMessages(counter))
+	out := &syncBuffer{}
+	stop := counter.Report(New(Writer(out), Fields(Timestamp(false))), -1, time.Millisecond)
+
+	const events = 2000
+	for i := 0; i < events; i++ {
+		log.Info("noisy")
+		if i%100 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	stop()
+	stop()
+
+	var total uint64
+	for _, count := range counter.TopK(-1) {
+		total += count.Count
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var report struct {
+			TopMessages []MessageCount `json:"top_messages"`
+		}
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			t.Fatalf("invalid report %q: %v", line, err)
+		}
+		for _, count := range report.TopMessages {
+			total += count.Count
+		}
+	}
+	if total != events {
+		t.Errorf("reported and remaining counts = %d, want %d", total, events)
+	}
+}
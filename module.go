@@ -0,0 +1,126 @@
+package rz
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// ForModule attributes the events of the logger to the module of the calling package, derived
+// once when the option is created: the module field (DefaultModuleFieldName) is added to the
+// logger's context, and the module is available to hooks and processors with Event.Module.
+// It lets library authors ship loggers the host application can identify and filter, e.g. with
+// ModuleFilter:
+//
+//     var log = hostLogger.With(rz.ForModule())
+//
+// The module is the path of the module containing the calling package, as recorded in the build
+// information of the binary, or the package path if it's not found.
+func ForModule() LoggerOption {
+	module := ""
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			module = funcModule(fn.Name())
+		}
+	}
+	return func(logger *Logger) {
+		if module == "" {
+			return
+		}
+		logger.module = module
+		Fields(String(DefaultModuleFieldName, module))(logger)
+	}
+}
+
+// funcModule returns the path of the module of the binary containing the fully qualified function
+// name fn, or its package path if not found.
+func funcModule(fn string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return packagePath(fn)
+	}
+	paths := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		paths = append(paths, dep.Path)
+	}
+	if module := funcModulePath(fn, paths); module != "" {
+		return module
+	}
+	return packagePath(fn)
+}
+
+// funcModulePath returns the longest of the module paths prefixing the fully qualified function name
+// fn, or "" if none matches. As the last element of a module path may contain dots (e.g.
+// "gopkg.in/yaml.v3"), the function name is not cut into a package path first.
+func funcModulePath(fn string, paths []string) string {
+	fn = unescapeFuncName(fn)
+	module := ""
+	for _, path := range paths {
+		if len(path) > len(module) && strings.HasPrefix(fn, path) && len(fn) > len(path) &&
+			(fn[len(path)] == '/' || fn[len(path)] == '.') {
+			module = path
+		}
+	}
+	return module
+}
+
+// packagePath returns the package path of the fully qualified function name fn, e.g.
+// "github.com/skerkour/rz" for "github.com/skerkour/rz.(*Logger).Info". The runtime escapes the
+// dots of the last element of the package path, e.g. "gopkg.in/yaml%2ev3.Unmarshal", so the
+// package path ends at the first dot after the last slash.
+func packagePath(fn string) string {
+	lastSlash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[lastSlash+1:], '.'); dot >= 0 {
+		fn = fn[:lastSlash+1+dot]
+	}
+	return unescapeFuncName(fn)
+}
+
+// unescapeFuncName unescapes the dots of the package path of the function name fn.
+func unescapeFuncName(fn string) string {
+	return strings.ReplaceAll(fn, "%2e", ".")
+}
+
+// hasPathPrefix returns true if path is prefix or a sub path of prefix.
+func hasPathPrefix(path, prefix string) bool {
+	return prefix != "" && strings.HasPrefix(path, prefix) &&
+		(len(path) == len(prefix) || path[len(prefix)] == '/')
+}
+
+// ModuleFilter is a LogProcessor dropping the events by module (see ForModule), to be added to
+// the SampleStage: if Include is not empty, only the modules matching one of its paths are kept,
+// then the modules matching one of the paths of Exclude are dropped. A path matches its sub
+// paths, e.g. "github.com/org" matches "github.com/org/lib". Events without module are kept.
+type ModuleFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Process implements the LogProcessor interface.
+func (f ModuleFilter) Process(e *Event, level LogLevel, message string) {
+	if e.module == "" {
+		return
+	}
+	if len(f.Include) > 0 && !matchModule(e.module, f.Include) {
+		e.discard()
+		return
+	}
+	if matchModule(e.module, f.Exclude) {
+		e.discard()
+	}
+}
+
+func matchModule(module string, paths []string) bool {
+	for _, path := range paths {
+		if hasPathPrefix(module, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (f ModuleFilter) DescribeConfig() string {
+	return "module_filter(include=[" + strings.Join(f.Include, ", ") + "], exclude=[" +
+		strings.Join(f.Exclude, ", ") + "])"
+}
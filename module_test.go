@@ -0,0 +1,81 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestForModule(t *testing.T) {
+	out := &bytes.Buffer{}
+	modules := []string{}
+	log := New(Writer(out), Fields(Timestamp(false)), ForModule(),
+		AddProcessor(EnrichStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+			modules = append(modules, e.Module())
+		})))
+
+	log.Info("hello")
+	if got, want := out.String(), `{"level":"info","module":"github.com/skerkour/rz","message":"hello"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(modules) != 1 || modules[0] != "github.com/skerkour/rz" {
+		t.Errorf("unexpected modules: %v", modules)
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	tests := map[string]string{
+		"github.com/skerkour/rz.(*Logger).Info": "github.com/skerkour/rz",
+		"github.com/org/lib/pkg.init.func1":     "github.com/org/lib/pkg",
+		"main.main":                             "main",
+		"gopkg.in/yaml%2ev3.Unmarshal":          "gopkg.in/yaml.v3",
+		"gopkg.in/yaml%2ev3.(*Node).Decode":     "gopkg.in/yaml.v3",
+	}
+	for fn, want := range tests {
+		if got := packagePath(fn); got != want {
+			t.Errorf("packagePath(%q): got %q, want %q", fn, got, want)
+		}
+	}
+}
+
+func TestFuncModulePath(t *testing.T) {
+	paths := []string{"example.com/app", "gopkg.in/yaml.v3", "github.com/org/lib", "github.com/org/lib/v2"}
+	tests := map[string]string{
+		"gopkg.in/yaml.v3.Unmarshal":            "gopkg.in/yaml.v3",
+		"gopkg.in/yaml%2ev3.Unmarshal":          "gopkg.in/yaml.v3",
+		"gopkg.in/yaml.v3/internal.parse":       "gopkg.in/yaml.v3",
+		"github.com/org/lib/v2/pkg.init.func1":  "github.com/org/lib/v2",
+		"github.com/org/lib.(*Client).Do":       "github.com/org/lib",
+		"github.com/org/library.F":              "",
+		"example.com/app/internal/server.Serve": "example.com/app",
+	}
+	for fn, want := range tests {
+		if got := funcModulePath(fn, paths); got != want {
+			t.Errorf("funcModulePath(%q): got %q, want %q", fn, got, want)
+		}
+	}
+}
+
+func TestModuleFilter(t *testing.T) {
+	tests := []struct {
+		filter ModuleFilter
+		module string
+		want   bool
+	}{
+		{ModuleFilter{}, "github.com/org/lib", true},
+		{ModuleFilter{Exclude: []string{"github.com/org"}}, "github.com/org/lib", false},
+		{ModuleFilter{Exclude: []string{"github.com/org"}}, "github.com/organization/lib", true},
+		{ModuleFilter{Exclude: []string{"github.com/org"}}, "", true},
+		{ModuleFilter{Include: []string{"github.com/org/lib"}}, "github.com/org/lib", true},
+		{ModuleFilter{Include: []string{"github.com/org/lib"}}, "github.com/org/other", false},
+		{ModuleFilter{Include: []string{"github.com/org"}, Exclude: []string{"github.com/org/lib"}}, "github.com/org/lib", false},
+	}
+	for _, tt := range tests {
+		e := newEvent(nil, InfoLevel)
+		e.module = tt.module
+		tt.filter.Process(e, e.level, "")
+		if got := e.Enabled(); got != tt.want {
+			t.Errorf("%s with %q: got %v, want %v", tt.filter.DescribeConfig(), tt.module, got, tt.want)
+		}
+		putEvent(e)
+	}
+}
@@ -0,0 +1,92 @@
+package rz
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MessageNormalizer is a LogProcessor normalizing the messages of events, so malicious or
+// accidental terminal escape sequences in logged user input can't mess up consoles and
+// downstream viewers. Invalid UTF-8 sequences are always replaced by U+FFFD.
+type MessageNormalizer struct {
+	// StripANSI removes ANSI escape sequences and control characters (except tabulations
+	// and line breaks).
+	StripANSI bool
+	// CollapseNewlines replaces each run of line breaks (and the surrounding spaces) by a single space.
+	CollapseNewlines bool
+	// Unicode, if not nil, is applied to the message to perform Unicode normalization.
+	// rz doesn't embed Unicode tables: use norm.NFC.String from golang.org/x/text/unicode/norm
+	// for NFC.
+	Unicode func(string) string
+}
+
+// NormalizeMessages appends a MessageNormalizer to the transform stage of logger's pipeline.
+func NormalizeMessages(normalizer MessageNormalizer) LoggerOption {
+	return AddProcessor(TransformStage, normalizer)
+}
+
+// Process implements the LogProcessor interface.
+func (n MessageNormalizer) Process(e *Event, level LogLevel, message string) {
+	if message != "" {
+		e.SetMessage(n.Normalize(message))
+	}
+}
+
+// Normalize returns the normalized message.
+func (n MessageNormalizer) Normalize(message string) string {
+	if !utf8.ValidString(message) {
+		message = strings.ToValidUTF8(message, string(utf8.RuneError))
+	}
+	if n.StripANSI && needsStrip(message) {
+		message = string(appendStripANSI(make([]byte, 0, len(message)), []byte(message), true))
+	}
+	if n.CollapseNewlines && strings.ContainsAny(message, "\r\n") {
+		message = collapseNewlines(message)
+	}
+	if n.Unicode != nil {
+		message = n.Unicode(message)
+	}
+	return message
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (n MessageNormalizer) DescribeConfig() string {
+	ret := "normalize("
+	options := []string{}
+	if n.StripANSI {
+		options = append(options, "strip_ansi")
+	}
+	if n.CollapseNewlines {
+		options = append(options, "collapse_newlines")
+	}
+	if n.Unicode != nil {
+		options = append(options, "unicode")
+	}
+	return ret + strings.Join(options, ", ") + ")"
+}
+
+func needsStrip(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 && c != '\t' && c != '\n' && c != '\r' || c == 0x7f || c == 0xc2 {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseNewlines(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	lines := strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' })
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte(' ')
+			line = strings.TrimLeft(line, " \t")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " \t")
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
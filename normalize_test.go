@@ -0,0 +1,42 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMessageNormalizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer MessageNormalizer
+		message    string
+		want       string
+	}{
+		{"InvalidUTF8", MessageNormalizer{}, "a\xffb", "a�b"},
+		{"CSI", MessageNormalizer{StripANSI: true}, "\x1b[31mred\x1b[0m text", "red text"},
+		{"OSC", MessageNormalizer{StripANSI: true}, "\x1b]0;title\x07hello\x1b]8;;http://x\x1b\\link", "hellolink"},
+		{"C1", MessageNormalizer{StripANSI: true}, "a\u009b2Jb\u0085c", "abc"},
+		{"Control", MessageNormalizer{StripANSI: true}, "a\x00b\x08c\td", "abc\td"},
+		{"Newlines", MessageNormalizer{CollapseNewlines: true}, "first line  \r\n\n  second\nthird", "first line second third"},
+		{"Unicode", MessageNormalizer{Unicode: strings.ToUpper}, "hello", "HELLO"},
+		{"Unchanged", MessageNormalizer{StripANSI: true, CollapseNewlines: true}, "héllo wörld", "héllo wörld"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.normalizer.Normalize(tt.message); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMessages(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), NormalizeMessages(MessageNormalizer{StripANSI: true, CollapseNewlines: true}))
+	log.Info("user \x1b[2Jinput\nwith newline")
+	if got, want := out.String(), `{"level":"info","message":"user input with newline"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
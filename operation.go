@@ -0,0 +1,60 @@
+package rz
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Operation is a long operation logged in two phases: a header event when it starts, and an
+// outcome event with its final status and duration when it ends, linked by the "event_id" field,
+// a ULID as stamped by EventIDs.
+// It's intended for operations where waiting for completion (or buffering) before logging is
+// unacceptable but correlation matters.
+type Operation struct {
+	logger *Logger
+	id     string
+	name   string
+	start  time.Time
+	ended  uint32
+}
+
+// StartOperation logs the header event of the operation name with info level, with the fields
+// "event_id" and "phase": "header", and returns the Operation to end.
+func (l *Logger) StartOperation(name string, fields ...Field) *Operation {
+	op := &Operation{
+		logger: l,
+		id:     NewULID(l.timestampFunc()),
+		name:   name,
+		start:  time.Now(),
+	}
+	fields = append([]Field{String("event_id", op.id), String("phase", "header")}, fields...)
+	l.logEvent(InfoLevel, name, nil, fields, false)
+	return op
+}
+
+// ID returns the event ID linking the events of the operation.
+func (o *Operation) ID() string {
+	return o.id
+}
+
+// End logs the outcome event of the operation, with the fields "event_id", "phase": "outcome",
+// "status" ("ok" or "error") and "duration". If err is not nil, it's logged with error level and
+// err is added to the event, otherwise it's logged with info level.
+// Only the first call to End logs an event.
+func (o *Operation) End(err error, fields ...Field) {
+	if !atomic.CompareAndSwapUint32(&o.ended, 0, 1) {
+		return
+	}
+	level, status := InfoLevel, "ok"
+	if err != nil {
+		level, status = ErrorLevel, "error"
+	}
+	fields = append([]Field{
+		String("event_id", o.id),
+		String("phase", "outcome"),
+		String("status", status),
+		Duration("duration", time.Since(o.start)),
+		Err(err),
+	}, fields...)
+	o.logger.logEvent(level, o.name, nil, fields, false)
+}
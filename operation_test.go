@@ -0,0 +1,56 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOperation(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	op := log.StartOperation("import", String("file", "users.csv"))
+	op.End(errors.New("invalid line"), Int("lines", 10))
+	op.End(nil)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("invalid number of events: %d", len(lines))
+	}
+	var header, outcome map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &outcome); err != nil {
+		t.Fatal(err)
+	}
+
+	if header["event_id"] != op.ID() || outcome["event_id"] != op.ID() || len(op.ID()) != 26 {
+		t.Errorf("events are not linked: %v, %v", header["event_id"], outcome["event_id"])
+	}
+	if header["phase"] != "header" || header["level"] != "info" || header["message"] != "import" || header["file"] != "users.csv" {
+		t.Errorf("invalid header event: %s", lines[0])
+	}
+	if outcome["phase"] != "outcome" || outcome["level"] != "error" || outcome["status"] != "error" ||
+		outcome["error"] != "invalid line" || outcome["lines"] != 10.0 || outcome["duration"] == nil {
+		t.Errorf("invalid outcome event: %s", lines[1])
+	}
+}
+
+func TestOperationEventIDs(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), EventIDs("event_id"))
+	op := log.StartOperation("import")
+	op.End(nil)
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if n := strings.Count(line, `"event_id":`); n != 1 {
+			t.Errorf("%d event_id fields in %s", n, line)
+		}
+		if id, _ := EventID([]byte(line), "event_id"); id != op.ID() {
+			t.Errorf("invalid event ID %q, want %q", id, op.ID())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package rz
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ProcessMetadataFieldName is the field name used by ProcessMetadataHook.
+const ProcessMetadataFieldName = "process"
+
+var containerIDRegexp = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// ProcessMetadata holds metadata of a process, intended to enrich the events of security agents.
+type ProcessMetadata struct {
+	PID        int
+	PPID       int
+	Executable string
+	// ContainerID is derived from the cgroup of the process (Linux only), empty if it's not
+	// running in a container.
+	ContainerID string
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface. The uid and gid are the ones of
+// the current process at the time of the call, as they may change during its life.
+func (m ProcessMetadata) MarshalRzObject(e *Event) {
+	e.int("pid", m.PID)
+	e.int("ppid", m.PPID)
+	e.int("uid", os.Getuid())
+	e.int("euid", os.Geteuid())
+	e.int("gid", os.Getgid())
+	e.int("egid", os.Getegid())
+	if m.Executable != "" {
+		e.string("exe", m.Executable)
+	}
+	if m.ContainerID != "" {
+		e.string("container_id", m.ContainerID)
+	}
+}
+
+// CurrentProcessMetadata returns the metadata of the current process. The fields which are not
+// available are left empty.
+func CurrentProcessMetadata() ProcessMetadata {
+	m := ProcessMetadata{
+		PID:  os.Getpid(),
+		PPID: os.Getppid(),
+	}
+	m.Executable, _ = os.Executable()
+	if f, err := os.Open("/proc/self/cgroup"); err == nil {
+		m.ContainerID = parseContainerID(f)
+		f.Close()
+	}
+	return m
+}
+
+// parseContainerID extracts the container ID from the content of a /proc/<pid>/cgroup file,
+// e.g. "0::/system.slice/docker-<id>.scope" or "12:pids:/kubepods/besteffort/pod<uid>/<id>".
+func parseContainerID(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		segments := strings.Split(parts[2], "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			if id := containerIDRegexp.FindString(segments[i]); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// ProcessMetadataHook returns a hook adding the metadata of the current process to the events,
+// under the "process" key. Use it with the AddHook option.
+func ProcessMetadataHook() LogHook {
+	metadata := CurrentProcessMetadata()
+	return HookFunc(func(e *Event, level LogLevel, message string) {
+		e.object(ProcessMetadataFieldName, metadata)
+	})
+}
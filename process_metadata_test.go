@@ -0,0 +1,51 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseContainerID(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		name   string
+		cgroup string
+		want   string
+	}{
+		{"Docker", "0::/system.slice/docker-" + id + ".scope\n", id},
+		{"Kubernetes", "12:pids:/kubepods/besteffort/pod1234/" + id + "\n1:name=systemd:/kubepods/besteffort/pod1234/" + id + "\n", id},
+		{"Containerd", "0::/kubepods.slice/cri-containerd-" + id + ".scope\n", id},
+		{"Host", "0::/user.slice/user-1000.slice/session-2.scope\n", ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseContainerID(strings.NewReader(tt.cgroup)); got != tt.want {
+				t.Errorf("invalid container ID: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessMetadataHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), AddHook(ProcessMetadataHook()))
+	log.Info("hello")
+
+	var event struct {
+		Process struct {
+			PID int
+			UID int
+			Exe string
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Process.PID != os.Getpid() || event.Process.UID != os.Getuid() || event.Process.Exe == "" {
+		t.Errorf("invalid process metadata: %s", out.String())
+	}
+}
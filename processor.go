@@ -0,0 +1,111 @@
+package rz
+
+// ProcessorStage identifies a stage of the event processing pipeline.
+// Stages always run in the following order: enrich, redact, transform, sample, encode
+// and write. Within a stage, processors run in the order they were added.
+type ProcessorStage uint8
+
+const (
+	// EnrichStage is used to add fields to an event. Hooks are run at the
+	// beginning of this stage, before the enrich processors.
+	EnrichStage ProcessorStage = iota
+	// RedactStage is used to remove or mask sensitive data.
+	RedactStage
+	// TransformStage is used to modify the event (message, level...).
+	TransformStage
+	// SampleStage is used to drop events based on their content.
+	SampleStage
+	// EncodeStage runs once the event payload is complete,
+	// before the formatter is applied.
+	EncodeStage
+	// WriteStage runs right before the event is written to the logger's writer.
+	WriteStage
+
+	processorStageCount = iota
+)
+
+func (s ProcessorStage) String() string {
+	switch s {
+	case EnrichStage:
+		return "enrich"
+	case RedactStage:
+		return "redact"
+	case TransformStage:
+		return "transform"
+	case SampleStage:
+		return "sample"
+	case EncodeStage:
+		return "encode"
+	case WriteStage:
+		return "write"
+	}
+	return ""
+}
+
+// LogProcessor defines an interface to an event processor.
+type LogProcessor interface {
+	// Process processes the event. Calling e.Append(Discard()) stops the pipeline
+	// and drops the event.
+	Process(e *Event, level LogLevel, message string)
+}
+
+// ProcessorFunc is an adaptor to allow the use of an ordinary function
+// as a LogProcessor.
+type ProcessorFunc func(e *Event, level LogLevel, message string)
+
+// Process implements the LogProcessor interface.
+func (p ProcessorFunc) Process(e *Event, level LogLevel, message string) {
+	p(e, level, message)
+}
+
+// processorPipeline holds the processors of each stage. It's never modified once
+// attached to a logger: options build a new copy instead.
+type processorPipeline [processorStageCount][]LogProcessor
+
+func (p *processorPipeline) clone() *processorPipeline {
+	ret := &processorPipeline{}
+	if p != nil {
+		for i := range p {
+			ret[i] = append([]LogProcessor(nil), p[i]...)
+		}
+	}
+	return ret
+}
+
+// run runs the processors of the given stage and returns false if the event was discarded.
+func (p *processorPipeline) run(stage ProcessorStage, e *Event) bool {
+	if p == nil {
+		return e.level != Disabled
+	}
+	for _, processor := range p[stage] {
+		if e.level == Disabled {
+			break
+		}
+		processor.Process(e, e.level, e.message)
+	}
+	return e.level != Disabled
+}
+
+// AddProcessor appends processor to the given stage of logger's pipeline.
+func AddProcessor(stage ProcessorStage, processor LogProcessor) LoggerOption {
+	return func(logger *Logger) {
+		if stage >= processorStageCount {
+			return
+		}
+		processors := logger.processors.clone()
+		processors[stage] = append(processors[stage], processor)
+		logger.processors = processors
+	}
+}
+
+// Processors replaces the processors of the given stage of logger's pipeline.
+func Processors(stage ProcessorStage, processors ...LogProcessor) LoggerOption {
+	return func(logger *Logger) {
+		if stage >= processorStageCount {
+			return
+		}
+		pipeline := logger.processors.clone()
+		pipeline[stage] = append([]LogProcessor(nil), processors...)
+		logger.processors = pipeline
+	}
+}
@@ -0,0 +1,100 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProcessorsOrder(t *testing.T) {
+	out := &bytes.Buffer{}
+	stages := []string{}
+	record := func(stage ProcessorStage) LogProcessor {
+		return ProcessorFunc(func(e *Event, level LogLevel, message string) {
+			stages = append(stages, stage.String())
+		})
+	}
+	log := New(
+		Writer(out),
+		Fields(Timestamp(false)),
+		AddProcessor(WriteStage, record(WriteStage)),
+		AddProcessor(SampleStage, record(SampleStage)),
+		AddProcessor(EnrichStage, record(EnrichStage)),
+		AddProcessor(EncodeStage, record(EncodeStage)),
+		AddProcessor(TransformStage, record(TransformStage)),
+		AddProcessor(RedactStage, record(RedactStage)),
+		AddHook(HookFunc(func(e *Event, level LogLevel, message string) {
+			stages = append(stages, "hook")
+		})),
+	)
+	log.Info("hello")
+
+	if got, want := strings.Join(stages, ","), "hook,enrich,redact,transform,sample,encode,write"; got != want {
+		t.Errorf("invalid processors order:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := out.String(), `{"level":"info","message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestProcessors(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		test func(log Logger)
+	}{
+		{"Enrich", `{"level":"info","foo":"bar","message":"a message"}` + "\n", func(log Logger) {
+			log = log.With(AddProcessor(EnrichStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+				e.Append(String("foo", "bar"))
+			})))
+			log.Info("a message")
+		}},
+		{"Transform", `{"level":"info","message":"A MESSAGE"}` + "\n", func(log Logger) {
+			log = log.With(AddProcessor(TransformStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+				e.SetMessage(strings.ToUpper(message))
+			})))
+			log.Info("a message")
+		}},
+		{"Sample", "", func(log Logger) {
+			called := false
+			log = log.With(
+				AddProcessor(SampleStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+					e.Append(Discard())
+				})),
+				AddProcessor(WriteStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+					called = true
+				})),
+			)
+			log.Info("a message")
+			if called {
+				panic("processor called after discard")
+			}
+		}},
+		{"Replace", `{"level":"info","message":"a message"}` + "\n", func(log Logger) {
+			log = log.With(
+				AddProcessor(EnrichStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+					e.Append(String("foo", "bar"))
+				})),
+				Processors(EnrichStage),
+			)
+			log.Info("a message")
+		}},
+		{"Child", `{"level":"info","message":"a message"}` + "\n", func(log Logger) {
+			_ = log.With(AddProcessor(EnrichStage, ProcessorFunc(func(e *Event, level LogLevel, message string) {
+				e.Append(String("foo", "bar"))
+			})))
+			log.Info("a message")
+		}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			log := New(Writer(out), Fields(Timestamp(false)))
+			tt.test(log)
+			if got, want := out.String(), tt.want; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
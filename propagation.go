@@ -0,0 +1,76 @@
+package rz
+
+// PropagationHeaderPrefix is the prefix of the names of the message headers holding the fields
+// propagated by InjectFields.
+var PropagationHeaderPrefix = "rz-"
+
+// DefaultPropagatedFields are the fields propagated by InjectFields and ExtractFields when no
+// field is given.
+var DefaultPropagatedFields = []string{"request_id", "trace_id", "span_id"}
+
+// HeaderCarrier is the headers of a message of a queue (Kafka, NATS, AMQP...), used to propagate
+// log fields across asynchronous hops. Adapt the header types of the clients, e.g. nats.Header,
+// or kafka record headers with a small wrapper.
+type HeaderCarrier interface {
+	// Get returns the value of the header key, or "" if there is none.
+	Get(key string) string
+	// Set sets the header key to value.
+	Set(key, value string)
+}
+
+// MapCarrier is a HeaderCarrier backed by a map.
+type MapCarrier map[string]string
+
+// Get implements HeaderCarrier.
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set implements HeaderCarrier.
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// InjectFields sets the headers named PropagationHeaderPrefix + field to the string values of the
+// fields of the logger's context (DefaultPropagatedFields if none is given), so the consumer of
+// the message can restore them with ExtractFields. The fields which are missing or not strings
+// are skipped. Only the JSON encoder is supported.
+//
+//     headers := rz.MapCarrier{}
+//     rz.FromCtx(ctx).InjectFields(headers)
+//     producer.Publish(subject, payload, headers)
+func (l Logger) InjectFields(headers HeaderCarrier, fields ...string) {
+	if len(fields) == 0 {
+		fields = DefaultPropagatedFields
+	}
+	l.contextMutex.Lock()
+	context := append([]byte{'{'}, l.context...)
+	l.contextMutex.Unlock()
+	for _, field := range fields {
+		if value, ok := stringFieldValue(context, fieldKey(field)); ok {
+			headers.Set(PropagationHeaderPrefix+field, value)
+		}
+	}
+}
+
+// ExtractFields returns a copy of the logger with the fields (DefaultPropagatedFields if none is
+// given) set by InjectFields in headers. The missing headers are skipped, and the logger is
+// returned unchanged if none is found.
+//
+//     logger := consumerLogger.ExtractFields(headers)
+//     logger.Info("order processed")
+func (l Logger) ExtractFields(headers HeaderCarrier, fields ...string) Logger {
+	if len(fields) == 0 {
+		fields = DefaultPropagatedFields
+	}
+	var extracted []Field
+	for _, field := range fields {
+		if value := headers.Get(PropagationHeaderPrefix + field); value != "" {
+			extracted = append(extracted, String(field, value))
+		}
+	}
+	if len(extracted) == 0 {
+		return l
+	}
+	return l.With(Fields(extracted...))
+}
@@ -0,0 +1,31 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFieldsPropagation(t *testing.T) {
+	out := &bytes.Buffer{}
+	producer := New(Writer(out), Fields(Timestamp(false), Int("attempt", 1), String("request_id", "r\"42"), String("trace_id", "abc")))
+	headers := MapCarrier{}
+	producer.InjectFields(headers)
+	if len(headers) != 2 || headers["rz-request_id"] != "r\"42" || headers["rz-trace_id"] != "abc" {
+		t.Fatalf("invalid headers: %v", headers)
+	}
+	producer.InjectFields(headers, "attempt")
+	if len(headers) != 2 {
+		t.Errorf("non-string field propagated: %v", headers)
+	}
+
+	consumer := New(Writer(out), Fields(Timestamp(false), String("service", "billing")))
+	logger := consumer.ExtractFields(headers)
+	logger.Info("processed")
+	logger = consumer.ExtractFields(MapCarrier{})
+	logger.Info("unknown")
+	want := `{"level":"info","service":"billing","request_id":"r\"42","trace_id":"abc","message":"processed"}` + "\n" +
+		`{"level":"info","service":"billing","message":"unknown"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,357 @@
+package rz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a compiled filter over decoded events, e.g.
+//
+//    level>=warn AND fields.user_id="42" AND msg~"timeout"
+//
+// A query is made of comparisons combined with AND, OR, NOT and parentheses (AND binds tighter
+// than OR). A comparison is a selector, an operator and a value (quoted or not):
+//
+//    level     the level of the event (debug, info, warn, error, fatal, panic)
+//    msg       the message of the event (alias: message)
+//    time      the timestamp of the event, compared with a RFC 3339 time
+//    fields.x  the field x of the event, compared as a number if both are numbers
+//
+// The operators are =, !=, <, <=, >, >= and ~ (regular expression match).
+type Query struct {
+	source string
+	root   queryNode
+}
+
+type queryNode interface {
+	match(e *DecodedEvent) bool
+}
+
+type queryAnd []queryNode
+type queryOr []queryNode
+type queryNot struct{ node queryNode }
+
+type queryCmp struct {
+	selector string
+	field    string
+	op       string
+	value    string
+	regexp   *regexp.Regexp
+	level    LogLevel
+	time     time.Time
+}
+
+func (q queryAnd) match(e *DecodedEvent) bool {
+	for _, node := range q {
+		if !node.match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q queryOr) match(e *DecodedEvent) bool {
+	for _, node := range q {
+		if node.match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q queryNot) match(e *DecodedEvent) bool {
+	return !q.node.match(e)
+}
+
+func (q *queryCmp) match(e *DecodedEvent) bool {
+	switch q.selector {
+	case "level":
+		if e.Level == NoLevel {
+			return q.op == "!="
+		}
+		return compareOrdered(int(e.Level), int(q.level), q.op)
+	case "msg":
+		return q.matchString(e.Message)
+	case "time":
+		if e.Time.IsZero() {
+			return false
+		}
+		return compareOrdered(e.Time.UnixNano(), q.time.UnixNano(), q.op)
+	}
+	value, ok := e.Fields[q.field]
+	if !ok {
+		return q.op == "!="
+	}
+	if q.op != "~" {
+		if number, ok := value.(json.Number); ok {
+			if a, err := number.Float64(); err == nil {
+				if b, err := strconv.ParseFloat(q.value, 64); err == nil {
+					return compareOrdered(a, b, q.op)
+				}
+			}
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		return q.matchString(value)
+	case json.Number:
+		return q.matchString(value.String())
+	case nil:
+		return q.matchString("null")
+	case bool:
+		return q.matchString(strconv.FormatBool(value))
+	default:
+		b, _ := json.Marshal(value)
+		return q.matchString(string(b))
+	}
+}
+
+func (q *queryCmp) matchString(s string) bool {
+	if q.regexp != nil {
+		return q.regexp.MatchString(s)
+	}
+	return compareOrdered(s, q.value, q.op)
+}
+
+func compareOrdered[T int | int64 | float64 | string](a, b T, op string) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// ErrInvalidQuery is the error wrapped by ParseQuery errors.
+var ErrInvalidQuery = errors.New("rz: invalid query")
+
+// ParseQuery compiles a query.
+func ParseQuery(source string) (*Query, error) {
+	tokens, err := tokenizeQuery(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &Query{source: source}, nil
+	}
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, p.tokens[p.pos].text)
+	}
+	return &Query{source: source, root: root}, nil
+}
+
+// MustParseQuery is like ParseQuery but panics if the query can't be parsed. It's intended for
+// tests and global variables.
+func MustParseQuery(source string) *Query {
+	q, err := ParseQuery(source)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Match returns true if the event matches the query. An empty query matches all the events.
+func (q *Query) Match(e DecodedEvent) bool {
+	return q.root == nil || q.root.match(&e)
+}
+
+// String returns the source of the query.
+func (q *Query) String() string {
+	return q.source
+}
+
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeQuery(source string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '~' || c == '=':
+			tokens = append(tokens, queryToken{text: string(c)})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			if i+1 < len(source) && source[i+1] == '=' {
+				tokens = append(tokens, queryToken{text: source[i : i+2]})
+				i += 2
+			} else if c == '!' {
+				return nil, fmt.Errorf("%w: unexpected '!'", ErrInvalidQuery)
+			} else {
+				tokens = append(tokens, queryToken{text: string(c)})
+				i++
+			}
+		case c == '"':
+			end := i + 1
+			for ; end < len(source) && source[end] != '"'; end++ {
+				if source[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidQuery)
+			}
+			text, err := strconv.Unquote(source[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+			}
+			tokens = append(tokens, queryToken{text: text, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(source) && !strings.ContainsRune(" \t\n\r()~=!<>\"", rune(source[end])); end++ {
+			}
+			tokens = append(tokens, queryToken{text: source[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) keyword(keyword string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := queryOr{node}
+	for p.keyword("OR") {
+		if node, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		or = append(or, node)
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	and := queryAnd{node}
+	for p.keyword("AND") {
+		if node, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		and = append(and, node)
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.keyword("NOT") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{node}, nil
+	}
+	if p.keyword("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, fmt.Errorf("%w: missing ')'", ErrInvalidQuery)
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (queryNode, error) {
+	if p.pos+3 > len(p.tokens) {
+		return nil, fmt.Errorf("%w: incomplete comparison", ErrInvalidQuery)
+	}
+	selector, op, value := p.tokens[p.pos], p.tokens[p.pos+1], p.tokens[p.pos+2]
+	p.pos += 3
+	switch op.text {
+	case "=", "!=", "<", "<=", ">", ">=", "~":
+	default:
+		return nil, fmt.Errorf("%w: invalid operator %q", ErrInvalidQuery, op.text)
+	}
+	if op.quoted {
+		return nil, fmt.Errorf("%w: invalid operator %q", ErrInvalidQuery, op.text)
+	}
+	cmp := &queryCmp{op: op.text, value: value.text}
+
+	switch name := strings.ToLower(selector.text); {
+	case selector.quoted:
+		return nil, fmt.Errorf("%w: invalid selector %q", ErrInvalidQuery, selector.text)
+	case name == "level":
+		cmp.selector = "level"
+		level := strings.ToLower(value.text)
+		if level == "warn" {
+			level = WarnLevel.String()
+		}
+		l, err := ParseLevel(level)
+		if err != nil || l == NoLevel || cmp.op == "~" {
+			return nil, fmt.Errorf("%w: invalid level comparison %q", ErrInvalidQuery, value.text)
+		}
+		cmp.level = l
+	case name == "msg" || name == "message":
+		cmp.selector = "msg"
+	case name == "time":
+		cmp.selector = "time"
+		t, err := time.Parse(time.RFC3339Nano, value.text)
+		if err != nil || cmp.op == "~" {
+			return nil, fmt.Errorf("%w: invalid time comparison %q", ErrInvalidQuery, value.text)
+		}
+		cmp.time = t
+	case strings.HasPrefix(selector.text, "fields.") && len(selector.text) > len("fields."):
+		cmp.selector = "fields"
+		cmp.field = selector.text[len("fields."):]
+	default:
+		return nil, fmt.Errorf("%w: invalid selector %q", ErrInvalidQuery, selector.text)
+	}
+	if cmp.op == "~" {
+		re, err := regexp.Compile(cmp.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		cmp.regexp = re
+	}
+	return cmp, nil
+}
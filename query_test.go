@@ -0,0 +1,87 @@
+package rz
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	event, err := DecodeEvent([]byte(`{"level":"warning","timestamp":"2020-01-01T00:00:00Z","user_id":"42","attempts":10,"ok":false,"message":"request timeout"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{``, true},
+		{`level>=warn AND fields.user_id="42" AND msg~"timeout"`, true},
+		{`level>=error`, false},
+		{`level=warning`, true},
+		{`level<info OR level>info`, true},
+		{`fields.user_id=43`, false},
+		{`fields.user_id!=43`, true},
+		{`fields.attempts>9`, true},
+		{`fields.attempts>9.5 and fields.attempts<=10`, true},
+		{`fields.attempts>"9"`, true},
+		{`fields.ok=false`, true},
+		{`fields.missing="x"`, false},
+		{`fields.missing!="x"`, true},
+		{`message="request timeout"`, true},
+		{`msg~"^timeout"`, false},
+		{`NOT (msg~"^timeout" OR level=debug)`, true},
+		{`time>="2020-01-01T00:00:00Z" AND time<2020-01-02T00:00:00Z`, true},
+		{`fields.user_id="4" OR fields.user_id="42" AND level=error`, false},
+		{`(fields.user_id="4" OR fields.user_id="42") AND level=warning`, true},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if got := q.Match(event); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	queries := []string{
+		`level>=verbose`,
+		`level~warn`,
+		`foo=bar`,
+		`fields.a=`,
+		`fields.a=="b"`,
+		`(fields.a=b`,
+		`fields.a=b)`,
+		`fields.a="b`,
+		`msg~"["`,
+		`fields.a=b AND`,
+		`time>yesterday`,
+	}
+	for _, query := range queries {
+		if _, err := ParseQuery(query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: invalid error: %v", query, err)
+		}
+	}
+}
+
+func TestStreamWriterSubscribeQuery(t *testing.T) {
+	stream := NewStreamWriter(10)
+	log := New(Writer(stream))
+	log.Info("1")
+	log.Error("2")
+	sub := stream.SubscribeQuery(MustParseQuery("level>=error"), -1, 10)
+	log.Warn("3")
+	log.Error("4")
+	sub.Close()
+
+	messages := ""
+	for event := range sub.C {
+		messages += event.Message
+	}
+	if messages != "24" {
+		t.Errorf("invalid events: %s", messages)
+	}
+}
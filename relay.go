@@ -0,0 +1,78 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrInvalidEvent is returned by Logger.Relay when the event is not a JSON object.
+var ErrInvalidEvent = errors.New("rz: invalid event")
+
+// Relay re-emits an encoded (JSON) event, e.g. received from another process, through the logger.
+// The level and message are read from the logger's level and message fields, the other fields
+// are kept in their original order, followed by fields.
+// If the event has a timestamp field, it's kept and the logger's timestamp is not added.
+// Write errors are handled as for the other log methods: only ErrInvalidEvent is returned.
+func (l *Logger) Relay(event []byte, fields ...Field) error {
+	return l.RelayFrom(event, "", "", fields...)
+}
+
+// RelayFrom re-emits an encoded (JSON) event like Relay, reading its level and message from the
+// fields levelFieldName and messageFieldName, for the events encoded with other field names than
+// the logger's. An empty field name is the logger's one. They're written with the logger's field
+// names.
+func (l *Logger) RelayFrom(event []byte, levelFieldName, messageFieldName string, fields ...Field) error {
+	if levelFieldName == "" {
+		levelFieldName = l.levelFieldName
+	}
+	if messageFieldName == "" {
+		messageFieldName = l.messageFieldName
+	}
+	decoder := json.NewDecoder(bytes.NewReader(event))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return ErrInvalidEvent
+	}
+	level, message := NoLevel, ""
+	relayed := make([]Field, 0, 8)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return ErrInvalidEvent
+		}
+		key, _ := token.(string)
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			return ErrInvalidEvent
+		}
+		switch key {
+		case levelFieldName:
+			var value string
+			if json.Unmarshal(raw, &value) == nil {
+				if parsed, err := ParseLevel(value); err == nil {
+					level = parsed
+					continue
+				}
+			}
+		case messageFieldName:
+			if json.Unmarshal(raw, &message) == nil {
+				continue
+			}
+		case l.timestampFieldName:
+			relayed = append(relayed, Timestamp(false))
+		}
+		compact := &bytes.Buffer{}
+		if err = json.Compact(compact, raw); err != nil {
+			return ErrInvalidEvent
+		}
+		relayed = append(relayed, RawJSON(key, compact.Bytes()))
+	}
+	if _, err := decoder.Token(); err != nil {
+		return ErrInvalidEvent
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return ErrInvalidEvent
+	}
+	return l.logEvent(level, message, nil, append(relayed, fields...), false)
+}
@@ -0,0 +1,51 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRelayFrom(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	if err := log.RelayFrom([]byte(`{"severity":"warning","msg":"slow","level":"x"}`), "severity", "msg"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"warning","level":"x","message":"slow"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  string
+		err   error
+	}{
+		{"Full", `{"level":"error","timestamp":"2020-01-01T00:00:00Z","b":1.50,"a":{"x": [1, 2]},"message":"failed"}`,
+			`{"level":"error","timestamp":"2020-01-01T00:00:00Z","b":1.50,"a":{"x":[1,2]},"source":"app","message":"failed"}` + "\n", nil},
+		{"NoLevel", `{"message":"hello"}`, `{"source":"app","message":"hello"}` + "\n", nil},
+		{"UnknownLevel", `{"level":"trace"}`, `{"level":"trace","source":"app"}` + "\n", nil},
+		{"Invalid", `plain`, "", ErrInvalidEvent},
+		{"Array", `[1]`, "", ErrInvalidEvent},
+		{"Trailing", `{"a":1} {}`, "", ErrInvalidEvent},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			log := New(Writer(out), Fields(Timestamp(true)))
+			if tt.name != "Full" {
+				log = log.With(Fields(Timestamp(false)))
+			}
+			if err := log.Relay([]byte(tt.event), String("source", "app")); err != tt.err {
+				t.Errorf("invalid error: got %v, want %v", err, tt.err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
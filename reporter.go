@@ -0,0 +1,31 @@
+package rz
+
+import (
+	"sync"
+	"time"
+)
+
+// report calls fn every interval until the returned function is called. Stopping waits for a
+// running call of fn to return, and is idempotent.
+func report(interval time.Duration, fn func()) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
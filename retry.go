@@ -0,0 +1,134 @@
+package rz
+
+import "time"
+
+// RetryAttempt adds the field "retry" with the metadata of an attempt of a retry loop:
+// attempt (starting at 1), max_attempts (omitted if <= 0), backoff (omitted if 0, the delay
+// before the next attempt) and last_error (omitted if nil).
+func RetryAttempt(attempt, maxAttempts int, backoff time.Duration, lastErr error) Field {
+	return func(e *Event) {
+		e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "retry"))
+		e.int("attempt", attempt)
+		if maxAttempts > 0 {
+			e.int("max_attempts", maxAttempts)
+		}
+		if backoff != 0 {
+			e.duration("backoff", backoff)
+		}
+		if lastErr != nil {
+			e.error("last_error", lastErr)
+		}
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+	}
+}
+
+// Retry tracks the attempts of a retry loop and logs a single summary event once it's done, with
+// a consistent schema. The failed attempts are only logged with LogAttempts.
+// It's not safe for concurrent use.
+//
+//     retry := logger.Retry("fetch user", 5)
+//     for attempt := 1; ; attempt++ {
+//         err := fetchUser()
+//         if err == nil || attempt == 5 {
+//             retry.Done(err)
+//             break
+//         }
+//         backoff := time.Duration(attempt) * time.Second
+//         retry.Failed(err, backoff)
+//         time.Sleep(backoff)
+//     }
+type Retry struct {
+	logger       *Logger
+	operation    string
+	maxAttempts  int
+	attempts     int
+	start        time.Time
+	totalBackoff time.Duration
+	lastErr      error
+	logAttempts  bool
+	attemptLevel LogLevel
+}
+
+// Retry starts tracking a retry loop for operation. maxAttempts can be <= 0 if unbounded.
+func (l *Logger) Retry(operation string, maxAttempts int) *Retry {
+	return &Retry{
+		logger:      l,
+		operation:   operation,
+		maxAttempts: maxAttempts,
+		start:       time.Now(),
+	}
+}
+
+// LogAttempts makes Failed log each failed attempt with level, in addition to the summary logged
+// by Done. It returns r.
+//
+//     retry := logger.Retry("fetch user", 5).LogAttempts(rz.DebugLevel)
+func (r *Retry) LogAttempts(level LogLevel) *Retry {
+	r.logAttempts = true
+	r.attemptLevel = level
+	return r
+}
+
+// Attempts returns the number of attempts made so far.
+func (r *Retry) Attempts() int {
+	return r.attempts
+}
+
+// Fields returns the fields describing the current attempt, to be added to other events of the
+// loop.
+func (r *Retry) Fields() Field {
+	operation, attempt, maxAttempts, lastErr := r.operation, r.attempts+1, r.maxAttempts, r.lastErr
+	return func(e *Event) {
+		e.string("operation", operation)
+		RetryAttempt(attempt, maxAttempts, 0, lastErr)(e)
+	}
+}
+
+// Failed records a failed attempt. backoff is the delay before the next attempt. The attempt is
+// only logged, with fields, if enabled with LogAttempts.
+func (r *Retry) Failed(err error, backoff time.Duration, fields ...Field) {
+	r.attempts++
+	r.lastErr = err
+	r.totalBackoff += backoff
+	if !r.logAttempts {
+		return
+	}
+	fields = append([]Field{
+		String("operation", r.operation),
+		RetryAttempt(r.attempts, r.maxAttempts, backoff, err),
+	}, fields...)
+	r.logger.logEvent(r.attemptLevel, "attempt failed", nil, fields, false)
+}
+
+// Done records the last attempt and logs a single summary event of the loop: with info level if
+// err is nil, and error level otherwise. The "retry_summary" field holds the number of attempts,
+// the total duration and backoff of the loop and whether it succeeded.
+func (r *Retry) Done(err error, fields ...Field) {
+	r.attempts++
+	if err != nil {
+		r.lastErr = err
+	}
+	level := InfoLevel
+	if err != nil {
+		level = ErrorLevel
+	}
+	attempts, maxAttempts, duration, totalBackoff, lastErr := r.attempts, r.maxAttempts, time.Since(r.start), r.totalBackoff, r.lastErr
+	fields = append([]Field{
+		String("operation", r.operation),
+		func(e *Event) {
+			e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "retry_summary"))
+			e.bool("success", err == nil)
+			e.int("attempts", attempts)
+			if maxAttempts > 0 {
+				e.int("max_attempts", maxAttempts)
+			}
+			e.duration("duration", duration)
+			e.duration("total_backoff", totalBackoff)
+			if lastErr != nil {
+				e.error("last_error", lastErr)
+			}
+			e.buf = e.encoder.AppendEndMarker(e.buf)
+		},
+	}, fields...)
+	r.logger.logEvent(level, "retry summary", nil, fields, false)
+}
@@ -0,0 +1,40 @@
+package rz
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+
+	retry := log.Retry("fetch", 3).LogAttempts(WarnLevel)
+	retry.Failed(errors.New("timeout"), time.Second)
+	log.Info("trying", retry.Fields())
+	retry.Failed(errors.New("refused"), 2*time.Second)
+	retry.Done(nil)
+
+	want := `^{"level":"warning","operation":"fetch","retry":{"attempt":1,"max_attempts":3,"backoff":1000,"last_error":"timeout"},"message":"attempt failed"}
+{"level":"info","operation":"fetch","retry":{"attempt":2,"max_attempts":3,"last_error":"timeout"},"message":"trying"}
+{"level":"warning","operation":"fetch","retry":{"attempt":2,"max_attempts":3,"backoff":2000,"last_error":"refused"},"message":"attempt failed"}
+{"level":"info","operation":"fetch","retry_summary":{"success":true,"attempts":3,"max_attempts":3,"duration":[0-9.e-]+,"total_backoff":3000,"last_error":"refused"},"message":"retry summary"}
+$`
+	if got := out.String(); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	// only the summary is logged by default
+	out.Reset()
+	retry = log.Retry("fetch", 0)
+	retry.Failed(errors.New("timeout"), time.Second)
+	retry.Done(errors.New("refused"))
+	want = `^{"level":"error","operation":"fetch","retry_summary":{"success":false,"attempts":2,"duration":[0-9.e-]+,"total_backoff":1000,"last_error":"refused"},"message":"retry summary"}
+$`
+	if got := out.String(); !regexp.MustCompile(want).MatchString(got) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
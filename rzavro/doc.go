@@ -0,0 +1,14 @@
+// Package rzavro provides an encoder writing rz events as the Avro binary records of a
+// user-provided schema, and a writer framing them in an Avro object container file, for data-lake
+// ingestion.
+//
+//    schema := rzavro.MustParseSchema(`{"type": "record", "name": "Event", "fields": [
+//        {"name": "level", "type": "string"},
+//        {"name": "message", "type": "string"},
+//        {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+//        {"name": "user_id", "type": ["null", "string"]}
+//    ]}`)
+//    w, err := rzavro.NewWriter(file, schema, rzavro.Deflate())
+//    logger := rz.New(rz.UseEncoder(rzavro.NewEncoder(schema)), rz.Writer(w))
+//    defer w.Close()
+package rzavro
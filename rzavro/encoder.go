@@ -0,0 +1,612 @@
+package rzavro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// Tags of the values of the events being built. Scalar values are followed by their Avro binary
+// encoding, so they are copied as is to the records of the fields of the same type.
+const (
+	tagObject byte = iota + 1 // fields (tagKey and a value), then tagEnd
+	tagArray                  // items, then tagEnd
+	tagEnd
+	tagKey    // Avro string
+	tagNull   // nothing
+	tagBool   // Avro boolean
+	tagLong   // Avro long
+	tagFloat  // Avro float
+	tagDouble // Avro double
+	tagString // Avro string
+	tagJSON   // Avro string holding a JSON value, from AppendInterface
+)
+
+var errInvalidEncoding = errors.New("rzavro: invalid event encoding")
+
+var _ rz.Encoder = Encoder{}
+
+// Encoder is a rz.Encoder encoding the events as Avro binary records of a schema, to be written
+// to an object container file by a Writer with the same schema:
+//
+//     log := rz.New(rz.UseEncoder(rzavro.NewEncoder(schema)), rz.Writer(w))
+//
+// The fields are encoded as they are added, and the record is assembled in the order of the
+// schema by AppendLineBreak once the event is complete. An event which can't be converted to
+// the schema (e.g. missing a field without default) is dropped: AppendLineBreak returns an empty
+// event, and the error is passed to rz.ErrorHandler.
+//
+// As the encoded events are not JSON, the processors, formatters and writers parsing them
+// (redaction, queries, console writer...) can't be used with this encoder.
+type Encoder struct {
+	schema *Schema
+}
+
+// NewEncoder returns an Encoder of the records of schema.
+func NewEncoder(schema *Schema) Encoder {
+	return Encoder{schema: schema}
+}
+
+var recordPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 500)
+		return &b
+	},
+}
+
+// AppendLineBreak implements the rz.Encoder interface: it converts the event dst to its Avro
+// record. Records are not delimited, the Writer frames them in blocks.
+func (e Encoder) AppendLineBreak(dst []byte) []byte {
+	event, end, err := parseValue(dst, 0)
+	if err == nil && (event.tag != tagObject || end != len(dst)) {
+		err = errInvalidEncoding
+	}
+	buf := recordPool.Get().(*[]byte)
+	out := (*buf)[:0]
+	if err == nil {
+		out, err = e.schema.root.encodeRecordValue(out, &event)
+	}
+	if err != nil {
+		handleError(err)
+		dst = dst[:0]
+	} else {
+		dst = append(dst[:0], out...)
+	}
+	if cap(out) <= 1<<16 {
+		*buf = out
+		recordPool.Put(buf)
+	}
+	return dst
+}
+
+func handleError(err error) {
+	if rz.ErrorHandler != nil {
+		rz.ErrorHandler(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "rzavro: could not encode event: %v\n", err)
+	}
+}
+
+// AppendBeginMarker implements the rz.Encoder interface.
+func (Encoder) AppendBeginMarker(dst []byte) []byte {
+	return append(dst, tagObject)
+}
+
+// AppendEndMarker implements the rz.Encoder interface.
+func (Encoder) AppendEndMarker(dst []byte) []byte {
+	return append(dst, tagEnd)
+}
+
+// AppendObjectData implements the rz.Encoder interface.
+func (Encoder) AppendObjectData(dst []byte, o []byte) []byte {
+	if len(o) > 0 && o[0] == tagObject {
+		o = o[1:]
+	}
+	return append(dst, o...)
+}
+
+// AppendArrayStart implements the rz.Encoder interface.
+func (Encoder) AppendArrayStart(dst []byte) []byte {
+	return append(dst, tagArray)
+}
+
+// AppendArrayEnd implements the rz.Encoder interface.
+func (Encoder) AppendArrayEnd(dst []byte) []byte {
+	return append(dst, tagEnd)
+}
+
+// AppendArrayDelim implements the rz.Encoder interface: items are not delimited.
+func (Encoder) AppendArrayDelim(dst []byte) []byte {
+	return dst
+}
+
+// AppendKey implements the rz.Encoder interface.
+func (Encoder) AppendKey(dst []byte, key string) []byte {
+	return append(appendLong(append(dst, tagKey), int64(len(key))), key...)
+}
+
+// AppendNil implements the rz.Encoder interface.
+func (Encoder) AppendNil(dst []byte) []byte {
+	return append(dst, tagNull)
+}
+
+// AppendString implements the rz.Encoder interface.
+func (Encoder) AppendString(dst []byte, s string) []byte {
+	return append(appendLong(append(dst, tagString), int64(len(s))), s...)
+}
+
+// AppendStrings implements the rz.Encoder interface.
+func (e Encoder) AppendStrings(dst []byte, vals []string) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendString(dst, val)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendBytes implements the rz.Encoder interface: s is encoded as a string, as with rz's JSON
+// encoder.
+func (Encoder) AppendBytes(dst, s []byte) []byte {
+	return append(appendLong(append(dst, tagString), int64(len(s))), s...)
+}
+
+// AppendHex implements the rz.Encoder interface.
+func (Encoder) AppendHex(dst, s []byte) []byte {
+	const hex = "0123456789abcdef"
+	dst = appendLong(append(dst, tagString), int64(len(s)*2))
+	for _, v := range s {
+		dst = append(dst, hex[v>>4], hex[v&0x0f])
+	}
+	return dst
+}
+
+// AppendBool implements the rz.Encoder interface.
+func (Encoder) AppendBool(dst []byte, val bool) []byte {
+	if val {
+		return append(dst, tagBool, 1)
+	}
+	return append(dst, tagBool, 0)
+}
+
+// AppendBools implements the rz.Encoder interface.
+func (e Encoder) AppendBools(dst []byte, vals []bool) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendBool(dst, val)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendInt64 implements the rz.Encoder interface.
+func (Encoder) AppendInt64(dst []byte, val int64) []byte {
+	return appendLong(append(dst, tagLong), val)
+}
+
+// AppendUint64 implements the rz.Encoder interface: values exceeding a long are kept as JSON
+// numbers, which can only be converted to doubles and strings.
+func (e Encoder) AppendUint64(dst []byte, val uint64) []byte {
+	if val > math.MaxInt64 {
+		return appendJSON(dst, strconv.AppendUint(nil, val, 10))
+	}
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt implements the rz.Encoder interface.
+func (e Encoder) AppendInt(dst []byte, val int) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt8 implements the rz.Encoder interface.
+func (e Encoder) AppendInt8(dst []byte, val int8) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt16 implements the rz.Encoder interface.
+func (e Encoder) AppendInt16(dst []byte, val int16) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt32 implements the rz.Encoder interface.
+func (e Encoder) AppendInt32(dst []byte, val int32) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendUint implements the rz.Encoder interface.
+func (e Encoder) AppendUint(dst []byte, val uint) []byte {
+	return e.AppendUint64(dst, uint64(val))
+}
+
+// AppendUint8 implements the rz.Encoder interface.
+func (e Encoder) AppendUint8(dst []byte, val uint8) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendUint16 implements the rz.Encoder interface.
+func (e Encoder) AppendUint16(dst []byte, val uint16) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendUint32 implements the rz.Encoder interface.
+func (e Encoder) AppendUint32(dst []byte, val uint32) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInts implements the rz.Encoder interface.
+func (e Encoder) AppendInts(dst []byte, vals []int) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendInts8 implements the rz.Encoder interface.
+func (e Encoder) AppendInts8(dst []byte, vals []int8) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendInts16 implements the rz.Encoder interface.
+func (e Encoder) AppendInts16(dst []byte, vals []int16) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendInts32 implements the rz.Encoder interface.
+func (e Encoder) AppendInts32(dst []byte, vals []int32) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendInts64 implements the rz.Encoder interface.
+func (e Encoder) AppendInts64(dst []byte, vals []int64) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, val)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendUints implements the rz.Encoder interface.
+func (e Encoder) AppendUints(dst []byte, vals []uint) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, uint64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendUints8 implements the rz.Encoder interface.
+func (e Encoder) AppendUints8(dst []byte, vals []uint8) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendUints16 implements the rz.Encoder interface.
+func (e Encoder) AppendUints16(dst []byte, vals []uint16) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendUints32 implements the rz.Encoder interface.
+func (e Encoder) AppendUints32(dst []byte, vals []uint32) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendUints64 implements the rz.Encoder interface.
+func (e Encoder) AppendUints64(dst []byte, vals []uint64) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, val)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendFloat32 implements the rz.Encoder interface.
+func (Encoder) AppendFloat32(dst []byte, val float32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(val))
+	return append(append(dst, tagFloat), b[:]...)
+}
+
+// AppendFloat64 implements the rz.Encoder interface.
+func (Encoder) AppendFloat64(dst []byte, val float64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(val))
+	return append(append(dst, tagDouble), b[:]...)
+}
+
+// AppendFloats32 implements the rz.Encoder interface.
+func (e Encoder) AppendFloats32(dst []byte, vals []float32) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendFloat32(dst, val)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendFloats64 implements the rz.Encoder interface.
+func (e Encoder) AppendFloats64(dst []byte, vals []float64) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendFloat64(dst, val)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendDuration implements the rz.Encoder interface.
+func (e Encoder) AppendDuration(dst []byte, d time.Duration, unit time.Duration, useInt bool) []byte {
+	if useInt {
+		return e.AppendInt64(dst, int64(d/unit))
+	}
+	return e.AppendFloat64(dst, float64(d)/float64(unit))
+}
+
+// AppendDurations implements the rz.Encoder interface.
+func (e Encoder) AppendDurations(dst []byte, vals []time.Duration, unit time.Duration, useInt bool) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendDuration(dst, val, unit, useInt)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendTime implements the rz.Encoder interface: t is encoded as its Unix time if format is
+// empty, as a string formatted with format otherwise. The strings are converted to the fields
+// with the timestamp logical types if they are RFC 3339 times.
+func (e Encoder) AppendTime(dst []byte, t time.Time, format string) []byte {
+	if format == "" {
+		return e.AppendInt64(dst, t.Unix())
+	}
+	var b [64]byte
+	return e.AppendBytes(dst, t.AppendFormat(b[:0], format))
+}
+
+// AppendTimes implements the rz.Encoder interface.
+func (e Encoder) AppendTimes(dst []byte, vals []time.Time, format string) []byte {
+	dst = append(dst, tagArray)
+	for _, val := range vals {
+		dst = e.AppendTime(dst, val, format)
+	}
+	return append(dst, tagEnd)
+}
+
+// AppendIPAddr implements the rz.Encoder interface.
+func (e Encoder) AppendIPAddr(dst []byte, ip net.IP) []byte {
+	return e.AppendString(dst, ip.String())
+}
+
+// AppendIPPrefix implements the rz.Encoder interface.
+func (e Encoder) AppendIPPrefix(dst []byte, pfx net.IPNet) []byte {
+	return e.AppendString(dst, pfx.String())
+}
+
+// AppendMACAddr implements the rz.Encoder interface.
+func (e Encoder) AppendMACAddr(dst []byte, ha net.HardwareAddr) []byte {
+	return e.AppendString(dst, ha.String())
+}
+
+// AppendInterface implements the rz.Encoder interface: i is marshaled to JSON, and converted to
+// the type of its field when the record is assembled.
+func (e Encoder) AppendInterface(dst []byte, i interface{}) []byte {
+	if raw, ok := i.(json.RawMessage); ok {
+		return appendJSON(dst, raw)
+	}
+	marshaled, err := json.Marshal(i)
+	if err != nil {
+		return e.AppendString(dst, fmt.Sprintf("marshaling error: %v", err))
+	}
+	return appendJSON(dst, marshaled)
+}
+
+func appendJSON(dst []byte, j []byte) []byte {
+	return append(appendLong(append(dst, tagJSON), int64(len(j))), j...)
+}
+
+// decodeJSON decodes the JSON value j with json.Number numbers.
+func decodeJSON(j []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(j))
+	decoder.UseNumber()
+	var v interface{}
+	err := decoder.Decode(&v)
+	return v, err
+}
+
+// value is a value of an event being built.
+type value struct {
+	tag byte
+	// data is the Avro encoding of scalars, and the JSON text of tagJSON values.
+	data []byte
+	// keys are the keys of objects, and items their values or the items of arrays.
+	keys  []string
+	items []value
+}
+
+// parseValue parses the value starting at src[i], and returns the index following it.
+func parseValue(src []byte, i int) (value, int, error) {
+	if i >= len(src) {
+		return value{}, i, errInvalidEncoding
+	}
+	v := value{tag: src[i]}
+	i++
+	switch v.tag {
+	case tagNull:
+		return v, i, nil
+	case tagBool:
+		if i+1 > len(src) {
+			return v, i, errInvalidEncoding
+		}
+		v.data = src[i : i+1]
+		return v, i + 1, nil
+	case tagLong:
+		_, n := binary.Varint(src[i:])
+		if n <= 0 {
+			return v, i, errInvalidEncoding
+		}
+		v.data = src[i : i+n]
+		return v, i + n, nil
+	case tagFloat, tagDouble:
+		size := 4
+		if v.tag == tagDouble {
+			size = 8
+		}
+		if i+size > len(src) {
+			return v, i, errInvalidEncoding
+		}
+		v.data = src[i : i+size]
+		return v, i + size, nil
+	case tagString, tagJSON:
+		start := i
+		s, i, ok := parseString(src, i)
+		if !ok {
+			return v, i, errInvalidEncoding
+		}
+		if v.data = s; v.tag == tagString {
+			v.data = src[start:i]
+		}
+		return v, i, nil
+	case tagArray, tagObject:
+		for i < len(src) && src[i] != tagEnd {
+			if v.tag == tagObject {
+				if src[i] != tagKey {
+					return v, i, errInvalidEncoding
+				}
+				key, next, ok := parseString(src, i+1)
+				if !ok {
+					return v, i, errInvalidEncoding
+				}
+				v.keys = append(v.keys, string(key))
+				i = next
+			}
+			item, next, err := parseValue(src, i)
+			if err != nil {
+				return v, i, err
+			}
+			v.items = append(v.items, item)
+			i = next
+		}
+		if i >= len(src) {
+			return v, i, errInvalidEncoding
+		}
+		return v, i + 1, nil
+	}
+	return v, i, errInvalidEncoding
+}
+
+// parseString parses the Avro string starting at src[i], and returns its bytes and the index
+// following it.
+func parseString(src []byte, i int) ([]byte, int, bool) {
+	length, n := binary.Varint(src[i:])
+	if n <= 0 || length < 0 || length > int64(len(src)-i-n) {
+		return nil, i, false
+	}
+	i += n
+	return src[i : i+int(length)], i + int(length), true
+}
+
+// field returns the value of the last field key of the object v.
+func (v *value) field(key string) (*value, bool) {
+	for i := len(v.keys) - 1; i >= 0; i-- {
+		if v.keys[i] == key {
+			return &v.items[i], true
+		}
+	}
+	return nil, false
+}
+
+// long returns the long of a tagLong value.
+func (v *value) long() int64 {
+	i, _ := binary.Varint(v.data)
+	return i
+}
+
+// float returns the float of a tagFloat or tagDouble value.
+func (v *value) float() float64 {
+	if v.tag == tagFloat {
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(v.data)))
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(v.data))
+}
+
+// string returns the string of a tagString value.
+func (v *value) string() string {
+	s, _, _ := parseString(v.data, 0)
+	return string(s)
+}
+
+// interfaceValue returns v decoded as with encoding/json with json.Number numbers, to convert
+// it with schemaType.encode.
+func (v *value) interfaceValue() (interface{}, error) {
+	switch v.tag {
+	case tagNull:
+		return nil, nil
+	case tagBool:
+		return v.data[0] == 1, nil
+	case tagLong:
+		return json.Number(strconv.FormatInt(v.long(), 10)), nil
+	case tagFloat, tagDouble:
+		f := v.float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("value %v is not a number", f)
+		}
+		bitSize := 64
+		if v.tag == tagFloat {
+			bitSize = 32
+		}
+		return json.Number(strconv.FormatFloat(f, 'f', -1, bitSize)), nil
+	case tagString:
+		return v.string(), nil
+	case tagJSON:
+		return decodeJSON(v.data)
+	case tagArray:
+		items := make([]interface{}, len(v.items))
+		for i := range v.items {
+			item, err := v.items[i].interfaceValue()
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case tagObject:
+		object := make(map[string]interface{}, len(v.keys))
+		for i, key := range v.keys {
+			item, err := v.items[i].interfaceValue()
+			if err != nil {
+				return nil, err
+			}
+			object[key] = item
+		}
+		return object, nil
+	}
+	return nil, errInvalidEncoding
+}
@@ -0,0 +1,128 @@
+package rzavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+const encoderSchema = `{"type": "record", "name": "Event", "fields": [
+	{"name": "level", "type": {"type": "enum", "name": "Level", "symbols": ["debug", "info", "warning", "error"]}},
+	{"name": "message", "type": "string"},
+	{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+	{"name": "service", "type": "string"},
+	{"name": "user_id", "type": ["null", "string"]},
+	{"name": "count", "type": "int"},
+	{"name": "ratio", "type": "float"},
+	{"name": "total", "type": "double"},
+	{"name": "max", "type": "double"},
+	{"name": "ok", "type": "boolean"},
+	{"name": "elapsed", "type": "double"},
+	{"name": "tags", "type": {"type": "array", "items": "string"}},
+	{"name": "ids", "type": {"type": "array", "items": ["null", "long"]}},
+	{"name": "user", "type": {"type": "record", "name": "User", "fields": [
+		{"name": "name", "type": "string"},
+		{"name": "admin", "type": "boolean", "default": false}
+	]}},
+	{"name": "labels", "type": {"type": "map", "values": "string"}},
+	{"name": "any", "type": {"type": "map", "values": {"type": "array", "items": "long"}}},
+	{"name": "raw", "type": ["null", {"type": "record", "name": "Raw", "fields": [{"name": "b", "type": "boolean"}]}]},
+	{"name": "hex", "type": "string"},
+	{"name": "ip", "type": "string"},
+	{"name": "missing", "type": ["null", "string"]},
+	{"name": "latency", "type": "double", "default": 0}
+]}`
+
+func encoderFields(log rz.Logger) []rz.Field {
+	return []rz.Field{
+		rz.String("user_id", "42"),
+		rz.Int("count", -7),
+		rz.Float32("ratio", 0.5),
+		rz.Int("total", 12),
+		rz.Uint64("max", math.MaxUint64),
+		rz.Bool("ok", true),
+		rz.Duration("elapsed", 1500*time.Millisecond),
+		rz.Strings("tags", []string{"a", ""}),
+		rz.Ints64("ids", []int64{1, -1}),
+		rz.Dict("user", log.NewDict(rz.String("name", "john"))),
+		rz.Dict("labels", log.NewDict(rz.String("env", "prod"), rz.String("zone", "a"), rz.String("env", "dev"))),
+		rz.Any("any", map[string][]int{"a": {1, 2}}),
+		rz.RawJSON("raw", []byte(`{"b":true}`)),
+		rz.Hex("hex", []byte{0xde, 0xad}),
+		rz.IP("ip", net.IPv4(10, 0, 0, 1)),
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	schema := MustParseSchema(encoderSchema)
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	options := []rz.LoggerOption{
+		rz.TimestampFunc(func() time.Time { return ts }),
+		rz.TimeFieldFormat(time.RFC3339Nano),
+	}
+	jsonOut, avroOut := &bytes.Buffer{}, &bytes.Buffer{}
+	jsonLog := rz.New(append(options, rz.Writer(jsonOut), rz.Fields(rz.String("service", "api")))...)
+	avroLog := rz.New(append(options, rz.Writer(avroOut), rz.UseEncoder(NewEncoder(schema)), rz.Fields(rz.String("service", "api")))...)
+	jsonLog.Info("hello", encoderFields(jsonLog)...)
+	avroLog.Info("hello", encoderFields(avroLog)...)
+
+	// the records are the ones of the JSON events converted to the schema
+	decoder := json.NewDecoder(jsonOut)
+	decoder.UseNumber()
+	event := map[string]interface{}{}
+	if err := decoder.Decode(&event); err != nil {
+		t.Fatal(err)
+	}
+	want, err := schema.root.encodeRecord(nil, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := avroOut.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("invalid record:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEncoderInvalidEvent(t *testing.T) {
+	var handled []error
+	rz.ErrorHandler = func(err error) { handled = append(handled, err) }
+	defer func() { rz.ErrorHandler = nil }()
+
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.UseEncoder(NewEncoder(MustParseSchema(testSchema))))
+	log.Info("hello", rz.String("user_id", "42"))
+	written := out.Len()
+	log.Log("no level")
+	log.Info("hello", rz.Bool("user_id", true))
+
+	if written == 0 {
+		t.Error("valid event is not written")
+	}
+	if out.Len() != written {
+		t.Errorf("invalid events are written: %v", out.Bytes()[written:])
+	}
+	if len(handled) != 2 || !strings.Contains(handled[0].Error(), `field "level"`) ||
+		!strings.Contains(handled[1].Error(), `field "user_id"`) {
+		t.Errorf("invalid errors: %v", handled)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	e := Encoder{}
+	src := e.AppendKey(e.AppendBeginMarker(nil), "a")
+	src = e.AppendEndMarker(e.AppendStrings(src, []string{"x"}))
+	if _, end, err := parseValue(src, 0); err != nil || end != len(src) {
+		t.Errorf("parseValue: %d, %v", end, err)
+	}
+	for i := 1; i < len(src); i++ {
+		if _, _, err := parseValue(src[:i], 0); !errors.Is(err, errInvalidEncoding) {
+			t.Errorf("truncated value %v: invalid error: %v", src[:i], err)
+		}
+	}
+}
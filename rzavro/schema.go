@@ -0,0 +1,569 @@
+package rzavro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidSchema is returned by ParseSchema when the schema is not a valid Avro schema.
+var ErrInvalidSchema = errors.New("rzavro: invalid schema")
+
+type kind uint8
+
+const (
+	kindNull kind = iota
+	kindBoolean
+	kindInt
+	kindLong
+	kindFloat
+	kindDouble
+	kindBytes
+	kindString
+	kindRecord
+	kindEnum
+	kindArray
+	kindMap
+	kindUnion
+	kindFixed
+)
+
+// Schema is a parsed Avro schema, describing the records written for the events. Each field of
+// the record is filled with the value of the event field of the same name, converted to the
+// field type.
+type Schema struct {
+	json []byte
+	root *schemaType
+}
+
+type schemaType struct {
+	kind        kind
+	name        string
+	logicalType string
+	fields      []schemaField // records
+	symbols     []string      // enums
+	items       *schemaType   // arrays and maps
+	branches    []*schemaType // unions
+	size        int           // fixeds
+}
+
+type schemaField struct {
+	name       string
+	typ        *schemaType
+	def        interface{}
+	hasDefault bool
+}
+
+// ParseSchema parses the JSON Avro schema of the records. The top level type must be a record,
+// whose fields are looked up by name in the events (e.g. "level", "message", "timestamp").
+//
+// Primitive types, records, enums, arrays, maps, unions and fixeds are supported, with the
+// timestamp-millis and timestamp-micros logical types converting the time strings of events.
+func ParseSchema(schema string) (*Schema, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(schema)))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	root, err := parseType(v, map[string]*schemaType{})
+	if err != nil {
+		return nil, err
+	}
+	if root.kind != kindRecord {
+		return nil, fmt.Errorf("%w: top level type must be a record", ErrInvalidSchema)
+	}
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, []byte(schema)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return &Schema{json: compact.Bytes(), root: root}, nil
+}
+
+// MustParseSchema is like ParseSchema but panics if the schema is invalid.
+func MustParseSchema(schema string) *Schema {
+	s, err := ParseSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the JSON schema.
+func (s *Schema) String() string {
+	return string(s.json)
+}
+
+var primitives = map[string]kind{
+	"null":    kindNull,
+	"boolean": kindBoolean,
+	"int":     kindInt,
+	"long":    kindLong,
+	"float":   kindFloat,
+	"double":  kindDouble,
+	"bytes":   kindBytes,
+	"string":  kindString,
+}
+
+func parseType(v interface{}, names map[string]*schemaType) (*schemaType, error) {
+	switch v := v.(type) {
+	case string:
+		if k, ok := primitives[v]; ok {
+			return &schemaType{kind: k}, nil
+		}
+		if t, ok := names[v]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSchema, v)
+	case []interface{}:
+		t := &schemaType{kind: kindUnion}
+		for _, branch := range v {
+			b, err := parseType(branch, names)
+			if err != nil {
+				return nil, err
+			}
+			if b.kind == kindUnion {
+				return nil, fmt.Errorf("%w: unions can't contain unions", ErrInvalidSchema)
+			}
+			t.branches = append(t.branches, b)
+		}
+		if len(t.branches) == 0 {
+			return nil, fmt.Errorf("%w: empty union", ErrInvalidSchema)
+		}
+		return t, nil
+	case map[string]interface{}:
+		return parseComplexType(v, names)
+	}
+	return nil, fmt.Errorf("%w: invalid type %v", ErrInvalidSchema, v)
+}
+
+func parseComplexType(v map[string]interface{}, names map[string]*schemaType) (*schemaType, error) {
+	typeName, _ := v["type"].(string)
+	name, _ := v["name"].(string)
+	logicalType, _ := v["logicalType"].(string)
+	if k, ok := primitives[typeName]; ok {
+		return &schemaType{kind: k, logicalType: logicalType}, nil
+	}
+
+	switch typeName {
+	case "record":
+		if name == "" {
+			return nil, fmt.Errorf("%w: record without name", ErrInvalidSchema)
+		}
+		t := &schemaType{kind: kindRecord, name: name}
+		names[name] = t
+		fields, _ := v["fields"].([]interface{})
+		for _, f := range fields {
+			f, _ := f.(map[string]interface{})
+			fieldName, _ := f["name"].(string)
+			if fieldName == "" {
+				return nil, fmt.Errorf("%w: field without name in record %q", ErrInvalidSchema, name)
+			}
+			fieldType, err := parseType(f["type"], names)
+			if err != nil {
+				return nil, err
+			}
+			def, hasDefault := f["default"]
+			t.fields = append(t.fields, schemaField{name: fieldName, typ: fieldType, def: def, hasDefault: hasDefault})
+		}
+		return t, nil
+	case "enum":
+		t := &schemaType{kind: kindEnum, name: name}
+		symbols, _ := v["symbols"].([]interface{})
+		for _, symbol := range symbols {
+			s, ok := symbol.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid symbol %v in enum %q", ErrInvalidSchema, symbol, name)
+			}
+			t.symbols = append(t.symbols, s)
+		}
+		if name != "" {
+			names[name] = t
+		}
+		return t, nil
+	case "array", "map":
+		itemsKey, k := "items", kindArray
+		if typeName == "map" {
+			itemsKey, k = "values", kindMap
+		}
+		items, err := parseType(v[itemsKey], names)
+		if err != nil {
+			return nil, err
+		}
+		return &schemaType{kind: k, items: items}, nil
+	case "fixed":
+		number, _ := v["size"].(json.Number)
+		size, err := number.Int64()
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("%w: invalid size of fixed %q", ErrInvalidSchema, name)
+		}
+		t := &schemaType{kind: kindFixed, name: name, size: int(size)}
+		if name != "" {
+			names[name] = t
+		}
+		return t, nil
+	}
+	if typeName == "" {
+		// {"type": {...}} or {"type": [...]}
+		if _, ok := v["type"]; ok {
+			return parseType(v["type"], names)
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSchema, typeName)
+}
+
+// encode appends the Avro binary encoding of v, decoded from JSON with json.Number numbers.
+func (t *schemaType) encode(dst []byte, v interface{}) ([]byte, error) {
+	switch t.kind {
+	case kindNull:
+		if v != nil {
+			return dst, errMismatch(t, v)
+		}
+		return dst, nil
+	case kindBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return dst, errMismatch(t, v)
+		}
+		if b {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case kindInt, kindLong:
+		i, err := t.long(v)
+		if err != nil {
+			return dst, err
+		}
+		if t.kind == kindInt && (i < math.MinInt32 || i > math.MaxInt32) {
+			return dst, errMismatch(t, v)
+		}
+		return appendLong(dst, i), nil
+	case kindFloat, kindDouble:
+		n, ok := v.(json.Number)
+		if !ok {
+			return dst, errMismatch(t, v)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return dst, errMismatch(t, v)
+		}
+		if t.kind == kindFloat {
+			var b [4]byte
+			binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+			return append(dst, b[:]...), nil
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+		return append(dst, b[:]...), nil
+	case kindBytes, kindString:
+		var s string
+		switch v := v.(type) {
+		case string:
+			s = v
+		case json.Number:
+			if t.kind != kindString {
+				return dst, errMismatch(t, v)
+			}
+			s = string(v)
+		default:
+			return dst, errMismatch(t, v)
+		}
+		return append(appendLong(dst, int64(len(s))), s...), nil
+	case kindFixed:
+		s, ok := v.(string)
+		if !ok || len(s) != t.size {
+			return dst, errMismatch(t, v)
+		}
+		return append(dst, s...), nil
+	case kindEnum:
+		s, _ := v.(string)
+		for i, symbol := range t.symbols {
+			if symbol == s {
+				return appendLong(dst, int64(i)), nil
+			}
+		}
+		return dst, errMismatch(t, v)
+	case kindRecord:
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return dst, errMismatch(t, v)
+		}
+		return t.encodeRecord(dst, object)
+	case kindArray:
+		items, ok := v.([]interface{})
+		if !ok {
+			return dst, errMismatch(t, v)
+		}
+		if len(items) > 0 {
+			dst = appendLong(dst, int64(len(items)))
+			for _, item := range items {
+				var err error
+				if dst, err = t.items.encode(dst, item); err != nil {
+					return dst, err
+				}
+			}
+		}
+		return append(dst, 0), nil
+	case kindMap:
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return dst, errMismatch(t, v)
+		}
+		if len(object) > 0 {
+			dst = appendLong(dst, int64(len(object)))
+			for _, key := range sortedKeys(object) {
+				var err error
+				dst = append(appendLong(dst, int64(len(key))), key...)
+				if dst, err = t.items.encode(dst, object[key]); err != nil {
+					return dst, err
+				}
+			}
+		}
+		return append(dst, 0), nil
+	case kindUnion:
+		// the first branch accepting the value is selected
+		for i, branch := range t.branches {
+			start := len(dst)
+			out, err := branch.encode(appendLong(dst, int64(i)), v)
+			if err == nil {
+				return out, nil
+			}
+			dst = dst[:start]
+		}
+		return dst, errMismatch(t, v)
+	}
+	return dst, errMismatch(t, v)
+}
+
+func (t *schemaType) encodeRecord(dst []byte, object map[string]interface{}) ([]byte, error) {
+	for _, field := range t.fields {
+		value, ok := object[field.name]
+		if !ok {
+			var err error
+			if dst, err = field.encodeMissing(dst); err != nil {
+				return dst, err
+			}
+			continue
+		}
+		var err error
+		if dst, err = field.typ.encode(dst, value); err != nil {
+			return dst, fmt.Errorf("rzavro: field %q: %w", field.name, err)
+		}
+	}
+	return dst, nil
+}
+
+// encodeMissing appends the default value of the field, or null if it's nullable.
+func (field *schemaField) encodeMissing(dst []byte) ([]byte, error) {
+	var err error
+	switch {
+	case field.hasDefault && field.typ.kind == kindUnion:
+		// the default value of a union is of the type of its first branch
+		if dst, err = field.typ.branches[0].encode(appendLong(dst, 0), field.def); err != nil {
+			return dst, fmt.Errorf("rzavro: field %q: invalid default: %w", field.name, err)
+		}
+	case field.hasDefault:
+		if dst, err = field.typ.encode(dst, field.def); err != nil {
+			return dst, fmt.Errorf("rzavro: field %q: %w", field.name, err)
+		}
+	case field.typ.nullable():
+		if dst, err = field.typ.encode(dst, nil); err != nil {
+			return dst, fmt.Errorf("rzavro: field %q: %w", field.name, err)
+		}
+	default:
+		return dst, fmt.Errorf("rzavro: missing field %q", field.name)
+	}
+	return dst, nil
+}
+
+// encodeValue appends the Avro binary encoding of the value v of an event. The scalars of the
+// type of the field are copied as is, the other values are converted with encode.
+func (t *schemaType) encodeValue(dst []byte, v *value) ([]byte, error) {
+	switch {
+	case t.kind == kindUnion:
+		// the first branch accepting the value is selected
+		for i, branch := range t.branches {
+			start := len(dst)
+			out, err := branch.encodeValue(appendLong(dst, int64(i)), v)
+			if err == nil {
+				return out, nil
+			}
+			dst = dst[:start]
+		}
+		return dst, errMismatchValue(t, v)
+	case t.kind == kindNull && v.tag == tagNull:
+		return dst, nil
+	case t.kind == kindBoolean && v.tag == tagBool,
+		t.kind == kindLong && v.tag == tagLong,
+		t.kind == kindFloat && v.tag == tagFloat,
+		t.kind == kindDouble && v.tag == tagDouble,
+		(t.kind == kindString || t.kind == kindBytes) && v.tag == tagString:
+		return append(dst, v.data...), nil
+	case t.kind == kindInt && v.tag == tagLong:
+		if i := v.long(); i < math.MinInt32 || i > math.MaxInt32 {
+			return dst, errMismatchValue(t, v)
+		}
+		return append(dst, v.data...), nil
+	case t.kind == kindEnum && v.tag == tagString:
+		s := v.string()
+		for i, symbol := range t.symbols {
+			if symbol == s {
+				return appendLong(dst, int64(i)), nil
+			}
+		}
+		return dst, errMismatchValue(t, v)
+	case t.kind == kindRecord && v.tag == tagObject:
+		return t.encodeRecordValue(dst, v)
+	case t.kind == kindArray && v.tag == tagArray:
+		if len(v.items) > 0 {
+			dst = appendLong(dst, int64(len(v.items)))
+			for i := range v.items {
+				var err error
+				if dst, err = t.items.encodeValue(dst, &v.items[i]); err != nil {
+					return dst, err
+				}
+			}
+		}
+		return append(dst, 0), nil
+	case t.kind == kindMap && v.tag == tagObject:
+		// entries are sorted by key as with encode, and the last value of a duplicated key is kept
+		entries := make([]int, 0, len(v.keys))
+		for i, key := range v.keys {
+			if last, _ := v.field(key); last == &v.items[i] {
+				entries = append(entries, i)
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return v.keys[entries[i]] < v.keys[entries[j]]
+		})
+		if len(entries) > 0 {
+			dst = appendLong(dst, int64(len(entries)))
+			for _, i := range entries {
+				var err error
+				dst = append(appendLong(dst, int64(len(v.keys[i]))), v.keys[i]...)
+				if dst, err = t.items.encodeValue(dst, &v.items[i]); err != nil {
+					return dst, err
+				}
+			}
+		}
+		return append(dst, 0), nil
+	}
+	i, err := v.interfaceValue()
+	if err != nil {
+		return dst, errMismatchValue(t, v)
+	}
+	return t.encode(dst, i)
+}
+
+// encodeRecordValue appends the Avro binary encoding of the record of the object v.
+func (t *schemaType) encodeRecordValue(dst []byte, v *value) ([]byte, error) {
+	for i := range t.fields {
+		field := &t.fields[i]
+		fv, ok := v.field(field.name)
+		if !ok {
+			var err error
+			if dst, err = field.encodeMissing(dst); err != nil {
+				return dst, err
+			}
+			continue
+		}
+		var err error
+		if dst, err = field.typ.encodeValue(dst, fv); err != nil {
+			return dst, fmt.Errorf("rzavro: field %q: %w", field.name, err)
+		}
+	}
+	return dst, nil
+}
+
+// nullable returns true if t is a union accepting null.
+func (t *schemaType) nullable() bool {
+	if t.kind == kindUnion {
+		for _, branch := range t.branches {
+			if branch.kind == kindNull {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// long converts v to an int or a long, parsing the times of the timestamp logical types.
+func (t *schemaType) long(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, errMismatch(t, v)
+		}
+		return i, nil
+	case string:
+		ts, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			break
+		}
+		switch t.logicalType {
+		case "timestamp-millis":
+			return ts.UnixNano() / int64(time.Millisecond), nil
+		case "timestamp-micros":
+			return ts.UnixNano() / int64(time.Microsecond), nil
+		}
+	}
+	return 0, errMismatch(t, v)
+}
+
+// errMismatchValue is like errMismatch for the values of events.
+func errMismatchValue(t *schemaType, v *value) error {
+	i, err := v.interfaceValue()
+	if err != nil {
+		return fmt.Errorf("value does not match type %s", t.typeName())
+	}
+	return errMismatch(t, i)
+}
+
+func errMismatch(t *schemaType, v interface{}) error {
+	return fmt.Errorf("value %s does not match type %s", strconv.Quote(fmt.Sprint(v)), t.typeName())
+}
+
+func (t *schemaType) typeName() string {
+	for name, k := range primitives {
+		if k == t.kind {
+			return name
+		}
+	}
+	switch t.kind {
+	case kindRecord:
+		return "record " + t.name
+	case kindEnum:
+		return "enum"
+	case kindArray:
+		return "array"
+	case kindMap:
+		return "map"
+	case kindUnion:
+		return "union"
+	case kindFixed:
+		return "fixed"
+	}
+	return "unknown"
+}
+
+// appendLong appends the zig-zag variable length encoding of i, as Avro ints and longs.
+func appendLong(dst []byte, i int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(dst, b[:binary.PutVarint(b[:], i)]...)
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,181 @@
+package rzavro
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/skerkour/rz"
+)
+
+// Codecs of the blocks of the object container file.
+const (
+	CodecNull    = "null"
+	CodecDeflate = "deflate"
+)
+
+// ErrClosed is returned by the writes of a closed Writer.
+var ErrClosed = errors.New("rzavro: writer is closed")
+
+var magic = []byte{'O', 'b', 'j', 1}
+
+// Writer is a rz.LevelWriter writing the records of a logger using an Encoder with the same schema
+// to an Avro object container file, so logs can be ingested as is by data-lake pipelines.
+// Records are buffered and written by blocks of BatchSize records, and on Flush and Close.
+// Empty events (the ones dropped by the Encoder) are ignored. It's safe for concurrent use.
+type Writer struct {
+	schema    *Schema
+	codec     string
+	batchSize int
+
+	mu         sync.Mutex
+	out        io.Writer
+	syncMarker [16]byte
+	block      []byte
+	records    int64
+	err        error
+	closed     bool
+}
+
+// WriterOption is used to configure a Writer.
+type WriterOption func(w *Writer)
+
+// BatchSize updates the number of records of the blocks. Default: 100.
+func BatchSize(size int) WriterOption {
+	return func(w *Writer) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// Deflate compresses the blocks with the deflate codec.
+func Deflate() WriterOption {
+	return func(w *Writer) {
+		w.codec = CodecDeflate
+	}
+}
+
+// NewWriter creates a Writer writing an object container file of the records of schema to out,
+// and writes its header.
+func NewWriter(out io.Writer, schema *Schema, options ...WriterOption) (*Writer, error) {
+	w := &Writer{
+		schema:    schema,
+		codec:     CodecNull,
+		batchSize: 100,
+		out:       out,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if _, err := rand.Read(w.syncMarker[:]); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(nil), magic...)
+	header = appendLong(header, 2)
+	header = appendBytes(appendBytes(header, []byte("avro.codec")), []byte(w.codec))
+	header = appendBytes(appendBytes(header, []byte("avro.schema")), schema.json)
+	header = appendLong(header, 0)
+	header = append(header, w.syncMarker[:]...)
+	if _, err := out.Write(header); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(rz.NoLevel, p)
+}
+
+// WriteLevel implements the rz.LevelWriter interface. p must be a record encoded by an Encoder.
+func (w *Writer) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.block = append(w.block, p...)
+	w.records++
+	if w.records >= int64(w.batchSize) {
+		if err := w.writeBlock(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// writeBlock writes the buffered records as a block. It must be called with the lock held.
+func (w *Writer) writeBlock() error {
+	if w.records == 0 || w.err != nil {
+		return w.err
+	}
+	data := w.block
+	if w.codec == CodecDeflate {
+		compressed := &bytes.Buffer{}
+		fw, _ := flate.NewWriter(compressed, flate.DefaultCompression)
+		fw.Write(data)
+		fw.Close()
+		data = compressed.Bytes()
+	}
+	block := appendLong(nil, w.records)
+	block = appendLong(block, int64(len(data)))
+	block = append(append(block, data...), w.syncMarker[:]...)
+	w.block = w.block[:0]
+	w.records = 0
+	// a partially written block corrupts the file: the next writes fail
+	_, w.err = w.out.Write(block)
+	return w.err
+}
+
+// Flush writes the buffered records as a block, and flushes the underlying writer if it
+// implements the rz.Flusher interface.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.writeBlock(); err != nil {
+		return err
+	}
+	if f, ok := w.out.(rz.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close writes the buffered records and closes the underlying writer if it implements
+// io.Closer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.writeBlock()
+	if c, ok := w.out.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// DescribeConfig implements the rz.ConfigDescriber interface.
+func (w *Writer) DescribeConfig() string {
+	return "avro(" + w.codec + ", " + strconv.Itoa(w.batchSize) + ")"
+}
+
+func appendBytes(dst []byte, b []byte) []byte {
+	return append(appendLong(dst, int64(len(b))), b...)
+}
@@ -0,0 +1,226 @@
+package rzavro
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+const testSchema = `{"type": "record", "name": "Event", "fields": [
+	{"name": "level", "type": {"type": "enum", "name": "Level", "symbols": ["debug", "info", "warning", "error"]}},
+	{"name": "message", "type": "string"},
+	{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+	{"name": "user_id", "type": ["null", "string"]},
+	{"name": "latency", "type": "double", "default": 0},
+	{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []}
+]}`
+
+// containerReader decodes the object container files written by the tests.
+type containerReader struct {
+	t *testing.T
+	r *bytes.Reader
+}
+
+func (c containerReader) long() int64 {
+	i, err := binary.ReadVarint(c.r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return i
+}
+
+func (c containerReader) bytes(n int64) []byte {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		c.t.Fatal(err)
+	}
+	return b
+}
+
+func (c containerReader) string() string {
+	return string(c.bytes(c.long()))
+}
+
+func (c containerReader) double() float64 {
+	var f float64
+	if err := binary.Read(c.r, binary.LittleEndian, &f); err != nil {
+		c.t.Fatal(err)
+	}
+	return f
+}
+
+// header checks the header of the file and returns its metadata and sync marker.
+func (c containerReader) header() (map[string]string, []byte) {
+	if !bytes.Equal(c.bytes(4), magic) {
+		c.t.Fatal("invalid magic")
+	}
+	meta := map[string]string{}
+	for n := c.long(); n != 0; n = c.long() {
+		for i := int64(0); i < n; i++ {
+			key := c.string()
+			meta[key] = c.string()
+		}
+	}
+	return meta, c.bytes(16)
+}
+
+// block returns the number of records and the decompressed data of the next block.
+func (c containerReader) block(codec string, sync []byte) (int64, containerReader) {
+	count, size := c.long(), c.long()
+	data := c.bytes(size)
+	if !bytes.Equal(c.bytes(16), sync) {
+		c.t.Fatal("invalid sync marker")
+	}
+	if codec == CodecDeflate {
+		var err error
+		if data, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(data))); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return count, containerReader{t: c.t, r: bytes.NewReader(data)}
+}
+
+func TestWriter(t *testing.T) {
+	for _, codec := range []string{CodecNull, CodecDeflate} {
+		t.Run(codec, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			options := []WriterOption{BatchSize(2)}
+			if codec == CodecDeflate {
+				options = append(options, Deflate())
+			}
+			schema := MustParseSchema(testSchema)
+			w, err := NewWriter(out, schema, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+			log := rz.New(rz.Writer(w), rz.UseEncoder(NewEncoder(schema)),
+				rz.TimestampFunc(func() time.Time { return ts }), rz.TimeFieldFormat(time.RFC3339Nano))
+			log.Info("hello", rz.String("user_id", "42"), rz.Float64("latency", 1.5))
+			log.Warn("world", rz.Strings("tags", []string{"a", "b"}))
+			log.Error("flushed on close")
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			c := containerReader{t: t, r: bytes.NewReader(out.Bytes())}
+			meta, sync := c.header()
+			if meta["avro.codec"] != codec || meta["avro.schema"] != MustParseSchema(testSchema).String() {
+				t.Errorf("invalid metadata: %v", meta)
+			}
+
+			count, records := c.block(codec, sync)
+			if count != 2 {
+				t.Fatalf("invalid record count: %d", count)
+			}
+			if level := records.long(); level != 1 {
+				t.Errorf("invalid level: %d", level)
+			}
+			if message := records.string(); message != "hello" {
+				t.Errorf("invalid message: %s", message)
+			}
+			if timestamp := records.long(); timestamp != ts.UnixNano()/int64(time.Millisecond) {
+				t.Errorf("invalid timestamp: %d", timestamp)
+			}
+			if branch, userID := records.long(), records.string(); branch != 1 || userID != "42" {
+				t.Errorf("invalid user_id: %d %s", branch, userID)
+			}
+			if latency := records.double(); latency != 1.5 {
+				t.Errorf("invalid latency: %v", latency)
+			}
+			if tags := records.long(); tags != 0 {
+				t.Errorf("invalid tags: %d", tags)
+			}
+
+			if level, message := records.long(), records.string(); level != 2 || message != "world" {
+				t.Errorf("invalid record: %d %s", level, message)
+			}
+			records.long()
+			if branch := records.long(); branch != 0 {
+				t.Errorf("invalid null user_id: %d", branch)
+			}
+			records.double()
+			if n, a, b, end := records.long(), records.string(), records.string(), records.long(); n != 2 || a != "a" || b != "b" || end != 0 {
+				t.Errorf("invalid tags: %d %s %s %d", n, a, b, end)
+			}
+			if records.r.Len() != 0 {
+				t.Errorf("unread block data: %d", records.r.Len())
+			}
+
+			if count, _ := c.block(codec, sync); count != 1 {
+				t.Errorf("invalid record count: %d", count)
+			}
+			if c.r.Len() != 0 {
+				t.Errorf("unread data: %d", c.r.Len())
+			}
+		})
+	}
+}
+
+func TestWriterEmptyEvent(t *testing.T) {
+	out := &bytes.Buffer{}
+	w, err := NewWriter(out, MustParseSchema(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := out.Len()
+	if _, err := w.Write(nil); err != nil {
+		t.Error(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Error(err)
+	}
+	if out.Len() != header {
+		t.Errorf("empty event is written: %v", out.Bytes()[header:])
+	}
+	w.Close()
+	if _, err := w.Write([]byte{0}); !errors.Is(err, ErrClosed) {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	invalid := []string{
+		`"string"`,
+		`{"type": "record", "fields": []}`,
+		`{"type": "record", "name": "E", "fields": [{"name": "a", "type": "unknown"}]}`,
+		`{"type": "record", "name": "E", "fields": [{"name": "a", "type": []}]}`,
+		`{"type": "record", "name": "E", "fields": [{"name": "a", "type": {"type": "fixed", "name": "F"}}]}`,
+		`{"type": "record"`,
+	}
+	for _, schema := range invalid {
+		if _, err := ParseSchema(schema); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%s: invalid error: %v", schema, err)
+		}
+	}
+
+	schema, err := ParseSchema(`{"type": "record", "name": "E", "fields": [
+		{"name": "a", "type": {"type": "record", "name": "Inner", "fields": [{"name": "x", "type": "int"}]}},
+		{"name": "b", "type": ["null", "Inner"]}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := schema.root.encodeRecord(nil, map[string]interface{}{
+		"a": map[string]interface{}{"x": jsonNumber("-1")},
+		"b": map[string]interface{}{"x": jsonNumber("2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 2, 4}; !bytes.Equal(record, want) {
+		t.Errorf("invalid record: %v, want %v", record, want)
+	}
+}
+
+func jsonNumber(s string) interface{} {
+	return json.Number(s)
+}
@@ -0,0 +1,36 @@
+// Command cbor2json converts the rz events encoded as CBOR read on stdin to JSON lines written on
+// stdout.
+//
+//    cbor2json < app.cbor | jq .
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/skerkour/rz/rzcbor"
+)
+
+func main() {
+	decoder := rzcbor.NewDecoder(os.Stdin)
+	out := bufio.NewWriter(os.Stdout)
+	var line []byte
+	var err error
+	for {
+		if line, err = decoder.Decode(line[:0]); err != nil {
+			break
+		}
+		if _, err = out.Write(line); err != nil {
+			break
+		}
+	}
+	if ferr := out.Flush(); err == io.EOF {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cbor2json: %v\n", err)
+		os.Exit(1)
+	}
+}
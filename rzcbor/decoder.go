@@ -0,0 +1,306 @@
+package rzcbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/skerkour/rz"
+)
+
+// maxDepth is the maximum nesting of the decoded arrays and maps.
+const maxDepth = 1000
+
+var (
+	// ErrInvalid is returned when decoding invalid or unsupported CBOR data.
+	ErrInvalid = errors.New("rzcbor: invalid CBOR data")
+
+	errBreak = errors.New("rzcbor: unexpected break")
+	jsonEnc  = rz.JSONEncoder{}
+)
+
+// Decoder reads CBOR encoded events from a stream and converts them to JSON, as rz's JSON encoder
+// would have written them.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next event and appends it to dst as a JSON line. It returns io.EOF when there
+// are no more events, and io.ErrUnexpectedEOF if the stream ends in the middle of an event.
+func (d *Decoder) Decode(dst []byte) ([]byte, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return dst, err
+	}
+	if b&0xe0 != majorMap {
+		return dst, fmt.Errorf("%w: event is not a map", ErrInvalid)
+	}
+	if err = d.r.UnreadByte(); err != nil {
+		return dst, err
+	}
+	dst, err = d.item(dst, 0)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, '\n'), nil
+}
+
+// ToJSON converts the CBOR encoded events of src to JSON lines appended to dst.
+func ToJSON(dst, src []byte) ([]byte, error) {
+	d := NewDecoder(bytes.NewReader(src))
+	for {
+		var err error
+		dst, err = d.Decode(dst)
+		if err == io.EOF {
+			return dst, nil
+		}
+		if err != nil {
+			return dst, err
+		}
+	}
+}
+
+// argument reads the argument of a head with additional information info. indefinite is true for
+// the indefinite length items.
+func (d *Decoder) argument(info byte) (arg uint64, indefinite bool, err error) {
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31:
+		return 0, true, nil
+	default:
+		return 0, false, fmt.Errorf("%w: reserved additional information %d", ErrInvalid, info)
+	}
+	var b [8]byte
+	if _, err = io.ReadFull(d.r, b[8-size:]); err != nil {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(b[:]), false, nil
+}
+
+// bytes reads the content of a byte or text string of major type major.
+func (d *Decoder) bytes(major byte, arg uint64, indefinite bool) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if !indefinite {
+		if _, err := io.CopyN(buf, d.r, int64(arg&math.MaxInt64)); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == breakByte {
+			return buf.Bytes(), nil
+		}
+		if b&0xe0 != major {
+			return nil, fmt.Errorf("%w: invalid chunk of indefinite length string", ErrInvalid)
+		}
+		n, chunkIndefinite, err := d.argument(b & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		if chunkIndefinite {
+			return nil, fmt.Errorf("%w: nested indefinite length string", ErrInvalid)
+		}
+		if _, err := io.CopyN(buf, d.r, int64(n&math.MaxInt64)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// item reads the next data item and appends it to dst as JSON.
+func (d *Decoder) item(dst []byte, depth int) ([]byte, error) {
+	if depth > maxDepth {
+		return dst, fmt.Errorf("%w: too deeply nested", ErrInvalid)
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return dst, err
+	}
+	major, info := b&0xe0, b&0x1f
+	if major == majorSimple {
+		return d.simple(dst, info)
+	}
+	arg, indefinite, err := d.argument(info)
+	if err != nil {
+		return dst, err
+	}
+	if indefinite && (major == majorUint || major == majorNegInt || major == majorTag) {
+		return dst, fmt.Errorf("%w: indefinite length of major type %d", ErrInvalid, major>>5)
+	}
+
+	switch major {
+	case majorUint:
+		return jsonEnc.AppendUint64(dst, arg), nil
+	case majorNegInt:
+		if arg <= math.MaxInt64 {
+			return jsonEnc.AppendInt64(dst, -1-int64(arg)), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return n.Add(n, big.NewInt(1)).Append(append(dst, '-'), 10), nil
+	case majorBytes, majorText:
+		s, err := d.bytes(major, arg, indefinite)
+		if err != nil {
+			return dst, err
+		}
+		return jsonEnc.AppendBytes(dst, s), nil
+	case majorArray:
+		dst = jsonEnc.AppendArrayStart(dst)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = d.item(dst, depth+1); err == errBreak && indefinite {
+				// remove the delimiter of the break
+				if i > 0 {
+					dst = dst[:len(dst)-1]
+				}
+				break
+			} else if err != nil {
+				return dst, err
+			}
+		}
+		return jsonEnc.AppendArrayEnd(dst), nil
+	case majorMap:
+		dst = jsonEnc.AppendBeginMarker(dst)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			key, err := d.key(indefinite)
+			if err == errBreak {
+				break
+			}
+			if err != nil {
+				return dst, err
+			}
+			dst = jsonEnc.AppendKey(dst, key)
+			if dst, err = d.item(dst, depth+1); err == errBreak {
+				return dst, fmt.Errorf("%w: map key without value", ErrInvalid)
+			} else if err != nil {
+				return dst, err
+			}
+		}
+		return jsonEnc.AppendEndMarker(dst), nil
+	}
+
+	// tags: embedded JSON is written as is, the other tagged items as their content
+	if arg != tagEmbeddedJSON {
+		return d.item(dst, depth+1)
+	}
+	b, err = d.r.ReadByte()
+	if err != nil {
+		return dst, err
+	}
+	if b&0xe0 != majorBytes {
+		return dst, fmt.Errorf("%w: embedded JSON is not a byte string", ErrInvalid)
+	}
+	n, indefinite, err := d.argument(b & 0x1f)
+	if err != nil {
+		return dst, err
+	}
+	s, err := d.bytes(majorBytes, n, indefinite)
+	if err != nil {
+		return dst, err
+	}
+	if !json.Valid(s) {
+		return jsonEnc.AppendBytes(dst, s), nil
+	}
+	compacted := &bytes.Buffer{}
+	json.Compact(compacted, s)
+	return append(dst, compacted.Bytes()...), nil
+}
+
+// key reads a text string map key.
+func (d *Decoder) key(indefinite bool) (string, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b == breakByte && indefinite {
+		return "", errBreak
+	}
+	if b&0xe0 != majorText {
+		return "", fmt.Errorf("%w: map key is not a text string", ErrInvalid)
+	}
+	n, keyIndefinite, err := d.argument(b & 0x1f)
+	if err != nil {
+		return "", err
+	}
+	s, err := d.bytes(majorText, n, keyIndefinite)
+	return string(s), err
+}
+
+// simple appends the simple value or float of additional information info.
+func (d *Decoder) simple(dst []byte, info byte) ([]byte, error) {
+	switch info {
+	case 20:
+		return jsonEnc.AppendBool(dst, false), nil
+	case 21:
+		return jsonEnc.AppendBool(dst, true), nil
+	case 22, 23:
+		return jsonEnc.AppendNil(dst), nil
+	case 31:
+		return dst, errBreak
+	}
+	var b [8]byte
+	switch info {
+	case 25:
+		if _, err := io.ReadFull(d.r, b[:2]); err != nil {
+			return dst, err
+		}
+		return jsonEnc.AppendFloat32(dst, halfFloat(binary.BigEndian.Uint16(b[:2]))), nil
+	case 26:
+		if _, err := io.ReadFull(d.r, b[:4]); err != nil {
+			return dst, err
+		}
+		return jsonEnc.AppendFloat32(dst, math.Float32frombits(binary.BigEndian.Uint32(b[:4]))), nil
+	case 27:
+		if _, err := io.ReadFull(d.r, b[:8]); err != nil {
+			return dst, err
+		}
+		return jsonEnc.AppendFloat64(dst, math.Float64frombits(binary.BigEndian.Uint64(b[:8]))), nil
+	}
+	return dst, fmt.Errorf("%w: unsupported simple value %d", ErrInvalid, info)
+}
+
+// halfFloat converts the IEEE 754 half-precision float h.
+func halfFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+	switch {
+	case exp == 0:
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+112)<<23 | mant<<13)
+}
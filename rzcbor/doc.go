@@ -0,0 +1,13 @@
+// Package rzcbor provides an encoder writing rz events as CBOR (RFC 8949) instead of JSON, to cut
+// the bytes on the wire of high-volume services, and a decoder converting them back to JSON.
+//
+//    logger := rz.New(rz.UseEncoder(rzcbor.Encoder{}), rz.Writer(conn))
+//
+//    // on the receiving side
+//    decoder := rzcbor.NewDecoder(conn)
+//    line, err := decoder.Decode(nil) // {"level":"info",...}\n
+//
+// The cbor2json command converts a CBOR stream read on stdin to JSON lines. As the encoded events
+// are not JSON, the processors, formatters and writers parsing them (redaction, queries, console
+// writer...) can't be used with this encoder.
+package rzcbor
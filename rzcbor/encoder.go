@@ -0,0 +1,394 @@
+package rzcbor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// CBOR major types.
+const (
+	majorUint   = 0 << 5
+	majorNegInt = 1 << 5
+	majorBytes  = 2 << 5
+	majorText   = 3 << 5
+	majorArray  = 4 << 5
+	majorMap    = 5 << 5
+	majorTag    = 6 << 5
+	majorSimple = 7 << 5
+)
+
+const (
+	indefiniteArray = majorArray | 31
+	indefiniteMap   = majorMap | 31
+	breakByte       = majorSimple | 31
+	falseByte       = majorSimple | 20
+	trueByte        = majorSimple | 21
+	nullByte        = majorSimple | 22
+	float32Byte     = majorSimple | 26
+	float64Byte     = majorSimple | 27
+
+	// tagEmbeddedJSON is the tag of the byte strings holding JSON encoded values (from
+	// AppendInterface and RawJSON fields).
+	tagEmbeddedJSON = 262
+)
+
+var _ rz.Encoder = Encoder{}
+
+// Encoder is a rz.Encoder encoding the events as CBOR (RFC 8949) maps. Events are self-delimited
+// and are not followed by a line break. The values are encoded as with rz's JSON encoder: times
+// and durations with the logger's format and unit, byte slices, hex, IP and MAC addresses as text
+// strings, and the values marshaled to JSON (Any, RawJSON...) as tagged byte strings.
+type Encoder struct{}
+
+// appendHead appends the head of a data item of major type major and argument n.
+func appendHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(dst, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(dst, major|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// AppendNil implements the rz.Encoder interface.
+func (Encoder) AppendNil(dst []byte) []byte {
+	return append(dst, nullByte)
+}
+
+// AppendBeginMarker implements the rz.Encoder interface.
+func (Encoder) AppendBeginMarker(dst []byte) []byte {
+	return append(dst, indefiniteMap)
+}
+
+// AppendEndMarker implements the rz.Encoder interface.
+func (Encoder) AppendEndMarker(dst []byte) []byte {
+	return append(dst, breakByte)
+}
+
+// AppendLineBreak implements the rz.Encoder interface: CBOR events are self-delimited.
+func (Encoder) AppendLineBreak(dst []byte) []byte {
+	return dst
+}
+
+// AppendObjectData implements the rz.Encoder interface.
+func (Encoder) AppendObjectData(dst []byte, o []byte) []byte {
+	if len(o) > 0 && o[0] == indefiniteMap {
+		o = o[1:]
+	}
+	return append(dst, o...)
+}
+
+// AppendArrayStart implements the rz.Encoder interface.
+func (Encoder) AppendArrayStart(dst []byte) []byte {
+	return append(dst, indefiniteArray)
+}
+
+// AppendArrayEnd implements the rz.Encoder interface.
+func (Encoder) AppendArrayEnd(dst []byte) []byte {
+	return append(dst, breakByte)
+}
+
+// AppendArrayDelim implements the rz.Encoder interface: CBOR doesn't use delimiters.
+func (Encoder) AppendArrayDelim(dst []byte) []byte {
+	return dst
+}
+
+// AppendKey implements the rz.Encoder interface.
+func (e Encoder) AppendKey(dst []byte, key string) []byte {
+	return e.AppendString(dst, key)
+}
+
+// AppendString implements the rz.Encoder interface.
+func (Encoder) AppendString(dst []byte, s string) []byte {
+	return append(appendHead(dst, majorText, uint64(len(s))), s...)
+}
+
+// AppendStrings implements the rz.Encoder interface.
+func (e Encoder) AppendStrings(dst []byte, vals []string) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendString(dst, val)
+	}
+	return dst
+}
+
+// AppendBytes implements the rz.Encoder interface.
+func (Encoder) AppendBytes(dst, s []byte) []byte {
+	return append(appendHead(dst, majorText, uint64(len(s))), s...)
+}
+
+// AppendHex implements the rz.Encoder interface.
+func (Encoder) AppendHex(dst, s []byte) []byte {
+	const hex = "0123456789abcdef"
+	dst = appendHead(dst, majorText, uint64(len(s)*2))
+	for _, v := range s {
+		dst = append(dst, hex[v>>4], hex[v&0x0f])
+	}
+	return dst
+}
+
+// AppendBool implements the rz.Encoder interface.
+func (Encoder) AppendBool(dst []byte, val bool) []byte {
+	if val {
+		return append(dst, trueByte)
+	}
+	return append(dst, falseByte)
+}
+
+// AppendBools implements the rz.Encoder interface.
+func (e Encoder) AppendBools(dst []byte, vals []bool) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendBool(dst, val)
+	}
+	return dst
+}
+
+// AppendInt64 implements the rz.Encoder interface.
+func (Encoder) AppendInt64(dst []byte, val int64) []byte {
+	if val < 0 {
+		return appendHead(dst, majorNegInt, uint64(^val))
+	}
+	return appendHead(dst, majorUint, uint64(val))
+}
+
+// AppendUint64 implements the rz.Encoder interface.
+func (Encoder) AppendUint64(dst []byte, val uint64) []byte {
+	return appendHead(dst, majorUint, val)
+}
+
+// AppendInt implements the rz.Encoder interface.
+func (e Encoder) AppendInt(dst []byte, val int) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt8 implements the rz.Encoder interface.
+func (e Encoder) AppendInt8(dst []byte, val int8) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt16 implements the rz.Encoder interface.
+func (e Encoder) AppendInt16(dst []byte, val int16) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendInt32 implements the rz.Encoder interface.
+func (e Encoder) AppendInt32(dst []byte, val int32) []byte {
+	return e.AppendInt64(dst, int64(val))
+}
+
+// AppendUint implements the rz.Encoder interface.
+func (e Encoder) AppendUint(dst []byte, val uint) []byte {
+	return e.AppendUint64(dst, uint64(val))
+}
+
+// AppendUint8 implements the rz.Encoder interface.
+func (e Encoder) AppendUint8(dst []byte, val uint8) []byte {
+	return e.AppendUint64(dst, uint64(val))
+}
+
+// AppendUint16 implements the rz.Encoder interface.
+func (e Encoder) AppendUint16(dst []byte, val uint16) []byte {
+	return e.AppendUint64(dst, uint64(val))
+}
+
+// AppendUint32 implements the rz.Encoder interface.
+func (e Encoder) AppendUint32(dst []byte, val uint32) []byte {
+	return e.AppendUint64(dst, uint64(val))
+}
+
+// AppendInts implements the rz.Encoder interface.
+func (e Encoder) AppendInts(dst []byte, vals []int) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return dst
+}
+
+// AppendInts8 implements the rz.Encoder interface.
+func (e Encoder) AppendInts8(dst []byte, vals []int8) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return dst
+}
+
+// AppendInts16 implements the rz.Encoder interface.
+func (e Encoder) AppendInts16(dst []byte, vals []int16) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return dst
+}
+
+// AppendInts32 implements the rz.Encoder interface.
+func (e Encoder) AppendInts32(dst []byte, vals []int32) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, int64(val))
+	}
+	return dst
+}
+
+// AppendInts64 implements the rz.Encoder interface.
+func (e Encoder) AppendInts64(dst []byte, vals []int64) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendInt64(dst, val)
+	}
+	return dst
+}
+
+// AppendUints implements the rz.Encoder interface.
+func (e Encoder) AppendUints(dst []byte, vals []uint) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, uint64(val))
+	}
+	return dst
+}
+
+// AppendUints8 implements the rz.Encoder interface.
+func (e Encoder) AppendUints8(dst []byte, vals []uint8) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, uint64(val))
+	}
+	return dst
+}
+
+// AppendUints16 implements the rz.Encoder interface.
+func (e Encoder) AppendUints16(dst []byte, vals []uint16) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, uint64(val))
+	}
+	return dst
+}
+
+// AppendUints32 implements the rz.Encoder interface.
+func (e Encoder) AppendUints32(dst []byte, vals []uint32) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, uint64(val))
+	}
+	return dst
+}
+
+// AppendUints64 implements the rz.Encoder interface.
+func (e Encoder) AppendUints64(dst []byte, vals []uint64) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendUint64(dst, val)
+	}
+	return dst
+}
+
+// AppendFloat32 implements the rz.Encoder interface.
+func (Encoder) AppendFloat32(dst []byte, val float32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], math.Float32bits(val))
+	return append(append(dst, float32Byte), b[:]...)
+}
+
+// AppendFloat64 implements the rz.Encoder interface.
+func (Encoder) AppendFloat64(dst []byte, val float64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(val))
+	return append(append(dst, float64Byte), b[:]...)
+}
+
+// AppendFloats32 implements the rz.Encoder interface.
+func (e Encoder) AppendFloats32(dst []byte, vals []float32) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendFloat32(dst, val)
+	}
+	return dst
+}
+
+// AppendFloats64 implements the rz.Encoder interface.
+func (e Encoder) AppendFloats64(dst []byte, vals []float64) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendFloat64(dst, val)
+	}
+	return dst
+}
+
+// AppendDuration implements the rz.Encoder interface.
+func (e Encoder) AppendDuration(dst []byte, d time.Duration, unit time.Duration, useInt bool) []byte {
+	if useInt {
+		return e.AppendInt64(dst, int64(d/unit))
+	}
+	return e.AppendFloat64(dst, float64(d)/float64(unit))
+}
+
+// AppendDurations implements the rz.Encoder interface.
+func (e Encoder) AppendDurations(dst []byte, vals []time.Duration, unit time.Duration, useInt bool) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendDuration(dst, val, unit, useInt)
+	}
+	return dst
+}
+
+// AppendTime implements the rz.Encoder interface: t is encoded as its Unix time if format is
+// empty, as a text string formatted with format otherwise.
+func (e Encoder) AppendTime(dst []byte, t time.Time, format string) []byte {
+	if format == "" {
+		return e.AppendInt64(dst, t.Unix())
+	}
+	var b [64]byte
+	return e.AppendBytes(dst, t.AppendFormat(b[:0], format))
+}
+
+// AppendTimes implements the rz.Encoder interface.
+func (e Encoder) AppendTimes(dst []byte, vals []time.Time, format string) []byte {
+	dst = appendHead(dst, majorArray, uint64(len(vals)))
+	for _, val := range vals {
+		dst = e.AppendTime(dst, val, format)
+	}
+	return dst
+}
+
+// AppendIPAddr implements the rz.Encoder interface.
+func (e Encoder) AppendIPAddr(dst []byte, ip net.IP) []byte {
+	return e.AppendString(dst, ip.String())
+}
+
+// AppendIPPrefix implements the rz.Encoder interface.
+func (e Encoder) AppendIPPrefix(dst []byte, pfx net.IPNet) []byte {
+	return e.AppendString(dst, pfx.String())
+}
+
+// AppendMACAddr implements the rz.Encoder interface.
+func (e Encoder) AppendMACAddr(dst []byte, ha net.HardwareAddr) []byte {
+	return e.AppendString(dst, ha.String())
+}
+
+// AppendInterface implements the rz.Encoder interface: i is marshaled to JSON, in a byte string
+// tagged as embedded JSON.
+func (e Encoder) AppendInterface(dst []byte, i interface{}) []byte {
+	marshaled, err := json.Marshal(i)
+	if err != nil {
+		return e.AppendString(dst, fmt.Sprintf("marshaling error: %v", err))
+	}
+	dst = appendHead(dst, majorTag, tagEmbeddedJSON)
+	return append(appendHead(dst, majorBytes, uint64(len(marshaled))), marshaled...)
+}
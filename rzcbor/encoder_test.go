@@ -0,0 +1,113 @@
+package rzcbor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+func testFields(log rz.Logger) []rz.Field {
+	ts := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return []rz.Field{
+		rz.String("string", "héllo \"world\"\n"),
+		rz.Strings("strings", []string{"a", ""}),
+		rz.Bool("bool", true),
+		rz.Bools("bools", []bool{false, true}),
+		rz.Int("int", -42),
+		rz.Ints("ints", []int{1, -1, 1 << 40}),
+		rz.Int64("min", math.MinInt64),
+		rz.Uint64("max", math.MaxUint64),
+		rz.Uints8("uints8", []uint8{0, 255}),
+		rz.Float32("float32", 1.5),
+		rz.Float64("float64", 3.14159),
+		rz.Floats64("floats64", []float64{math.NaN(), math.Inf(1), -0.5}),
+		rz.Duration("duration", 1500*time.Millisecond),
+		rz.Durations("durations", []time.Duration{time.Second}),
+		rz.Time("time", ts),
+		rz.Times("times", []time.Time{ts}),
+		rz.Bytes("bytes", []byte("raw bytes")),
+		rz.Hex("hex", []byte{0xde, 0xad}),
+		rz.IP("ip", net.IPv4(10, 0, 0, 1)),
+		rz.HardwareAddr("mac", net.HardwareAddr{1, 2, 3, 4, 5, 6}),
+		rz.Error("error", errors.New("failed")),
+		rz.Any("any", map[string]interface{}{"a": []int{1, 2}}),
+		rz.RawJSON("raw", []byte(`{"b":true}`)),
+		rz.Dict("dict", log.NewDict(rz.String("nested", "value"), rz.Ints("empty", nil))),
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, format := range []string{time.RFC3339, "", rz.TimeFormatUnixMs} {
+		options := []rz.LoggerOption{
+			rz.TimestampFunc(func() time.Time { return ts }),
+			rz.TimeFieldFormat(format),
+		}
+		jsonOut, cborOut := &bytes.Buffer{}, &bytes.Buffer{}
+		jsonLog := rz.New(append(options, rz.Writer(jsonOut), rz.Fields(rz.String("service", "api")))...)
+		cborLog := rz.New(append(options, rz.Writer(cborOut), rz.UseEncoder(Encoder{}), rz.Fields(rz.String("service", "api")))...)
+		jsonLog.Info("hello", testFields(jsonLog)...)
+		cborLog.Info("hello", testFields(cborLog)...)
+		jsonLog.Warn("second")
+		cborLog.Warn("second")
+
+		if cborOut.Len() >= jsonOut.Len() {
+			t.Errorf("CBOR output (%d bytes) is not smaller than JSON (%d bytes)", cborOut.Len(), jsonOut.Len())
+		}
+		decoded, err := ToJSON(nil, cborOut.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(decoded) != jsonOut.String() {
+			t.Errorf("invalid decoded events with format %q:\ngot:  %s\nwant: %s", format, decoded, jsonOut.String())
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	encoder := Encoder{}
+	event := encoder.AppendBeginMarker(nil)
+	event = encoder.AppendString(encoder.AppendKey(event, "message"), "hello")
+	event = encoder.AppendEndMarker(event)
+
+	d := NewDecoder(bytes.NewReader(append(event, event[:len(event)-3]...)))
+	line, err := d.Decode(nil)
+	if err != nil || string(line) != `{"message":"hello"}`+"\n" {
+		t.Errorf("invalid event: %q, %v", line, err)
+	}
+	if _, err := d.Decode(nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("invalid error for a truncated event: %v", err)
+	}
+
+	tests := []struct {
+		cbor []byte
+		json string
+	}{
+		// definite length map and array, half float, undefined, tags
+		{[]byte{0xa2, 0x61, 'a', 0x82, 0xf9, 0x3c, 0x00, 0xf7, 0x61, 'b', 0xc1, 0x1a, 0x5e, 0x0d, 0x5e, 0x65}, `{"a":[1,null],"b":1577934437}`},
+		// indefinite length text string
+		{[]byte{0xbf, 0x61, 'k', 0x7f, 0x62, 'a', 'b', 0x61, 'c', 0xff, 0xff}, `{"k":"abc"}`},
+		// smallest negative integer
+		{[]byte{0xa1, 0x61, 'n', 0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, `{"n":-18446744073709551616}`},
+	}
+	for _, test := range tests {
+		got, err := ToJSON(nil, test.cbor)
+		if err != nil {
+			t.Errorf("%x: %v", test.cbor, err)
+		} else if string(got) != test.json+"\n" {
+			t.Errorf("%x: got %s, want %s", test.cbor, got, test.json)
+		}
+	}
+
+	for _, invalid := range [][]byte{{0x01}, {0xa1, 0x01, 0x01}, {0xbf, 0x61, 'k', 0xff}} {
+		if _, err := ToJSON(nil, invalid); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%x: invalid error: %v", invalid, err)
+		}
+	}
+}
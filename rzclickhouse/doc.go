@@ -0,0 +1,15 @@
+// Package rzclickhouse provides a writer batching rz events and inserting them into a ClickHouse
+// table over the HTTP interface, mapping the standard fields to columns and the other fields to
+// a Map(String, String) or JSON column.
+//
+//    CREATE TABLE logs (
+//        timestamp DateTime64(3),
+//        level LowCardinality(String),
+//        message String,
+//        fields Map(String, String)
+//    ) ENGINE = MergeTree ORDER BY timestamp
+//
+//    w := rzclickhouse.NewWriter("http://localhost:8123", "logs")
+//    logger := rz.New(rz.Writer(w))
+//    defer w.Close()
+package rzclickhouse
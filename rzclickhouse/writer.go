@@ -0,0 +1,389 @@
+package rzclickhouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// ExtraFormat is the type of the column holding the fields not mapped to a column.
+type ExtraFormat uint8
+
+const (
+	// ExtraMap stores the extra fields in a Map(String, String) column. Strings are stored as is,
+	// other values as JSON.
+	ExtraMap ExtraFormat = iota
+	// ExtraJSON stores the extra fields as a JSON object, in a String or JSON column.
+	ExtraJSON
+)
+
+// ErrClosed is returned by the writes of a closed Writer.
+var ErrClosed = errors.New("rzclickhouse: writer is closed")
+
+// Writer is a rz.LevelWriter batching events and inserting them into a ClickHouse table with the
+// JSONEachRow format over the HTTP interface. It's safe for concurrent use.
+//
+// A batch is inserted when it reaches BatchSize events, every FlushInterval, and on Flush and
+// Close. The write adding the last event of a batch returns the error of the insert; errors of the
+// periodic inserts are passed to rz.ErrorHandler. Events of a failed insert are dropped.
+type Writer struct {
+	endpoint      string
+	table         string
+	database      string
+	user          string
+	password      string
+	client        *http.Client
+	columns       map[string]string
+	extraColumn   string
+	extraFormat   ExtraFormat
+	idField       string
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	batch  bytes.Buffer
+	rows   int
+	ids    []string // event IDs of the batch
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// WriterOption is used to configure a Writer.
+type WriterOption func(w *Writer)
+
+// Database updates the database of the table. Default: the default database of the user.
+func Database(database string) WriterOption {
+	return func(w *Writer) {
+		w.database = database
+	}
+}
+
+// Credentials updates the user and password used to authenticate the inserts.
+func Credentials(user, password string) WriterOption {
+	return func(w *Writer) {
+		w.user = user
+		w.password = password
+	}
+}
+
+// HTTPClient updates the client used to send the inserts. Default: a client with a 10 seconds
+// timeout. Its Transport can be any http.RoundTripper, e.g. an HTTP/3 one for lossy links:
+//
+//    rzclickhouse.HTTPClient(&http.Client{Transport: &http3.RoundTripper{}}) // github.com/quic-go/quic-go/http3
+//
+// As inserts are not idempotent, they're never sent as 0-RTT early data, even if the transport
+// supports it.
+func HTTPClient(client *http.Client) WriterOption {
+	return func(w *Writer) {
+		w.client = client
+	}
+}
+
+// Column maps the event field to column. The timestamp, level and message fields (with rz's
+// default names) are mapped to the columns of the same name by default; an empty column removes
+// the mapping of field.
+func Column(field, column string) WriterOption {
+	return func(w *Writer) {
+		if column == "" {
+			delete(w.columns, field)
+		} else {
+			w.columns[field] = column
+		}
+	}
+}
+
+// Extra updates the column holding the fields not mapped to a column, and its format.
+// Default: "fields", with ExtraMap. An empty column drops the extra fields.
+func Extra(column string, format ExtraFormat) WriterOption {
+	return func(w *Writer) {
+		w.extraColumn = column
+		w.extraFormat = format
+	}
+}
+
+// EventID maps the event IDs stamped by rz.EventIDs in field to column, and makes the inserts
+// idempotent: each insert sends a deduplication token derived from the IDs of its events, so a
+// batch inserted again after a failure is ignored by the tables supporting deduplication
+// (Replicated*MergeTree, or MergeTree with non_replicated_deduplication_window). Events replayed in
+// other batches can be deduplicated by a ReplacingMergeTree table ordered by column.
+func EventID(field, column string) WriterOption {
+	return func(w *Writer) {
+		w.idField = field
+		w.columns[field] = column
+	}
+}
+
+// BatchSize updates the maximum number of events of an insert. Default: 1000.
+func BatchSize(size int) WriterOption {
+	return func(w *Writer) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// FlushInterval updates the interval of the periodic inserts. Default: 5 seconds. A zero
+// interval disables them.
+func FlushInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.flushInterval = interval
+	}
+}
+
+// NewWriter creates a Writer inserting events into table, through the HTTP interface of the
+// ClickHouse server at endpoint (e.g. "http://localhost:8123").
+func NewWriter(endpoint, table string, options ...WriterOption) *Writer {
+	w := &Writer{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		table:    table,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		columns: map[string]string{
+			rz.DefaultTimestampFieldName: "timestamp",
+			rz.DefaultLevelFieldName:     "level",
+			rz.DefaultMessageFieldName:   "message",
+		},
+		extraColumn:   "fields",
+		extraFormat:   ExtraMap,
+		batchSize:     1000,
+		flushInterval: 5 * time.Second,
+		done:          make(chan struct{}),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.flushInterval > 0 {
+		w.wg.Add(1)
+		go w.run()
+	}
+	return w
+}
+
+func (w *Writer) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				handleError(err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(rz.NoLevel, p)
+}
+
+// WriteLevel implements the rz.LevelWriter interface.
+func (w *Writer) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	row, err := w.row(p)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	w.batch.Write(row)
+	w.batch.WriteByte('\n')
+	w.rows++
+	if w.idField != "" {
+		if id, ok := rz.EventID(p, w.idField); ok {
+			w.ids = append(w.ids, id)
+		}
+	}
+	if w.rows >= w.batchSize {
+		if err := w.insert(context.Background()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// row converts the JSON encoded event p to a JSONEachRow row.
+func (w *Writer) row(p []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	event := map[string]interface{}{}
+	if err := decoder.Decode(&event); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]interface{}, len(w.columns)+1)
+	extra := map[string]interface{}{}
+	for field, value := range event {
+		if column, ok := w.columns[field]; ok {
+			row[column] = value
+		} else {
+			extra[field] = value
+		}
+	}
+	if w.extraColumn != "" {
+		if w.extraFormat == ExtraJSON {
+			encoded, err := json.Marshal(extra)
+			if err != nil {
+				return nil, err
+			}
+			row[w.extraColumn] = string(encoded)
+		} else {
+			values := make(map[string]string, len(extra))
+			for field, value := range extra {
+				if s, ok := value.(string); ok {
+					values[field] = s
+					continue
+				}
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return nil, err
+				}
+				values[field] = string(encoded)
+			}
+			row[w.extraColumn] = values
+		}
+	}
+	return json.Marshal(row)
+}
+
+// insert sends the batch to the server. It must be called with the lock held.
+func (w *Writer) insert(ctx context.Context) error {
+	if w.rows == 0 {
+		return nil
+	}
+	body := append([]byte(nil), w.batch.Bytes()...)
+	token := deduplicationToken(w.ids)
+	w.batch.Reset()
+	w.rows = 0
+	w.ids = w.ids[:0]
+
+	table := w.table
+	if w.database != "" {
+		table = w.database + "." + table
+	}
+	query := url.Values{}
+	query.Set("query", "INSERT INTO "+table+" FORMAT JSONEachRow")
+	query.Set("date_time_input_format", "best_effort")
+	if token != "" {
+		query.Set("insert_deduplication_token", token)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint+"/?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.user != "" {
+		req.Header.Set("X-ClickHouse-User", w.user)
+		req.Header.Set("X-ClickHouse-Key", w.password)
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("rzclickhouse: insert failed: %s: %s", res.Status, bytes.TrimSpace(message))
+	}
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// deduplicationToken returns the hex encoded SHA-256 of the event IDs of a batch, or "" if none.
+func deduplicationToken(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write([]byte(id))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Flush implements the rz.Flusher interface: it inserts the batched events.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.insert(context.Background())
+}
+
+// Close inserts the batched events and stops the periodic inserts.
+func (w *Writer) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext implements the rz.ContextCloser interface: the final insert is bounded by ctx.
+func (w *Writer) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	err := w.insert(ctx)
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+// CheckHealth implements the rz.HealthChecker interface: it pings the server.
+func (w *Writer) CheckHealth() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.endpoint+"/ping", nil)
+	if err != nil {
+		return err
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("rzclickhouse: ping failed: %s", res.Status)
+	}
+	return nil
+}
+
+// DescribeConfig implements the rz.ConfigDescriber interface.
+func (w *Writer) DescribeConfig() string {
+	columns := make([]string, 0, len(w.columns))
+	for field, column := range w.columns {
+		columns = append(columns, field+"="+column)
+	}
+	sort.Strings(columns)
+	return "clickhouse(" + strconv.Quote(w.table) + ", " + strings.Join(columns, ",") + ")"
+}
+
+func handleError(err error) {
+	if rz.ErrorHandler != nil {
+		rz.ErrorHandler(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "rzclickhouse: could not insert events: %v\n", err)
+	}
+}
@@ -0,0 +1,168 @@
+package rzclickhouse
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+type testServer struct {
+	mu      sync.Mutex
+	queries []string
+	bodies  []string
+	users   []string
+	tokens  []string
+	status  int
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, r.URL.Query().Get("query"))
+	s.bodies = append(s.bodies, string(body))
+	s.users = append(s.users, r.Header.Get("X-ClickHouse-User"))
+	s.tokens = append(s.tokens, r.URL.Query().Get("insert_deduplication_token"))
+	if s.status != 0 {
+		http.Error(w, "Code: 60. DB::Exception: Table default.logs doesn't exist", s.status)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	handler := &testServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, "logs", Database("app"), Credentials("rz", "secret"), BatchSize(2),
+		FlushInterval(0), Column("user_id", "user_id"))
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	log := rz.New(rz.Writer(w), rz.TimestampFunc(func() time.Time { return ts }))
+	log.Info("hello", rz.String("user_id", "42"), rz.Int("status", 200), rz.String("path", "/"))
+	log.Warn("world")
+	log.Error("on close")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`{"fields":{"path":"/","status":"200"},"level":"info","message":"hello","timestamp":"2020-01-02T03:04:05Z","user_id":"42"}` + "\n" +
+			`{"fields":{},"level":"warning","message":"world","timestamp":"2020-01-02T03:04:05Z"}` + "\n",
+		`{"fields":{},"level":"error","message":"on close","timestamp":"2020-01-02T03:04:05Z"}` + "\n",
+	}
+	if len(handler.bodies) != len(want) {
+		t.Fatalf("invalid inserts: %v", handler.bodies)
+	}
+	for i := range want {
+		if handler.bodies[i] != want[i] {
+			t.Errorf("invalid insert %d:\ngot:  %v\nwant: %v", i, handler.bodies[i], want[i])
+		}
+		if got := handler.queries[i]; got != "INSERT INTO app.logs FORMAT JSONEachRow" {
+			t.Errorf("invalid query: %s", got)
+		}
+		if handler.users[i] != "rz" {
+			t.Errorf("invalid user: %s", handler.users[i])
+		}
+	}
+
+	if _, err := w.Write([]byte(`{}`)); err != ErrClosed {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestWriterExtraJSON(t *testing.T) {
+	handler := &testServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, "logs", Extra("extra", ExtraJSON), Column("level", ""), FlushInterval(10*time.Millisecond))
+	defer w.Close()
+	if _, err := w.Write([]byte(`{"level":"info","message":"hello","n":1.5,"tags":["a"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		handler.mu.Lock()
+		inserts := len(handler.bodies)
+		handler.mu.Unlock()
+		if inserts > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	want := `{"extra":"{\"level\":\"info\",\"n\":1.5,\"tags\":[\"a\"]}","message":"hello"}` + "\n"
+	if len(handler.bodies) != 1 || handler.bodies[0] != want {
+		t.Errorf("invalid inserts:\ngot:  %v\nwant: %v", handler.bodies, want)
+	}
+}
+
+func TestWriterEventID(t *testing.T) {
+	handler := &testServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, "logs", FlushInterval(0), Extra("", ExtraMap), EventID("id", "event_id"))
+	defer w.Close()
+	// the second batch replays the first one
+	for _, batch := range [][]string{{"01ARYZ6S410000000000000000", "01ARYZ6S410000000000000001"},
+		{"01ARYZ6S410000000000000000", "01ARYZ6S410000000000000001"}, {"01ARYZ6S410000000000000002"}, {""}} {
+		for _, id := range batch {
+			if id == "" {
+				w.Write([]byte(`{"message":"no id"}`))
+			} else {
+				w.Write([]byte(`{"id":"` + id + `","message":"hello"}`))
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !strings.HasPrefix(handler.bodies[0], `{"event_id":"01ARYZ6S410000000000000000","message":"hello"}`) {
+		t.Errorf("event ID not inserted: %s", handler.bodies[0])
+	}
+	tokens := handler.tokens
+	if len(tokens) != 4 || len(tokens[0]) != 64 || tokens[1] != tokens[0] || tokens[2] == tokens[0] || tokens[3] != "" {
+		t.Errorf("invalid deduplication tokens: %q", tokens)
+	}
+}
+
+func TestWriterInsertError(t *testing.T) {
+	handler := &testServer{status: http.StatusNotFound}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, "logs", FlushInterval(0))
+	defer w.Close()
+	w.Write([]byte(`{"message":"hello"}`))
+	err := w.Flush()
+	if err == nil || !strings.Contains(err.Error(), "doesn't exist") {
+		t.Errorf("invalid error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("failed batch is not dropped: %v", err)
+	}
+}
+
+func TestWriterCheckHealth(t *testing.T) {
+	handler := &testServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, "logs", FlushInterval(0))
+	defer w.Close()
+	if err := w.CheckHealth(); err != nil {
+		t.Error(err)
+	}
+	handler.status = http.StatusServiceUnavailable
+	if err := w.CheckHealth(); err == nil {
+		t.Error("unavailable server is healthy")
+	}
+}
@@ -0,0 +1,12 @@
+// Package rzgelf provides a writer sending rz events to Graylog as GELF 1.1 messages, over UDP
+// (chunked, optionally compressed) or TCP, optionally with TLS.
+//
+//    w, err := rzgelf.NewWriter("udp", "graylog:12201")
+//    logger := rz.New(rz.Writer(w))
+//    defer w.Close()
+//
+// The level, message and timestamp fields of the events are mapped to the GELF level (syslog
+// severity), short_message and timestamp fields, the other fields to additional fields, prefixed
+// with "_". Loggers with other field names or time format than rz's defaults must pass them
+// with the FieldNames and TimeFieldFormat options.
+package rzgelf
@@ -0,0 +1,355 @@
+package rzgelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+const (
+	// DefaultChunkSize is the default maximum size of the UDP datagrams, safe on most networks.
+	DefaultChunkSize = 1420
+
+	chunkHeaderSize = 12
+	maxChunks       = 128
+)
+
+var (
+	// ErrTooLarge is returned by the writes of events too large for 128 UDP chunks.
+	ErrTooLarge = errors.New("rzgelf: message too large")
+	// ErrClosed is returned by the writes of a closed Writer.
+	ErrClosed = errors.New("rzgelf: writer is closed")
+
+	chunkMagic = []byte{0x1e, 0x0f}
+)
+
+// Writer is a rz.LevelWriter sending events as GELF 1.1 messages to a Graylog input. It's safe
+// for concurrent use.
+//
+// Over UDP, messages larger than the chunk size are split in chunks. Over TCP, messages are
+// delimited by a null byte, and the connection is reopened by the write following an error.
+type Writer struct {
+	network        string
+	addr           string
+	host           string
+	tlsConfig      *tls.Config
+	compress       bool
+	chunkSize      int
+	timeout        time.Duration
+	messageField   string
+	timestampField string
+	levelField     string
+	timeFormat     string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// WriterOption is used to configure a Writer.
+type WriterOption func(w *Writer)
+
+// Host updates the host field of the messages. Default: os.Hostname().
+func Host(host string) WriterOption {
+	return func(w *Writer) {
+		w.host = host
+	}
+}
+
+// TLS enables TLS with config, for the TCP network.
+func TLS(config *tls.Config) WriterOption {
+	return func(w *Writer) {
+		w.tlsConfig = config
+	}
+}
+
+// Compress compresses the UDP messages with gzip.
+func Compress() WriterOption {
+	return func(w *Writer) {
+		w.compress = true
+	}
+}
+
+// ChunkSize updates the maximum size of the UDP datagrams. Default: DefaultChunkSize.
+func ChunkSize(size int) WriterOption {
+	return func(w *Writer) {
+		if size > chunkHeaderSize {
+			w.chunkSize = size
+		}
+	}
+}
+
+// Timeout updates the timeout of the connections and the writes. Default: 10 seconds.
+func Timeout(timeout time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.timeout = timeout
+	}
+}
+
+// FieldNames updates the names of the message, timestamp and level fields of the events, which
+// should be the ones of the logger. Default: rz's default names.
+func FieldNames(messageField, timestampField, levelField string) WriterOption {
+	return func(w *Writer) {
+		w.messageField = messageField
+		w.timestampField = timestampField
+		w.levelField = levelField
+	}
+}
+
+// TimeFieldFormat updates the time field format of the events, which should be the one of the
+// logger, so their timestamps are decoded with the right layout or UNIX time resolution.
+// Default: rz.DefaultTimeFieldFormat.
+func TimeFieldFormat(format string) WriterOption {
+	return func(w *Writer) {
+		w.timeFormat = format
+	}
+}
+
+// NewWriter creates a Writer sending messages to the Graylog input at addr, on network "udp"
+// or "tcp", and opens its connection.
+func NewWriter(network, addr string, options ...WriterOption) (*Writer, error) {
+	hostname, _ := os.Hostname()
+	w := &Writer{
+		network:        network,
+		addr:           addr,
+		host:           hostname,
+		chunkSize:      DefaultChunkSize,
+		timeout:        10 * time.Second,
+		messageField:   rz.DefaultMessageFieldName,
+		timestampField: rz.DefaultTimestampFieldName,
+		levelField:     rz.DefaultLevelFieldName,
+		timeFormat:     rz.DefaultTimeFieldFormat,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// dial opens the connection. w.mu must be held.
+func (w *Writer) dial() (err error) {
+	dialer := &net.Dialer{Timeout: w.timeout}
+	if w.tlsConfig != nil && !w.isUDP() {
+		w.conn, err = tls.DialWithDialer(dialer, w.network, w.addr, w.tlsConfig)
+	} else {
+		w.conn, err = dialer.Dial(w.network, w.addr)
+	}
+	return err
+}
+
+func (w *Writer) isUDP() bool {
+	return strings.HasPrefix(w.network, "udp")
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(rz.NoLevel, p)
+}
+
+// WriteLevel implements the rz.LevelWriter interface.
+func (w *Writer) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	message, err := w.message(level, p)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.conn == nil {
+		if err = w.dial(); err != nil {
+			return 0, err
+		}
+	}
+	if w.timeout > 0 {
+		w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	}
+	if w.isUDP() {
+		err = w.sendUDP(message)
+	} else if _, err = w.conn.Write(append(message, 0)); err != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// message converts the JSON encoded event p to a GELF message.
+func (w *Writer) message(level rz.LogLevel, p []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	event := map[string]interface{}{}
+	if err := decoder.Decode(&event); err != nil {
+		return nil, err
+	}
+
+	message := make(map[string]interface{}, len(event)+4)
+	message["version"] = "1.1"
+	message["host"] = w.host
+	message["level"] = rz.SyslogSeverity(level)
+	message["short_message"] = "-"
+	message["timestamp"] = timestamp(event[w.timestampField], w.timeFormat)
+	for field, value := range event {
+		switch field {
+		case w.levelField, w.timestampField:
+			continue
+		case w.messageField:
+			if s, ok := value.(string); ok && s != "" {
+				message["short_message"] = s
+				continue
+			}
+		}
+		switch value.(type) {
+		case string, json.Number:
+		default:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			value = string(encoded)
+		}
+		message[additionalField(field)] = value
+	}
+	return json.Marshal(message)
+}
+
+// unitsPerSecond are the resolutions of the integer UNIX time formats other than seconds.
+var unitsPerSecond = map[string]int64{
+	rz.TimeFormatUnixMs:    1e3,
+	rz.TimeFormatUnixMicro: 1e6,
+	rz.TimeFormatUnixNano:  1e9,
+}
+
+// timestamp returns the GELF timestamp (seconds since the UNIX epoch) of the timestamp field value
+// encoded with the time format, or of the current time if it's missing or invalid.
+func timestamp(value interface{}, format string) json.Number {
+	t := time.Now()
+	switch value := value.(type) {
+	case string:
+		if parsed, err := time.Parse(format, value); err == nil {
+			t = parsed
+		} else if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			t = parsed
+		}
+	case json.Number:
+		// numbers are in seconds when the time field format is empty, unix or unix_decimal
+		perSecond, ok := unitsPerSecond[format]
+		if !ok {
+			return value
+		}
+		units, err := value.Int64()
+		if err != nil {
+			return value
+		}
+		t = time.Unix(units/perSecond, units%perSecond*(int64(time.Second)/perSecond))
+	}
+	return json.Number(strconv.FormatFloat(float64(t.UnixNano()/1e6)/1e3, 'f', -1, 64))
+}
+
+// additionalField returns the name of the GELF additional field of field: "_" followed by field,
+// with the characters other than letters, digits, "_", "." and "-" replaced by "_". As "_id" is
+// reserved, the id field becomes "__id".
+func additionalField(field string) string {
+	name := []byte("_" + field)
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			name[i] = '_'
+		}
+	}
+	if string(name) == "_id" {
+		return "__id"
+	}
+	return string(name)
+}
+
+// sendUDP sends message as a datagram, or as chunks if it's too large. w.mu must be held.
+func (w *Writer) sendUDP(message []byte) error {
+	if w.compress {
+		compressed := &bytes.Buffer{}
+		gz := gzip.NewWriter(compressed)
+		gz.Write(message)
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		message = compressed.Bytes()
+	}
+	if len(message) <= w.chunkSize {
+		_, err := w.conn.Write(message)
+		return err
+	}
+
+	dataSize := w.chunkSize - chunkHeaderSize
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > maxChunks {
+		return ErrTooLarge
+	}
+	chunk := make([]byte, 0, w.chunkSize)
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		end := (i + 1) * dataSize
+		if end > len(message) {
+			end = len(message)
+		}
+		chunk = append(append(chunk[:0], chunkMagic...), id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, message[i*dataSize:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+// CloseContext implements the rz.ContextCloser interface.
+func (w *Writer) CloseContext(ctx context.Context) error {
+	return w.Close()
+}
+
+// DescribeConfig implements the rz.ConfigDescriber interface.
+func (w *Writer) DescribeConfig() string {
+	config := "gelf(" + w.network + "://" + w.addr
+	if w.tlsConfig != nil && !w.isUDP() {
+		config += ", tls"
+	}
+	if w.compress && w.isUDP() {
+		config += ", gzip"
+	}
+	return config + ")"
+}
@@ -0,0 +1,169 @@
+package rzgelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+var testTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := NewWriter("udp", conn.LocalAddr().String(), Host("web-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := rz.New(rz.Writer(w), rz.TimestampFunc(func() time.Time { return testTime }))
+	log.Error("failed", rz.String("id", "42"), rz.Int("status", 500), rz.Bool("retry", true),
+		rz.String("user name", "john"))
+
+	buf := make([]byte, 65536)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"__id":"42","_retry":"true","_status":500,"_user_name":"john","host":"web-1","level":3,` +
+		`"short_message":"failed","timestamp":1577934245,"version":"1.1"}`
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		format string
+		want   json.Number
+	}{
+		{json.Number("1577934245"), "", "1577934245"},
+		{json.Number("1577934245"), rz.TimeFormatUnix, "1577934245"},
+		{json.Number("1577934245.250000000"), rz.TimeFormatUnixDecimal, "1577934245.250000000"},
+		{json.Number("1577934245250"), rz.TimeFormatUnixMs, "1577934245.25"},
+		{json.Number("1577934245250000"), rz.TimeFormatUnixMicro, "1577934245.25"},
+		{json.Number("1577934245250000000"), rz.TimeFormatUnixNano, "1577934245.25"},
+		{"2020-01-02T03:04:05.25Z", time.RFC3339, "1577934245.25"},
+		{"02/01/2020 03:04:05", "02/01/2006 15:04:05", "1577934245"},
+	}
+	for _, tt := range tests {
+		if got := timestamp(tt.value, tt.format); got != tt.want {
+			t.Errorf("timestamp(%v, %q) = %s, want %s", tt.value, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestWriterUDPChunks(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := NewWriter("udp", conn.LocalAddr().String(), ChunkSize(100), Compress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := rz.New(rz.Writer(w))
+	// random data doesn't compress, so the message is split in several chunks
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i*7919%93 + 33)
+	}
+	log.Info(string(data))
+
+	compressed := []byte{}
+	count := -1
+	for i := 0; i != count; i++ {
+		buf := make([]byte, 200)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk := buf[:n]
+		if n > 100 || !bytes.Equal(chunk[:2], chunkMagic) || int(chunk[10]) != i {
+			t.Fatalf("invalid chunk %d: %v", i, chunk)
+		}
+		count = int(chunk[11])
+		compressed = append(compressed, chunk[12:]...)
+	}
+	if count < 2 {
+		t.Errorf("the message must be chunked, got %d chunks", count)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, _ := io.ReadAll(gz)
+	decoded := map[string]interface{}{}
+	if err = json.Unmarshal(message, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["short_message"] != string(data) {
+		t.Errorf("invalid message: %s", message)
+	}
+}
+
+func TestWriterTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	messages := make(chan string)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			message, err := r.ReadString(0)
+			if err != nil {
+				close(messages)
+				return
+			}
+			messages <- message
+		}
+	}()
+
+	w, err := NewWriter("tcp", listener.Addr().String(), Host("web-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := rz.New(rz.Writer(w), rz.TimestampFunc(func() time.Time { return testTime }))
+	log.Info("first")
+	log.Log("")
+	w.Close()
+
+	want := []string{
+		`{"host":"web-1","level":6,"short_message":"first","timestamp":1577934245,"version":"1.1"}` + "\x00",
+		`{"host":"web-1","level":5,"short_message":"-","timestamp":1577934245,"version":"1.1"}` + "\x00",
+	}
+	got := []string{}
+	for message := range messages {
+		got = append(got, message)
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := w.DescribeConfig(), "gelf(tcp://"+listener.Addr().String()+")"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
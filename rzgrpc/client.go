@@ -0,0 +1,112 @@
+package rzgrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryClientInterceptor returns a unary client interceptor logging the calls, with the logger of
+// the context (see rz.FromCtx) if any, or logger.
+func UnaryClientInterceptor(logger rz.Logger, options ...Option) grpc.UnaryClientInterceptor {
+	i := newInterceptor(logger, "grpc client call", options)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		callLogger := i.clientLogger(ctx, method)
+		p := &peer.Peer{}
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(p))...)
+
+		var fields []rz.Field
+		if i.logsPayloads(method) {
+			fields = i.appendPayload(fields, i.requestField, req)
+			if err == nil {
+				fields = i.appendPayload(fields, i.responseField, reply)
+			}
+		}
+		i.logCall(callLogger, start, p, err, fields)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a stream client interceptor logging the calls when the stream
+// ends, i.e. when RecvMsg returns an error (io.EOF for a successful call), and each sent and
+// received message at debug level if payloads are enabled for the method. Streams which are not
+// received until the end are not logged.
+func StreamClientInterceptor(logger rz.Logger, options ...Option) grpc.StreamClientInterceptor {
+	i := newInterceptor(logger, "grpc client call", options)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		callLogger := i.clientLogger(ctx, method)
+		p := &peer.Peer{}
+		stream, err := streamer(ctx, desc, cc, method, append(opts, grpc.Peer(p))...)
+		if err != nil {
+			i.logCall(callLogger, start, p, err, nil)
+			return nil, err
+		}
+		return &clientStream{
+			ClientStream: stream,
+			i:            i,
+			logger:       callLogger,
+			payloads:     i.logsPayloads(method),
+			start:        start,
+			peer:         p,
+		}, nil
+	}
+}
+
+// clientLogger returns the logger of a client call, with the method field.
+func (i *interceptor) clientLogger(ctx context.Context, method string) *rz.Logger {
+	logger := i.logger
+	if ctxLogger, ok := rz.LoggerFromCtx(ctx); ok {
+		logger = *ctxLogger
+	}
+	logger = logger.ForCtx(ctx)
+	if i.methodField != "" {
+		logger = logger.With(rz.Fields(rz.String(i.methodField, method)))
+	}
+	return &logger
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	i        *interceptor
+	logger   *rz.Logger
+	payloads bool
+	start    time.Time
+	peer     *peer.Peer
+	once     sync.Once
+}
+
+// SendMsg logs the sent messages.
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil && s.payloads {
+		s.logger.Debug("grpc message sent", s.i.appendPayload(nil, s.i.requestField, m)...)
+	}
+	return err
+}
+
+// RecvMsg logs the received messages, and the call when the stream ends.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		if s.payloads {
+			s.logger.Debug("grpc message received", s.i.appendPayload(nil, s.i.responseField, m)...)
+		}
+		return nil
+	}
+	s.once.Do(func() {
+		callErr := err
+		if errors.Is(err, io.EOF) {
+			callErr = nil
+		}
+		s.i.logCall(s.logger, s.start, s.peer, callErr, nil)
+	})
+	return err
+}
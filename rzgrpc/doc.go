@@ -0,0 +1,31 @@
+// Package rzgrpc provides gRPC server and client interceptors logging the calls with a rz Logger
+// (method, status code, peer and duration, at a level depending on the code, see Level), and
+// optionally their request and response messages, encoded as JSON with size caps and redaction.
+//
+//    server := grpc.NewServer(
+//        grpc.UnaryInterceptor(rzgrpc.UnaryServerInterceptor(logger,
+//            rzgrpc.Payloads("/shop.Orders/*"),
+//            rzgrpc.PayloadMaxSize(2048),
+//        )),
+//        grpc.StreamInterceptor(rzgrpc.StreamServerInterceptor(logger)),
+//    )
+//
+//    conn, err := grpc.NewClient(target,
+//        grpc.WithUnaryInterceptor(rzgrpc.UnaryClientInterceptor(logger)),
+//        grpc.WithStreamInterceptor(rzgrpc.StreamClientInterceptor(logger)),
+//    )
+//
+// The fields of the messages annotated with the standard debug_redact option are removed from the
+// logged payloads:
+//
+//    message Login {
+//        string user = 1;
+//        string password = 2 [debug_redact = true];
+//    }
+//
+// Proto logs other messages the same way:
+//
+//    log.Info("order created", rzgrpc.Proto("order", order))
+//
+// It's a separate module, so the rz module doesn't depend on gRPC.
+package rzgrpc
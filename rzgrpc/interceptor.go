@@ -0,0 +1,264 @@
+package rzgrpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/skerkour/rz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type interceptor struct {
+	logger         rz.Logger
+	message        string
+	methodField    string
+	codeField      string
+	peerField      string
+	durationField  string
+	requestField   string
+	responseField  string
+	payloadMethods []string
+	maxPayloadSize int
+	redactFields   map[string]bool
+	emitDefaults   bool
+}
+
+// Option is used to configure the interceptors.
+type Option func(*interceptor)
+
+// Message updates the message of the call events. Default: "grpc call", or "grpc client call" for
+// the client interceptors.
+func Message(message string) Option {
+	return func(i *interceptor) {
+		i.message = message
+	}
+}
+
+// Method updates the field name of the full method of the calls ("/package.Service/Method").
+// Default: "grpc_method". Set an empty string to disable the field.
+func Method(methodFieldName string) Option {
+	return func(i *interceptor) {
+		i.methodField = methodFieldName
+	}
+}
+
+// Code updates the field name of the status code of the calls. Default: "grpc_code". Set an empty
+// string to disable the field.
+func Code(codeFieldName string) Option {
+	return func(i *interceptor) {
+		i.codeField = codeFieldName
+	}
+}
+
+// Peer updates the field name of the address of the peer of the calls: the client for the server
+// interceptors, and the server for the client interceptors. Default: "grpc_peer". Set an empty
+// string to disable the field.
+func Peer(peerFieldName string) Option {
+	return func(i *interceptor) {
+		i.peerField = peerFieldName
+	}
+}
+
+// Duration updates the field name of the duration of the calls, in milliseconds. Default:
+// "duration". Set an empty string to disable the field.
+func Duration(durationFieldName string) Option {
+	return func(i *interceptor) {
+		i.durationField = durationFieldName
+	}
+}
+
+// PayloadFields updates the field names of the request and response messages. Default:
+// "grpc_request" and "grpc_response".
+func PayloadFields(requestFieldName, responseFieldName string) Option {
+	return func(i *interceptor) {
+		i.requestField = requestFieldName
+		i.responseField = responseFieldName
+	}
+}
+
+// Payloads enables the logging of the request and response messages of methods: full method
+// names ("/package.Service/Method"), or prefixes ending with "*" ("/package.Service/*", or "*" for
+// all the methods). Payloads are disabled by default, as they may hold personal data and be large.
+func Payloads(methods ...string) Option {
+	return func(i *interceptor) {
+		i.payloadMethods = append(i.payloadMethods, methods...)
+	}
+}
+
+// PayloadMaxSize updates the maximum size of the JSON encoding of a logged message. Larger
+// messages are logged truncated, as a string, with a "<field>_truncated" field. Default: 4096.
+func PayloadMaxSize(size int) Option {
+	return func(i *interceptor) {
+		if size > 0 {
+			i.maxPayloadSize = size
+		}
+	}
+}
+
+// EmitDefaults includes the fields with default values (zero, empty...) in the logged messages,
+// which are omitted by default. The redacted fields are then logged with their default value.
+func EmitDefaults() Option {
+	return func(i *interceptor) {
+		i.emitDefaults = true
+	}
+}
+
+// Redact removes the fields from the logged messages, in addition to the fields annotated with
+// debug_redact. Fields are full names, e.g. "shop.Customer.email".
+func Redact(fullNames ...string) Option {
+	return func(i *interceptor) {
+		for _, name := range fullNames {
+			i.redactFields[name] = true
+		}
+	}
+}
+
+func newInterceptor(logger rz.Logger, message string, options []Option) *interceptor {
+	i := &interceptor{
+		logger:         logger,
+		message:        message,
+		methodField:    "grpc_method",
+		codeField:      "grpc_code",
+		peerField:      "grpc_peer",
+		durationField:  "duration",
+		requestField:   "grpc_request",
+		responseField:  "grpc_response",
+		maxPayloadSize: 4096,
+		redactFields:   map[string]bool{},
+	}
+	for _, option := range options {
+		option(i)
+	}
+	return i
+}
+
+// logsPayloads returns true if the messages of method are logged.
+func (i *interceptor) logsPayloads(method string) bool {
+	for _, pattern := range i.payloadMethods {
+		if pattern == method || strings.HasSuffix(pattern, "*") && strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// callLogger returns the logger of a call, with the method field, and the context holding it.
+func (i *interceptor) callLogger(ctx context.Context, method string) (*rz.Logger, context.Context) {
+	logger := i.logger.ForCtx(ctx)
+	if i.methodField != "" {
+		logger = logger.With(rz.Fields(rz.String(i.methodField, method)))
+	}
+	return &logger, logger.ToCtx(ctx)
+}
+
+func (i *interceptor) logCall(logger *rz.Logger, start time.Time, p *peer.Peer, err error, fields []rz.Field) {
+	code := status.Code(err)
+	if i.peerField != "" && p != nil && p.Addr != nil {
+		fields = append(fields, rz.String(i.peerField, p.Addr.String()))
+	}
+	if i.codeField != "" {
+		fields = append(fields, rz.String(i.codeField, code.String()))
+	}
+	if i.durationField != "" {
+		durationMs := time.Since(start).Nanoseconds() / 1000000
+		if durationMs < 1 {
+			durationMs = 1
+		}
+		fields = append(fields, rz.Int64(i.durationField, durationMs))
+	}
+	if err != nil {
+		fields = append(fields, rz.Err(err))
+	}
+	logger.LogWithLevel(Level(code), i.message, fields...)
+}
+
+// Level returns the level of the calls with code: rz.InfoLevel for OK, rz.ErrorLevel for the
+// server errors (Unknown, DeadlineExceeded, Unimplemented, Internal, Unavailable and DataLoss),
+// and rz.WarnLevel for the others.
+func Level(code codes.Code) rz.LogLevel {
+	switch code {
+	case codes.OK:
+		return rz.InfoLevel
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return rz.ErrorLevel
+	}
+	return rz.WarnLevel
+}
+
+// UnaryServerInterceptor returns a unary server interceptor logging the calls. The logger of the
+// call, with the method field, is stored in the context passed to the handler (see rz.FromCtx).
+func UnaryServerInterceptor(logger rz.Logger, options ...Option) grpc.UnaryServerInterceptor {
+	i := newInterceptor(logger, "grpc call", options)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		callLogger, ctx := i.callLogger(ctx, info.FullMethod)
+		res, err := handler(ctx, req)
+
+		var fields []rz.Field
+		if i.logsPayloads(info.FullMethod) {
+			fields = i.appendPayload(fields, i.requestField, req)
+			if err == nil {
+				fields = i.appendPayload(fields, i.responseField, res)
+			}
+		}
+		p, _ := peer.FromContext(ctx)
+		i.logCall(callLogger, start, p, err, fields)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor logging the calls, and each
+// received and sent message at debug level if payloads are enabled for the method. The logger of
+// the call is stored in the context of the stream.
+func StreamServerInterceptor(logger rz.Logger, options ...Option) grpc.StreamServerInterceptor {
+	i := newInterceptor(logger, "grpc call", options)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		callLogger, ctx := i.callLogger(stream.Context(), info.FullMethod)
+		err := handler(srv, &serverStream{
+			ServerStream: stream,
+			ctx:          ctx,
+			i:            i,
+			logger:       callLogger,
+			payloads:     i.logsPayloads(info.FullMethod),
+		})
+		p, _ := peer.FromContext(ctx)
+		i.logCall(callLogger, start, p, err, nil)
+		return err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	i        *interceptor
+	logger   *rz.Logger
+	payloads bool
+}
+
+// Context returns the context of the stream, holding the logger of the call.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg logs the received messages.
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.payloads {
+		s.logger.Debug("grpc message received", s.i.appendPayload(nil, s.i.requestField, m)...)
+	}
+	return err
+}
+
+// SendMsg logs the sent messages.
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil && s.payloads {
+		s.logger.Debug("grpc message sent", s.i.appendPayload(nil, s.i.responseField, m)...)
+	}
+	return err
+}
@@ -0,0 +1,274 @@
+package rzgrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/skerkour/rz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// loginDescriptor returns the descriptor of:
+//
+//    message Login {
+//        string user = 1;
+//        string password = 2 [debug_redact = true];
+//        Session session = 3;
+//    }
+//    message Session {
+//        string id = 1;
+//        string token = 2;
+//    }
+func loginDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typeName string, redact bool) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+		if typeName != "" {
+			fd.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			fd.TypeName = proto.String(typeName)
+		}
+		if redact {
+			fd.Options = &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}
+		}
+		return fd
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("login.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Login"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("user", 1, "", false),
+				field("password", 2, "", true),
+				field("session", 3, ".test.Session", false),
+			}},
+			{Name: proto.String("Session"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, "", false),
+				field("token", 2, "", false),
+			}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Messages().ByName("Login")
+}
+
+func newLogin(t *testing.T) proto.Message {
+	md := loginDescriptor(t)
+	login := dynamicpb.NewMessage(md)
+	login.Set(md.Fields().ByName("user"), protoreflect.ValueOfString("bob"))
+	login.Set(md.Fields().ByName("password"), protoreflect.ValueOfString("hunter2"))
+	session := login.Mutable(md.Fields().ByName("session")).Message()
+	session.Set(session.Descriptor().Fields().ByName("id"), protoreflect.ValueOfString("s1"))
+	session.Set(session.Descriptor().Fields().ByName("token"), protoreflect.ValueOfString("secret"))
+	return login
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	interceptor := UnaryServerInterceptor(logger, Duration(""), Payloads("/test.Auth/*"), Redact("test.Session.token"))
+	login := newLogin(t)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		rz.FromCtx(ctx).Info("checking")
+		return req, nil
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 51234}})
+	if _, err := interceptor(ctx, login, &grpc.UnaryServerInfo{FullMethod: "/test.Auth/Login"}, handler); err != nil {
+		t.Fatal(err)
+	}
+	failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no user")
+	}
+	interceptor(context.Background(), login, &grpc.UnaryServerInfo{FullMethod: "/test.Users/Get"}, failing)
+
+	want := `{"level":"info","grpc_method":"/test.Auth/Login","message":"checking"}` + "\n" +
+		`{"level":"info","grpc_method":"/test.Auth/Login","grpc_request":{"user":"bob","session":{"id":"s1"}},"grpc_response":{"user":"bob","session":{"id":"s1"}},"grpc_peer":"10.0.0.1:51234","grpc_code":"OK","message":"grpc call"}` + "\n" +
+		`{"level":"warning","grpc_method":"/test.Users/Get","grpc_code":"NotFound","error":"rpc error: code = NotFound desc = no user","message":"grpc call"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	// the message itself is not redacted
+	if fd := login.ProtoReflect().Descriptor().Fields().ByName("password"); login.ProtoReflect().Get(fd).String() != "hunter2" {
+		t.Error("the request was modified")
+	}
+}
+
+func TestPayloadMaxSize(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	interceptor := UnaryServerInterceptor(logger, Duration(""), Code(""), Method(""), Payloads("*"), PayloadMaxSize(10))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "boom")
+	}
+	interceptor(context.Background(), newLogin(t), &grpc.UnaryServerInfo{FullMethod: "/test.Auth/Login"}, handler)
+
+	if got, want := out.String(), `{"level":"error","grpc_request":"{\"user\":\"b","grpc_request_truncated":true,"error":"rpc error: code = Internal desc = boom","message":"grpc call"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	received []proto.Message
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.received[0])
+	s.received = s.received[1:]
+	return nil
+}
+
+func (s *testStream) SendMsg(m interface{}) error {
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	interceptor := StreamServerInterceptor(logger, Duration(""), Payloads("/test.Auth/Stream"))
+	login := newLogin(t)
+	stream := &testStream{ctx: context.Background(), received: []proto.Message{login}}
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Auth/Stream"}, func(srv interface{}, stream grpc.ServerStream) error {
+		m := dynamicpb.NewMessage(login.ProtoReflect().Descriptor())
+		if err := stream.RecvMsg(m); err != nil {
+			return err
+		}
+		return stream.SendMsg(m)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		`{"level":"debug","grpc_method":"/test.Auth/Stream","grpc_request":{"user":"bob","session":{"id":"s1","token":"secret"}},"message":"grpc message received"}`,
+		`{"level":"debug","grpc_method":"/test.Auth/Stream","grpc_response":{"user":"bob","session":{"id":"s1","token":"secret"}},"message":"grpc message sent"}`,
+		`{"level":"info","grpc_method":"/test.Auth/Stream","grpc_code":"OK","message":"grpc call"}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", lines, want)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	if Level(codes.OK) != rz.InfoLevel || Level(codes.InvalidArgument) != rz.WarnLevel || Level(codes.Unavailable) != rz.ErrorLevel {
+		t.Error("invalid level mapping")
+	}
+}
+
+type testClientStream struct {
+	grpc.ClientStream
+	received []proto.Message
+}
+
+func (s *testClientStream) SendMsg(m interface{}) error {
+	return nil
+}
+
+func (s *testClientStream) RecvMsg(m interface{}) error {
+	if len(s.received) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(proto.Message), s.received[0])
+	s.received = s.received[1:]
+	return nil
+}
+
+// setPeer sets the peer of the call like grpc does once the call is done.
+func setPeer(opts []grpc.CallOption) {
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.PeerCallOption); ok {
+			o.PeerAddr.Addr = &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 443}
+		}
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	interceptor := UnaryClientInterceptor(logger, Duration(""))
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		setPeer(opts)
+		return status.Error(codes.Unavailable, "connection refused")
+	}
+
+	requestLogger := logger.With(rz.Fields(rz.String("request_id", "42")))
+	ctx := requestLogger.ToCtx(context.Background())
+	interceptor(ctx, "/test.Users/Get", newLogin(t), nil, nil, invoker)
+
+	want := `{"level":"error","request_id":"42","grpc_method":"/test.Users/Get","grpc_peer":"10.0.0.2:443","grpc_code":"Unavailable","error":"rpc error: code = Unavailable desc = connection refused","message":"grpc client call"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	interceptor := StreamClientInterceptor(logger, Duration(""), Peer(""), Payloads("*"), Redact("test.Session.token"))
+	login := newLogin(t)
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{received: []proto.Message{login}}, nil
+	}
+
+	stream, err := interceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test.Auth/Sessions", streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.SendMsg(login)
+	for err == nil {
+		err = stream.RecvMsg(dynamicpb.NewMessage(login.ProtoReflect().Descriptor()))
+	}
+	stream.RecvMsg(dynamicpb.NewMessage(login.ProtoReflect().Descriptor()))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		`{"level":"debug","grpc_method":"/test.Auth/Sessions","grpc_request":{"user":"bob","session":{"id":"s1"}},"message":"grpc message sent"}`,
+		`{"level":"debug","grpc_method":"/test.Auth/Sessions","grpc_response":{"user":"bob","session":{"id":"s1"}},"message":"grpc message received"}`,
+		`{"level":"info","grpc_method":"/test.Auth/Sessions","grpc_code":"OK","message":"grpc client call"}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", lines, want)
+	}
+}
+
+func TestProto(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	login := newLogin(t)
+	empty := dynamicpb.NewMessage(login.ProtoReflect().Descriptor())
+
+	logger.Info("login", Proto("login", login, Redact("test.Session.token")), Proto("empty", empty, EmitDefaults()), Proto("other", empty))
+
+	want := `{"level":"info","login":{"user":"bob","session":{"id":"s1"}},"empty":{"user":"","password":"","session":null},"other":{},"message":"login"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package rzgrpc
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/skerkour/rz"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Proto returns a field with msg encoded as JSON with protojson, without the fields annotated with
+// debug_redact and the ones of the Redact options, and capped in size (see PayloadMaxSize). The
+// options not related to messages are ignored.
+//
+//     log.Info("order created", rzgrpc.Proto("order", order, rzgrpc.Redact("shop.Customer.email")))
+func Proto(key string, msg proto.Message, options ...Option) rz.Field {
+	i := newInterceptor(rz.Logger{}, "", options)
+	return func(e *rz.Event) {
+		e.Append(i.appendPayload(nil, key, msg)...)
+	}
+}
+
+// appendPayload appends the field of the JSON encoding of the message m, redacted and capped.
+// Non-protobuf messages are ignored.
+func (i *interceptor) appendPayload(fields []rz.Field, field string, m interface{}) []rz.Field {
+	message, ok := m.(proto.Message)
+	if !ok || field == "" {
+		return fields
+	}
+	if i.hasRedacted(message.ProtoReflect().Descriptor(), map[protoreflect.FullName]bool{}) {
+		message = proto.Clone(message)
+		i.redact(message.ProtoReflect())
+	}
+	encoded, err := protojson.MarshalOptions{EmitUnpopulated: i.emitDefaults}.Marshal(message)
+	if err != nil {
+		return append(fields, rz.String(field+"_error", err.Error()))
+	}
+	// protojson output is deliberately unstable: compact it so events are single lines
+	compacted := bytes.NewBuffer(make([]byte, 0, len(encoded)))
+	if err = json.Compact(compacted, encoded); err == nil {
+		encoded = compacted.Bytes()
+	}
+	if len(encoded) > i.maxPayloadSize {
+		return append(fields, rz.String(field, string(encoded[:i.maxPayloadSize])), rz.Bool(field+"_truncated", true))
+	}
+	return append(fields, rz.RawJSON(field, encoded))
+}
+
+func (i *interceptor) isRedacted(fd protoreflect.FieldDescriptor) bool {
+	if i.redactFields[string(fd.FullName())] {
+		return true
+	}
+	options, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && options.GetDebugRedact()
+}
+
+// hasRedacted returns true if messages of type md may have redacted fields, so messages without
+// any are not cloned.
+func (i *interceptor) hasRedacted(md protoreflect.MessageDescriptor, visited map[protoreflect.FullName]bool) bool {
+	if visited[md.FullName()] {
+		return false
+	}
+	visited[md.FullName()] = true
+	fields := md.Fields()
+	for j := 0; j < fields.Len(); j++ {
+		fd := fields.Get(j)
+		if i.isRedacted(fd) {
+			return true
+		}
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		if fd.Message() != nil && i.hasRedacted(fd.Message(), visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact clears the redacted fields of m and of its nested messages.
+func (i *interceptor) redact(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case i.isRedacted(fd):
+			m.Clear(fd)
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+					i.redact(value.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				list := v.List()
+				for j := 0; j < list.Len(); j++ {
+					i.redact(list.Get(j).Message())
+				}
+			}
+		case fd.Message() != nil:
+			i.redact(v.Message())
+		}
+		return true
+	})
+}
@@ -0,0 +1,47 @@
+package rzhttp
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/skerkour/rz"
+)
+
+// DefaultDebugHeader is the request header checked by DebugOverride when header is empty.
+const DefaultDebugHeader = "X-Debug"
+
+// DebugOverride is a middleware making a single request log at debug level regardless of the
+// configured level: when the request has the header (DefaultDebugHeader if empty) and validate
+// accepts it, the request context gets a rz.DebugLevel override (see rz.WithLevelOverride),
+// applied by rz.FromCtx, rz.Logger.ForCtx and the Handler middleware.
+//
+// validate must authenticate the request (e.g. with DebugToken) so clients can't flood the logs:
+// requests are never overridden if it's nil.
+//
+//     router.Use(rzhttp.DebugOverride("", rzhttp.DebugToken("", os.Getenv("DEBUG_TOKEN"))))
+//     router.Use(rzhttp.Handler(logger))
+func DebugOverride(header string, validate func(r *http.Request) bool) func(next http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultDebugHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if validate != nil && r.Header.Get(header) != "" && validate(r) {
+				r = r.WithContext(rz.WithLevelOverride(r.Context(), rz.DebugLevel))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DebugToken returns a DebugOverride validator accepting the requests whose header
+// (DefaultDebugHeader if empty) is token. An empty token accepts no request.
+func DebugToken(header, token string) func(r *http.Request) bool {
+	if header == "" {
+		header = DefaultDebugHeader
+	}
+	return func(r *http.Request) bool {
+		value := r.Header.Get(header)
+		return token != "" && subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1
+	}
+}
@@ -0,0 +1,65 @@
+package rzhttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+func TestDebugOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		validate func(r *http.Request) bool
+		request  map[string]string
+		override bool
+	}{
+		{"valid token", "", DebugToken("", "secret"), map[string]string{DefaultDebugHeader: "secret"}, true},
+		{"invalid token", "", DebugToken("", "secret"), map[string]string{DefaultDebugHeader: "secre"}, false},
+		{"longer token", "", DebugToken("", "secret"), map[string]string{DefaultDebugHeader: "secrets"}, false},
+		{"no header", "", DebugToken("", "secret"), nil, false},
+		{"empty token", "", DebugToken("", ""), map[string]string{DefaultDebugHeader: ""}, false},
+		{"custom header", "X-Trace-Debug", DebugToken("X-Trace-Debug", "secret"), map[string]string{"X-Trace-Debug": "secret"}, true},
+		{"header of another validator", "", DebugToken("X-Other", "secret"), map[string]string{DefaultDebugHeader: "1", "X-Other": "secret"}, true},
+		{"nil validate", "", nil, map[string]string{DefaultDebugHeader: "secret"}, false},
+		{"validate without header", "", func(r *http.Request) bool { return true }, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var level rz.LogLevel
+			var override bool
+			handler := DebugOverride(tt.header, tt.validate)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				level, override = rz.LevelOverride(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for header, value := range tt.request {
+				r.Header.Set(header, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			if override != tt.override || (override && level != rz.DebugLevel) {
+				t.Errorf("override = %v (%v), want %v", override, level, tt.override)
+			}
+		})
+	}
+}
+
+func TestDebugOverrideLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Level(rz.InfoLevel), rz.Fields(rz.Timestamp(false)))
+	handler := DebugOverride("", DebugToken("", "secret"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqLog := log.ForCtx(r.Context())
+		reqLog.Debug("details")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(DefaultDebugHeader, "secret")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got, want := out.String(), `{"level":"debug","message":"details"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
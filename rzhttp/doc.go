@@ -1,3 +1,5 @@
-// Package rzhttp provides an helper middleware to log HTTP requests
+// Package rzhttp provides an helper middleware to log HTTP requests, a middleware to debug
+// a single request regardless of the configured level, and a middleware linking the logs of a
+// request to its runtime/trace task and span.
 // See https://github.com/skerkour/rz/tree/master/examples/http for a working example
 package rzhttp
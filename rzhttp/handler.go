@@ -1,8 +1,14 @@
 package rzhttp
 
 import (
+	"context"
+	"encoding/hex"
+	"hash"
+	"io"
 	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/skerkour/rz"
@@ -14,6 +20,13 @@ type ctxKeyRequestID int
 // RequestIDCtxKey is the key that holds the unique request ID in a request context.
 const RequestIDCtxKey ctxKeyRequestID = 0
 
+const (
+	// DefaultRequestIDHeader is a common response header for the request ID (see ResponseHeaders).
+	DefaultRequestIDHeader = "X-Request-ID"
+	// DefaultTraceIDHeader is a common response header for the trace ID (see ResponseHeaders).
+	DefaultTraceIDHeader = "X-Trace-ID"
+)
+
 type httpHandler struct {
 	logger             rz.Logger
 	message            string
@@ -27,6 +40,44 @@ type httpHandler struct {
 	statusField        string
 	durationField      string
 	requestIDField     string
+	pathField          string
+	requestFields      []func(r *http.Request) []rz.Field
+	contextLogger      bool
+	routeField         string
+	routePatterns      []func(r *http.Request) string
+	traceIDField       string
+	spanIDField        string
+	bodyHashField      string
+	bodySizeField      string
+	bodyHash           rz.HashFunc
+	quiet              *quietPaths
+	requestIDHeader    string
+	traceIDHeader      string
+}
+
+// DefaultHealthPaths are the paths of the usual health, readiness and metrics endpoints, quieted
+// by QuietHealthChecks.
+var DefaultHealthPaths = []string{"/health", "/healthz", "/livez", "/readyz", "/ready", "/ping", "/metrics"}
+
+type quietPaths struct {
+	paths []string
+	level rz.LogLevel
+	every uint32
+	count uint32
+}
+
+func (q *quietPaths) match(path string) bool {
+	for _, p := range q.paths {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// sample returns true for one request out of every.
+func (q *quietPaths) sample() bool {
+	return q.every <= 1 || atomic.AddUint32(&q.count, 1)%q.every == 1
 }
 
 // HandlerOption are used to configure a HTTPHandler.
@@ -109,7 +160,119 @@ func RequestID(requestIDFieldName string) HandlerOption {
 	}
 }
 
-// Handler is a helper middleware to log HTTP requests
+// Path is used to add a field with the path of the request URL, without its query. It's disabled
+// by default, as the url field holds the raw request URI.
+func Path(pathFieldName string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.pathField = pathFieldName
+	}
+}
+
+// RequestFields is used to add the fields returned by fn, called once the request is served, to
+// the access log, e.g. the user or the tenant of the request.
+func RequestFields(fn func(r *http.Request) []rz.Field) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.requestFields = append(handler.requestFields, fn)
+	}
+}
+
+// ContextLogger is used to enable or disable the request-scoped logger stored in the request
+// context (see Handler). It's enabled by default.
+func ContextLogger(enabled bool) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.contextLogger = enabled
+	}
+}
+
+// Route is used to updated HTTPHandler's route field name, holding the pattern of the route
+// matching the request, if detected (see RoutePatterns and SetRoute). Set an empty string to disable the field.
+func Route(routeFieldName string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.routeField = routeFieldName
+	}
+}
+
+// RoutePatterns is used to detect the route patterns of third party routers, logged in the route
+// field: the detectors are called in order once the request is served, and return the pattern of
+// the route matching the request, or an empty string. See ContextRoutePattern (chi) and
+// TemplateRoutePattern (gorilla/mux). The patterns of net/http ServeMux (Go 1.23+) are always
+// detected.
+func RoutePatterns(detectors ...func(r *http.Request) string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.routePatterns = append(handler.routePatterns, detectors...)
+	}
+}
+
+// TraceID is used to updated HTTPHandler's trace ID field name, added if the request context holds
+// a trace ID (see Trace). Set an empty string to disable the field.
+func TraceID(traceIDFieldName string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.traceIDField = traceIDFieldName
+	}
+}
+
+// SpanID is used to updated HTTPHandler's span ID field name, added if the request context holds
+// a span ID (see Trace). Set an empty string to disable the field.
+func SpanID(spanIDFieldName string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.spanIDField = spanIDFieldName
+	}
+}
+
+// RequestBodyHash is used to add a field with the hex encoded hash of the request body, computed
+// with hashFunc (rz.DefaultHash if nil) while the handler reads it, so the body is not buffered.
+// The hash only covers the bytes read by the handler. It's disabled by default.
+func RequestBodyHash(bodyHashFieldName string, hashFunc rz.HashFunc) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.bodyHashField = bodyHashFieldName
+		handler.bodyHash = hashFunc
+	}
+}
+
+// RequestBodySize is used to add a field with the number of bytes of the request body read by the
+// handler. It's disabled by default.
+func RequestBodySize(bodySizeFieldName string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.bodySizeField = bodySizeFieldName
+	}
+}
+
+// ResponseHeaders is used to write the request ID and the trace ID of the request context (see
+// RequestIDCtxKey and Trace), if any, to the response headers, so clients can quote the IDs
+// present in the logs. An empty header name disables the header (the default). It's usually
+// used with DefaultRequestIDHeader and DefaultTraceIDHeader.
+func ResponseHeaders(requestIDHeader, traceIDHeader string) HandlerOption {
+	return func(handler *httpHandler) {
+		handler.requestIDHeader = requestIDHeader
+		handler.traceIDHeader = traceIDHeader
+	}
+}
+
+// QuietPaths is used to demote the successful requests (status lower than 400) to paths (or
+// below them) to level, and to log only one of every such requests (all of them if every is 0
+// or 1). Failed requests are logged as usual.
+func QuietPaths(paths []string, level rz.LogLevel, every uint32) HandlerOption {
+	quiet := &quietPaths{paths: paths, level: level, every: every}
+	return func(handler *httpHandler) {
+		handler.quiet = quiet
+	}
+}
+
+// QuietHealthChecks is a preset silencing the health, readiness and metrics endpoints, usually the
+// first source of useless log volume: the successful requests to DefaultHealthPaths are logged at
+// debug level, one out of 100.
+func QuietHealthChecks() HandlerOption {
+	return QuietPaths(DefaultHealthPaths, rz.DebugLevel, 100)
+}
+
+// Handler is a helper middleware to log HTTP requests. The level override of the request context,
+// if any (see DebugOverride), is applied to the logger.
+//
+// The request context holds a request-scoped logger retrieved with rz.FromCtx: the logger of the
+// context (e.g. stored by Trace) or logger, with the request ID field, if any, so the handlers
+// don't need a logger parameter:
+//
+//     rz.FromCtx(r.Context()).Info("user created")
 func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler) http.Handler {
 	logger = logger.With()
 	return func(next http.Handler) http.Handler {
@@ -118,7 +281,7 @@ func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler)
 
 			// store a copy of the logger
 			handler := httpHandler{
-				logger:             logger,
+				logger:             logger.ForCtx(r.Context()),
 				message:            "access",
 				urlField:           "url",
 				methodField:        "method",
@@ -130,6 +293,10 @@ func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler)
 				statusField:        "status",
 				durationField:      "duration",
 				requestIDField:     "request_id",
+				contextLogger:      true,
+				traceIDField:       "trace_id",
+				spanIDField:        "span_id",
+				routeField:         "route",
 			}
 			for _, option := range options {
 				option(&handler)
@@ -165,6 +332,10 @@ func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler)
 				handler.logger.Append(rz.String(handler.urlField, r.RequestURI))
 			}
 
+			if handler.pathField != "" {
+				handler.logger.Append(rz.String(handler.pathField, r.URL.Path))
+			}
+
 			if handler.hostField != "" {
 				handler.logger.Append(rz.String(handler.hostField, r.Host))
 			}
@@ -182,8 +353,61 @@ func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler)
 				handler.logger.Append(rz.String(handler.userAgentField, r.Header.Get("user-agent")))
 			}
 
+			if handler.requestIDHeader != "" {
+				if requestID, ok := r.Context().Value(RequestIDCtxKey).(string); ok && requestID != "" {
+					w.Header().Set(handler.requestIDHeader, requestID)
+				}
+			}
+			if handler.traceIDHeader != "" {
+				if traceID, ok := r.Context().Value(TraceIDCtxKey).(string); ok && traceID != "" {
+					w.Header().Set(handler.traceIDHeader, traceID)
+				}
+			}
+
+			var body *hashingBody
+			if handler.bodyHashField != "" || handler.bodySizeField != "" {
+				body = &hashingBody{ReadCloser: r.Body}
+				if handler.bodyHashField != "" {
+					if handler.bodyHash == nil {
+						handler.bodyHash = rz.DefaultHash
+					}
+					body.hash = handler.bodyHash()
+				}
+				if r.Body != nil && r.Body != http.NoBody {
+					r.Body = body
+				}
+			}
+
+			rt := &route{}
+			ctx := withRoute(r.Context(), rt)
+			if handler.contextLogger {
+				ctx = requestLogger(ctx, logger, handler.requestIDField)
+			}
+			r = r.WithContext(ctx)
+
 			next.ServeHTTP(resWrapper, r)
 
+			if handler.routeField != "" {
+				if pattern := routePattern(r, rt, handler.routePatterns); pattern != "" {
+					handler.logger.Append(rz.String(handler.routeField, pattern))
+				}
+			}
+			if len(rt.fields) > 0 {
+				handler.logger.Append(rt.fields...)
+			}
+			for _, fn := range handler.requestFields {
+				handler.logger.Append(fn(r)...)
+			}
+
+			if body != nil {
+				if body.hash != nil {
+					handler.logger.Append(rz.String(handler.bodyHashField, hex.EncodeToString(body.hash.Sum(nil))))
+				}
+				if handler.bodySizeField != "" {
+					handler.logger.Append(rz.Int64(handler.bodySizeField, body.size))
+				}
+			}
+
 			if handler.sizeField != "" {
 				handler.logger.Append(rz.Int(handler.sizeField, resWrapper.written))
 			}
@@ -211,7 +435,18 @@ func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler)
 				handler.logger.Append(rz.String(handler.requestIDField, requestID))
 			}
 
+			if traceID, ok := r.Context().Value(TraceIDCtxKey).(string); ok && handler.traceIDField != "" {
+				handler.logger.Append(rz.String(handler.traceIDField, traceID))
+			}
+			if spanID, ok := r.Context().Value(SpanIDCtxKey).(string); ok && handler.spanIDField != "" {
+				handler.logger.Append(rz.String(handler.spanIDField, spanID))
+			}
+
 			switch {
+			case status < 400 && handler.quiet != nil && handler.quiet.match(r.URL.Path):
+				if handler.quiet.sample() {
+					handler.logger.LogWithLevel(handler.quiet.level, handler.message)
+				}
 			case status < 400:
 				handler.logger.Info(handler.message)
 			case status < 500:
@@ -223,6 +458,20 @@ func Handler(logger rz.Logger, options ...HandlerOption) func(next http.Handler)
 	}
 }
 
+// requestLogger returns a copy of ctx holding its logger, or logger if it has none, with the
+// request ID field.
+func requestLogger(ctx context.Context, logger rz.Logger, requestIDField string) context.Context {
+	var fields []rz.Field
+	if requestID, ok := ctx.Value(RequestIDCtxKey).(string); ok && requestID != "" && requestIDField != "" {
+		fields = append(fields, rz.String(requestIDField, requestID))
+	}
+	if _, ok := rz.LoggerFromCtx(ctx); ok {
+		return rz.WithCtxFields(ctx, fields...)
+	}
+	logger = logger.With(rz.Fields(fields...))
+	return logger.ToCtx(ctx)
+}
+
 type responseWrapper struct {
 	http.ResponseWriter
 	http.Flusher
@@ -245,6 +494,23 @@ func (w *responseWrapper) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// hashingBody hashes and counts the bytes of a request body as they are read.
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	size int64
+}
+
+// Read wrapper to hash the request body.
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.hash != nil {
+		b.hash.Write(p[:n])
+	}
+	b.size += int64(n)
+	return n, err
+}
+
 // Flush implementation.
 func (w *responseWrapper) Flush() {
 	if w.Flusher != nil {
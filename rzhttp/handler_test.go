@@ -0,0 +1,220 @@
+package rzhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+// serve serves r with the Handler middleware and next, and returns the access logs.
+func serve(t *testing.T, next http.HandlerFunc, r *http.Request, options ...HandlerOption) []map[string]interface{} {
+	t.Helper()
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Level(rz.DebugLevel), rz.Fields(rz.Timestamp(false)))
+	Handler(log, options...)(next).ServeHTTP(httptest.NewRecorder(), r)
+	return decodeEvents(t, out)
+}
+
+func decodeEvents(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	events := []map[string]interface{}{}
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		event := map[string]interface{}{}
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func readBody(n int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		io.CopyN(io.Discard, r.Body, n)
+	}
+}
+
+func TestHandlerRequestBody(t *testing.T) {
+	body := "hello world"
+	sum := sha256.Sum256([]byte(body))
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	events := serve(t, readBody(100), r, RequestBodyHash("body_hash", nil), RequestBodySize("body_size"))
+	if len(events) != 1 {
+		t.Fatalf("invalid access logs: %v", events)
+	}
+	if got, want := events[0]["body_hash"], hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("body_hash = %v, want %v", got, want)
+	}
+	if got := events[0]["body_size"]; got != float64(len(body)) {
+		t.Errorf("body_size = %v, want %d", got, len(body))
+	}
+
+	// only the bytes read by the handler are covered
+	sum512 := sha512.Sum512([]byte("hello"))
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	events = serve(t, readBody(5), r, RequestBodyHash("body_hash", rz.SHA512), RequestBodySize("body_size"))
+	if got, want := events[0]["body_hash"], hex.EncodeToString(sum512[:]); got != want {
+		t.Errorf("body_hash = %v, want %v", got, want)
+	}
+	if got := events[0]["body_size"]; got != float64(5) {
+		t.Errorf("body_size = %v, want 5", got)
+	}
+
+	// disabled by default
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	events = serve(t, readBody(100), r)
+	if _, ok := events[0]["body_hash"]; ok {
+		t.Errorf("body_hash logged by default: %v", events[0])
+	}
+	if _, ok := events[0]["body_size"]; ok {
+		t.Errorf("body_size logged by default: %v", events[0])
+	}
+}
+
+func TestHandlerQuietPaths(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Level(rz.DebugLevel), rz.Fields(rz.Timestamp(false)))
+	status := http.StatusOK
+	handler := Handler(log, QuietPaths([]string{"/internal"}, rz.DebugLevel, 3), Duration(""))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/internal/stats", nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/internal", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/internals", nil))
+	status = http.StatusServiceUnavailable
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/internal/stats", nil))
+
+	var got []string
+	for _, event := range decodeEvents(t, out) {
+		got = append(got, event["level"].(string)+" "+event["url"].(string))
+	}
+	want := []string{
+		// one of every 3 successful requests, demoted
+		"debug /internal/stats",
+		"debug /internal",
+		"info /internals",
+		// failed requests are logged as usual
+		"error /internal/stats",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("invalid access logs:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestHandlerQuietHealthChecks(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Level(rz.InfoLevel), rz.Fields(rz.Timestamp(false)))
+	handler := Handler(log, QuietHealthChecks())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, path := range DefaultHealthPaths {
+		for i := 0; i < 10; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	events := decodeEvents(t, out)
+	if len(events) != 1 || events[0]["url"] != "/users" {
+		t.Errorf("health checks logged above debug level: %v", events)
+	}
+}
+
+func TestHandlerResponseHeaders(t *testing.T) {
+	log := rz.New(rz.Writer(&bytes.Buffer{}))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	withIDs := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), RequestIDCtxKey, "req-1")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, TraceIDCtxKey, "trace-1")))
+		})
+	}
+
+	res := httptest.NewRecorder()
+	withIDs(Handler(log, ResponseHeaders(DefaultRequestIDHeader, DefaultTraceIDHeader))(next)).
+		ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := res.Header().Get(DefaultRequestIDHeader); got != "req-1" {
+		t.Errorf("%s = %q, want req-1", DefaultRequestIDHeader, got)
+	}
+	if got := res.Header().Get(DefaultTraceIDHeader); got != "trace-1" {
+		t.Errorf("%s = %q, want trace-1", DefaultTraceIDHeader, got)
+	}
+
+	// disabled headers, and requests without IDs
+	res = httptest.NewRecorder()
+	withIDs(Handler(log, ResponseHeaders("", DefaultTraceIDHeader))(next)).
+		ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := res.Header().Get(DefaultRequestIDHeader); got != "" {
+		t.Errorf("disabled %s = %q", DefaultRequestIDHeader, got)
+	}
+	res = httptest.NewRecorder()
+	Handler(log, ResponseHeaders(DefaultRequestIDHeader, DefaultTraceIDHeader))(next).
+		ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(res.Header()) != 0 {
+		t.Errorf("headers set without IDs: %v", res.Header())
+	}
+}
+
+func TestHandlerContextLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	var found bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var logger *rz.Logger
+		logger, found = rz.LoggerFromCtx(r.Context())
+		if found {
+			logger.Info("user created")
+		}
+	})
+	withRequestID := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), RequestIDCtxKey, "req-1")))
+		})
+	}
+
+	withRequestID(Handler(log, Duration(""))(next)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	events := decodeEvents(t, out)
+	if len(events) != 2 {
+		t.Fatalf("invalid logs: %v", events)
+	}
+	if events[0]["message"] != "user created" || events[0]["request_id"] != "req-1" {
+		t.Errorf("invalid request-scoped log: %v", events[0])
+	}
+	if events[1]["message"] != "access" || events[1]["request_id"] != "req-1" {
+		t.Errorf("invalid access log: %v", events[1])
+	}
+
+	// the logger already in the context gets the request ID field
+	out.Reset()
+	traced := log.With(rz.Fields(rz.String("trace_id", "trace-1")))
+	withLogger := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(traced.ToCtx(r.Context())))
+		})
+	}
+	withRequestID(withLogger(Handler(log, Duration(""))(next))).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	events = decodeEvents(t, out)
+	if len(events) != 2 || events[0]["trace_id"] != "trace-1" || events[0]["request_id"] != "req-1" {
+		t.Errorf("invalid request-scoped log: %v", events)
+	}
+
+	out.Reset()
+	Handler(log, ContextLogger(false))(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if found {
+		t.Error("request-scoped logger stored when disabled")
+	}
+}
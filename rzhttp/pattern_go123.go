@@ -0,0 +1,14 @@
+//go:build go1.23
+// +build go1.23
+
+package rzhttp
+
+import (
+	"net/http"
+)
+
+// requestPattern returns the pattern of the net/http ServeMux route matching r. It's empty with
+// the Go 1.21 ServeMux (GODEBUG httpmuxgo121=1, the default of the modules older than Go 1.22).
+func requestPattern(r *http.Request) string {
+	return r.Pattern
+}
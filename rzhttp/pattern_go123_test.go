@@ -0,0 +1,35 @@
+//go:build go1.23
+// +build go1.23
+
+// the go directive of go.mod enables the Go 1.21 ServeMux, without patterns
+//go:debug httpmuxgo121=0
+
+package rzhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerServeMuxPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r, "/orders/:id")
+	})
+
+	events := serve(t, mux.ServeHTTP, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if got, want := events[0]["route"], "GET /users/{id}"; got != want {
+		t.Errorf("route = %v, want %v", got, want)
+	}
+	// an explicit pattern overrides the ServeMux one
+	events = serve(t, mux.ServeHTTP, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+	if got, want := events[0]["route"], "/orders/:id"; got != want {
+		t.Errorf("route = %v, want %v", got, want)
+	}
+	events = serve(t, mux.ServeHTTP, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if route, ok := events[0]["route"]; ok {
+		t.Errorf("route = %v for an unmatched request", route)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !go1.23
+// +build !go1.23
+
+package rzhttp
+
+import (
+	"net/http"
+)
+
+// requestPattern returns an empty string: http.Request.Pattern requires Go 1.23.
+func requestPattern(r *http.Request) string {
+	return ""
+}
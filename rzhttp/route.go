@@ -0,0 +1,101 @@
+package rzhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/skerkour/rz"
+)
+
+type ctxKeyRoute int
+
+const routeCtxKey ctxKeyRoute = 0
+
+// route holds the pattern and the static fields of the route matching a request, set by the
+// routing handlers while the Handler middleware serves the request.
+type route struct {
+	pattern string
+	fields  []rz.Field
+}
+
+// SetRoute sets the route pattern of the request logged by the Handler middleware, and adds the
+// fields to its access log, e.g. the team owning the route or its SLO tier. It's intended for the
+// handlers knowing their route when the router's patterns are not detected (see RoutePatterns).
+// An explicit pattern overrides the detected one, an empty pattern keeps it. It does nothing
+// outside of Handler.
+func SetRoute(r *http.Request, pattern string, fields ...rz.Field) {
+	if rt, ok := r.Context().Value(routeCtxKey).(*route); ok {
+		if pattern != "" {
+			rt.pattern = pattern
+		}
+		rt.fields = append(rt.fields, fields...)
+	}
+}
+
+// ContextRoutePattern returns a route pattern detector (see RoutePatterns) for the routers storing
+// in the request context, under key, a value with a RoutePattern() string method, like chi:
+//
+//    router.Use(rzhttp.Handler(logger, rzhttp.RoutePatterns(rzhttp.ContextRoutePattern(chi.RouteCtxKey))))
+//
+// As chi matches the routes of its sub-routers while serving, the pattern is read once the
+// request is served, so it's complete.
+func ContextRoutePattern(key interface{}) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		if rctx, ok := r.Context().Value(key).(interface{ RoutePattern() string }); ok && rctx != nil {
+			return rctx.RoutePattern()
+		}
+		return ""
+	}
+}
+
+// TemplateRoutePattern returns a route pattern detector (see RoutePatterns) for the routers
+// returning the current route of a request, with a GetPathTemplate() (string, error) method, like
+// gorilla/mux, when Handler is a middleware of the router:
+//
+//    router.Use(rzhttp.Handler(logger, rzhttp.RoutePatterns(rzhttp.TemplateRoutePattern(mux.CurrentRoute))))
+func TemplateRoutePattern[R interface{ GetPathTemplate() (string, error) }](currentRoute func(r *http.Request) R) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		var none R
+		route := currentRoute(r)
+		if interface{}(route) == interface{}(none) {
+			return ""
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return ""
+		}
+		return template
+	}
+}
+
+// RouteFields is a middleware adding static fields to the access logs of the requests it serves
+// (see SetRoute), to wrap the handler of a route:
+//
+//    mux.Handle("/users/{id}", rzhttp.RouteFields(rz.String("team", "identity"))(usersHandler))
+func RouteFields(fields ...rz.Field) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SetRoute(r, "", fields...)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// withRoute returns a copy of ctx holding rt.
+func withRoute(ctx context.Context, rt *route) context.Context {
+	return context.WithValue(ctx, routeCtxKey, rt)
+}
+
+// routePattern returns the pattern of the route matching r once served: set with SetRoute,
+// detected by one of detectors, or matched by a net/http ServeMux (Go 1.23+) served by Handler.
+func routePattern(r *http.Request, rt *route, detectors []func(r *http.Request) string) string {
+	if rt.pattern != "" {
+		return rt.pattern
+	}
+	for _, detect := range detectors {
+		if pattern := detect(r); pattern != "" {
+			return pattern
+		}
+	}
+	return requestPattern(r)
+}
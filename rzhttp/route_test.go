@@ -0,0 +1,94 @@
+package rzhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+// chiContext mimics the route context of chi.
+type chiContext struct{ pattern string }
+
+func (c *chiContext) RoutePattern() string { return c.pattern }
+
+type chiKey struct{}
+
+// gorillaRoute mimics the routes of gorilla/mux.
+type gorillaRoute struct{ template string }
+
+func (r *gorillaRoute) GetPathTemplate() (string, error) {
+	if r.template == "" {
+		return "", errors.New("mux: route doesn't have a path")
+	}
+	return r.template, nil
+}
+
+type gorillaKey struct{}
+
+func currentRoute(r *http.Request) *gorillaRoute {
+	route, _ := r.Context().Value(gorillaKey{}).(*gorillaRoute)
+	return route
+}
+
+func TestHandlerRoutePatterns(t *testing.T) {
+	detectors := RoutePatterns(ContextRoutePattern(chiKey{}), TemplateRoutePattern(currentRoute))
+	tests := []struct {
+		name  string
+		ctx   func(ctx context.Context) context.Context
+		next  http.HandlerFunc
+		route interface{}
+	}{
+		{"chi", func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, chiKey{}, &chiContext{})
+		}, func(w http.ResponseWriter, r *http.Request) {
+			// chi completes the pattern while serving
+			r.Context().Value(chiKey{}).(*chiContext).pattern = "/users/{id}"
+		}, "/users/{id}"},
+		{"gorilla", func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, gorillaKey{}, &gorillaRoute{template: "/users/{id:[0-9]+}"})
+		}, nil, "/users/{id:[0-9]+}"},
+		{"gorilla without template", func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, gorillaKey{}, &gorillaRoute{})
+		}, nil, nil},
+		{"no route", func(ctx context.Context) context.Context { return ctx }, nil, nil},
+		{"SetRoute", func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, chiKey{}, &chiContext{pattern: "/users/*"})
+		}, func(w http.ResponseWriter, r *http.Request) {
+			SetRoute(r, "/users/{id}")
+		}, "/users/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := tt.next
+			if next == nil {
+				next = func(w http.ResponseWriter, r *http.Request) {}
+			}
+			r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			events := serve(t, next, r.WithContext(tt.ctx(r.Context())), detectors)
+			if got := events[0]["route"]; got != tt.route {
+				t.Errorf("route = %v, want %v", got, tt.route)
+			}
+		})
+	}
+}
+
+func TestRouteFields(t *testing.T) {
+	next := RouteFields(rz.String("team", "identity"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r, "", rz.String("slo", "gold"))
+	}))
+	events := serve(t, next.ServeHTTP, httptest.NewRequest(http.MethodGet, "/users/42", nil), Route("pattern"))
+	if events[0]["team"] != "identity" || events[0]["slo"] != "gold" {
+		t.Errorf("route fields not logged: %v", events[0])
+	}
+	if _, ok := events[0]["pattern"]; ok {
+		t.Errorf("empty pattern logged: %v", events[0])
+	}
+
+	// outside of Handler
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	SetRoute(r, "/", rz.String("team", "identity"))
+}
@@ -0,0 +1,149 @@
+package rzhttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"runtime/trace"
+	"strings"
+
+	"github.com/skerkour/rz"
+)
+
+type ctxKeyTrace int
+
+const (
+	// TraceIDCtxKey is the key that holds the trace ID in a request context.
+	TraceIDCtxKey ctxKeyTrace = iota
+	// SpanIDCtxKey is the key that holds the span ID in a request context.
+	SpanIDCtxKey
+)
+
+// SpanStarter starts a span (e.g. an OpenTelemetry span) for a request. It returns the context of
+// the span, its trace and span IDs, and a function ending it.
+//
+//     rzhttp.TraceSpan(func(r *http.Request) (context.Context, string, string, func()) {
+//         ctx, span := tracer.Start(r.Context(), r.Method+" "+r.URL.Path)
+//         sc := span.SpanContext()
+//         return ctx, sc.TraceID().String(), sc.SpanID().String(), func() { span.End() }
+//     })
+type SpanStarter func(r *http.Request) (ctx context.Context, traceID, spanID string, end func())
+
+type tracer struct {
+	startSpan    SpanStarter
+	traceIDField string
+	spanIDField  string
+}
+
+// TraceOption are used to configure the Trace middleware.
+type TraceOption func(*tracer)
+
+// TraceSpan makes the Trace middleware start a span for each request with start, and use its
+// IDs instead of generating them.
+func TraceSpan(start SpanStarter) TraceOption {
+	return func(t *tracer) {
+		t.startSpan = start
+	}
+}
+
+// TraceFields updates the names of the trace and span ID fields added to the logger of the
+// request. Default: "trace_id" and "span_id". An empty string disables the field.
+func TraceFields(traceIDFieldName, spanIDFieldName string) TraceOption {
+	return func(t *tracer) {
+		t.traceIDField = traceIDFieldName
+		t.spanIDField = spanIDFieldName
+	}
+}
+
+// Trace is a middleware linking the logs of a request to its traces: it opens a runtime/trace
+// task and region per request, annotated with a trace ID and a span ID, and stores in the request
+// context the IDs (see TraceIDCtxKey and SpanIDCtxKey) and a copy of logger with them as fields,
+// retrieved with rz.FromCtx. The Handler middleware adds them to the access log.
+//
+// The trace ID is taken from the W3C traceparent header of the request if it's valid, and
+// generated otherwise, as the span ID. With TraceSpan, the IDs of the started span are used.
+// If the IDs can't be generated (the system random number generator failed), the request is
+// served as is.
+//
+//     router.Use(rzhttp.Trace(logger))
+//     router.Use(rzhttp.Handler(logger))
+func Trace(logger rz.Logger, options ...TraceOption) func(next http.Handler) http.Handler {
+	t := tracer{
+		traceIDField: "trace_id",
+		spanIDField:  "span_id",
+	}
+	for _, option := range options {
+		option(&t)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			var traceID, spanID string
+			if t.startSpan != nil {
+				var end func()
+				ctx, traceID, spanID, end = t.startSpan(r)
+				if end != nil {
+					defer end()
+				}
+			} else {
+				var err error
+				traceID = parentTraceID(r.Header.Get("traceparent"))
+				if traceID == "" {
+					traceID, err = randomID(16)
+				}
+				if err == nil {
+					spanID, err = randomID(8)
+				}
+				if err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			ctx, task := trace.NewTask(ctx, "http.request")
+			defer task.End()
+			trace.Log(ctx, "trace_id", traceID)
+			trace.Log(ctx, "span_id", spanID)
+
+			fields := make([]rz.Field, 0, 2)
+			if t.traceIDField != "" {
+				fields = append(fields, rz.String(t.traceIDField, traceID))
+			}
+			if t.spanIDField != "" {
+				fields = append(fields, rz.String(t.spanIDField, spanID))
+			}
+			requestLogger := logger.With(rz.Fields(fields...))
+			ctx = context.WithValue(ctx, TraceIDCtxKey, traceID)
+			ctx = context.WithValue(ctx, SpanIDCtxKey, spanID)
+			ctx = requestLogger.ToCtx(ctx)
+
+			trace.WithRegion(ctx, "http.handler", func() {
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		})
+	}
+}
+
+// parentTraceID returns the trace ID of a W3C traceparent header
+// ("00-<trace id>-<parent id>-<flags>"), or an empty string if it's invalid.
+func parentTraceID(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(traceID); err != nil || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	return traceID
+}
+
+// randomID returns a random hex encoded ID of size bytes.
+func randomID(size int) (string, error) {
+	id := make([]byte, size)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,115 @@
+package rzhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+func TestParentTraceID(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{" 00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01 ", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		// future versions may have more fields
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"", ""},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"000-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e47360-00f067aa0ba902b7-01", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b-01", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01", ""},
+	}
+	for _, tt := range tests {
+		if got := parentTraceID(tt.header); got != tt.want {
+			t.Errorf("parentTraceID(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	var traceID, spanID string
+	handler := Trace(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID, _ = r.Context().Value(TraceIDCtxKey).(string)
+		spanID, _ = r.Context().Value(SpanIDCtxKey).(string)
+		rz.FromCtx(r.Context()).Info("handled")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %q, want the parent trace ID", traceID)
+	}
+	if len(spanID) != 16 || spanID == "00f067aa0ba902b7" {
+		t.Errorf("invalid span ID: %q", spanID)
+	}
+	want := `{"level":"info","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"` + spanID + `","message":"handled"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("traceparent", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if len(traceID) != 32 || traceID == "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("invalid generated trace ID: %q", traceID)
+	}
+	event := map[string]string{}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event["trace_id"] != traceID || event["span_id"] != spanID {
+		t.Errorf("invalid IDs in the request logger: %v", event)
+	}
+}
+
+func TestTraceFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	handler := Trace(log, TraceFields("", "span"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rz.FromCtx(r.Context()).Info("handled")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := out.String(); strings.Contains(got, "trace_id") || !strings.Contains(got, `"span":"`) {
+		t.Errorf("invalid log output: %s", got)
+	}
+}
+
+func TestTraceSpan(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	ended := false
+	type spanKey struct{}
+	start := func(r *http.Request) (context.Context, string, string, func()) {
+		return context.WithValue(r.Context(), spanKey{}, true), "trace", "span", func() { ended = true }
+	}
+	var inSpan bool
+	handler := Trace(log, TraceSpan(start))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inSpan, _ = r.Context().Value(spanKey{}).(bool)
+		rz.FromCtx(r.Context()).Info("handled")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !inSpan || !ended {
+		t.Errorf("span not used: in span = %v, ended = %v", inSpan, ended)
+	}
+	if got, want := out.String(), `{"level":"info","trace_id":"trace","span_id":"span","message":"handled"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
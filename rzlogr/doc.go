@@ -0,0 +1,6 @@
+// Package rzlogr provides a logr.LogSink logging with a rz Logger, so rz can be used in Kubernetes
+// controllers and the other libraries logging with logr.
+//
+//    logger := rz.New(rz.Writer(os.Stderr))
+//    ctrl.SetLogger(rzlogr.NewLogger(logger))
+package rzlogr
@@ -0,0 +1,134 @@
+package rzlogr
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/skerkour/rz"
+)
+
+// DefaultNameFieldName is the default field name of the name of the loggers (see
+// logr.Logger.WithName).
+const DefaultNameFieldName = "logger"
+
+// LogSink is a logr.LogSink logging with a rz Logger. The V-levels are mapped to the rz levels
+// (see Level), the values of WithValues are added to the context of the logger, and the names of
+// WithName are joined with "/" in the name field.
+type LogSink struct {
+	logger        rz.Logger
+	name          string
+	nameFieldName string
+}
+
+var _ logr.LogSink = (*LogSink)(nil)
+
+// SinkOption is used to configure a LogSink.
+type SinkOption func(s *LogSink)
+
+// NameField updates the field name of the name of the loggers. An empty string disables the
+// field.
+func NameField(fieldName string) SinkOption {
+	return func(s *LogSink) {
+		s.nameFieldName = fieldName
+	}
+}
+
+// NewLogSink creates a LogSink logging with logger.
+func NewLogSink(logger rz.Logger, options ...SinkOption) *LogSink {
+	s := &LogSink{
+		logger:        logger,
+		nameFieldName: DefaultNameFieldName,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// NewLogger creates a logr.Logger logging with logger.
+func NewLogger(logger rz.Logger, options ...SinkOption) logr.Logger {
+	return logr.New(NewLogSink(logger, options...))
+}
+
+// Level maps a logr V-level to a rz level: V(0) is rz.InfoLevel, and the greater verbosities
+// rz.DebugLevel.
+func Level(level int) rz.LogLevel {
+	if level <= 0 {
+		return rz.InfoLevel
+	}
+	return rz.DebugLevel
+}
+
+// Init implements the logr.LogSink interface.
+func (s *LogSink) Init(info logr.RuntimeInfo) {}
+
+// Enabled implements the logr.LogSink interface.
+func (s *LogSink) Enabled(level int) bool {
+	return Level(level) >= s.logger.GetLevel()
+}
+
+// Info implements the logr.LogSink interface.
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.logger.LogWithLevel(Level(level), msg, s.fields(nil, keysAndValues)...)
+}
+
+// Error implements the logr.LogSink interface.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := make([]rz.Field, 0, 1+len(keysAndValues)/2)
+	if err != nil {
+		fields = append(fields, rz.Err(err))
+	}
+	s.logger.Error(msg, s.fields(fields, keysAndValues)...)
+}
+
+func (s *LogSink) fields(fields []rz.Field, keysAndValues []interface{}) []rz.Field {
+	if s.name != "" && s.nameFieldName != "" {
+		fields = append(fields, rz.String(s.nameFieldName, s.name))
+	}
+	return appendFields(fields, keysAndValues)
+}
+
+// WithValues implements the logr.LogSink interface.
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	ret := *s
+	ret.logger = s.logger.With(rz.Fields(appendFields(nil, keysAndValues)...))
+	return &ret
+}
+
+// WithName implements the logr.LogSink interface.
+func (s *LogSink) WithName(name string) logr.LogSink {
+	ret := *s
+	if ret.name == "" {
+		ret.name = name
+	} else {
+		ret.name += "/" + name
+	}
+	return &ret
+}
+
+// appendFields appends the fields of the key/value pairs to fields. Keys which are not strings are
+// formatted with fmt, and a missing value is logged as "<no-value>", as logr's funcr does.
+func appendFields(fields []rz.Field, keysAndValues []interface{}) []rz.Field {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprintf("<non-string-key: %v>", keysAndValues[i])
+		}
+		var value interface{} = "<no-value>"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		if marshaler, ok := value.(logr.Marshaler); ok {
+			value = marshaler.MarshalLog()
+		}
+		switch v := value.(type) {
+		case string:
+			fields = append(fields, rz.String(key, v))
+		case error:
+			fields = append(fields, rz.Error(key, v))
+		default:
+			fields = append(fields, rz.Any(key, v))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,48 @@
+package rzlogr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+type secret string
+
+func (s secret) MarshalLog() interface{} {
+	return "***"
+}
+
+func TestLogSink(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewLogger(rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)), rz.Level(rz.InfoLevel)))
+
+	logger.Info("started", "workers", 4, "token", secret("abc"))
+	logger.WithName("controller").WithName("pod").WithValues("namespace", "default").
+		Error(errors.New("boom"), "reconcile failed", "attempt", 2, 42, "odd")
+	logger.V(1).Info("hidden")
+
+	want := `{"level":"info","workers":4,"token":"***","message":"started"}` + "\n" +
+		`{"level":"error","namespace":"default","error":"boom","logger":"controller/pod","attempt":2,"<non-string-key: 42>":"odd","message":"reconcile failed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	logger = NewLogger(rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false))), NameField(""))
+	logger.WithName("ignored").V(2).Info("debug", "missing")
+	if got, want := out.String(), `{"level":"debug","missing":"<no-value>","message":"debug"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	if Level(0) != rz.InfoLevel || Level(1) != rz.DebugLevel || Level(5) != rz.DebugLevel {
+		t.Error("invalid level mapping")
+	}
+	sink := NewLogSink(rz.New(rz.Level(rz.InfoLevel)))
+	if !sink.Enabled(0) || sink.Enabled(1) {
+		t.Error("invalid enabled levels at info level")
+	}
+}
@@ -0,0 +1,11 @@
+// Package rzloki provides a writer batching rz events and pushing them to Grafana Loki, with
+// stream labels derived from event fields.
+//
+//    w := rzloki.NewWriter("http://localhost:3100", rzloki.Label("service", "service"),
+//        rzloki.StaticLabels(map[string]string{"env": "prod"}))
+//    logger := rz.New(rz.Writer(w))
+//    defer w.Close()
+//
+// Events are pushed as is, as the lines of the streams; LogQL's json parser extracts their
+// fields.
+package rzloki
@@ -0,0 +1,506 @@
+package rzloki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// PushPath is the path of the push endpoint of the Loki API.
+const PushPath = "/loki/api/v1/push"
+
+// ErrClosed is returned by the writes of a closed Writer.
+var ErrClosed = errors.New("rzloki: writer is closed")
+
+// Writer is a rz.LevelWriter batching events and pushing them to Loki. It's safe for concurrent
+// use.
+//
+// A batch is pushed when it reaches BatchSize events, every FlushInterval, and on Flush and Close.
+// The batches are pushed in order by a background goroutine, so writes never wait for Loki, which
+// rejects the entries older than the last one of their stream. Failed pushes are retried with an
+// exponential backoff when the error is temporary (network errors, 429 and 5xx responses); events
+// of a batch still failing are dropped. Flush returns the error of its push; the errors of the
+// other pushes are passed to rz.ErrorHandler.
+type Writer struct {
+	endpoint      string
+	client        *http.Client
+	tenant        string
+	user          string
+	password      string
+	labels        map[string]string // field -> label
+	staticLabels  map[string]string
+	idField       string
+	batchSize     int
+	flushInterval time.Duration
+	maxAttempts   int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	queueSize     int
+	now           func() time.Time
+
+	mu      sync.Mutex
+	batch   []entry
+	queue   []pushItem // batches waiting to be pushed, in order
+	closed  bool
+	lastErr error
+	wake    chan struct{}
+	ctx     context.Context // context of the pushes, canceled by CloseContext
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// pushItem is a batch waiting to be pushed. done, if not nil, receives the error of the push.
+type pushItem struct {
+	batch []entry
+	done  chan error
+}
+
+type entry struct {
+	labels string // canonical JSON object of the labels, the key of the stream
+	time   time.Time
+	line   string
+}
+
+// WriterOption is used to configure a Writer.
+type WriterOption func(w *Writer)
+
+// HTTPClient updates the client used to push the events. Default: a client with a 10 seconds
+// timeout. Its Transport can be any http.RoundTripper, e.g. an HTTP/3 one for lossy links:
+//
+//    rzloki.HTTPClient(&http.Client{Transport: &http3.RoundTripper{}}) // github.com/quic-go/quic-go/http3
+//
+// The pushes are POST requests, never sent as 0-RTT early data, even if the transport supports it.
+func HTTPClient(client *http.Client) WriterOption {
+	return func(w *Writer) {
+		w.client = client
+	}
+}
+
+// Tenant updates the tenant of the events (X-Scope-OrgID header), for multi-tenant servers.
+func Tenant(tenant string) WriterOption {
+	return func(w *Writer) {
+		w.tenant = tenant
+	}
+}
+
+// BasicAuth updates the user and password used to authenticate the pushes.
+func BasicAuth(user, password string) WriterOption {
+	return func(w *Writer) {
+		w.user = user
+		w.password = password
+	}
+}
+
+// Label adds the label of the streams holding the value of the event field. By default, the level
+// field (with rz's default name) is mapped to the "level" label; an empty label removes the
+// mapping of field. As each set of label values is a stream, fields with many values (ids...)
+// must not be mapped.
+func Label(field, label string) WriterOption {
+	return func(w *Writer) {
+		if label == "" {
+			delete(w.labels, field)
+		} else {
+			w.labels[field] = label
+		}
+	}
+}
+
+// StaticLabels adds labels with fixed values to all the streams.
+func StaticLabels(labels map[string]string) WriterOption {
+	return func(w *Writer) {
+		for label, value := range labels {
+			w.staticLabels[label] = value
+		}
+	}
+}
+
+// EventID uses the time of the event IDs stamped by rz.EventIDs in field as the timestamp of the
+// entries, instead of the time of the write. As Loki ignores an entry with the same stream,
+// timestamp and line as an existing one, the events pushed again by at-least-once deliveries
+// (retries, relays...) are deduplicated. Events without an ID are timestamped when written.
+func EventID(field string) WriterOption {
+	return func(w *Writer) {
+		w.idField = field
+	}
+}
+
+// BatchSize updates the maximum number of events of a push. Default: 1000.
+func BatchSize(size int) WriterOption {
+	return func(w *Writer) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// FlushInterval updates the interval of the periodic pushes. Default: 5 seconds. A zero interval
+// disables them.
+func FlushInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.flushInterval = interval
+	}
+}
+
+// Retry updates the maximum number of attempts of a push, and the bounds of the backoff between
+// attempts, doubled after each attempt. Default: 5 attempts, from 500ms to 30s.
+func Retry(maxAttempts int, minBackoff, maxBackoff time.Duration) WriterOption {
+	return func(w *Writer) {
+		if maxAttempts > 0 {
+			w.maxAttempts = maxAttempts
+		}
+		w.minBackoff = minBackoff
+		w.maxBackoff = maxBackoff
+	}
+}
+
+// QueueSize updates the maximum number of full batches waiting to be pushed. Beyond it, the oldest
+// batch is dropped and the drop is passed to rz.ErrorHandler. Default: 10.
+func QueueSize(size int) WriterOption {
+	return func(w *Writer) {
+		if size > 0 {
+			w.queueSize = size
+		}
+	}
+}
+
+// NewWriter creates a Writer pushing events to the Loki server at endpoint (e.g.
+// "http://localhost:3100").
+func NewWriter(endpoint string, options ...WriterOption) *Writer {
+	w := &Writer{
+		endpoint:      strings.TrimSuffix(endpoint, "/"),
+		client:        &http.Client{Timeout: 10 * time.Second},
+		labels:        map[string]string{rz.DefaultLevelFieldName: "level"},
+		staticLabels:  map[string]string{},
+		batchSize:     1000,
+		flushInterval: 5 * time.Second,
+		maxAttempts:   5,
+		minBackoff:    500 * time.Millisecond,
+		maxBackoff:    30 * time.Second,
+		queueSize:     10,
+		now:           time.Now,
+		wake:          make(chan struct{}, 1),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// run pushes the queued batches in order, and queues the batch every flush interval. It returns
+// once the writer is closed and the queue is empty.
+func (w *Writer) run() {
+	defer w.wg.Done()
+	var tick <-chan time.Time
+	if w.flushInterval > 0 {
+		ticker := time.NewTicker(w.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-w.wake:
+		case <-tick:
+			w.mu.Lock()
+			w.enqueue(w.take(), nil)
+			w.mu.Unlock()
+		}
+		for {
+			w.mu.Lock()
+			if len(w.queue) == 0 {
+				closed := w.closed
+				w.mu.Unlock()
+				if closed {
+					return
+				}
+				break
+			}
+			item := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mu.Unlock()
+
+			err := w.push(w.ctx, item.batch)
+			if item.done != nil {
+				item.done <- err
+			} else if err != nil {
+				handleError(err)
+			}
+		}
+	}
+}
+
+// enqueue queues batch to be pushed by the background goroutine, dropping the oldest full batch if
+// the queue is full. w.mu must be held.
+func (w *Writer) enqueue(batch []entry, done chan error) {
+	if len(batch) == 0 && done == nil {
+		return
+	}
+	full := 0
+	for _, item := range w.queue {
+		if item.done == nil {
+			full++
+		}
+	}
+	if done == nil && full >= w.queueSize {
+		for i, item := range w.queue {
+			if item.done == nil {
+				w.queue = append(w.queue[:i], w.queue[i+1:]...)
+				handleError(fmt.Errorf("rzloki: push queue is full, dropped %d events", len(item.batch)))
+				break
+			}
+		}
+	}
+	w.queue = append(w.queue, pushItem{batch: batch, done: done})
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(rz.NoLevel, p)
+}
+
+// WriteLevel implements the rz.LevelWriter interface.
+func (w *Writer) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	labels, err := w.streamLabels(p)
+	if err != nil {
+		return 0, err
+	}
+
+	ts, ok := w.eventTime(p)
+	if !ok {
+		ts = w.now()
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, ErrClosed
+	}
+	w.batch = append(w.batch, entry{
+		labels: labels,
+		time:   ts,
+		line:   string(bytes.TrimRight(p, "\n")),
+	})
+	if len(w.batch) >= w.batchSize {
+		w.enqueue(w.take(), nil)
+	}
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+// eventTime returns the time of the event ID of p, if enabled.
+func (w *Writer) eventTime(p []byte) (time.Time, bool) {
+	if w.idField == "" {
+		return time.Time{}, false
+	}
+	id, ok := rz.EventID(p, w.idField)
+	if !ok {
+		return time.Time{}, false
+	}
+	return rz.ULIDTime(id)
+}
+
+// streamLabels returns the labels of the stream of the JSON encoded event p.
+func (w *Writer) streamLabels(p []byte) (string, error) {
+	labels := make(map[string]string, len(w.staticLabels)+len(w.labels))
+	for label, value := range w.staticLabels {
+		labels[label] = value
+	}
+	if len(w.labels) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(p))
+		decoder.UseNumber()
+		event := map[string]interface{}{}
+		if err := decoder.Decode(&event); err != nil {
+			return "", err
+		}
+		for field, label := range w.labels {
+			switch value := event[field].(type) {
+			case nil:
+			case string:
+				labels[label] = value
+			default:
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return "", err
+				}
+				labels[label] = string(encoded)
+			}
+		}
+	}
+	// encoding/json sorts the keys, so identical label sets have the same encoding
+	encoded, err := json.Marshal(labels)
+	return string(encoded), err
+}
+
+// take returns the batch and resets it. w.mu must be held.
+func (w *Writer) take() []entry {
+	batch := w.batch
+	w.batch = nil
+	return batch
+}
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+type stream struct {
+	Stream json.RawMessage `json:"stream"`
+	Values [][2]string     `json:"values"`
+}
+
+// push pushes batch, with retries. It's only called by the background goroutine.
+func (w *Writer) push(ctx context.Context, batch []entry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	streams := []stream{}
+	indexes := map[string]int{}
+	for _, e := range batch {
+		i, ok := indexes[e.labels]
+		if !ok {
+			i = len(streams)
+			indexes[e.labels] = i
+			streams = append(streams, stream{Stream: json.RawMessage(e.labels)})
+		}
+		streams[i].Values = append(streams[i].Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), e.line})
+	}
+	body, err := json.Marshal(pushRequest{Streams: streams})
+	if err != nil {
+		return err
+	}
+
+	backoff := w.minBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.send(ctx, body)
+		w.mu.Lock()
+		w.lastErr = err
+		w.mu.Unlock()
+		if err == nil || !retry || attempt >= w.maxAttempts {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		if backoff *= 2; backoff > w.maxBackoff {
+			backoff = w.maxBackoff
+		}
+	}
+}
+
+// send sends a push request, and returns whether it can be retried if it failed.
+func (w *Writer) send(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint+PushPath, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.tenant != "" {
+		req.Header.Set("X-Scope-OrgID", w.tenant)
+	}
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, res.Body)
+		return false, nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	err = fmt.Errorf("rzloki: push failed: %s: %s", res.Status, strings.TrimSpace(string(message)))
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500, err
+}
+
+// Flush implements the rz.Flusher interface: it queues the batch and waits for the queued batches
+// to be pushed.
+func (w *Writer) Flush() error {
+	done := make(chan error, 1)
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.enqueue(w.take(), done)
+	w.mu.Unlock()
+	return <-done
+}
+
+// Close pushes the queued batches and the batch, and stops the background goroutine.
+func (w *Writer) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext implements the rz.ContextCloser interface: the pushes stop when ctx is done, and
+// the remaining batches are dropped.
+func (w *Writer) CloseContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.enqueue(w.take(), done)
+	w.mu.Unlock()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	w.cancel()
+	w.wg.Wait()
+	return err
+}
+
+// CheckHealth implements the rz.HealthChecker interface: it returns the error of the last push.
+func (w *Writer) CheckHealth() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// DescribeConfig implements the rz.ConfigDescriber interface.
+func (w *Writer) DescribeConfig() string {
+	labels := make([]string, 0, len(w.labels)+len(w.staticLabels))
+	for field, label := range w.labels {
+		labels = append(labels, label+"="+field)
+	}
+	for label, value := range w.staticLabels {
+		labels = append(labels, label+"="+strconv.Quote(value))
+	}
+	sort.Strings(labels)
+	return "loki(" + strconv.Quote(w.endpoint) + ", " + strings.Join(labels, ",") + ")"
+}
+
+func handleError(err error) {
+	if rz.ErrorHandler != nil {
+		rz.ErrorHandler(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "rzloki: could not push events: %v\n", err)
+	}
+}
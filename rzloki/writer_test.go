@@ -0,0 +1,198 @@
+package rzloki
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+type testServer struct {
+	mu       sync.Mutex
+	bodies   []string
+	tenants  []string
+	statuses []int // statuses of the next responses
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != PushPath {
+		http.NotFound(w, r)
+		return
+	}
+	s.bodies = append(s.bodies, string(body))
+	s.tenants = append(s.tenants, r.Header.Get("X-Scope-OrgID"))
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		http.Error(w, "error", status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestWriter(t *testing.T) {
+	handler := &testServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, Tenant("team"), BatchSize(3), FlushInterval(0), Label("service", "service"),
+		StaticLabels(map[string]string{"env": "prod"}))
+	ts := time.Unix(0, 1000)
+	w.now = func() time.Time { return ts }
+	log := rz.New(rz.Writer(w), rz.Fields(rz.Timestamp(false)))
+	log.Info("hello", rz.String("service", "api"))
+	log.Info("world", rz.String("service", "api"))
+	log.Error("failed")
+	log.Warn("on close")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`{"streams":[{"stream":{"env":"prod","level":"info","service":"api"},"values":[` +
+			`["1000","{\"level\":\"info\",\"service\":\"api\",\"message\":\"hello\"}"],` +
+			`["1000","{\"level\":\"info\",\"service\":\"api\",\"message\":\"world\"}"]]},` +
+			`{"stream":{"env":"prod","level":"error"},"values":[["1000","{\"level\":\"error\",\"message\":\"failed\"}"]]}]}`,
+		`{"streams":[{"stream":{"env":"prod","level":"warning"},"values":[["1000","{\"level\":\"warning\",\"message\":\"on close\"}"]]}]}`,
+	}
+	if len(handler.bodies) != len(want) {
+		t.Fatalf("invalid pushes: %v", handler.bodies)
+	}
+	for i := range want {
+		if handler.bodies[i] != want[i] {
+			t.Errorf("push %d: got %s, want %s", i, handler.bodies[i], want[i])
+		}
+		if handler.tenants[i] != "team" {
+			t.Errorf("push %d: invalid tenant %q", i, handler.tenants[i])
+		}
+	}
+}
+
+func TestWriterEventID(t *testing.T) {
+	handler := &testServer{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, FlushInterval(0), Label(rz.DefaultLevelFieldName, ""), EventID("id"))
+	defer w.Close()
+	w.now = func() time.Time { return time.Unix(0, 1000) }
+	w.Write([]byte(`{"id":"01ARYZ6S410000000000000000","message":"hello"}`))
+	w.Write([]byte(`{"message":"no id"}`))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"streams":[{"stream":{},"values":[` +
+		`["1469918176385000000","{\"id\":\"01ARYZ6S410000000000000000\",\"message\":\"hello\"}"],` +
+		`["1000","{\"message\":\"no id\"}"]]}]}`
+	if len(handler.bodies) != 1 || handler.bodies[0] != want {
+		t.Errorf("invalid pushes:\ngot:  %v\nwant: %v", handler.bodies, want)
+	}
+}
+
+func TestWriterRetry(t *testing.T) {
+	handler := &testServer{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	w := NewWriter(server.URL, FlushInterval(0), Retry(3, time.Millisecond, time.Millisecond))
+	defer w.Close()
+	log := rz.New(rz.Writer(w))
+	log.Info("hello")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(handler.bodies) != 3 {
+		t.Errorf("got %d attempts, want 3", len(handler.bodies))
+	}
+
+	handler.statuses = []int{http.StatusBadRequest}
+	log.Info("invalid")
+	if err := w.Flush(); err == nil {
+		t.Error("the push must fail")
+	}
+	if len(handler.bodies) != 4 {
+		t.Errorf("client errors must not be retried, got %d attempts", len(handler.bodies)-3)
+	}
+	if w.CheckHealth() == nil {
+		t.Error("CheckHealth must return the error of the last push")
+	}
+}
+
+func TestWriterBackgroundPush(t *testing.T) {
+	release := make(chan struct{})
+	handler := &testServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	w := NewWriter(server.URL, BatchSize(1), FlushInterval(0), Label(rz.DefaultLevelFieldName, ""))
+	w.now = func() time.Time { return time.Unix(0, 1000) }
+	start := time.Now()
+	for _, message := range []string{"1", "2", "3"} {
+		if _, err := w.Write([]byte(`{"message":"` + message + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("writes waited for the pushes: %v", elapsed)
+	}
+
+	close(release)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(handler.bodies) != 3 {
+		t.Fatalf("invalid pushes: %v", handler.bodies)
+	}
+	for i, message := range []string{"1", "2", "3"} {
+		want := `{"streams":[{"stream":{},"values":[["1000","{\"message\":\"` + message + `\"}"]]}]}`
+		if handler.bodies[i] != want {
+			t.Errorf("push %d: got %s, want %s", i, handler.bodies[i], want)
+		}
+	}
+}
+
+func TestWriterQueueSize(t *testing.T) {
+	release := make(chan struct{})
+	handler := &testServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var dropped []error
+	rz.ErrorHandler = func(err error) { dropped = append(dropped, err) }
+	defer func() { rz.ErrorHandler = nil }()
+
+	w := NewWriter(server.URL, BatchSize(1), QueueSize(1), FlushInterval(0))
+	w.Write([]byte(`{"message":"1"}`))
+	// the first batch is being pushed once the queue is empty
+	for {
+		w.mu.Lock()
+		n := len(w.queue)
+		w.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	w.Write([]byte(`{"message":"2"}`))
+	w.Write([]byte(`{"message":"3"}`))
+	close(release)
+	w.Close()
+
+	if len(handler.bodies) != 2 || len(dropped) != 1 {
+		t.Errorf("invalid pushes %v and drops %v", handler.bodies, dropped)
+	}
+}
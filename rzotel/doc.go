@@ -0,0 +1,10 @@
+// Package rzotel provides a hook correlating the events with OpenTelemetry traces: the trace and
+// span IDs of the span in the context of the events (see rz.Ctx) are added as fields.
+//
+//    logger := rz.New(rz.Hooks(rzotel.TraceHook()))
+//    ctx, span := tracer.Start(ctx, "checkout")
+//    logger.Info("order created", rz.Ctx(ctx))
+//    // {"level":"info","trace_id":"4bf9...","span_id":"00f0...","message":"order created"}
+//
+// It's a separate module, so the rz module doesn't depend on OpenTelemetry.
+package rzotel
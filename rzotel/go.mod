@@ -0,0 +1,12 @@
+module github.com/skerkour/rz/rzotel
+
+go 1.22
+
+require (
+	github.com/skerkour/rz v0.0.0
+	go.opentelemetry.io/otel/trace v1.32.0
+)
+
+require go.opentelemetry.io/otel v1.32.0 // indirect
+
+replace github.com/skerkour/rz => ../
@@ -0,0 +1,68 @@
+package rzotel
+
+import (
+	"github.com/skerkour/rz"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type traceHook struct {
+	traceIDField string
+	spanIDField  string
+	sampledField string
+}
+
+// HookOption is used to configure the hook created by TraceHook.
+type HookOption func(h *traceHook)
+
+// FieldNames updates the field names of the trace and span IDs. Default: "trace_id" and
+// "span_id", as rzhttp.Trace. An empty string disables the field.
+func FieldNames(traceIDFieldName, spanIDFieldName string) HookOption {
+	return func(h *traceHook) {
+		h.traceIDField = traceIDFieldName
+		h.spanIDField = spanIDFieldName
+	}
+}
+
+// Sampled adds a boolean field telling whether the span is sampled, i.e. whether the trace is
+// available in the tracing backend.
+func Sampled(sampledFieldName string) HookOption {
+	return func(h *traceHook) {
+		h.sampledField = sampledFieldName
+	}
+}
+
+// TraceHook returns a hook adding the trace and span IDs of the span in the context of the
+// events, if it's valid. Events without context (see rz.Ctx) are left as is. With
+// rz.HookRegistry, it's registered with rz.HookPriorityEnrich.
+func TraceHook(options ...HookOption) rz.LogHook {
+	h := &traceHook{
+		traceIDField: "trace_id",
+		spanIDField:  "span_id",
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+// Run implements the rz.LogHook interface.
+func (h *traceHook) Run(e *rz.Event, level rz.LogLevel, message string) {
+	sc := trace.SpanContextFromContext(e.Context())
+	if !sc.IsValid() {
+		return
+	}
+	if h.traceIDField != "" {
+		e.Append(rz.String(h.traceIDField, sc.TraceID().String()))
+	}
+	if h.spanIDField != "" {
+		e.Append(rz.String(h.spanIDField, sc.SpanID().String()))
+	}
+	if h.sampledField != "" {
+		e.Append(rz.Bool(h.sampledField, sc.IsSampled()))
+	}
+}
+
+// DescribeConfig implements the rz.ConfigDescriber interface.
+func (h *traceHook) DescribeConfig() string {
+	return "otel_trace(" + h.traceIDField + ", " + h.spanIDField + ")"
+}
@@ -0,0 +1,33 @@
+package rzotel
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/skerkour/rz"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)), rz.Hooks(TraceHook(Sampled("trace_sampled"))))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	log.Info("traced", rz.Ctx(ctx))
+	log.Info("untraced", rz.Ctx(context.Background()))
+	log.Info("no context")
+
+	want := `{"level":"info","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_sampled":true,"message":"traced"}` + "\n" +
+		`{"level":"info","message":"untraced"}` + "\n" +
+		`{"level":"info","message":"no context"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
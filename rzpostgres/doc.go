@@ -0,0 +1,18 @@
+// Package rzpostgres provides a writer batching rz audit events and copying them into a
+// PostgreSQL table with COPY, spooling the batches to a local file while the database is
+// unavailable.
+//
+//    CREATE TABLE audit (
+//        timestamp timestamptz NOT NULL,
+//        level text NOT NULL,
+//        message text NOT NULL,
+//        fields jsonb NOT NULL
+//    );
+//
+//    db, _ := sql.Open("postgres", dsn) // github.com/lib/pq
+//    w := rzpostgres.NewWriter(rzpostgres.SQLCopier(db), "audit", rzpostgres.Spool("/var/spool/app/audit"))
+//    logger := rz.New(rz.Writer(w))
+//    defer w.Close()
+//
+// Drivers without COPY support in database/sql (e.g. pgx) are used with a CopierFunc.
+package rzpostgres
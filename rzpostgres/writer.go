@@ -0,0 +1,468 @@
+package rzpostgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// SpoolFileName is the name of the spool file in the spool directory.
+const SpoolFileName = "rzpostgres.spool"
+
+// ErrClosed is returned by the writes of a closed Writer.
+var ErrClosed = errors.New("rzpostgres: writer is closed")
+
+// Copier copies rows into the columns of a PostgreSQL table, all or none of them.
+type Copier interface {
+	Copy(ctx context.Context, table string, columns []string, rows [][]interface{}) error
+}
+
+// CopierFunc is an adapter to use a function as a Copier, e.g. with pgx:
+//
+//    rzpostgres.CopierFunc(func(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+//        _, err := conn.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+//        return err
+//    })
+type CopierFunc func(ctx context.Context, table string, columns []string, rows [][]interface{}) error
+
+// Copy implements the Copier interface.
+func (f CopierFunc) Copy(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+	return f(ctx, table, columns, rows)
+}
+
+// SQLCopier returns a Copier running COPY FROM STDIN in a transaction of db, for the drivers
+// supporting it through prepared statements, like github.com/lib/pq.
+func SQLCopier(db *sql.DB) Copier {
+	return CopierFunc(func(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		stmt, err := tx.PrepareContext(ctx, copyStatement(table, columns))
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if _, err = stmt.ExecContext(ctx, row...); err != nil {
+				stmt.Close()
+				return err
+			}
+		}
+		// the final empty exec flushes the copied rows
+		if _, err = stmt.ExecContext(ctx); err != nil {
+			stmt.Close()
+			return err
+		}
+		if err = stmt.Close(); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// copyStatement returns the COPY FROM STDIN statement for the columns of table. table can be
+// qualified with its schema.
+func copyStatement(table string, columns []string) string {
+	parts := strings.Split(table, ".")
+	for i := range parts {
+		parts[i] = quoteIdentifier(parts[i])
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	return "COPY " + strings.Join(parts, ".") + " (" + strings.Join(quoted, ", ") + ") FROM STDIN"
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Writer is a rz.LevelWriter batching events and copying them into a PostgreSQL table. It's safe
+// for concurrent use.
+//
+// A batch is copied when it reaches BatchSize events, every FlushInterval, and on Flush and Close.
+// If a copy fails and a spool directory is set, the events of the batch are appended to the spool
+// file instead of being dropped, and the error is passed to rz.ErrorHandler; the spooled events
+// are copied first on the next flush, so the order of the events is kept. Without spool, or if the
+// spool can't be written, the write adding the last event of the batch returns the error (errors
+// of periodic copies are passed to rz.ErrorHandler) and its events are dropped.
+type Writer struct {
+	copier        Copier
+	table         string
+	columns       map[string]string
+	extraColumn   string
+	spoolDir      string
+	batchSize     int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	batch   [][]byte
+	lastErr error
+	spooled bool
+	closed  bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// WriterOption is used to configure a Writer.
+type WriterOption func(w *Writer)
+
+// Column maps the event field to column. The timestamp, level and message fields (with rz's
+// default names) are mapped to the columns of the same name by default; an empty column removes
+// the mapping of field.
+func Column(field, column string) WriterOption {
+	return func(w *Writer) {
+		if column == "" {
+			delete(w.columns, field)
+		} else {
+			w.columns[field] = column
+		}
+	}
+}
+
+// Extra updates the json or jsonb column holding the fields not mapped to a column. Default:
+// "fields". An empty column drops the extra fields.
+func Extra(column string) WriterOption {
+	return func(w *Writer) {
+		w.extraColumn = column
+	}
+}
+
+// Spool enables the failover of the failed batches to a spool file in dir, created if needed.
+func Spool(dir string) WriterOption {
+	return func(w *Writer) {
+		w.spoolDir = dir
+	}
+}
+
+// BatchSize updates the maximum number of events of a copy. Default: 1000.
+func BatchSize(size int) WriterOption {
+	return func(w *Writer) {
+		if size > 0 {
+			w.batchSize = size
+		}
+	}
+}
+
+// FlushInterval updates the interval of the periodic copies. Default: 5 seconds. A zero interval
+// disables them.
+func FlushInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.flushInterval = interval
+	}
+}
+
+// NewWriter creates a Writer copying events into table with copier.
+func NewWriter(copier Copier, table string, options ...WriterOption) *Writer {
+	w := &Writer{
+		copier: copier,
+		table:  table,
+		columns: map[string]string{
+			rz.DefaultTimestampFieldName: "timestamp",
+			rz.DefaultLevelFieldName:     "level",
+			rz.DefaultMessageFieldName:   "message",
+		},
+		extraColumn:   "fields",
+		batchSize:     1000,
+		flushInterval: 5 * time.Second,
+		done:          make(chan struct{}),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.spoolDir != "" {
+		if info, err := os.Stat(w.spoolPath()); err == nil && info.Size() > 0 {
+			w.spooled = true
+		}
+	}
+	if w.flushInterval > 0 {
+		w.wg.Add(1)
+		go w.run()
+	}
+	return w
+}
+
+func (w *Writer) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				handleError(err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(rz.NoLevel, p)
+}
+
+// WriteLevel implements the rz.LevelWriter interface.
+func (w *Writer) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	event := bytes.TrimSpace(p)
+	if len(event) == 0 || event[0] != '{' || !json.Valid(event) {
+		return 0, errors.New("rzpostgres: invalid event")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	w.batch = append(w.batch, append([]byte(nil), event...))
+	if len(w.batch) >= w.batchSize {
+		if err := w.copy(context.Background()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// columnNames returns the sorted columns of the table.
+func (w *Writer) columnNames() []string {
+	columns := make([]string, 0, len(w.columns)+1)
+	for _, column := range w.columns {
+		columns = append(columns, column)
+	}
+	if w.extraColumn != "" {
+		columns = append(columns, w.extraColumn)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// rows converts the JSON encoded events to rows of the columns. Values of the columns are passed
+// as text, nested objects and arrays as JSON; missing fields are NULL.
+func (w *Writer) rows(columns []string, events [][]byte) ([][]interface{}, error) {
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		index[column] = i
+	}
+	rows := make([][]interface{}, 0, len(events))
+	for _, p := range events {
+		decoder := json.NewDecoder(bytes.NewReader(p))
+		decoder.UseNumber()
+		event := map[string]json.RawMessage{}
+		if err := decoder.Decode(&event); err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(columns))
+		extra := map[string]json.RawMessage{}
+		for field, value := range event {
+			column, ok := w.columns[field]
+			if !ok {
+				extra[field] = value
+				continue
+			}
+			var s string
+			if err := json.Unmarshal(value, &s); err == nil {
+				row[index[column]] = s
+			} else if !bytes.Equal(value, []byte("null")) {
+				row[index[column]] = string(value)
+			}
+		}
+		if w.extraColumn != "" {
+			encoded, err := json.Marshal(extra)
+			if err != nil {
+				return nil, err
+			}
+			row[index[w.extraColumn]] = string(encoded)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// copy copies the spooled events, then the batch. It must be called with the lock held.
+func (w *Writer) copy(ctx context.Context) error {
+	if len(w.batch) == 0 && !w.spooled {
+		return nil
+	}
+	batch := w.batch
+	w.batch = nil
+
+	err := w.replay(ctx)
+	if err == nil && len(batch) > 0 {
+		err = w.copyEvents(ctx, batch)
+	}
+	w.lastErr = err
+	if err == nil || len(batch) == 0 {
+		return err
+	}
+	if w.spoolDir == "" {
+		return err
+	}
+	if serr := w.spool(batch); serr != nil {
+		return fmt.Errorf("rzpostgres: copy failed: %v, and spooling failed: %w", err, serr)
+	}
+	handleError(fmt.Errorf("rzpostgres: copy failed, %d events spooled: %w", len(batch), err))
+	return nil
+}
+
+func (w *Writer) copyEvents(ctx context.Context, events [][]byte) error {
+	columns := w.columnNames()
+	rows, err := w.rows(columns, events)
+	if err != nil {
+		return err
+	}
+	return w.copier.Copy(ctx, w.table, columns, rows)
+}
+
+func (w *Writer) spoolPath() string {
+	return filepath.Join(w.spoolDir, SpoolFileName)
+}
+
+// spool appends events to the spool file and syncs it.
+func (w *Writer) spool(events [][]byte) error {
+	if err := os.MkdirAll(w.spoolDir, 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.spoolPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(file)
+	for _, event := range events {
+		buf.Write(event)
+		buf.WriteByte('\n')
+	}
+	err = buf.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	w.spooled = true
+	return nil
+}
+
+// replay copies the spooled events by batches, and removes the spool file once they're all
+// copied. The batches already copied are removed from the file when a copy fails.
+func (w *Writer) replay(ctx context.Context) error {
+	if !w.spooled {
+		return nil
+	}
+	data, err := os.ReadFile(w.spoolPath())
+	if errors.Is(err, os.ErrNotExist) {
+		w.spooled = false
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	events := [][]byte{}
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		// a truncated last line (crash while spooling) is dropped
+		if len(line) > 0 && json.Valid(line) {
+			events = append(events, line)
+		}
+	}
+	for len(events) > 0 {
+		n := w.batchSize
+		if n > len(events) {
+			n = len(events)
+		}
+		if err = w.copyEvents(ctx, events[:n]); err != nil {
+			break
+		}
+		events = events[n:]
+	}
+	if err == nil {
+		w.spooled = false
+		return os.Remove(w.spoolPath())
+	}
+
+	remaining := &bytes.Buffer{}
+	for _, event := range events {
+		remaining.Write(event)
+		remaining.WriteByte('\n')
+	}
+	tmp := w.spoolPath() + ".tmp"
+	if werr := os.WriteFile(tmp, remaining.Bytes(), 0600); werr == nil {
+		os.Rename(tmp, w.spoolPath())
+	}
+	return err
+}
+
+// Flush implements the rz.Flusher interface: it copies the spooled and batched events.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.copy(context.Background())
+}
+
+// Close copies the spooled and batched events and stops the periodic copies.
+func (w *Writer) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext implements the rz.ContextCloser interface: the final copy is bounded by ctx.
+func (w *Writer) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	err := w.copy(ctx)
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+// CheckHealth implements the rz.HealthChecker interface: it returns the error of the last copy,
+// nil if it succeeded.
+func (w *Writer) CheckHealth() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// DescribeConfig implements the rz.ConfigDescriber interface.
+func (w *Writer) DescribeConfig() string {
+	columns := make([]string, 0, len(w.columns))
+	for field, column := range w.columns {
+		columns = append(columns, field+"="+column)
+	}
+	sort.Strings(columns)
+	config := "postgres(" + strconv.Quote(w.table) + ", " + strings.Join(columns, ",")
+	if w.spoolDir != "" {
+		config += ", spool=" + strconv.Quote(w.spoolDir)
+	}
+	return config + ")"
+}
+
+func handleError(err error) {
+	if rz.ErrorHandler != nil {
+		rz.ErrorHandler(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "rzpostgres: %v\n", err)
+	}
+}
@@ -0,0 +1,142 @@
+package rzpostgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+type testCopier struct {
+	mu      sync.Mutex
+	fail    bool
+	columns []string
+	copies  [][][]interface{}
+}
+
+func (c *testCopier) Copy(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail {
+		return errors.New("connection refused")
+	}
+	c.columns = columns
+	c.copies = append(c.copies, rows)
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	copier := &testCopier{}
+	w := NewWriter(copier, "audit", BatchSize(2), FlushInterval(0), Column("user_id", "user_id"))
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	log := rz.New(rz.Writer(w), rz.TimestampFunc(func() time.Time { return ts }))
+	log.Info("login", rz.Int("user_id", 42), rz.String("ip", "10.0.0.1"))
+	log.Warn("logout")
+	log.Error("on close")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantColumns := []string{"fields", "level", "message", "timestamp", "user_id"}
+	if !reflect.DeepEqual(copier.columns, wantColumns) {
+		t.Errorf("invalid columns: %v", copier.columns)
+	}
+	want := [][][]interface{}{
+		{
+			{`{"ip":"10.0.0.1"}`, "info", "login", "2020-01-02T03:04:05Z", "42"},
+			{`{}`, "warning", "logout", "2020-01-02T03:04:05Z", nil},
+		},
+		{
+			{`{}`, "error", "on close", "2020-01-02T03:04:05Z", nil},
+		},
+	}
+	if !reflect.DeepEqual(copier.copies, want) {
+		t.Errorf("invalid copies:\ngot:  %v\nwant: %v", copier.copies, want)
+	}
+	if _, err := w.Write([]byte(`{"message":"closed"}`)); err != ErrClosed {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestWriterSpool(t *testing.T) {
+	var handled []error
+	rz.ErrorHandler = func(err error) { handled = append(handled, err) }
+	defer func() { rz.ErrorHandler = nil }()
+
+	dir := filepath.Join(t.TempDir(), "spool")
+	copier := &testCopier{fail: true}
+	w := NewWriter(copier, "audit", BatchSize(2), FlushInterval(0), Spool(dir), Extra(""))
+	log := rz.New(rz.Writer(w), rz.Fields(rz.Timestamp(false)))
+	log.Info("first")
+	log.Info("second")
+	if len(handled) != 1 {
+		t.Fatalf("invalid handled errors: %v", handled)
+	}
+	log.Info("third")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, SpoolFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"info","message":"first"}` + "\n" + `{"level":"info","message":"second"}` + "\n" +
+		`{"level":"info","message":"third"}` + "\n"
+	if string(data) != want {
+		t.Errorf("invalid spool: %q", data)
+	}
+
+	// a new writer copies the spool of the previous one first
+	w2 := NewWriter(copier, "audit", BatchSize(2), FlushInterval(0), Spool(dir), Extra(""))
+	copier.fail = false
+	log = rz.New(rz.Writer(w2), rz.Fields(rz.Timestamp(false)))
+	log.Info("fourth")
+	if err := w2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var messages []interface{}
+	for _, rows := range copier.copies {
+		for _, row := range rows {
+			messages = append(messages, row[1])
+		}
+	}
+	if !reflect.DeepEqual(messages, []interface{}{"first", "second", "third", "fourth"}) {
+		t.Errorf("invalid copied messages: %v", messages)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SpoolFileName)); !os.IsNotExist(err) {
+		t.Errorf("spool is not removed: %v", err)
+	}
+}
+
+func TestWriterWithoutSpool(t *testing.T) {
+	copier := &testCopier{fail: true}
+	w := NewWriter(copier, "audit", BatchSize(1), FlushInterval(0))
+	if _, err := w.Write([]byte(`{"message":"dropped"}`)); err == nil {
+		t.Error("copy error is not returned")
+	}
+	if err := w.CheckHealth(); err == nil {
+		t.Error("writer is healthy after a failed copy")
+	}
+	copier.fail = false
+	w.Write([]byte(`{"message":"copied"}`))
+	if err := w.CheckHealth(); err != nil {
+		t.Error(err)
+	}
+	if _, err := w.Write([]byte(`"not an object"`)); err == nil {
+		t.Error("invalid event is written")
+	}
+}
+
+func TestCopyStatement(t *testing.T) {
+	got := copyStatement(`audit.events`, []string{"level", `we"ird`})
+	want := `COPY "audit"."events" ("level", "we""ird") FROM STDIN`
+	if got != want {
+		t.Errorf("invalid statement: %s", got)
+	}
+}
@@ -0,0 +1,19 @@
+// Package rzschema provides a registry of the schemas of the events emitted by an application,
+// and generators of TypeScript, JSON Schema and Protobuf definitions of them, so the consumers of
+// the logs (dashboards, alerts, ingestion pipelines) stay in sync with the producers.
+//
+//    func init() {
+//        rzschema.Register(rzschema.Schema{
+//            Name:    "http_request",
+//            Message: "request served",
+//            Fields: []rzschema.Field{
+//                {Name: "status", Type: rzschema.Int, Required: true},
+//                {Name: "latency", Type: rzschema.Float, Description: "in milliseconds"},
+//            },
+//        })
+//    }
+//
+// A small command of the producer, run with go generate, then writes the definitions:
+//
+//    rzschema.TypeScript(file, rzschema.Schemas()...)
+package rzschema
@@ -0,0 +1,350 @@
+package rzschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const generatedHeader = "// Code generated by rzschema. DO NOT EDIT.\n"
+
+// typeName converts the name of a schema or field to a type name, e.g. "http_request" to
+// "HttpRequest".
+func typeName(name string) string {
+	var b strings.Builder
+	upper := true
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			if upper {
+				c -= 'a' - 'A'
+			}
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+			if b.Len() == 0 {
+				b.WriteByte('E')
+			}
+		default:
+			upper = true
+			continue
+		}
+		upper = false
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 {
+		return "Event"
+	}
+	return b.String()
+}
+
+func validate(schemas []Schema) error {
+	names := map[string]string{}
+	for _, schema := range schemas {
+		if err := schema.validate(); err != nil {
+			return err
+		}
+		name := typeName(schema.Name)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%w: schemas %s and %s have the same type name %s", ErrInvalidSchema, other, schema.Name, name)
+		}
+		names[name] = schema.Name
+	}
+	return nil
+}
+
+// TypeScript writes a TypeScript interface for each schema, and an Event type union of them.
+func TypeScript(w io.Writer, schemas ...Schema) error {
+	if err := validate(schemas); err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(generatedHeader)
+	names := make([]string, 0, len(schemas))
+	for _, schema := range schemas {
+		name := typeName(schema.Name)
+		names = append(names, name)
+		buf.WriteByte('\n')
+		writeTSComment(buf, "", schema.Description)
+		buf.WriteString("export interface " + name + " ")
+		writeTSObject(buf, "", schema.AllFields())
+		buf.WriteString("\n")
+	}
+	if len(names) > 0 {
+		buf.WriteString("\nexport type Event = " + strings.Join(names, " | ") + ";\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeTSComment(buf *bytes.Buffer, indent, description string) {
+	if description != "" {
+		buf.WriteString(indent + "/** " + strings.ReplaceAll(description, "*/", "* /") + " */\n")
+	}
+}
+
+func writeTSObject(buf *bytes.Buffer, indent string, fields []Field) {
+	buf.WriteString("{\n")
+	for _, field := range fields {
+		writeTSComment(buf, indent+"  ", field.Description)
+		buf.WriteString(indent + "  " + tsKey(field.Name))
+		if !field.Required {
+			buf.WriteByte('?')
+		}
+		buf.WriteString(": ")
+		writeTSType(buf, indent+"  ", field)
+		buf.WriteString(";\n")
+	}
+	buf.WriteString(indent + "}")
+}
+
+func writeTSType(buf *bytes.Buffer, indent string, field Field) {
+	switch field.Type {
+	case String:
+		if len(field.Enum) == 0 {
+			buf.WriteString("string")
+			return
+		}
+		for i, value := range field.Enum {
+			if i > 0 {
+				buf.WriteString(" | ")
+			}
+			buf.WriteString(strconv.Quote(value))
+		}
+	case Int, Float:
+		buf.WriteString("number")
+	case Bool:
+		buf.WriteString("boolean")
+	case Time:
+		buf.WriteString("string")
+	case Object:
+		writeTSObject(buf, indent, field.Fields)
+	case Array:
+		items := *field.Items
+		if items.Type == String && len(items.Enum) > 1 {
+			buf.WriteByte('(')
+			writeTSType(buf, indent, items)
+			buf.WriteByte(')')
+		} else {
+			writeTSType(buf, indent, items)
+		}
+		buf.WriteString("[]")
+	default:
+		buf.WriteString("unknown")
+	}
+}
+
+// tsKey returns name as an identifier if it's valid, or as a string literal.
+func tsKey(name string) string {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || i > 0 && c >= '0' && c <= '9') {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// JSONSchema writes a JSON Schema (draft 2020-12) document defining each schema in $defs, and
+// validating the events matching any of them.
+func JSONSchema(w io.Writer, schemas ...Schema) error {
+	if err := validate(schemas); err != nil {
+		return err
+	}
+	defs := map[string]interface{}{}
+	refs := make([]interface{}, 0, len(schemas))
+	for _, schema := range schemas {
+		name := typeName(schema.Name)
+		def := jsonSchemaObject(schema.AllFields())
+		if schema.AdditionalFields {
+			delete(def, "additionalProperties")
+		}
+		def["title"] = schema.Name
+		if schema.Description != "" {
+			def["description"] = schema.Description
+		}
+		defs[name] = def
+		refs = append(refs, map[string]interface{}{"$ref": "#/$defs/" + name})
+	}
+	document := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs":   defs,
+		"anyOf":   refs,
+	}
+	encoded, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(encoded, '\n'))
+	return err
+}
+
+func jsonSchemaObject(fields []Field) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, field := range fields {
+		properties[field.Name] = jsonSchemaType(field)
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+	object := map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+func jsonSchemaType(field Field) map[string]interface{} {
+	var t map[string]interface{}
+	switch field.Type {
+	case String:
+		t = map[string]interface{}{"type": "string"}
+		if len(field.Enum) > 0 {
+			t["enum"] = field.Enum
+		}
+	case Int:
+		t = map[string]interface{}{"type": "integer"}
+	case Float:
+		t = map[string]interface{}{"type": "number"}
+	case Bool:
+		t = map[string]interface{}{"type": "boolean"}
+	case Time:
+		t = map[string]interface{}{"type": "string", "format": "date-time"}
+	case Object:
+		t = jsonSchemaObject(field.Fields)
+	case Array:
+		t = map[string]interface{}{"type": "array", "items": jsonSchemaType(*field.Items)}
+	default:
+		t = map[string]interface{}{}
+	}
+	if field.Description != "" {
+		t["description"] = field.Description
+	}
+	return t
+}
+
+// Protobuf writes a proto3 file of package pkg with a message for each schema, whose JSON mapping
+// matches the events: times are google.protobuf.Timestamp, values of any type
+// google.protobuf.Value, and objects nested messages. The field names which are not valid
+// identifiers are converted, with their original name as json_name. Arrays of arrays are not
+// supported.
+func Protobuf(w io.Writer, pkg string, schemas ...Schema) error {
+	if err := validate(schemas); err != nil {
+		return err
+	}
+	messages := &bytes.Buffer{}
+	imports := map[string]bool{}
+	for _, schema := range schemas {
+		messages.WriteByte('\n')
+		if err := writeProtoMessage(messages, imports, "", typeName(schema.Name), schema.Description, schema.AllFields()); err != nil {
+			return err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(generatedHeader + "\nsyntax = \"proto3\";\n")
+	if pkg != "" {
+		buf.WriteString("\npackage " + pkg + ";\n")
+	}
+	if imports["struct"] || imports["timestamp"] {
+		buf.WriteByte('\n')
+	}
+	if imports["struct"] {
+		buf.WriteString("import \"google/protobuf/struct.proto\";\n")
+	}
+	if imports["timestamp"] {
+		buf.WriteString("import \"google/protobuf/timestamp.proto\";\n")
+	}
+	buf.Write(messages.Bytes())
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeProtoMessage(buf *bytes.Buffer, imports map[string]bool, indent, name, description string, fields []Field) error {
+	if description != "" {
+		buf.WriteString(indent + "// " + description + "\n")
+	}
+	buf.WriteString(indent + "message " + name + " {\n")
+	names := map[string]bool{}
+	for i, field := range fields {
+		fieldName := protoFieldName(field.Name)
+		if names[fieldName] {
+			return fmt.Errorf("%w: fields of %s with the same protobuf name %s", ErrInvalidSchema, name, fieldName)
+		}
+		names[fieldName] = true
+
+		t, items := field, false
+		if field.Type == Array {
+			t, items = *field.Items, true
+			if t.Type == Array {
+				return fmt.Errorf("%w: arrays of arrays are not supported: %s.%s", ErrInvalidSchema, name, field.Name)
+			}
+		}
+		typ := protoType(t, imports)
+		if t.Type == Object {
+			typ = typeName(field.Name)
+			if items {
+				typ += "Item"
+			}
+			if err := writeProtoMessage(buf, imports, indent+"  ", typ, "", t.Fields); err != nil {
+				return err
+			}
+		}
+
+		if field.Description != "" {
+			buf.WriteString(indent + "  // " + field.Description + "\n")
+		}
+		buf.WriteString(indent + "  ")
+		if items {
+			buf.WriteString("repeated ")
+		} else if !field.Required && t.Type != Object && t.Type != Time && t.Type != Any {
+			buf.WriteString("optional ")
+		}
+		buf.WriteString(typ + " " + fieldName + " = " + strconv.Itoa(i+1))
+		if fieldName != field.Name {
+			buf.WriteString(" [json_name = " + strconv.Quote(field.Name) + "]")
+		}
+		buf.WriteString(";\n")
+	}
+	buf.WriteString(indent + "}\n")
+	return nil
+}
+
+func protoType(field Field, imports map[string]bool) string {
+	switch field.Type {
+	case String:
+		return "string"
+	case Int:
+		return "int64"
+	case Float:
+		return "double"
+	case Bool:
+		return "bool"
+	case Time:
+		imports["timestamp"] = true
+		return "google.protobuf.Timestamp"
+	case Object:
+		return ""
+	}
+	imports["struct"] = true
+	return "google.protobuf.Value"
+}
+
+// protoFieldName converts name to a valid field name: letters, digits and underscores, starting
+// with a letter.
+func protoFieldName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || !(b[0] >= 'a' && b[0] <= 'z' || b[0] >= 'A' && b[0] <= 'Z') {
+		b = append([]byte("f_"), b...)
+	}
+	return string(b)
+}
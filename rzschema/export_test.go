@@ -0,0 +1,152 @@
+package rzschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var testSchemas = []Schema{
+	{
+		Name:        "http_request",
+		Description: "An HTTP request served.",
+		Message:     "request served",
+		Fields: []Field{
+			{Name: "status", Type: Int, Required: true},
+			{Name: "latency", Type: Float, Description: "in milliseconds"},
+			{Name: "user", Type: Object, Fields: []Field{{Name: "id", Type: String, Required: true}}},
+			{Name: "tags", Type: Array, Items: &Field{Type: String}},
+			{Name: "http.method", Type: String, Enum: []string{"GET", "POST"}},
+		},
+	},
+	{Name: "job_done", Fields: []Field{{Name: "result", Type: Any}}},
+}
+
+func TestTypeScript(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := TypeScript(buf, testSchemas...); err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by rzschema. DO NOT EDIT.
+
+/** An HTTP request served. */
+export interface HttpRequest {
+  level?: "debug" | "info" | "warning" | "error" | "fatal" | "panic";
+  message: "request served";
+  timestamp?: string;
+  status: number;
+  /** in milliseconds */
+  latency?: number;
+  user?: {
+    id: string;
+  };
+  tags?: string[];
+  "http.method"?: "GET" | "POST";
+}
+
+export interface JobDone {
+  level?: "debug" | "info" | "warning" | "error" | "fatal" | "panic";
+  message?: string;
+  timestamp?: string;
+  result?: unknown;
+}
+
+export type Event = HttpRequest | JobDone;
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := JSONSchema(buf, testSchemas...); err != nil {
+		t.Fatal(err)
+	}
+	var document struct {
+		Defs  map[string]map[string]interface{} `json:"$defs"`
+		AnyOf []map[string]string               `json:"anyOf"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if len(document.AnyOf) != 2 || document.AnyOf[0]["$ref"] != "#/$defs/HttpRequest" {
+		t.Errorf("unexpected anyOf: %v", document.AnyOf)
+	}
+	got, _ := json.Marshal(document.Defs["HttpRequest"])
+	want := `{"additionalProperties":false,"description":"An HTTP request served.","properties":{` +
+		`"http.method":{"enum":["GET","POST"],"type":"string"},` +
+		`"latency":{"description":"in milliseconds","type":"number"},` +
+		`"level":{"enum":["debug","info","warning","error","fatal","panic"],"type":"string"},` +
+		`"message":{"enum":["request served"],"type":"string"},` +
+		`"status":{"type":"integer"},` +
+		`"tags":{"items":{"type":"string"},"type":"array"},` +
+		`"timestamp":{"format":"date-time","type":"string"},` +
+		`"user":{"additionalProperties":false,"properties":{"id":{"type":"string"}},"required":["id"],"type":"object"}},` +
+		`"required":["message","status"],"title":"http_request","type":"object"}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Protobuf(buf, "logs.v1", testSchemas...); err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by rzschema. DO NOT EDIT.
+
+syntax = "proto3";
+
+package logs.v1;
+
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+// An HTTP request served.
+message HttpRequest {
+  optional string level = 1;
+  string message = 2;
+  google.protobuf.Timestamp timestamp = 3;
+  int64 status = 4;
+  // in milliseconds
+  optional double latency = 5;
+  message User {
+    string id = 1;
+  }
+  User user = 6;
+  repeated string tags = 7;
+  optional string http_method = 8 [json_name = "http.method"];
+}
+
+message JobDone {
+  optional string level = 1;
+  optional string message = 2;
+  google.protobuf.Timestamp timestamp = 3;
+  google.protobuf.Value result = 4;
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	schema := Schema{Name: "a", Fields: []Field{{Name: "b", Type: Array, Items: &Field{Type: Array, Items: &Field{}}}}}
+	if err := Protobuf(buf, "", schema); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("got %v, want ErrInvalidSchema", err)
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	tests := map[string]string{
+		"http_request": "HttpRequest",
+		"user.login":   "UserLogin",
+		"2fa":          "E2fa",
+		"":             "Event",
+	}
+	for name, want := range tests {
+		if got := typeName(name); got != want {
+			t.Errorf("typeName(%q): got %q, want %q", name, got, want)
+		}
+	}
+}
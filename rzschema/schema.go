@@ -0,0 +1,182 @@
+package rzschema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/skerkour/rz"
+)
+
+// ErrInvalidSchema is returned by the generators when a schema is invalid.
+var ErrInvalidSchema = errors.New("rzschema: invalid schema")
+
+// Type is the type of the value of a field.
+type Type uint8
+
+const (
+	// Any is a value of any type.
+	Any Type = iota
+	// String is a string (rz.String, rz.Error...).
+	String
+	// Int is an integer (rz.Int, rz.Uint64...).
+	Int
+	// Float is a floating point number (rz.Float64, rz.Duration...).
+	Float
+	// Bool is a boolean.
+	Bool
+	// Time is a time formatted as RFC 3339 (rz.Time with the default time field format).
+	Time
+	// Object is an object of the Fields of the field (rz.Dict, rz.Object).
+	Object
+	// Array is an array of Items.
+	Array
+)
+
+// String returns the name of the type.
+func (t Type) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Time:
+		return "time"
+	case Object:
+		return "object"
+	case Array:
+		return "array"
+	}
+	return "any"
+}
+
+// Field describes a field of an event.
+type Field struct {
+	Name        string
+	Type        Type
+	Required    bool
+	Description string
+	// Enum restricts the values of a String field.
+	Enum []string
+	// Fields are the fields of an Object field.
+	Fields []Field
+	// Items describes the items of an Array field; its name is ignored.
+	Items *Field
+}
+
+// Schema describes an event emitted by the application.
+type Schema struct {
+	// Name identifies the event, e.g. "http_request". It's converted to the type names of the
+	// generated definitions.
+	Name        string
+	Description string
+	// Message is the message of the events, if they all have the same.
+	Message string
+	// Fields are the fields of the events, in addition to the standard level, message and
+	// timestamp fields (with rz's default names), which don't need to be described.
+	Fields []Field
+	// AdditionalFields allows the events to have fields not described by the schema. The fields
+	// of the objects must always be described.
+	AdditionalFields bool
+}
+
+// AllFields returns the standard fields not described by the schema, followed by its fields.
+func (s Schema) AllFields() []Field {
+	defined := map[string]bool{}
+	for _, field := range s.Fields {
+		defined[field.Name] = true
+	}
+	standard := []Field{
+		{Name: rz.DefaultLevelFieldName, Type: String, Enum: levels()},
+		{Name: rz.DefaultMessageFieldName, Type: String, Required: s.Message != ""},
+		{Name: rz.DefaultTimestampFieldName, Type: Time},
+	}
+	if s.Message != "" {
+		standard[1].Enum = []string{s.Message}
+	}
+	fields := make([]Field, 0, len(standard)+len(s.Fields))
+	for _, field := range standard {
+		if !defined[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	return append(fields, s.Fields...)
+}
+
+func levels() []string {
+	return []string{
+		rz.DebugLevel.String(), rz.InfoLevel.String(), rz.WarnLevel.String(),
+		rz.ErrorLevel.String(), rz.FatalLevel.String(), rz.PanicLevel.String(),
+	}
+}
+
+// validate returns an error if the schema has no name, or a field has no name, a duplicated
+// name, or a missing description of its items.
+func (s Schema) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("%w: schema without name", ErrInvalidSchema)
+	}
+	return validateFields(s.Name, s.Fields)
+}
+
+func validateFields(path string, fields []Field) error {
+	names := map[string]bool{}
+	for _, field := range fields {
+		if field.Name == "" {
+			return fmt.Errorf("%w: field without name in %s", ErrInvalidSchema, path)
+		}
+		if names[field.Name] {
+			return fmt.Errorf("%w: duplicated field %s.%s", ErrInvalidSchema, path, field.Name)
+		}
+		names[field.Name] = true
+		if err := validateField(path+"."+field.Name, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateField(path string, field Field) error {
+	switch field.Type {
+	case Object:
+		return validateFields(path, field.Fields)
+	case Array:
+		if field.Items == nil {
+			return fmt.Errorf("%w: array %s without items", ErrInvalidSchema, path)
+		}
+		return validateField(path+"[]", *field.Items)
+	}
+	return nil
+}
+
+var (
+	schemasMu sync.Mutex
+	schemas   = map[string]Schema{}
+)
+
+// Register registers schema, replacing any schema previously registered with the same name.
+// It's safe for concurrent use, but schemas are usually registered at init time.
+func Register(schema Schema) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	schemas[schema.Name] = schema
+}
+
+// Schemas returns the registered schemas, sorted by name.
+func Schemas() []Schema {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	ret := make([]Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		ret = append(ret, schema)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
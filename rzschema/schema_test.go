@@ -0,0 +1,46 @@
+package rzschema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	Register(Schema{Name: "b"})
+	Register(Schema{Name: "a", Description: "first"})
+	Register(Schema{Name: "a"})
+	got := Schemas()
+	if len(got) != 2 || got[0].Name != "a" || got[0].Description != "" || got[1].Name != "b" {
+		t.Errorf("unexpected schemas: %v", got)
+	}
+}
+
+func TestAllFields(t *testing.T) {
+	fields := Schema{Name: "a", Message: "hello", Fields: []Field{{Name: "level", Type: Int}}}.AllFields()
+	names := []string{}
+	for _, field := range fields {
+		names = append(names, field.Name+":"+field.Type.String())
+	}
+	if got, want := names, []string{"message:string", "timestamp:time", "level:int"}; len(got) != len(want) ||
+		got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !fields[0].Required || len(fields[0].Enum) != 1 || fields[0].Enum[0] != "hello" {
+		t.Errorf("the message must be required with the schema message: %+v", fields[0])
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []Schema{
+		{},
+		{Name: "a", Fields: []Field{{Type: String}}},
+		{Name: "a", Fields: []Field{{Name: "b"}, {Name: "b"}}},
+		{Name: "a", Fields: []Field{{Name: "b", Type: Array}}},
+		{Name: "a", Fields: []Field{{Name: "b", Type: Object, Fields: []Field{{Name: "c"}, {Name: "c"}}}}},
+	}
+	for _, schema := range tests {
+		if err := schema.validate(); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%+v: got %v, want ErrInvalidSchema", schema, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package rzschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// ErrNonConforming is returned when an event doesn't conform to a schema.
+var ErrNonConforming = errors.New("rzschema: event doesn't conform to schema")
+
+// Validate returns an error wrapping ErrNonConforming if the JSON encoded event doesn't conform
+// to the schema: a required field is missing, a field has a value of another type (or not one of
+// its Enum), or the event has a field not described by the schema, unless AdditionalFields is
+// true. The fields of the objects are checked the same way.
+func (s Schema) Validate(event []byte) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	object, err := decodeObject(event)
+	if err != nil {
+		return err
+	}
+	if err = s.validateObject(object); err != nil {
+		return fmt.Errorf("%w %s: %v", ErrNonConforming, s.Name, err)
+	}
+	return nil
+}
+
+func (s Schema) validateObject(object map[string]interface{}) error {
+	return validateValues(s.Name, s.AllFields(), object, s.AdditionalFields)
+}
+
+func decodeObject(event []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(event))
+	decoder.UseNumber()
+	object := map[string]interface{}{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("%w: invalid event: %v", ErrNonConforming, err)
+	}
+	return object, nil
+}
+
+func validateValues(path string, fields []Field, object map[string]interface{}, additional bool) error {
+	described := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		described[field.Name] = true
+		value, ok := object[field.Name]
+		if !ok {
+			if field.Required {
+				return fmt.Errorf("missing required field %s.%s", path, field.Name)
+			}
+			continue
+		}
+		if err := validateValue(path+"."+field.Name, field, value); err != nil {
+			return err
+		}
+	}
+	if additional {
+		return nil
+	}
+	unknown := []string{}
+	for name := range object {
+		if !described[name] {
+			unknown = append(unknown, path+"."+name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("undescribed fields %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func validateValue(path string, field Field, value interface{}) error {
+	valid := true
+	switch field.Type {
+	case String:
+		s, ok := value.(string)
+		if valid = ok; ok && len(field.Enum) > 0 {
+			valid = false
+			for _, v := range field.Enum {
+				if v == s {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Errorf("field %s: %q is not one of %q", path, s, field.Enum)
+			}
+		}
+	case Int:
+		n, ok := value.(json.Number)
+		valid = ok && !strings.ContainsAny(n.String(), ".eE")
+	case Float:
+		_, valid = value.(json.Number)
+	case Bool:
+		_, valid = value.(bool)
+	case Time:
+		s, ok := value.(string)
+		if valid = ok; ok {
+			_, err := time.Parse(time.RFC3339Nano, s)
+			valid = err == nil
+		}
+	case Object:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			valid = false
+			break
+		}
+		return validateValues(path, field.Fields, object, false)
+	case Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			valid = false
+			break
+		}
+		for i, item := range items {
+			if err := validateValue(fmt.Sprintf("%s[%d]", path, i), *field.Items, item); err != nil {
+				return err
+			}
+		}
+	}
+	if !valid {
+		return fmt.Errorf("field %s: %s is not a %s", path, typeOf(value), field.Type)
+	}
+	return nil
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "array"
+}
+
+// Match returns the schema the JSON encoded event conforms to: the schema with the message of
+// the event if there is one, otherwise the first conforming schema without message. It returns an
+// error wrapping ErrNonConforming if there is none.
+func Match(event []byte, schemas ...Schema) (Schema, error) {
+	object, err := decodeObject(event)
+	if err != nil {
+		return Schema{}, err
+	}
+	message, _ := object[rz.DefaultMessageFieldName].(string)
+	for _, schema := range schemas {
+		if schema.Message != "" && schema.Message == message {
+			if err := schema.validate(); err != nil {
+				return Schema{}, err
+			}
+			if err := schema.validateObject(object); err != nil {
+				return schema, fmt.Errorf("%w %s: %v", ErrNonConforming, schema.Name, err)
+			}
+			return schema, nil
+		}
+	}
+	errs := []string{}
+	for _, schema := range schemas {
+		if schema.Message != "" {
+			continue
+		}
+		if err := schema.validate(); err != nil {
+			return Schema{}, err
+		}
+		err := schema.validateObject(object)
+		if err == nil {
+			return schema, nil
+		}
+		errs = append(errs, schema.Name+": "+err.Error())
+	}
+	if len(errs) == 0 {
+		return Schema{}, fmt.Errorf("%w: no schema for message %q", ErrNonConforming, message)
+	}
+	return Schema{}, fmt.Errorf("%w: no schema matches the event (%s)", ErrNonConforming, strings.Join(errs, "; "))
+}
@@ -0,0 +1,62 @@
+package rzschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema := testSchemas[0]
+	tests := []struct {
+		event string
+		err   string
+	}{
+		{`{"level":"info","message":"request served","status":200,"user":{"id":"42"},"tags":["a"],"timestamp":"2020-01-02T03:04:05Z"}`, ""},
+		{`{"message":"request served","status":200,"latency":1.5}`, ""},
+		{`{"message":"request served"}`, "missing required field http_request.status"},
+		{`{"message":"request served","status":2.5}`, "field http_request.status: number is not a int"},
+		{`{"message":"request served","status":200,"user":{}}`, "missing required field http_request.user.id"},
+		{`{"message":"request served","status":200,"tags":[1]}`, "field http_request.tags[0]: number is not a string"},
+		{`{"message":"request served","status":200,"http.method":"PUT"}`, `field http_request.http.method: "PUT" is not one of ["GET" "POST"]`},
+		{`{"message":"request served","status":200,"timestamp":"yesterday"}`, "field http_request.timestamp: string is not a time"},
+		{`{"message":"request served","status":200,"user_id":"42"}`, "undescribed fields http_request.user_id"},
+		{`{"message":"request served","status":200,"user":{"id":"42","name":"john"}}`, "undescribed fields http_request.user.name"},
+		{`not json`, "invalid event"},
+	}
+	for _, tt := range tests {
+		err := schema.Validate([]byte(tt.event))
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.event, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrNonConforming) || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got %v, want %s", tt.event, err, tt.err)
+		}
+	}
+
+	schema.AdditionalFields = true
+	if err := schema.Validate([]byte(`{"message":"request served","status":200,"user_id":"42"}`)); err != nil {
+		t.Errorf("additional fields must be allowed: %v", err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	schema, err := Match([]byte(`{"message":"request served","status":200}`), testSchemas...)
+	if err != nil || schema.Name != "http_request" {
+		t.Errorf("got %s, %v", schema.Name, err)
+	}
+	schema, err = Match([]byte(`{"message":"done","result":[1]}`), testSchemas...)
+	if err != nil || schema.Name != "job_done" {
+		t.Errorf("got %s, %v", schema.Name, err)
+	}
+	if _, err = Match([]byte(`{"message":"request served"}`), testSchemas...); !errors.Is(err, ErrNonConforming) {
+		t.Errorf("got %v, want ErrNonConforming", err)
+	}
+	if _, err = Match([]byte(`{"message":"done","status":1}`), testSchemas...); err == nil ||
+		!strings.Contains(err.Error(), "job_done: undescribed fields job_done.status") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build go1.21
+// +build go1.21
+
+// Package rzslog provides a log/slog Handler emitting the records through a rz Logger, so code
+// written against the standard library's slog logs with the fields, hooks and writers of the
+// application.
+//
+//    logger := rz.New(rz.Writer(os.Stderr))
+//    slog.SetDefault(slog.New(rzslog.NewHandler(logger)))
+//    slog.Info("user created", "id", 42, slog.Group("request", "method", "POST"))
+//    // {"level":"info","timestamp":"...","id":42,"request":{"method":"POST"},"message":"user created"}
+package rzslog
@@ -0,0 +1,168 @@
+//go:build go1.21
+// +build go1.21
+
+package rzslog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// Handler is a slog.Handler logging with a rz Logger. Groups are nested objects, and the slog
+// levels are mapped to the rz levels (see Level). The events are timestamped with the time of the
+// records.
+type Handler struct {
+	logger rz.Logger
+	// groups are the groups opened by WithGroup, and attrs the attributes added in each of them.
+	// The attributes added before any group are fields of logger.
+	groups []string
+	attrs  [][]slog.Attr
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler creates a Handler logging with logger.
+func NewHandler(logger rz.Logger) *Handler {
+	return &Handler{logger: logger}
+}
+
+// Level maps a slog level to a rz level: the levels below slog.LevelInfo are rz.DebugLevel,
+// below slog.LevelWarn rz.InfoLevel, below slog.LevelError rz.WarnLevel, and the others
+// rz.ErrorLevel, so slog records never exit or panic.
+func Level(level slog.Level) rz.LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return rz.DebugLevel
+	case level < slog.LevelWarn:
+		return rz.InfoLevel
+	case level < slog.LevelError:
+		return rz.WarnLevel
+	}
+	return rz.ErrorLevel
+}
+
+// Enabled implements the slog.Handler interface. The level override of ctx, if any (see
+// rz.WithLevelOverride), is applied.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	logger := h.logger.ForCtx(ctx)
+	return Level(level) >= logger.GetLevel()
+}
+
+// Handle implements the slog.Handler interface. The level override of ctx, if any, is applied,
+// but ctx doesn't bound the write of the event (see rz.Ctx): records logged with the context of
+// a finished request are not dropped.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	logger := h.logger.ForCtx(ctx)
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	fields := append(h.nest(&logger, attrs), rz.EventTime(record.Time))
+	logger.LogWithLevel(Level(record.Level), record.Message, fields...)
+	return nil
+}
+
+// nest returns the fields of attrs in the open groups, nested in the objects of the groups with
+// their attributes.
+func (h *Handler) nest(logger *rz.Logger, attrs []slog.Attr) []rz.Field {
+	fields := appendFields(logger, nil, attrs)
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		groupFields := appendFields(logger, nil, h.attrs[i])
+		groupFields = append(groupFields, fields...)
+		if len(groupFields) == 0 {
+			// empty groups are omitted
+			fields = nil
+			continue
+		}
+		fields = []rz.Field{rz.Dict(h.groups[i], logger.NewDict(groupFields...))}
+	}
+	return fields
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	ret := h.clone()
+	if len(ret.groups) == 0 {
+		ret.logger = ret.logger.With(rz.Fields(appendFields(&ret.logger, nil, attrs)...))
+		return ret
+	}
+	last := len(ret.attrs) - 1
+	ret.attrs[last] = append(ret.attrs[last][:len(ret.attrs[last]):len(ret.attrs[last])], attrs...)
+	return ret
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	ret := h.clone()
+	ret.groups = append(ret.groups, name)
+	ret.attrs = append(ret.attrs, nil)
+	return ret
+}
+
+func (h *Handler) clone() *Handler {
+	return &Handler{
+		logger: h.logger,
+		groups: append([]string(nil), h.groups...),
+		attrs:  append([][]slog.Attr(nil), h.attrs...),
+	}
+}
+
+// appendFields appends the fields of attrs to fields.
+func appendFields(logger *rz.Logger, fields []rz.Field, attrs []slog.Attr) []rz.Field {
+	for _, attr := range attrs {
+		fields = appendField(logger, fields, attr)
+	}
+	return fields
+}
+
+func appendField(logger *rz.Logger, fields []rz.Field, attr slog.Attr) []rz.Field {
+	value := attr.Value.Resolve()
+	if attr.Key == "" && value.Kind() != slog.KindGroup {
+		// empty attributes are ignored
+		return fields
+	}
+	switch value.Kind() {
+	case slog.KindString:
+		return append(fields, rz.String(attr.Key, value.String()))
+	case slog.KindInt64:
+		return append(fields, rz.Int64(attr.Key, value.Int64()))
+	case slog.KindUint64:
+		return append(fields, rz.Uint64(attr.Key, value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, rz.Float64(attr.Key, value.Float64()))
+	case slog.KindBool:
+		return append(fields, rz.Bool(attr.Key, value.Bool()))
+	case slog.KindDuration:
+		return append(fields, rz.Duration(attr.Key, value.Duration()))
+	case slog.KindTime:
+		return append(fields, rz.Time(attr.Key, value.Time()))
+	case slog.KindGroup:
+		group := appendFields(logger, nil, value.Group())
+		if len(group) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			// groups without key are inlined
+			return append(fields, group...)
+		}
+		return append(fields, rz.Dict(attr.Key, logger.NewDict(group...)))
+	}
+	switch v := value.Any().(type) {
+	case error:
+		return append(fields, rz.Error(attr.Key, v))
+	case time.Time:
+		return append(fields, rz.Time(attr.Key, v))
+	}
+	return append(fields, rz.Any(attr.Key, value.Any()))
+}
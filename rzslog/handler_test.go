@@ -0,0 +1,96 @@
+//go:build go1.21
+// +build go1.21
+
+package rzslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+func TestHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := slog.New(NewHandler(rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))))
+
+	logger.Info("hello", "n", 1, slog.Bool("ok", true), slog.Duration("elapsed", 2*time.Second), slog.Any("err", errors.New("boom")))
+	logger.With("service", "api").WithGroup("request").With("method", "GET").WithGroup("details").
+		Warn("done", slog.Group("", slog.String("inlined", "x")), slog.Group("user", "id", "42"), slog.Group("none"), slog.Attr{})
+	logger.WithGroup("request").Error("failed")
+
+	want := `{"level":"info","n":1,"ok":true,"elapsed":2000,"err":"boom","message":"hello"}` + "\n" +
+		`{"level":"warning","service":"api","request":{"method":"GET","details":{"inlined":"x","user":{"id":"42"}}},"message":"done"}` + "\n" +
+		`{"level":"error","message":"failed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestHandlerEnabled(t *testing.T) {
+	h := NewHandler(rz.New(rz.Writer(&bytes.Buffer{}), rz.Level(rz.WarnLevel)))
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info is enabled at warn level")
+	}
+	if !h.Enabled(context.Background(), slog.LevelError+4) {
+		t.Error("error+4 is disabled at warn level")
+	}
+	if !h.Enabled(rz.WithLevelOverride(context.Background(), rz.DebugLevel), slog.LevelDebug) {
+		t.Error("debug is disabled with a debug level override")
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := map[slog.Level]rz.LogLevel{
+		slog.LevelDebug - 4: rz.DebugLevel,
+		slog.LevelDebug:     rz.DebugLevel,
+		slog.LevelInfo:      rz.InfoLevel,
+		slog.LevelInfo + 1:  rz.InfoLevel,
+		slog.LevelWarn:      rz.WarnLevel,
+		slog.LevelError:     rz.ErrorLevel,
+		slog.LevelError + 8: rz.ErrorLevel,
+	}
+	for level, want := range tests {
+		if got := Level(level); got != want {
+			t.Errorf("Level(%v) = %v, want %v", level, got, want)
+		}
+	}
+}
+
+// ctxWriter is a rz.ContextWriter dropping the events whose context is done.
+type ctxWriter struct {
+	bytes.Buffer
+}
+
+func (w *ctxWriter) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	return w.Write(p)
+}
+
+func (w *ctxWriter) WriteContext(ctx context.Context, level rz.LogLevel, p []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.Write(p)
+}
+
+func TestHandlerContextAndTime(t *testing.T) {
+	out := &ctxWriter{}
+	logger := slog.New(NewHandler(rz.New(rz.Writer(out))))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the record of a finished request is still written, with its time
+	record := slog.NewRecord(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelError, "failed", 0)
+	if err := logger.Handler().Handle(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"level":"error","timestamp":"2020-01-02T03:04:05Z","message":"failed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
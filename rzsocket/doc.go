@@ -0,0 +1,8 @@
+// Package rzsocket provides a unix socket listener accepting rz events from other local
+// processes and relaying them through a shared logger, e.g. to build a per-host logging daemon.
+//
+// Clients only need a rz logger writing to the socket:
+//
+//    conn, err := net.Dial("unixgram", "/run/rz.sock")
+//    logger := rz.New(rz.Writer(conn))
+package rzsocket
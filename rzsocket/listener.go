@@ -0,0 +1,179 @@
+package rzsocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/skerkour/rz"
+)
+
+// Listener accepts events over a unix socket and relays them through a logger with
+// rz.Logger.Relay. With a datagram socket ("unixgram"), each datagram is an event. With a
+// stream socket ("unix"), events are separated by line breaks.
+// Invalid events are dropped.
+type Listener struct {
+	network      string
+	path         string
+	logger       rz.Logger
+	maxEventSize int
+	fields       []rz.Field
+
+	mu       sync.Mutex
+	packet   net.PacketConn
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// ListenerOption is used to configure a Listener.
+type ListenerOption func(l *Listener)
+
+// MaxEventSize updates the maximum size in bytes of an event. Default: 64KiB.
+func MaxEventSize(size int) ListenerOption {
+	return func(l *Listener) {
+		l.maxEventSize = size
+	}
+}
+
+// Fields adds fields to each relayed event.
+func Fields(fields ...rz.Field) ListenerOption {
+	return func(l *Listener) {
+		l.fields = append(l.fields, fields...)
+	}
+}
+
+// Listen creates a Listener on the unix socket at path. network must be "unixgram" or "unix".
+// A stale socket file at path is removed.
+func Listen(network, path string, logger rz.Logger, options ...ListenerOption) (*Listener, error) {
+	l := &Listener{
+		network:      network,
+		path:         path,
+		logger:       logger,
+		maxEventSize: 64 * 1024,
+		conns:        map[net.Conn]struct{}{},
+	}
+	for _, option := range options {
+		option(l)
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	var err error
+	switch network {
+	case "unixgram":
+		l.packet, err = net.ListenPacket(network, path)
+	case "unix":
+		l.listener, err = net.Listen(network, path)
+	default:
+		err = net.UnknownNetworkError(network)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Addr returns the address of the listener.
+func (l *Listener) Addr() net.Addr {
+	if l.packet != nil {
+		return l.packet.LocalAddr()
+	}
+	return l.listener.Addr()
+}
+
+// Serve relays the received events until the listener is closed.
+func (l *Listener) Serve() error {
+	if l.packet != nil {
+		return l.servePacket()
+	}
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if l.isClosed() {
+				l.wg.Wait()
+				return nil
+			}
+			return err
+		}
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go l.serveConn(conn)
+	}
+}
+
+func (l *Listener) servePacket() error {
+	buf := make([]byte, l.maxEventSize)
+	for {
+		n, _, err := l.packet.ReadFrom(buf)
+		if err != nil {
+			if l.isClosed() {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		l.relay(buf[:n])
+	}
+}
+
+func (l *Listener) serveConn(conn net.Conn) {
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		conn.Close()
+		l.wg.Done()
+	}()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), l.maxEventSize)
+	for scanner.Scan() {
+		l.relay(scanner.Bytes())
+	}
+}
+
+func (l *Listener) relay(event []byte) {
+	event = bytes.TrimSpace(event)
+	if len(event) > 0 {
+		l.logger.Relay(event, l.fields...)
+	}
+}
+
+func (l *Listener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// Close closes the listener and the open connections, and removes the socket file.
+func (l *Listener) Close() (err error) {
+	l.mu.Lock()
+	l.closed = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	if l.packet != nil {
+		err = l.packet.Close()
+		os.Remove(l.path)
+	} else {
+		// closing a unix listener removes the socket file
+		err = l.listener.Close()
+	}
+	return err
+}
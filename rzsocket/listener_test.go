@@ -0,0 +1,77 @@
+package rzsocket
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestListener(t *testing.T) {
+	for _, network := range []string{"unixgram", "unix"} {
+		network := network
+		t.Run(network, func(t *testing.T) {
+			out := &syncBuffer{}
+			logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+			path := filepath.Join(t.TempDir(), "rz.sock")
+			listener, err := Listen(network, path, logger, Fields(rz.String("host", "a")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			done := make(chan error)
+			go func() {
+				done <- listener.Serve()
+			}()
+
+			conn, err := net.Dial(network, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := rz.New(rz.Writer(conn), rz.Fields(rz.Timestamp(false), rz.String("app", "client")))
+			client.Info("hello")
+			conn.Write([]byte("not json\n"))
+			client.Error("failed", rz.Int("code", 2))
+
+			want := `{"level":"info","app":"client","host":"a","message":"hello"}
+{"level":"error","app":"client","code":2,"host":"a","message":"failed"}
+`
+			deadline := time.Now().Add(5 * time.Second)
+			for strings.Count(out.String(), "\n") < 2 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := out.String(); got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+
+			conn.Close()
+			if err = listener.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err = <-done; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
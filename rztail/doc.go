@@ -0,0 +1,3 @@
+// Package rztail provides a file tailer re-emitting the lines of log files through a rz logger,
+// turning rz into a minimal log shipper (e.g. for sidecars).
+package rztail
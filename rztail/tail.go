@@ -0,0 +1,228 @@
+package rztail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// Tailer follows a file and re-emits its lines through a logger.
+//
+// JSON lines are re-emitted with their level, message, timestamp and fields with Logger.RelayFrom,
+// other lines are re-emitted as messages with info level. The source fields ("source_path" and
+// "source_line" by default) are added to each event.
+//
+// The file is polled, and reopened when it's rotated (renamed or removed and recreated)
+// or truncated.
+type Tailer struct {
+	path         string
+	logger       rz.Logger
+	pollInterval time.Duration
+	fromStart    bool
+	pathField    string
+	lineField    string
+	levelField   string
+	messageField string
+	fields       []rz.Field
+	query        *rz.Query
+	line         int
+}
+
+// TailerOption is used to configure a Tailer.
+type TailerOption func(t *Tailer)
+
+// PollInterval updates the interval at which the file is polled for new lines. Default: 250ms.
+func PollInterval(interval time.Duration) TailerOption {
+	return func(t *Tailer) {
+		t.pollInterval = interval
+	}
+}
+
+// FromStart makes the tailer read the file from its start instead of its end.
+func FromStart(enable bool) TailerOption {
+	return func(t *Tailer) {
+		t.fromStart = enable
+	}
+}
+
+// SourcePath updates the field name for the path of the file. Set an empty string to disable the field.
+func SourcePath(pathFieldName string) TailerOption {
+	return func(t *Tailer) {
+		t.pathField = pathFieldName
+	}
+}
+
+// SourceLine updates the field name for the line number. Set an empty string to disable the field.
+func SourceLine(lineFieldName string) TailerOption {
+	return func(t *Tailer) {
+		t.lineField = lineFieldName
+	}
+}
+
+// ParseFields updates the field names of level and message used to parse JSON lines.
+// An empty string keeps the field name of the logger, the default.
+func ParseFields(levelFieldName, messageFieldName string) TailerOption {
+	return func(t *Tailer) {
+		t.levelField = levelFieldName
+		t.messageField = messageFieldName
+	}
+}
+
+// Fields adds fields to each re-emitted event.
+func Fields(fields ...rz.Field) TailerOption {
+	return func(t *Tailer) {
+		t.fields = append(t.fields, fields...)
+	}
+}
+
+// Filter only re-emits the lines matching query (see rz.Query). Plain lines are matched as
+// messages with info level.
+func Filter(query *rz.Query) TailerOption {
+	return func(t *Tailer) {
+		t.query = query
+	}
+}
+
+// New creates a Tailer following the file at path.
+func New(path string, logger rz.Logger, options ...TailerOption) *Tailer {
+	t := &Tailer{
+		path:         path,
+		logger:       logger,
+		pollInterval: 250 * time.Millisecond,
+		pathField:    "source_path",
+		lineField:    "source_line",
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
+
+// Run follows the file until ctx is done. It waits for the file to exist.
+func (t *Tailer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	var file *os.File
+	var reader *bufio.Reader
+	var partial []byte
+	fromStart := t.fromStart
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		if file == nil {
+			f, err := os.Open(t.path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if f != nil {
+				if !fromStart {
+					if _, err = f.Seek(0, io.SeekEnd); err != nil {
+						f.Close()
+						return err
+					}
+				}
+				file, reader, partial, t.line = f, bufio.NewReader(f), nil, 0
+			}
+			// files created after the start are always read from their start
+			fromStart = true
+		}
+
+		if file != nil {
+			var err error
+			if partial, err = t.read(reader, partial); err != nil {
+				return err
+			}
+			if t.rotated(file) {
+				// drain the lines written before the rotation
+				if partial, err = t.read(reader, partial); err != nil {
+					return err
+				}
+				if len(partial) > 0 {
+					t.emit(partial)
+				}
+				file.Close()
+				file = nil
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// read emits the complete lines available in reader and returns the incomplete last line.
+func (t *Tailer) read(reader *bufio.Reader, partial []byte) ([]byte, error) {
+	for {
+		line, err := reader.ReadBytes('\n')
+		partial = append(partial, line...)
+		if err == io.EOF {
+			return partial, nil
+		} else if err != nil {
+			return partial, err
+		}
+		t.emit(partial[:len(partial)-1])
+		partial = partial[:0]
+	}
+}
+
+// rotated returns true if the file at path is not file anymore or was truncated.
+func (t *Tailer) rotated(file *os.File) bool {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	current, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	if !os.SameFile(info, current) {
+		return true
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	return err == nil && info.Size() < offset
+}
+
+// emit re-emits a line through the logger. Blank lines are counted but not re-emitted.
+func (t *Tailer) emit(line []byte) {
+	t.line++
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	if t.query != nil {
+		event, err := rz.DecodeEvent(line)
+		if err != nil {
+			event = rz.DecodedEvent{Level: rz.InfoLevel, Message: string(line), Raw: line}
+		}
+		if !t.query.Match(event) {
+			return
+		}
+	}
+	fields := make([]rz.Field, 0, len(t.fields)+3)
+	if t.pathField != "" {
+		fields = append(fields, rz.String(t.pathField, t.path))
+	}
+	if t.lineField != "" {
+		fields = append(fields, rz.Int(t.lineField, t.line))
+	}
+	fields = append(fields, t.fields...)
+
+	if t.logger.RelayFrom(line, t.levelField, t.messageField, fields...) == rz.ErrInvalidEvent {
+		t.logger.Info(string(line), fields...)
+	}
+}
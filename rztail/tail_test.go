@@ -0,0 +1,149 @@
+package rztail
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func appendFile(t *testing.T, path, data string) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err = f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFor(t *testing.T, out *syncBuffer, lines int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for strings.Count(out.String(), "\n") < lines {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %d lines:\n%s", lines, out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTailer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, `{"level":"error","message":"failed","count":2,"nested":{"a":true}}`+"\n")
+
+	out := &syncBuffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	tailer := New(path, logger, FromStart(true), PollInterval(5*time.Millisecond), SourcePath("file"), Fields(rz.String("host", "a")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tailer.Run(ctx)
+	}()
+
+	waitFor(t, out, 1)
+	appendFile(t, path, "plain line\n\n \r\npartial")
+	waitFor(t, out, 2)
+	appendFile(t, path, " line\n")
+	waitFor(t, out, 3)
+
+	// rotation
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, `{"level":"info","message":"rotated"}`+"\n")
+	waitFor(t, out, 4)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"level":"error","count":2,"nested":{"a":true},"file":"` + path + `","source_line":1,"host":"a","message":"failed"}
+{"level":"info","file":"` + path + `","source_line":2,"host":"a","message":"plain line"}
+{"level":"info","file":"` + path + `","source_line":5,"host":"a","message":"partial line"}
+{"level":"info","file":"` + path + `","source_line":1,"host":"a","message":"rotated"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTailerFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, `{"level":"info","message":"skipped"}`+"\n"+`{"level":"error","message":"kept"}`+"\nplain timeout\nplain\n")
+
+	out := &syncBuffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	tailer := New(path, logger, FromStart(true), PollInterval(5*time.Millisecond), SourcePath(""), SourceLine(""),
+		Filter(rz.MustParseQuery(`level>=error OR msg~"timeout"`)))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tailer.Run(ctx)
+	}()
+	waitFor(t, out, 2)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"level":"error","message":"kept"}
+{"level":"info","message":"plain timeout"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTailerParseFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, `{"severity":"error","msg":"failed","code":3}`+"\n"+`{"severity":"debug","message":"kept"}`+"\n")
+
+	out := &syncBuffer{}
+	logger := rz.New(rz.Writer(out), rz.Fields(rz.Timestamp(false)))
+	tailer := New(path, logger, FromStart(true), PollInterval(5*time.Millisecond), SourcePath(""), SourceLine(""),
+		ParseFields("severity", "msg"))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tailer.Run(ctx)
+	}()
+	waitFor(t, out, 2)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// the message of the second line is relayed as a field
+	want := `{"level":"error","code":3,"message":"failed"}
+{"level":"debug","message":"kept"}
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
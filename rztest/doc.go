@@ -0,0 +1,2 @@
+// Package rztest provides helpers to test code using rz, and conformance tests for rz's wire format.
+package rztest
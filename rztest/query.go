@@ -0,0 +1,61 @@
+package rztest
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/skerkour/rz"
+)
+
+// DecodeEvents decodes the JSON encoded events of r, one per line (e.g. the output of a logger
+// writing to a bytes.Buffer).
+func DecodeEvents(r io.Reader) ([]rz.DecodedEvent, error) {
+	events := []rz.DecodedEvent{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<24)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		event, err := rz.DecodeEvent(append([]byte(nil), line...))
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// Filter returns the events matching query (see rz.Query).
+func Filter(events []rz.DecodedEvent, query *rz.Query) []rz.DecodedEvent {
+	ret := []rz.DecodedEvent{}
+	for _, event := range events {
+		if query.Match(event) {
+			ret = append(ret, event)
+		}
+	}
+	return ret
+}
+
+// AssertCount reports an error if the number of events of output (see DecodeEvents) matching
+// query (see rz.ParseQuery) is not count.
+//
+//     rztest.AssertCount(t, out, `level>=warn AND fields.user_id="42" AND msg~"timeout"`, 1)
+func AssertCount(t TB, output []byte, query string, count int) {
+	t.Helper()
+	q, err := rz.ParseQuery(query)
+	if err != nil {
+		t.Errorf("rztest: %v", err)
+		return
+	}
+	events, err := DecodeEvents(bytes.NewReader(output))
+	if err != nil {
+		t.Errorf("rztest: decoding events: %v", err)
+		return
+	}
+	if matching := Filter(events, q); len(matching) != count {
+		t.Errorf("rztest: %d events match %q, want %d", len(matching), query, count)
+	}
+}
@@ -0,0 +1,38 @@
+package rztest
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+func TestAssertCount(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out))
+	logger.Warn("request timeout", rz.String("user_id", "42"))
+	logger.Warn("request timeout", rz.String("user_id", "43"))
+	logger.Info("request timeout", rz.String("user_id", "42"))
+	logger.Error("connection reset", rz.String("user_id", "42"))
+
+	AssertCount(t, out.Bytes(), `level>=warn AND fields.user_id="42" AND msg~"timeout"`, 1)
+	AssertCount(t, out.Bytes(), `fields.user_id="42"`, 3)
+
+	rec := &recordingTB{}
+	AssertCount(rec, out.Bytes(), `level>=error`, 2)
+	AssertCount(rec, out.Bytes(), `level>>error`, 1)
+	if len(rec.errors) != 2 {
+		t.Errorf("invalid errors: %v", rec.errors)
+	}
+}
+
+type recordingTB struct {
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,126 @@
+package rztest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// Clock is a fake clock for deterministic timestamps. Each call to Now returns the
+// previous time advanced by the clock's step. Use it with rz.TimestampFunc(clock.Now).
+type Clock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+// NewClock returns a clock starting at start and advancing by step at each call to Now.
+func NewClock(start time.Time, step time.Duration) *Clock {
+	return &Clock{now: start.Add(-step), step: step}
+}
+
+// Now returns the current time of the clock and advances it.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+// record is a recorded event.
+type record struct {
+	Level string `json:"level"`
+	Event string `json:"event"`
+}
+
+// Recorder is a rz.LevelWriter recording events to w, to be replayed later with Replay.
+// It's safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	enc    *json.Encoder
+}
+
+// NewRecorder returns a Recorder recording events to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, enc: json.NewEncoder(w)}
+}
+
+// RecordFile returns a Recorder recording events to the file at path, which is truncated.
+func RecordFile(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	recorder := NewRecorder(file)
+	recorder.closer = file
+	return recorder, nil
+}
+
+// Write implements the io.Writer interface.
+func (r *Recorder) Write(p []byte) (n int, err error) {
+	return r.WriteLevel(rz.NoLevel, p)
+}
+
+// WriteLevel implements the rz.LevelWriter interface.
+func (r *Recorder) WriteLevel(level rz.LogLevel, p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err = r.enc.Encode(record{Level: level.String(), Event: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the file opened by RecordFile.
+func (r *Recorder) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// Replay writes the events recorded in r by a Recorder to w, with their level if w
+// implements rz.LevelWriter. It returns the number of replayed events.
+func Replay(r io.Reader, w io.Writer) (int, error) {
+	lw, ok := w.(rz.LevelWriter)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<24)
+	count := 0
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return count, err
+		}
+		level, err := rz.ParseLevel(rec.Level)
+		if err != nil {
+			return count, err
+		}
+		if ok {
+			_, err = lw.WriteLevel(level, []byte(rec.Event))
+		} else {
+			_, err = w.Write([]byte(rec.Event))
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// ReplayFile replays the events recorded in the file at path to w. See Replay.
+func ReplayFile(path string, w io.Writer) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return Replay(file, w)
+}
@@ -0,0 +1,54 @@
+package rztest
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+type levelRecorder struct {
+	bytes.Buffer
+	levels []rz.LogLevel
+}
+
+func (w *levelRecorder) WriteLevel(level rz.LogLevel, p []byte) (int, error) {
+	w.levels = append(w.levels, level)
+	return w.Write(p)
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	recorder, err := RecordFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Second)
+	log := rz.New(rz.Writer(recorder), rz.TimestampFunc(clock.Now))
+	log.Info("first")
+	log.Warn("second", rz.Int("n", 2))
+	log.Log("third")
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &levelRecorder{}
+	n, err := ReplayFile(path, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("replayed %d events, want 3", n)
+	}
+	want := `{"level":"info","timestamp":"2020-01-01T00:00:00Z","message":"first"}` + "\n" +
+		`{"level":"warning","n":2,"timestamp":"2020-01-01T00:00:01Z","message":"second"}` + "\n" +
+		`{"timestamp":"2020-01-01T00:00:02Z","message":"third"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid replayed output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := out.levels, []rz.LogLevel{rz.InfoLevel, rz.WarnLevel, rz.NoLevel}; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("invalid replayed levels: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package rztest
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/skerkour/rz/rzschema"
+)
+
+// AssertConforms reports an error for each event of output (JSON encoded, one per line) which
+// doesn't conform to one of the schemas (see rzschema.Match), or to one of the registered schemas
+// if none is given. Producers can run it against the output of their code to fail CI before a
+// renamed or retyped field breaks a dashboard or an alert:
+//
+//     out := &bytes.Buffer{}
+//     handler := NewHandler(rz.New(rz.Writer(out)))
+//     handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+//     rztest.AssertConforms(t, out.Bytes())
+func AssertConforms(t TB, output []byte, schemas ...rzschema.Schema) {
+	t.Helper()
+	if len(schemas) == 0 {
+		schemas = rzschema.Schemas()
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		event := bytes.TrimSpace(scanner.Bytes())
+		if len(event) == 0 {
+			continue
+		}
+		if _, err := rzschema.Match(event, schemas...); err != nil {
+			t.Errorf("rztest: event %d: %v: %s", line, err, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Errorf("rztest: decoding events: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package rztest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skerkour/rz"
+	"github.com/skerkour/rz/rzschema"
+)
+
+func TestAssertConforms(t *testing.T) {
+	schemas := []rzschema.Schema{
+		{
+			Name:    "http_request",
+			Message: "request served",
+			Fields:  []rzschema.Field{{Name: "status", Type: rzschema.Int, Required: true}},
+		},
+	}
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out))
+	logger.Info("request served", rz.Int("status", 200))
+	AssertConforms(t, out.Bytes(), schemas...)
+
+	logger.Info("request served", rz.Int("status_code", 200))
+	logger.Info("unknown event")
+	rec := &recordingTB{}
+	AssertConforms(rec, out.Bytes(), schemas...)
+	if len(rec.errors) != 2 {
+		t.Errorf("invalid errors: %v", rec.errors)
+	}
+}
@@ -0,0 +1,114 @@
+package rztest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// timeProbes are the times encoded by TimeFormatConformance, in chronological order. They cover
+// the neighbourhood of a leap second (which time.Time can't represent: 23:59:60 is normalized
+// to the next second), single nanosecond steps and times far away from the UNIX epoch.
+var timeProbes = []time.Time{
+	time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Unix(-1, 0).UTC(),
+	time.Unix(-1, 1).UTC(),
+	time.Unix(0, -1).UTC(),
+	time.Unix(0, 0).UTC(),
+	time.Unix(0, 1).UTC(),
+	time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC),
+	time.Date(2016, 12, 31, 23, 59, 59, 999999998, time.UTC),
+	time.Date(2016, 12, 31, 23, 59, 59, 999999999, time.UTC),
+	time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2017, 1, 1, 0, 0, 0, 1, time.UTC),
+	time.Date(2262, 4, 12, 0, 0, 0, 0, time.UTC),
+	time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+}
+
+// TimeFormatConformance checks that the time format registered under name (see
+// rz.RegisterTimeFormat) produces valid JSON values, encodes times deterministically, and
+// that numeric encodings are ordered like the times they encode and never round a time up to
+// the next second (e.g. 23:59:59.999999999 must not be stamped as the following midnight).
+//
+//     func TestTimeFormat(t *testing.T) {
+//         rz.RegisterTimeFormat("gps_ms", rz.EpochTimeEncoder(gpsEpoch, time.Millisecond))
+//         rztest.TimeFormatConformance(t, "gps_ms")
+//     }
+func TimeFormatConformance(t TB, name string) {
+	t.Helper()
+
+	if _, ok := rz.LookupTimeFormat(name); !ok {
+		t.Errorf("rztest: unknown time format: %q", name)
+		return
+	}
+
+	values := make([]interface{}, len(timeProbes))
+	for i, probe := range timeProbes {
+		value, err := encodeTime(name, probe)
+		if err != nil {
+			t.Errorf("rztest: %q: %s is not encoded as a valid JSON value: %v", name, probe.Format(time.RFC3339Nano), err)
+			return
+		}
+		again, _ := encodeTime(name, probe)
+		if again != value {
+			t.Errorf("rztest: %q: %s is not encoded deterministically: %v then %v", name, probe.Format(time.RFC3339Nano), value, again)
+		}
+		switch value.(type) {
+		case json.Number, string:
+		default:
+			t.Errorf("rztest: %q: %s is not encoded as a number or a string: %v", name, probe.Format(time.RFC3339Nano), value)
+			return
+		}
+		values[i] = value
+	}
+
+	leapSecond, _ := encodeTime(name, time.Date(2016, 12, 31, 23, 59, 60, 0, time.UTC))
+	if midnight, _ := encodeTime(name, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)); leapSecond != midnight {
+		t.Errorf("rztest: %q: 23:59:60 is encoded as %v, want %v", name, leapSecond, midnight)
+	}
+
+	numbers := make([]*big.Rat, len(values))
+	for i, value := range values {
+		number, ok := value.(json.Number)
+		if !ok {
+			return
+		}
+		if numbers[i], ok = new(big.Rat).SetString(string(number)); !ok {
+			t.Errorf("rztest: %q: invalid number: %s", name, number)
+			return
+		}
+	}
+	for i := 1; i < len(numbers); i++ {
+		if numbers[i].Cmp(numbers[i-1]) < 0 {
+			t.Errorf("rztest: %q: %s is encoded as %v, before %s encoded as %v", name,
+				timeProbes[i].Format(time.RFC3339Nano), values[i], timeProbes[i-1].Format(time.RFC3339Nano), values[i-1])
+		}
+	}
+
+	second := time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC)
+	start, _ := encodeTime(name, second)
+	end, _ := encodeTime(name, second.Add(time.Second-time.Nanosecond))
+	next, _ := encodeTime(name, second.Add(time.Second))
+	if start != next && end == next {
+		t.Errorf("rztest: %q: 23:59:59.999999999 is rounded up to the next second: %v", name, end)
+	}
+}
+
+// encodeTime logs t with the time format name and returns its decoded JSON value.
+func encodeTime(name string, t time.Time) (interface{}, error) {
+	out := &bytes.Buffer{}
+	logger := rz.New(rz.Writer(out), rz.TimeFieldFormat(name), rz.Fields(rz.Timestamp(false)))
+	logger.Log("", rz.Time("t", t))
+
+	event := map[string]interface{}{}
+	decoder := json.NewDecoder(out)
+	decoder.UseNumber()
+	if err := decoder.Decode(&event); err != nil {
+		return nil, err
+	}
+	return event["t"], nil
+}
@@ -0,0 +1,30 @@
+package rztest
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+func TestTimeFormatConformance(t *testing.T) {
+	rz.RegisterTimeFormat("rztest_gps_ms", rz.EpochTimeEncoder(time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), time.Millisecond))
+	rz.RegisterTimeFormat("rztest_minutes", rz.EpochTimeEncoder(time.Unix(0, 0), time.Minute))
+
+	for _, name := range rz.TimeFormats() {
+		t.Run(name, func(t *testing.T) {
+			TimeFormatConformance(t, name)
+		})
+	}
+
+	rz.RegisterTimeFormat("rztest_rounded", func(dst []byte, t time.Time) []byte {
+		return strconv.AppendInt(dst, t.Round(time.Second).Unix(), 10)
+	})
+	rec := &recordingTB{}
+	TimeFormatConformance(rec, "rztest_rounded")
+	TimeFormatConformance(rec, "rztest_unknown")
+	if len(rec.errors) != 2 {
+		t.Errorf("invalid errors: %v", rec.errors)
+	}
+}
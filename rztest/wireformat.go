@@ -0,0 +1,71 @@
+package rztest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/skerkour/rz"
+)
+
+// TB is the subset of testing.TB used by the conformance tests.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// NewLoggerFunc creates the logger under test, writing to w.
+type NewLoggerFunc func(w io.Writer) rz.Logger
+
+// WireFormatConformance checks that the events emitted by the logger created by newLogger
+// conform to the given version of the wire format. Downstream parsers can run it against their
+// logger configuration to detect breaking changes when upgrading rz.
+//
+//     func TestWireFormat(t *testing.T) {
+//         rztest.WireFormatConformance(t, rz.WireFormatV1, func(w io.Writer) rz.Logger {
+//             return rz.New(rz.Writer(w), rz.WireFormatVersion(rz.WireFormatV1))
+//         })
+//     }
+func WireFormatConformance(t TB, version rz.WireFormat, newLogger NewLoggerFunc) {
+	t.Helper()
+
+	spec, ok := version.Spec()
+	if !ok {
+		t.Errorf("rztest: unknown wire format version: %d", version)
+		return
+	}
+
+	levels := []rz.LogLevel{rz.DebugLevel, rz.InfoLevel, rz.WarnLevel, rz.ErrorLevel}
+	for _, level := range levels {
+		out := &bytes.Buffer{}
+		logger := newLogger(out).With(rz.Level(rz.DebugLevel), rz.Fields(rz.Timestamp(true), rz.Caller(true)))
+		logger.LogWithLevel(level, "conformance", rz.Err(errors.New("conformance error")))
+
+		event := map[string]interface{}{}
+		if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+			t.Errorf("rztest: event is not valid JSON: %v: %s", err, out.String())
+			continue
+		}
+
+		if got, want := event[spec.LevelFieldName], spec.LevelNames[level]; got != want {
+			t.Errorf("rztest: invalid %q field: got %v, want %v", spec.LevelFieldName, got, want)
+		}
+		if got, want := event[spec.MessageFieldName], "conformance"; got != want {
+			t.Errorf("rztest: invalid %q field: got %v, want %v", spec.MessageFieldName, got, want)
+		}
+		if got, want := event[spec.ErrorFieldName], "conformance error"; got != want {
+			t.Errorf("rztest: invalid %q field: got %v, want %v", spec.ErrorFieldName, got, want)
+		}
+		if _, ok := event[spec.CallerFieldName].(string); !ok {
+			t.Errorf("rztest: missing %q field", spec.CallerFieldName)
+		}
+		timestamp, ok := event[spec.TimestampFieldName].(string)
+		if !ok {
+			t.Errorf("rztest: missing %q field", spec.TimestampFieldName)
+		} else if _, err := time.Parse(spec.TimeFieldFormat, timestamp); err != nil {
+			t.Errorf("rztest: invalid %q field: %v", spec.TimestampFieldName, err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package rztest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/skerkour/rz"
+)
+
+func TestWireFormatConformance(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		WireFormatConformance(t, rz.WireFormatLatest, func(w io.Writer) rz.Logger {
+			return rz.New(rz.Writer(w))
+		})
+	})
+
+	t.Run("V1", func(t *testing.T) {
+		WireFormatConformance(t, rz.WireFormatV1, func(w io.Writer) rz.Logger {
+			return rz.New(rz.Writer(w), rz.MessageFieldName("msg"), rz.WireFormatVersion(rz.WireFormatV1))
+		})
+	})
+}
@@ -61,9 +61,16 @@ type SamplerBurst struct {
 	// NextSampler is the sampler used after the burst is reached. If nil,
 	// events are always rejected after the burst.
 	NextSampler LogSampler
+	// ReportDropped adds the field "sampled_dropped" to the first event of a
+	// period, with the number of events dropped since the previous report. It's
+	// only supported when SamplerBurst is the sampler of the logger, or a
+	// sampler of its SamplerLevel.
+	ReportDropped bool
 
 	counter uint32
 	resetAt int64
+	dropped uint64 // dropped in the current period
+	pending uint64 // dropped in the previous periods, to report
 }
 
 // Sample implements the Sampler interface.
@@ -73,10 +80,21 @@ func (s *SamplerBurst) Sample(lvl LogLevel) bool {
 			return true
 		}
 	}
-	if s.NextSampler == nil {
-		return false
+	if s.NextSampler != nil && s.NextSampler.Sample(lvl) {
+		return true
+	}
+	if s.ReportDropped {
+		atomic.AddUint64(&s.dropped, 1)
+	}
+	return false
+}
+
+// takeDropped returns the number of dropped events to report, and resets it.
+func (s *SamplerBurst) takeDropped() uint64 {
+	if !s.ReportDropped {
+		return 0
 	}
-	return s.NextSampler.Sample(lvl)
+	return atomic.SwapUint64(&s.pending, 0)
 }
 
 func (s *SamplerBurst) inc() uint32 {
@@ -91,6 +109,8 @@ func (s *SamplerBurst) inc() uint32 {
 		if !reset {
 			// Lost the race with another goroutine trying to reset.
 			c = atomic.AddUint32(&s.counter, 1)
+		} else if s.ReportDropped {
+			atomic.AddUint64(&s.pending, atomic.SwapUint64(&s.dropped, 0))
 		}
 	} else {
 		c = atomic.AddUint32(&s.counter, 1)
@@ -98,7 +118,23 @@ func (s *SamplerBurst) inc() uint32 {
 	return c
 }
 
-// SamplerLevel applies a different sampler for each level.
+// droppedReporter is implemented by the samplers reporting the number of events they dropped
+// (see SamplerBurst.ReportDropped).
+type droppedReporter interface {
+	takeDropped() uint64
+}
+
+// SamplerLevel applies a different sampler for each level. The events of the levels without a
+// sampler, and of the fatal and panic levels, are never sampled out:
+//
+//     // sample debug 1 in 100, info 1 in 10, keep warn and above
+//     log := rz.New(rz.Sampler(rz.SamplerLevel{
+//         DebugSampler: &rz.SamplerBasic{N: 100},
+//         InfoSampler:  &rz.SamplerBasic{N: 10},
+//     }))
+//
+// The events dropped by the SamplerBurst samplers with ReportDropped are reported by the next
+// event logged, whatever its level.
 type SamplerLevel struct {
 	DebugSampler LogSampler
 	InfoSampler  LogSampler
@@ -128,3 +164,14 @@ func (s SamplerLevel) Sample(lvl LogLevel) bool {
 	}
 	return true
 }
+
+// takeDropped returns the number of dropped events to report of the samplers of all the levels.
+func (s SamplerLevel) takeDropped() uint64 {
+	var dropped uint64
+	for _, sampler := range []LogSampler{s.DebugSampler, s.InfoSampler, s.WarnSampler, s.ErrorSampler} {
+		if reporter, ok := sampler.(droppedReporter); ok {
+			dropped += reporter.takeDropped()
+		}
+	}
+	return dropped
+}
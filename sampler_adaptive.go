@@ -0,0 +1,95 @@
+package rz
+
+import (
+	"sync"
+	"time"
+)
+
+// SamplerAdaptive sheds load during log storms: it measures the number of events per period, and
+// when it exceeds Budget, samples the events of the following period 1 in N so about Budget
+// events are kept, N being computed from the measured throughput. Full logging is restored in the
+// period following one whose throughput fits in the budget. The events beyond Budget in a period
+// are always dropped, so the budget holds during the first period of a storm.
+// Combine it with SamplerLevel to protect the warnings and errors. It's safe for concurrent use.
+//
+//     log := rz.New(rz.Sampler(&rz.SamplerAdaptive{Budget: 1000}))
+type SamplerAdaptive struct {
+	// Budget is the number of events allowed per period. If 0, all events are kept.
+	Budget uint32
+	// Period is the measurement period. Default: one second.
+	Period time.Duration
+	// ReportDropped adds the field "sampled_dropped" to the first event of a
+	// period, with the number of events dropped since the previous report (see
+	// SamplerBurst.ReportDropped).
+	ReportDropped bool
+
+	now func() time.Duration // monotonic clock, for the tests
+
+	mu      sync.Mutex
+	resetAt time.Duration
+	offered uint64 // events of the current period
+	kept    uint32 // events kept in the current period
+	rate    uint64 // 1 in rate events are kept in the current period
+	dropped uint64
+	pending uint64
+}
+
+// Sample implements the Sampler interface.
+func (s *SamplerAdaptive) Sample(lvl LogLevel) bool {
+	if s.Budget == 0 {
+		return true
+	}
+	now := monotonicNow
+	if s.now != nil {
+		now = s.now
+	}
+	t := now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t >= s.resetAt {
+		period := s.Period
+		if period <= 0 {
+			period = time.Second
+		}
+		// the throughput of the previous period sets the rate of this one; a period without any
+		// event means the previous storm is over
+		s.rate = 1
+		if t < s.resetAt+period && s.offered > uint64(s.Budget) {
+			s.rate = (s.offered + uint64(s.Budget) - 1) / uint64(s.Budget)
+		}
+		s.resetAt = t + period
+		s.offered, s.kept = 0, 0
+		s.pending += s.dropped
+		s.dropped = 0
+	}
+	s.offered++
+	if s.kept < s.Budget && (s.offered-1)%s.rate == 0 {
+		s.kept++
+		return true
+	}
+	s.dropped++
+	return false
+}
+
+// Rate returns the current sample rate: 1 in Rate events are kept. It's 1 when there is no storm.
+func (s *SamplerAdaptive) Rate() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rate == 0 {
+		return 1
+	}
+	return s.rate
+}
+
+// takeDropped returns the number of dropped events to report, and resets it.
+func (s *SamplerAdaptive) takeDropped() uint64 {
+	if !s.ReportDropped {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := s.pending
+	s.pending = 0
+	return dropped
+}
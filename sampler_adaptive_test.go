@@ -0,0 +1,46 @@
+package rz
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplerAdaptive(t *testing.T) {
+	var now time.Duration
+	s := &SamplerAdaptive{Budget: 10, ReportDropped: true, now: func() time.Duration { return now }}
+	sample := func(events int) int {
+		kept := 0
+		for i := 0; i < events; i++ {
+			if s.Sample(InfoLevel) {
+				kept++
+			}
+		}
+		return kept
+	}
+
+	if kept := sample(100); kept != 10 || s.Rate() != 1 {
+		t.Errorf("storm start: kept %d, rate %d", kept, s.Rate())
+	}
+	now += time.Second
+	if kept := sample(100); kept != 10 || s.Rate() != 10 {
+		t.Errorf("storm: kept %d, rate %d", kept, s.Rate())
+	}
+	if dropped := s.takeDropped(); dropped != 90 {
+		t.Errorf("takeDropped() = %d", dropped)
+	}
+	now += time.Second
+	if kept := sample(5); kept != 1 || s.Rate() != 10 {
+		t.Errorf("storm end: kept %d, rate %d", kept, s.Rate())
+	}
+	now += time.Second
+	if kept := sample(5); kept != 5 || s.Rate() != 1 {
+		t.Errorf("after storm: kept %d, rate %d", kept, s.Rate())
+	}
+	now += time.Second
+	sample(100)
+	// no event for a while: the storm is over
+	now += time.Minute
+	if kept := sample(5); kept != 5 || s.Rate() != 1 {
+		t.Errorf("after pause: kept %d, rate %d", kept, s.Rate())
+	}
+}
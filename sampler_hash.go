@@ -0,0 +1,67 @@
+package rz
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// HashSampler is a LogProcessor keeping 1 in N events depending on the hash of the string field
+// Field (e.g. "user_id" or "trace_id", including the logger's context fields): all the events
+// with the same value are either kept or dropped, preserving complete per-request narratives at a
+// reduced volume. The decision only depends on the value, so services sampling with the same N
+// and hash keep the same requests. Events without the field are kept. Add it to a logger with
+// the HashSampling option.
+//
+// The field is read from the payload of the events, which requires a JSON based encoder
+// (JSONEncoder or LogfmtEncoder): with the other encoders, all the events are kept.
+type HashSampler struct {
+	field string
+	key   []byte
+	n     uint32
+	hash  HashFunc
+}
+
+// NewHashSampler returns a HashSampler keeping 1 in n values of field, hashed with hashFunc, or
+// DefaultHash if nil.
+func NewHashSampler(field string, n uint32, hashFunc HashFunc) *HashSampler {
+	if hashFunc == nil {
+		hashFunc = DefaultHash
+	}
+	return &HashSampler{
+		field: field,
+		key:   fieldKey(field),
+		n:     n,
+		hash:  hashFunc,
+	}
+}
+
+// HashSampling adds a HashSampler of field hashed with DefaultHash, keeping 1 in n values, to the
+// sample stage of logger's pipeline. As the sampled values may be identifiers shared with other
+// services, they're hashed with the configured, FIPS approved, hash.
+func HashSampling(field string, n uint32) LoggerOption {
+	return AddProcessor(SampleStage, NewHashSampler(field, n, nil))
+}
+
+// Keep returns true if the events with the field value are kept.
+func (s *HashSampler) Keep(value string) bool {
+	if s.n <= 1 {
+		return true
+	}
+	h := s.hash()
+	h.Write([]byte(value))
+	var sum [8]byte
+	copy(sum[:], h.Sum(nil))
+	return binary.BigEndian.Uint64(sum[:])%uint64(s.n) == 0
+}
+
+// Process implements the LogProcessor interface.
+func (s *HashSampler) Process(e *Event, level LogLevel, message string) {
+	if value, ok := e.stringField(s.key); ok && !s.Keep(value) {
+		e.discard()
+	}
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (s *HashSampler) DescribeConfig() string {
+	return "hash_sampler(" + s.field + ", 1/" + strconv.FormatUint(uint64(s.n), 10) + ")"
+}
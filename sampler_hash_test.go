@@ -0,0 +1,70 @@
+package rz
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHashSampling(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), HashSampling("user_id", 4))
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		user := strconv.Itoa(i)
+		userLog := log.With(Fields(String("user_id", user)))
+		userLog.Info("request started")
+		userLog.Info("request finished", Int("status", 200))
+		events := strings.Count(out.String(), "\n")
+		if events != 0 && events != 2 {
+			t.Fatalf("user %s: %d events kept", user, events)
+		}
+		if events == 2 {
+			kept++
+			if !NewHashSampler("user_id", 4, nil).Keep(user) {
+				t.Errorf("Keep(%s) = false", user)
+			}
+		}
+		out.Reset()
+	}
+	if kept < 200 || kept > 300 {
+		t.Errorf("%d users kept on 1000, want ~250", kept)
+	}
+
+	log.Info("no user")
+	if out.Len() == 0 {
+		t.Error("event without the field dropped")
+	}
+}
+
+func TestHashSamplerHashFunc(t *testing.T) {
+	s := NewHashSampler("trace_id", 2, SHA256)
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if s.Keep(strconv.Itoa(i)) {
+			kept++
+		}
+		if s.Keep(strconv.Itoa(i)) != s.Keep(strconv.Itoa(i)) {
+			t.Fatal("Keep() is not consistent")
+		}
+	}
+	if kept < 30 || kept > 70 {
+		t.Errorf("%d values kept on 100, want ~50", kept)
+	}
+	if got, want := s.DescribeConfig(), "hash_sampler(trace_id, 1/2)"; got != want {
+		t.Errorf("DescribeConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestHashSamplerOtherEncoder(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), UseEncoder(testEncoder{name: "other"}), HashSampling("user_id", 1000))
+	for i := 0; i < 10; i++ {
+		log.Info("request", String("user_id", strconv.Itoa(i)))
+	}
+	if got := strings.Count(out.String(), "\n"); got != 10 {
+		t.Errorf("%d events kept on 10, want all of them", got)
+	}
+}
@@ -0,0 +1,136 @@
+package rz
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type subjectCtxKey struct{}
+
+// WithSubject returns a copy of ctx holding the subject (user, tenant...) of the operation. It's
+// read by TargetedSampler from the context attached to events with the Ctx field.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectCtxKey{}, subject)
+}
+
+// SubjectFromCtx returns the subject stored in ctx by WithSubject.
+func SubjectFromCtx(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(subjectCtxKey{}).(string)
+	return subject, ok
+}
+
+// TargetedSampler lets the events of a set of targeted subjects (users, tenants...) bypass the
+// level and the sampler of a logger, up to a cap, so support can deep debug the issues of a few
+// users in production without raising the verbosity of the whole service.
+// Add it to a logger with the TargetedSampling option. It's safe for concurrent use.
+//
+// The subject of an event is read from the context attached with the Ctx field (see WithSubject),
+// or else from the string field named Field (including the logger's context fields). Reading the
+// field requires a JSON based encoder (JSONEncoder or LogfmtEncoder): with the other encoders,
+// only the subjects from the context are targeted.
+type TargetedSampler struct {
+	field  string
+	key    []byte
+	cap    uint32
+	period time.Duration
+
+	mu       sync.Mutex
+	targets  map[string]struct{}
+	count    uint32
+	resetAt  time.Time
+	bypassed uint64
+}
+
+// NewTargetedSampler returns a TargetedSampler reading the subject from field, and letting at most
+// cap events per period (0 for no period, the cap is then global) bypass the logger's restrictions.
+func NewTargetedSampler(field string, cap uint32, period time.Duration, subjects ...string) *TargetedSampler {
+	s := &TargetedSampler{
+		field:  field,
+		key:    fieldKey(field),
+		cap:    cap,
+		period: period,
+	}
+	s.SetTargets(subjects...)
+	return s
+}
+
+// TargetedSampling lets the events of the subjects targeted by sampler bypass the logger's level
+// and sampler. The events dropped by the level are then built to read their subject, so it
+// costs allocations and CPU on disabled levels: remove it once the debugging session is over.
+func TargetedSampling(sampler *TargetedSampler) LoggerOption {
+	return func(logger *Logger) {
+		logger.targeted = sampler
+	}
+}
+
+// SetTargets replaces the targeted subjects, and resets the cap.
+func (s *TargetedSampler) SetTargets(subjects ...string) {
+	targets := make(map[string]struct{}, len(subjects))
+	for _, subject := range subjects {
+		targets[subject] = struct{}{}
+	}
+	s.mu.Lock()
+	s.targets = targets
+	s.count = 0
+	s.resetAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// Targets returns the sorted targeted subjects.
+func (s *TargetedSampler) Targets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	targets := make([]string, 0, len(s.targets))
+	for subject := range s.targets {
+		targets = append(targets, subject)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// Bypassed returns the number of events which bypassed the logger's restrictions.
+func (s *TargetedSampler) Bypassed() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bypassed
+}
+
+// allow returns true if e belongs to a targeted subject and the cap is not reached.
+func (s *TargetedSampler) allow(e *Event) bool {
+	subject, ok := "", false
+	if e.ctx != nil {
+		subject, ok = SubjectFromCtx(e.ctx)
+	}
+	if !ok && s.field != "" {
+		subject, ok = e.stringField(s.key)
+	}
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.targets[subject]; !ok {
+		return false
+	}
+	if s.period > 0 {
+		if now := time.Now(); now.After(s.resetAt) {
+			s.resetAt = now.Add(s.period)
+			s.count = 0
+		}
+	}
+	if s.count >= s.cap {
+		return false
+	}
+	s.count++
+	s.bypassed++
+	return true
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (s *TargetedSampler) DescribeConfig() string {
+	return "targeted(" + strconv.Quote(s.field) + ", " + strconv.Itoa(len(s.Targets())) + " subjects)"
+}
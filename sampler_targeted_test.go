@@ -0,0 +1,60 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTargetedSampler(t *testing.T) {
+	out := &bytes.Buffer{}
+	targeted := NewTargetedSampler("user_id", 3, 0, "42")
+	log := New(Writer(out), Level(ErrorLevel), Sampler(SamplerRandom(0)), TargetedSampling(targeted), Fields(Timestamp(false)))
+
+	log.Debug("targeted", String("user_id", "42"))
+	log.Debug("other", String("user_id", "43"))
+	log.Info("no subject")
+	child := log.With(Fields(String("user_id", "42")))
+	child.Info("targeted context")
+	log.Debug("targeted ctx", Ctx(WithSubject(context.Background(), "42")))
+	log.Debug("capped", String("user_id", "42"))
+
+	want := `{"level":"debug","user_id":"42","message":"targeted"}` + "\n" +
+		`{"level":"info","user_id":"42","message":"targeted context"}` + "\n" +
+		`{"level":"debug","message":"targeted ctx"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := targeted.Bypassed(); got != 3 {
+		t.Errorf("invalid bypassed count: %d", got)
+	}
+
+	out.Reset()
+	targeted.SetTargets("43", "44")
+	if got := targeted.Targets(); len(got) != 2 || got[0] != "43" || got[1] != "44" {
+		t.Errorf("invalid targets: %v", got)
+	}
+	log.Debug("targeted", String("user_id", "42"))
+	log.Debug("other", String("user_id", "43"))
+	if got, want := out.String(), `{"level":"debug","user_id":"43","message":"other"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTargetedSamplerPeriod(t *testing.T) {
+	targeted := NewTargetedSampler("tenant", 1, time.Hour, "acme")
+	e := newEvent(nil, DebugLevel)
+	e.string("tenant", "acme")
+	if !targeted.allow(e) {
+		t.Error("first event is not allowed")
+	}
+	if targeted.allow(e) {
+		t.Error("event over the cap is allowed")
+	}
+	targeted.resetAt = time.Now().Add(-time.Second)
+	if !targeted.allow(e) {
+		t.Error("event of the next period is not allowed")
+	}
+	putEvent(e)
+}
@@ -1,6 +1,7 @@
 package rz
 
 import (
+	"bytes"
 	"testing"
 	"time"
 )
@@ -80,3 +81,46 @@ func BenchmarkSamplers(b *testing.B) {
 		})
 	}
 }
+
+func TestSamplerBurstReportDropped(t *testing.T) {
+	out := &bytes.Buffer{}
+	sampler := &SamplerBurst{Burst: 2, Period: 50 * time.Millisecond, ReportDropped: true}
+	log := New(Writer(out), Fields(Timestamp(false)), Sampler(sampler))
+
+	for i := 0; i < 5; i++ {
+		log.Info("first period")
+	}
+	time.Sleep(60 * time.Millisecond)
+	log.Info("second period")
+	log.Info("second period")
+
+	want := `{"level":"info","message":"first period"}` + "\n" +
+		`{"level":"info","message":"first period"}` + "\n" +
+		`{"level":"info","sampled_dropped":3,"message":"second period"}` + "\n" +
+		`{"level":"info","message":"second period"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSamplerLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), Sampler(SamplerLevel{
+		DebugSampler: &SamplerBasic{N: 100},
+		InfoSampler:  &SamplerBurst{Burst: 1, Period: time.Hour, ReportDropped: true},
+	}))
+
+	for i := 0; i < 200; i++ {
+		log.Debug("debug")
+		log.Info("info")
+	}
+	log.Warn("warn")
+
+	want := `{"level":"info","message":"info"}` + "\n" +
+		`{"level":"debug","message":"debug"}` + "\n" +
+		`{"level":"debug","message":"debug"}` + "\n" +
+		`{"level":"warning","message":"warn"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package rz
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// maxMissedRuns bounds the count of the missed runs of a ScheduledTask, for schedules with
+// short intervals after a long pause.
+const maxMissedRuns = 10000
+
+// Schedule computes the run times of a scheduled task. It's implemented by the schedules of
+// most cron libraries (e.g. robfig/cron's cron.Schedule).
+type Schedule interface {
+	// Next returns the next run time after t.
+	Next(t time.Time) time.Time
+}
+
+type everySchedule time.Duration
+
+// Every returns a Schedule running every interval.
+func Every(interval time.Duration) Schedule {
+	return everySchedule(interval)
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// ScheduledTask logs a summary of each run of a task of an embedded scheduler: "scheduled task
+// run" with the fields "task", "schedule", "start", "duration", "status" ("ok", "error" or
+// "panic"), "next_run" and the error, if any, at info level, or error level if the run failed.
+//
+// When a run starts after the following scheduled time (the scheduler was paused or the previous
+// run overran), "scheduled task missed runs" is logged at warn level first, with the fields
+// "missed" (the number of runs missed) and "missed_since" (the first missed run time).
+// It's safe for concurrent use.
+//
+//     task := logger.ScheduledTask("cleanup", "@every 1h", rz.Every(time.Hour))
+//     scheduler.AddFunc("@every 1h", task.Wrap(cleanup))
+type ScheduledTask struct {
+	logger   Logger
+	name     string
+	spec     string
+	schedule Schedule
+	now      func() time.Time
+
+	mu       sync.Mutex
+	expected time.Time // the next scheduled run time, zero before the first run
+}
+
+// ScheduledTask returns a ScheduledTask logging the runs of the task name, with the schedule
+// described by spec (e.g. its cron expression).
+func (l Logger) ScheduledTask(name, spec string, schedule Schedule) *ScheduledTask {
+	return &ScheduledTask{
+		logger:   l,
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		now:      time.Now,
+	}
+}
+
+// Run runs fn and logs its summary. A panic of fn is recovered, and Run returns an error
+// wrapping ErrJobPanicked.
+func (t *ScheduledTask) Run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	start := t.now()
+	next := t.schedule.Next(start)
+	t.checkMissed(start, next)
+
+	defer func() {
+		fields := []Field{
+			String("task", t.name),
+			String("schedule", t.spec),
+			Time("start", start),
+			Duration("duration", t.now().Sub(start)),
+		}
+		level, status := InfoLevel, "ok"
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanicked, r)
+			level, status = ErrorLevel, "panic"
+			fields = append(fields, String("panic", fmt.Sprint(r)), String("stack", string(debug.Stack())))
+		} else if err != nil {
+			level, status = ErrorLevel, "error"
+		}
+		fields = append(fields, String("status", status), Time("next_run", next), Err(err))
+		t.logger.LogWithLevel(level, "scheduled task run", fields...)
+	}()
+	return fn(ctx)
+}
+
+// checkMissed logs the runs scheduled between the expected run time and start, and updates the
+// expected run time to next.
+func (t *ScheduledTask) checkMissed(start, next time.Time) {
+	t.mu.Lock()
+	expected := t.expected
+	t.expected = next
+	t.mu.Unlock()
+	if expected.IsZero() {
+		return
+	}
+
+	// the run is for the last scheduled time before start, the previous ones were missed
+	missed := 0
+	for following := t.schedule.Next(expected); !following.After(start) && missed < maxMissedRuns; following = t.schedule.Next(following) {
+		missed++
+	}
+	if missed > 0 {
+		t.logger.Warn("scheduled task missed runs",
+			String("task", t.name),
+			String("schedule", t.spec),
+			Int("missed", missed),
+			Time("missed_since", expected),
+		)
+	}
+}
+
+// Wrap returns a function running fn with Run and the background context, for schedulers
+// running func() tasks. The errors are only logged.
+func (t *ScheduledTask) Wrap(fn func(ctx context.Context) error) func() {
+	return func() {
+		t.Run(context.Background(), fn)
+	}
+}
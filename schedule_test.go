@@ -0,0 +1,56 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduledTask(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	task := log.ScheduledTask("cleanup", "@every 1h", Every(time.Hour))
+	now := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	task.now = func() time.Time {
+		return now
+	}
+
+	task.Run(context.Background(), func(ctx context.Context) error {
+		now = now.Add(2 * time.Second)
+		return nil
+	})
+	// on time
+	now = time.Date(2020, 1, 1, 11, 0, 1, 0, time.UTC)
+	task.Wrap(func(ctx context.Context) error {
+		return errors.New("locked")
+	})()
+	// runs of 12:00 and 13:00 missed
+	now = time.Date(2020, 1, 1, 14, 0, 1, 0, time.UTC)
+	err := task.Run(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+	if !errors.Is(err, ErrJobPanicked) {
+		t.Errorf("Run() = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("invalid number of events: %d\n%s", len(lines), out.String())
+	}
+	want := []string{
+		`{"level":"info","task":"cleanup","schedule":"@every 1h","start":"2020-01-01T10:00:00Z","duration":2000,"status":"ok","next_run":"2020-01-01T11:00:00Z","message":"scheduled task run"}`,
+		`{"level":"error","task":"cleanup","schedule":"@every 1h","start":"2020-01-01T11:00:01Z","duration":0,"status":"error","next_run":"2020-01-01T12:00:01Z","error":"locked","message":"scheduled task run"}`,
+		`{"level":"warning","task":"cleanup","schedule":"@every 1h","missed":2,"missed_since":"2020-01-01T12:00:01Z","message":"scheduled task missed runs"}`,
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("invalid event %d:\ngot:  %v\nwant: %v", i, lines[i], line)
+		}
+	}
+	if !strings.Contains(lines[3], `"status":"panic"`) || !strings.Contains(lines[3], `"panic":"boom"`) {
+		t.Errorf("invalid panic event: %s", lines[3])
+	}
+}
@@ -0,0 +1,82 @@
+package rz
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestScrubber(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false), String("owner", "ops@example.com")), Scrub())
+
+	log.Info("payment by jane.doe@example.com",
+		String("card", "4111 1111 1111 1111"),
+		String("order", "4111111111111112"),
+		Dict("request", log.NewDict(String("authorization", "Bearer abc.DEF-123"))),
+		Strings("emails", []string{"a@b.io", "none"}),
+		String("a@b.io", "key"),
+	)
+	want := `{"level":"info","owner":"[EMAIL]","card":"[CARD]","order":"4111111111111112",` +
+		`"request":{"authorization":"[TOKEN]"},"emails":["[EMAIL]","none"],"a@b.io":"key",` +
+		`"message":"payment by [EMAIL]"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestScrubberCustomRule(t *testing.T) {
+	out := &bytes.Buffer{}
+	rule := ScrubRule{Name: "iban", Pattern: regexp.MustCompile(`\bFR\d{2}(\d{4})\d+`), Replacement: "FR**$1"}
+	scrubber := NewScrubber(rule)
+	log := New(Writer(out), Fields(Timestamp(false)), AddProcessor(RedactStage, scrubber))
+
+	log.Warn("refund", String("iban", "FR7630006000011234567890189"), Int("amount", 10))
+	want := `{"level":"warning","iban":"FR**3000","amount":10,"message":"refund"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := scrubber.DescribeConfig(), "scrubber(iban)"; got != want {
+		t.Errorf("DescribeConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := map[string]bool{
+		"4111111111111111":    true,
+		"5500-0000-0000-0004": true,
+		"4111111111111112":    false,
+		"1234567890123":       false,
+	}
+	for number, want := range tests {
+		if got := luhnValid(number); got != want {
+			t.Errorf("luhnValid(%q) = %v, want %v", number, got, want)
+		}
+	}
+}
+
+func BenchmarkScrubber(b *testing.B) {
+	fields := []Field{String("user", "42"), String("path", "/api/orders"), Int("status", 200)}
+	b.Run("disabled", func(b *testing.B) {
+		log := New(Writer(io.Discard))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.Info("order created", fields...)
+		}
+	})
+	b.Run("no match", func(b *testing.B) {
+		log := New(Writer(io.Discard), Scrub())
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.Info("order created", fields...)
+		}
+	})
+	b.Run("match", func(b *testing.B) {
+		log := New(Writer(io.Discard), Scrub())
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.Info("order created", String("email", "jane.doe@example.com"), String("card", "4111 1111 1111 1111"))
+		}
+	})
+}
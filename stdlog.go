@@ -0,0 +1,70 @@
+package rz
+
+import (
+	"log"
+	"strings"
+)
+
+// levelPrefixes are the level prefixes recognized by ParseLevelPrefix.
+var levelPrefixes = map[string]LogLevel{
+	"TRACE":    DebugLevel,
+	"DEBUG":    DebugLevel,
+	"INFO":     InfoLevel,
+	"NOTICE":   InfoLevel,
+	"WARN":     WarnLevel,
+	"WARNING":  WarnLevel,
+	"ERR":      ErrorLevel,
+	"ERROR":    ErrorLevel,
+	"CRIT":     ErrorLevel,
+	"CRITICAL": ErrorLevel,
+	"FATAL":    FatalLevel,
+	"PANIC":    PanicLevel,
+}
+
+// ParseLevelPrefix recognizes the level prefixes used by third-party libraries logging with the
+// standard library log, e.g. "[ERROR] connection lost" or "[warn] retrying". It returns the
+// level and the message without the prefix, or false if message has no known prefix.
+func ParseLevelPrefix(message string) (level LogLevel, rest string, ok bool) {
+	trimmed := strings.TrimLeft(message, " ")
+	if len(trimmed) < 3 || trimmed[0] != '[' {
+		return NoLevel, message, false
+	}
+	end := strings.IndexByte(trimmed, ']')
+	if end < 0 {
+		return NoLevel, message, false
+	}
+	level, ok = levelPrefixes[strings.ToUpper(trimmed[1:end])]
+	if !ok {
+		return NoLevel, message, false
+	}
+	return level, strings.TrimLeft(trimmed[end+1:], " "), true
+}
+
+type stdLogWriter struct {
+	logger Logger
+	level  LogLevel
+}
+
+func (w stdLogWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if n > 0 && p[n-1] == '\n' {
+		// Trim CR added by stdlog.
+		p = p[0 : n-1]
+	}
+	level, message, ok := ParseLevelPrefix(string(p))
+	if !ok {
+		level = w.level
+	}
+	// fatal and panic levels are only logged: the standard library log exits or panics itself
+	w.logger.LogWithLevel(level, message)
+	return
+}
+
+// StdLogger returns a standard library *log.Logger writing with the logger at level, e.g. for
+// the ErrorLog of an http.Server. The messages with a level prefix (see ParseLevelPrefix) are
+// written at the level of the prefix instead.
+//
+//    server := &http.Server{ErrorLog: logger.StdLogger(rz.ErrorLevel)}
+func (l Logger) StdLogger(level LogLevel) *log.Logger {
+	return log.New(stdLogWriter{logger: l, level: level}, "", 0)
+}
@@ -0,0 +1,48 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	std := log.StdLogger(WarnLevel)
+	std.Print("tls handshake error")
+	std.Print("[ERROR] connection lost")
+	std.Printf(" [debug]  retrying in %ds", 2)
+	std.Print("[unknown] kept as is")
+
+	want := `{"level":"warning","message":"tls handshake error"}` + "\n" +
+		`{"level":"error","message":"connection lost"}` + "\n" +
+		`{"level":"debug","message":"retrying in 2s"}` + "\n" +
+		`{"level":"warning","message":"[unknown] kept as is"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestParseLevelPrefix(t *testing.T) {
+	tests := []struct {
+		message string
+		level   LogLevel
+		rest    string
+		ok      bool
+	}{
+		{"[WARN] disk almost full", WarnLevel, "disk almost full", true},
+		{"[Warning]disk almost full", WarnLevel, "disk almost full", true},
+		{"[ERR] failed", ErrorLevel, "failed", true},
+		{"[FATAL] stop", FatalLevel, "stop", true},
+		{"[TRACE] dump", DebugLevel, "dump", true},
+		{"no prefix", NoLevel, "no prefix", false},
+		{"[request 42] done", NoLevel, "[request 42] done", false},
+		{"[INFO", NoLevel, "[INFO", false},
+	}
+	for _, test := range tests {
+		level, rest, ok := ParseLevelPrefix(test.message)
+		if level != test.level || rest != test.rest || ok != test.ok {
+			t.Errorf("ParseLevelPrefix(%q) = %v, %q, %v", test.message, level, rest, ok)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package rz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// HealthChecker defines an interface a writer shipping events to a remote endpoint may implement
+// in order to report whether it's able to deliver events, e.g. to systemd with NotifySystemd.
+type HealthChecker interface {
+	// CheckHealth returns an error if the writer can't deliver events.
+	CheckHealth() error
+}
+
+// CheckHealth returns the error of w if it implements HealthChecker, nil otherwise.
+func CheckHealth(w io.Writer) error {
+	if hc, ok := w.(HealthChecker); ok {
+		return hc.CheckHealth()
+	}
+	return nil
+}
+
+// SdNotify sends state (e.g. "READY=1") to the systemd notification socket of the process, given
+// by the NOTIFY_SOCKET environment variable. It returns false if the variable is not set, i.e.
+// the process is not run by systemd with notifications enabled.
+func SdNotify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SdWatchdogInterval returns the watchdog interval of the unit, given by the WATCHDOG_USEC
+// environment variable, or 0 if the watchdog is not enabled for the process.
+func SdWatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("rz: invalid WATCHDOG_USEC: %q", usec)
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// NotifySystemd reports the readiness and the liveness of the log pipeline to systemd, until ctx
+// is done: READY=1 is sent once all the writers implementing HealthChecker are healthy (checked
+// every second), then, if the watchdog of the unit is enabled, WATCHDOG=1 is sent every half
+// watchdog interval while they stay healthy, so systemd restarts the unit when the pipeline is
+// down for too long. The errors of the writers are reported in STATUS. Units can then depend on
+// the log pipeline being up with Type=notify:
+//
+//     go rz.NotifySystemd(ctx, clickhouseWriter)
+//
+// It returns nil immediately if the process is not run by systemd with notifications enabled,
+// and ctx.Err() once ctx is done.
+func NotifySystemd(ctx context.Context, writers ...io.Writer) error {
+	if os.Getenv("NOTIFY_SOCKET") == "" {
+		return nil
+	}
+	watchdog, err := SdWatchdogInterval()
+	if err != nil {
+		return err
+	}
+	check := func() error {
+		for _, w := range writers {
+			if err := CheckHealth(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	status := ""
+	for {
+		err := check()
+		if err == nil {
+			break
+		}
+		if s := "log pipeline unavailable: " + err.Error(); s != status {
+			status = s
+			SdNotify("STATUS=" + status)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if _, err := SdNotify("READY=1\nSTATUS=log pipeline ready"); err != nil {
+		return err
+	}
+	if watchdog == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ticker.Reset(watchdog / 2)
+	healthy := true
+	for {
+		if err := check(); err != nil {
+			healthy = false
+			SdNotify("STATUS=log pipeline unavailable: " + err.Error())
+		} else {
+			state := "WATCHDOG=1"
+			if !healthy {
+				state += "\nSTATUS=log pipeline ready"
+				healthy = true
+			}
+			SdNotify(state)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package rz
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+type healthWriter struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (w *healthWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *healthWriter) CheckHealth() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *healthWriter) setErr(err error) {
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+}
+
+func TestNotifySystemd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix datagram sockets are not supported")
+	}
+	dir, err := os.MkdirTemp("", "rz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	w := &healthWriter{err: errors.New("connection refused")}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- NotifySystemd(ctx, MultiLevelWriter(NewAsyncWriter(w)))
+	}()
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+	if got := read(); got != "STATUS=log pipeline unavailable: connection refused" {
+		t.Errorf("invalid state: %q", got)
+	}
+	w.setErr(nil)
+	if got := read(); got != "READY=1\nSTATUS=log pipeline ready" {
+		t.Errorf("invalid state: %q", got)
+	}
+	if got := read(); got != "WATCHDOG=1" {
+		t.Errorf("invalid state: %q", got)
+	}
+	w.setErr(errors.New("timeout"))
+	for got := read(); got == "WATCHDOG=1"; got = read() {
+	}
+	w.setErr(nil)
+	for got := read(); got != "WATCHDOG=1\nSTATUS=log pipeline ready"; got = read() {
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("invalid error: %v", err)
+	}
+}
+
+func TestNotifySystemdWithoutSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := SdNotify("READY=1"); sent || err != nil {
+		t.Errorf("notification sent without socket: %v, %v", sent, err)
+	}
+	if err := NotifySystemd(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,177 @@
+package rz
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Names of the time formats registered by default. They can be used with the TimeFieldFormat
+// option in place of a time layout.
+const (
+	// TimeFormatUnix encodes times as the integer number of seconds since the UNIX epoch.
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMs encodes times as the integer number of milliseconds since the UNIX epoch.
+	TimeFormatUnixMs = "unix_ms"
+	// TimeFormatUnixMicro encodes times as the integer number of microseconds since the UNIX epoch.
+	TimeFormatUnixMicro = "unix_us"
+	// TimeFormatUnixNano encodes times as the integer number of nanoseconds since the UNIX epoch.
+	TimeFormatUnixNano = "unix_ns"
+	// TimeFormatUnixDecimal encodes times as the number of seconds since the UNIX epoch with
+	// a nanosecond decimal part, without going through a float so no precision is lost.
+	TimeFormatUnixDecimal = "unix_decimal"
+)
+
+// TimeEncoder appends t encoded as a JSON value (a number or a quoted string) to dst.
+type TimeEncoder func(dst []byte, t time.Time) []byte
+
+var (
+	timeFormatsMu sync.Mutex
+	// timeFormats holds a map[string]TimeEncoder, replaced on each registration so the
+	// encoding path can read it without locking.
+	timeFormats atomic.Value
+)
+
+func init() {
+	timeFormats.Store(map[string]TimeEncoder{
+		TimeFormatUnix:        EpochTimeEncoder(time.Unix(0, 0), time.Second),
+		TimeFormatUnixMs:      EpochTimeEncoder(time.Unix(0, 0), time.Millisecond),
+		TimeFormatUnixMicro:   EpochTimeEncoder(time.Unix(0, 0), time.Microsecond),
+		TimeFormatUnixNano:    EpochTimeEncoder(time.Unix(0, 0), time.Nanosecond),
+		TimeFormatUnixDecimal: appendUnixDecimal,
+	})
+}
+
+// RegisterTimeFormat registers encoder under name, replacing any format previously registered
+// with the same name. Loggers whose time field format is name then encode their timestamps and
+// time fields with encoder instead of formatting them as a time layout.
+// It's safe for concurrent use, but formats are usually registered at init time.
+func RegisterTimeFormat(name string, encoder TimeEncoder) {
+	timeFormatsMu.Lock()
+	defer timeFormatsMu.Unlock()
+	old := timeFormats.Load().(map[string]TimeEncoder)
+	formats := make(map[string]TimeEncoder, len(old)+1)
+	for k, v := range old {
+		formats[k] = v
+	}
+	formats[name] = encoder
+	timeFormats.Store(formats)
+}
+
+// LookupTimeFormat returns the encoder registered under name.
+func LookupTimeFormat(name string) (TimeEncoder, bool) {
+	encoder, ok := timeFormats.Load().(map[string]TimeEncoder)[name]
+	return encoder, ok
+}
+
+// TimeFormats returns the sorted names of the registered time formats.
+func TimeFormats() []string {
+	formats := timeFormats.Load().(map[string]TimeEncoder)
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EpochTimeEncoder returns a TimeEncoder encoding times as the integer number of resolution
+// units elapsed since epoch (negative before epoch). Partial units are truncated towards the
+// past, so an event is never stamped with a time later than the one it happened at.
+//
+// Resolutions dividing or multiple of a second are computed from the seconds and nanoseconds
+// of the times, so they don't overflow for times more than 292 years away from epoch (counts
+// exceeding an int64 are still encoded exactly).
+func EpochTimeEncoder(epoch time.Time, resolution time.Duration) TimeEncoder {
+	if resolution <= 0 {
+		resolution = time.Nanosecond
+	}
+	epochSec, epochNsec := epoch.Unix(), int64(epoch.Nanosecond())
+	return func(dst []byte, t time.Time) []byte {
+		sec, nsec := t.Unix()-epochSec, int64(t.Nanosecond())-epochNsec
+		if nsec < 0 {
+			sec--
+			nsec += int64(time.Second)
+		}
+		var units int64
+		switch {
+		case time.Second%resolution == 0:
+			perSecond := int64(time.Second / resolution)
+			if sec > math.MaxInt64/perSecond-1 || sec < math.MinInt64/perSecond {
+				// e.g. nanoseconds after year 2262: the count doesn't fit in an int64
+				units := new(big.Int).Mul(big.NewInt(sec), big.NewInt(perSecond))
+				return units.Add(units, big.NewInt(nsec/int64(resolution))).Append(dst, 10)
+			}
+			units = sec*perSecond + nsec/int64(resolution)
+		case resolution%time.Second == 0:
+			units = floorDiv(sec, int64(resolution/time.Second))
+		default:
+			units = floorDiv(int64(t.Sub(epoch)), int64(resolution))
+		}
+		return strconv.AppendInt(dst, units, 10)
+	}
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// appendUnixDecimal is the TimeEncoder of the TimeFormatUnixDecimal format.
+func appendUnixDecimal(dst []byte, t time.Time) []byte {
+	sec, nsec := t.Unix(), t.Nanosecond()
+	if sec < 0 && nsec > 0 {
+		// -1.5s is stored as sec=-2, nsec=5e8
+		sec++
+		nsec = int(time.Second) - nsec
+		if sec == 0 {
+			dst = append(dst, '-')
+		}
+	}
+	dst = strconv.AppendInt(dst, sec, 10)
+	dst = append(dst, '.')
+	frac := strconv.Itoa(nsec)
+	for i := len(frac); i < 9; i++ {
+		dst = append(dst, '0')
+	}
+	return append(dst, frac...)
+}
+
+// appendTime appends t encoded with the registered time format named format, or formatted
+// with the format layout by encoder if there is none.
+func appendTime(encoder Encoder, dst []byte, t time.Time, format string) []byte {
+	if timeEncoder, ok := LookupTimeFormat(format); ok {
+		if jsonBased(encoder) {
+			return timeEncoder(dst, t)
+		}
+		return appendJSON(encoder, dst, timeEncoder(nil, t))
+	}
+	return encoder.AppendTime(dst, t, format)
+}
+
+// appendTimes is like appendTime for a list of times.
+func appendTimes(encoder Encoder, dst []byte, vals []time.Time, format string) []byte {
+	timeEncoder, ok := LookupTimeFormat(format)
+	if !ok {
+		return encoder.AppendTimes(dst, vals, format)
+	}
+	dst = encoder.AppendArrayStart(dst)
+	for i, t := range vals {
+		if i > 0 {
+			dst = encoder.AppendArrayDelim(dst)
+		}
+		if jsonBased(encoder) {
+			dst = timeEncoder(dst, t)
+		} else {
+			dst = appendJSON(encoder, dst, timeEncoder(nil, t))
+		}
+	}
+	return encoder.AppendArrayEnd(dst)
+}
@@ -0,0 +1,68 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTimeFormatRegistry(t *testing.T) {
+	ts := time.Date(2017, 1, 1, 0, 0, 1, 500000000, time.UTC)
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{TimeFormatUnix, `1483228801`},
+		{TimeFormatUnixMs, `1483228801500`},
+		{TimeFormatUnixMicro, `1483228801500000`},
+		{TimeFormatUnixNano, `1483228801500000000`},
+		{TimeFormatUnixDecimal, `1483228801.500000000`},
+		{time.RFC3339, `"2017-01-01T00:00:01Z"`},
+	}
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		log := New(Writer(out), TimeFieldFormat(c.format), TimestampFunc(func() time.Time { return ts }))
+		log.Log("", Time("t", ts), Times("ts", []time.Time{ts, ts}))
+		want := `{"t":` + c.want + `,"ts":[` + c.want + `,` + c.want + `],"timestamp":` + c.want + "}\n"
+		if got := out.String(); got != want {
+			t.Errorf("%s: invalid log output:\ngot:  %v\nwant: %v", c.format, got, want)
+		}
+	}
+
+	RegisterTimeFormat("test_days", EpochTimeEncoder(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 24*time.Hour))
+	if _, ok := LookupTimeFormat("test_days"); !ok {
+		t.Error("test_days is not registered")
+	}
+	out := &bytes.Buffer{}
+	log := New(Writer(out), TimeFieldFormat("test_days"), Fields(Timestamp(false)))
+	log.Log("", Time("t", ts), Time("before", time.Date(1999, 12, 31, 23, 0, 0, 0, time.UTC)))
+	if got, want := out.String(), `{"t":6210,"before":-1}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEpochTimeEncoderBeforeEpoch(t *testing.T) {
+	cases := []struct {
+		t          time.Time
+		resolution time.Duration
+		want       string
+	}{
+		{time.Unix(-1, 500000000), time.Second, "-1"},
+		{time.Unix(-1, 500000000), time.Millisecond, "-500"},
+		{time.Unix(0, -1), time.Microsecond, "-1"},
+		{time.Unix(-61, 0), time.Minute, "-2"},
+		{time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Nanosecond, "-62135596800000000000"},
+		{time.Date(2262, 4, 12, 0, 0, 0, 0, time.UTC), time.Nanosecond, "9223372800000000000"},
+	}
+	for _, c := range cases {
+		if got := string(EpochTimeEncoder(time.Unix(0, 0), c.resolution)(nil, c.t)); got != c.want {
+			t.Errorf("%v/%v: got %s, want %s", c.t, c.resolution, got, c.want)
+		}
+	}
+	if got, want := string(appendUnixDecimal(nil, time.Unix(-2, 500000000))), "-1.500000000"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := string(appendUnixDecimal(nil, time.Unix(-1, 1))), "-0.999999999"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
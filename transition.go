@@ -0,0 +1,114 @@
+package rz
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Transition describes a state transition of an entity (e.g. an order or a payment).
+// It implements the LogObjectMarshaler interface.
+type Transition struct {
+	// Entity is the type of the entity, e.g. "order".
+	Entity string
+	// ID identifies the entity.
+	ID     string
+	From   string
+	To     string
+	Reason string
+	// Actor is who or what triggered the transition.
+	Actor string
+}
+
+// MarshalRzObject implements the LogObjectMarshaler interface. Empty fields are omitted.
+func (t Transition) MarshalRzObject(e *Event) {
+	e.string("entity", t.Entity)
+	if t.ID != "" {
+		e.string("id", t.ID)
+	}
+	e.string("from", t.From)
+	e.string("to", t.To)
+	if t.Reason != "" {
+		e.string("reason", t.Reason)
+	}
+	if t.Actor != "" {
+		e.string("actor", t.Actor)
+	}
+}
+
+// TransitionTable holds the allowed state transitions of entities. It's safe for concurrent use.
+type TransitionTable struct {
+	mu          sync.RWMutex
+	transitions map[string]map[string]map[string]struct{}
+}
+
+// NewTransitionTable returns an empty TransitionTable.
+func NewTransitionTable() *TransitionTable {
+	return &TransitionTable{transitions: map[string]map[string]map[string]struct{}{}}
+}
+
+// Allow registers the transitions of entity from from to each state of to.
+func (t *TransitionTable) Allow(entity, from string, to ...string) *TransitionTable {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	states, ok := t.transitions[entity]
+	if !ok {
+		states = map[string]map[string]struct{}{}
+		t.transitions[entity] = states
+	}
+	targets, ok := states[from]
+	if !ok {
+		targets = map[string]struct{}{}
+		states[from] = targets
+	}
+	for _, state := range to {
+		targets[state] = struct{}{}
+	}
+	return t
+}
+
+// Valid returns true if the transition is allowed. Transitions of entities without any registered
+// transition are always valid.
+func (t *TransitionTable) Valid(transition Transition) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	states, ok := t.transitions[transition.Entity]
+	if !ok {
+		return true
+	}
+	_, ok = states[transition.From][transition.To]
+	return ok
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (t *TransitionTable) DescribeConfig() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	entities := make([]string, 0, len(t.transitions))
+	for entity := range t.transitions {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return "transitions(" + strings.Join(entities, ", ") + ")"
+}
+
+// Transitions sets the table used by Logger.Transition to validate transitions.
+func Transitions(table *TransitionTable) LoggerOption {
+	return func(logger *Logger) {
+		logger.transitions = table
+	}
+}
+
+// Transition logs the state transition with info level, under the "transition" key and with a
+// consistent schema. If the logger has a transition table (see the Transitions option) and the
+// transition is not allowed, it's logged with error level and the "transition_valid" field set
+// to false.
+func (l *Logger) Transition(transition Transition, fields ...Field) {
+	level := InfoLevel
+	fields = append([]Field{Object("transition", transition)}, fields...)
+	if l.transitions != nil && !l.transitions.Valid(transition) {
+		level = ErrorLevel
+		fields = append(fields, Bool("transition_valid", false))
+	}
+	l.logEvent(level, "state transition", nil, fields, false)
+}
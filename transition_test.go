@@ -0,0 +1,25 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	table := NewTransitionTable().
+		Allow("order", "created", "paid", "cancelled").
+		Allow("order", "paid", "shipped")
+
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)), Transitions(table))
+	log.Transition(Transition{Entity: "order", ID: "42", From: "created", To: "paid", Actor: "stripe"})
+	log.Transition(Transition{Entity: "order", ID: "42", From: "created", To: "shipped", Reason: "manual"}, String("foo", "bar"))
+	log.Transition(Transition{Entity: "user", From: "active", To: "banned"})
+
+	want := `{"level":"info","transition":{"entity":"order","id":"42","from":"created","to":"paid","actor":"stripe"},"message":"state transition"}` + "\n" +
+		`{"level":"error","transition":{"entity":"order","id":"42","from":"created","to":"shipped","reason":"manual"},"foo":"bar","transition_valid":false,"message":"state transition"}` + "\n" +
+		`{"level":"info","transition":{"entity":"user","from":"active","to":"banned"},"message":"state transition"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package rz
+
+import (
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator generates monotonic ULIDs: within the same millisecond, the random part of the
+// previous ULID is incremented, so IDs are strictly increasing.
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+var ulids = &ulidGenerator{}
+
+// NewULID returns a new ULID (https://github.com/ulid/spec) for the time t: 26 characters
+// sortable by time, unique across processes.
+func NewULID(t time.Time) string {
+	return ulids.next(t)
+}
+
+func (g *ulidGenerator) next(t time.Time) string {
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	g.mu.Lock()
+	if ms > g.lastMs {
+		g.lastMs = ms
+		rand.Read(g.entropy[:])
+	} else {
+		// same (or earlier) millisecond: keep the last one and increment the entropy
+		ms = g.lastMs
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+		}
+	}
+	var id [16]byte
+	id[0], id[1], id[2], id[3], id[4], id[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	copy(id[6:], g.entropy[:])
+	g.mu.Unlock()
+	return encodeULID(id)
+}
+
+// encodeULID encodes the 128 bits of id in 26 Crockford's base32 characters.
+func encodeULID(id [16]byte) string {
+	var dst [26]byte
+	// 130 bits of output for 128 bits of input: the first character carries 3 bits
+	var acc uint32
+	bits := 2 // leading padding bits
+	j := 0
+	for _, b := range id {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			dst[j] = crockfordBase32[(acc>>uint(bits))&0x1f]
+			j++
+		}
+	}
+	return string(dst[:])
+}
+
+// ULIDTime returns the time encoded in the ULID id, with a millisecond precision. ok is false if id
+// is not a valid ULID.
+func ULIDTime(id string) (t time.Time, ok bool) {
+	if len(id) != 26 || id[0] > '7' {
+		return time.Time{}, false
+	}
+	var ms uint64
+	for i := 0; i < len(id); i++ {
+		c := strings.IndexByte(crockfordBase32, id[i])
+		if c < 0 {
+			return time.Time{}, false
+		}
+		if i < 10 {
+			ms = ms<<5 | uint64(c)
+		}
+	}
+	return time.Unix(0, int64(ms)*int64(time.Millisecond)), true
+}
@@ -0,0 +1,61 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestULID(t *testing.T) {
+	// the spec example: the timestamp 1469918176385 encodes to 01ARYZ6S41
+	var id [16]byte
+	ms := uint64(1469918176385)
+	id[0], id[1], id[2], id[3], id[4], id[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	if got := encodeULID(id)[:10]; got != "01ARYZ6S41" {
+		t.Errorf("invalid timestamp encoding: %s", got)
+	}
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	if got := encodeULID(max); got != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("invalid max encoding: %s", got)
+	}
+
+	now := time.Now()
+	previous := NewULID(now)
+	for i := 0; i < 1000; i++ {
+		next := NewULID(now)
+		if len(next) != 26 || next <= previous {
+			t.Fatalf("ULIDs not monotonic: %s then %s", previous, next)
+		}
+		previous = next
+	}
+
+	if got, ok := ULIDTime("01ARYZ6S41" + "0000000000000000"); !ok || got.UnixNano() != 1469918176385*int64(time.Millisecond) {
+		t.Errorf("ULIDTime = %v, %v", got, ok)
+	}
+	if got, ok := ULIDTime(previous); !ok || !got.Equal(now.Truncate(time.Millisecond)) {
+		t.Errorf("ULIDTime(%s) = %v, %v, want %v", previous, got, ok, now)
+	}
+	for _, invalid := range []string{"", "01ARYZ6S41", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "01ARYZ6S41000000000000000U"} {
+		if _, ok := ULIDTime(invalid); ok {
+			t.Errorf("ULIDTime(%q) is valid", invalid)
+		}
+	}
+}
+
+func TestEventIDs(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(out), EventIDs("id"))
+	log.Info("a")
+	first, ok := EventID(out.Bytes(), "id")
+	if !ok || len(first) != 26 {
+		t.Fatalf("invalid event ID: %q in %s", first, out.String())
+	}
+	out.Reset()
+	log.Info("b")
+	if second, _ := EventID(out.Bytes(), "id"); second <= first {
+		t.Errorf("invalid event IDs: %s then %s", first, second)
+	}
+}
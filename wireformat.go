@@ -0,0 +1,76 @@
+package rz
+
+// WireFormat identifies a version of the events' wire format: the names of the
+// built-in fields and the encoding of levels and timestamps.
+// Downstream parsers can rely on a given version being stable across releases.
+type WireFormat uint8
+
+const (
+	// WireFormatV1 is the first version of the wire format.
+	WireFormatV1 WireFormat = iota + 1
+
+	// WireFormatLatest is the version of the wire format emitted by default.
+	WireFormatLatest = WireFormatV1
+)
+
+// WireFormatSpec describes the built-in fields of a wire format version.
+type WireFormatSpec struct {
+	Version             WireFormat
+	TimestampFieldName  string
+	LevelFieldName      string
+	MessageFieldName    string
+	ErrorFieldName      string
+	CallerFieldName     string
+	ErrorStackFieldName string
+	TimeFieldFormat     string
+	// LevelNames maps each level to its encoded value.
+	LevelNames map[LogLevel]string
+}
+
+// Spec returns the specification of the wire format version. ok is false if
+// the version is unknown.
+func (v WireFormat) Spec() (spec WireFormatSpec, ok bool) {
+	switch v {
+	case WireFormatV1:
+		return WireFormatSpec{
+			Version:             WireFormatV1,
+			TimestampFieldName:  "timestamp",
+			LevelFieldName:      "level",
+			MessageFieldName:    "message",
+			ErrorFieldName:      "error",
+			CallerFieldName:     "caller",
+			ErrorStackFieldName: "stack",
+			TimeFieldFormat:     "2006-01-02T15:04:05Z07:00",
+			LevelNames: map[LogLevel]string{
+				DebugLevel: "debug",
+				InfoLevel:  "info",
+				WarnLevel:  "warning",
+				ErrorLevel: "error",
+				FatalLevel: "fatal",
+				PanicLevel: "panic",
+			},
+		}, true
+	}
+	return WireFormatSpec{}, false
+}
+
+// WireFormatVersion configures the logger to emit events using the given version of the wire
+// format. It can be used to keep emitting a previous version after an upgrade of rz, until all
+// the downstream parsers are updated. It resets the built-in fields names and the time format,
+// so it should be passed before any option customizing them.
+// Unknown versions are ignored.
+func WireFormatVersion(version WireFormat) LoggerOption {
+	return func(logger *Logger) {
+		spec, ok := version.Spec()
+		if !ok {
+			return
+		}
+		logger.timestampFieldName = spec.TimestampFieldName
+		logger.levelFieldName = spec.LevelFieldName
+		logger.messageFieldName = spec.MessageFieldName
+		logger.errorFieldName = spec.ErrorFieldName
+		logger.callerFieldName = spec.CallerFieldName
+		logger.errorStackFieldName = spec.ErrorStackFieldName
+		logger.timeFieldFormat = spec.TimeFieldFormat
+	}
+}
@@ -0,0 +1,31 @@
+package rz
+
+import "testing"
+
+func TestWireFormatLatestDefaults(t *testing.T) {
+	spec, ok := WireFormatLatest.Spec()
+	if !ok {
+		t.Fatal("WireFormatLatest.Spec() is unknown")
+	}
+	fields := []struct {
+		name, got, want string
+	}{
+		{"timestamp", DefaultTimestampFieldName, spec.TimestampFieldName},
+		{"level", DefaultLevelFieldName, spec.LevelFieldName},
+		{"message", DefaultMessageFieldName, spec.MessageFieldName},
+		{"error", DefaultErrorFieldName, spec.ErrorFieldName},
+		{"caller", DefaultCallerFieldName, spec.CallerFieldName},
+		{"stack", DefaultErrorStackFieldName, spec.ErrorStackFieldName},
+		{"time format", DefaultTimeFieldFormat, spec.TimeFieldFormat},
+	}
+	for _, field := range fields {
+		if field.got != field.want {
+			t.Errorf("default %s field does not match the latest wire format: got %q, want %q", field.name, field.got, field.want)
+		}
+	}
+	for level, name := range spec.LevelNames {
+		if level.String() != name {
+			t.Errorf("level %d does not match the latest wire format: got %q, want %q", level, level.String(), name)
+		}
+	}
+}
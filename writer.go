@@ -1,6 +1,7 @@
 package rz
 
 import (
+	"context"
 	"io"
 	"sync"
 )
@@ -12,6 +13,30 @@ type LevelWriter interface {
 	WriteLevel(level LogLevel, p []byte) (n int, err error)
 }
 
+// Flusher defines an interface a writer buffering events (asynchronously or in batches)
+// may implement in order to let the logger wait for the events to be written.
+type Flusher interface {
+	// Flush blocks until all the buffered events are written.
+	Flush() error
+}
+
+// flush flushes w if it implements the Flusher interface.
+func flush(w interface{}) error {
+	if f, ok := w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// SharedWriter defines an interface a writer retaining events after the write call returns
+// (e.g. an asynchronous writer) may implement in order to receive a copy of the event
+// which is shared with the other writers of a MultiLevelWriter, instead of copying it itself.
+type SharedWriter interface {
+	// WriteShared writes p, bounded by ctx like ContextWriter.WriteContext. The writer may
+	// retain p but must not modify it.
+	WriteShared(ctx context.Context, level LogLevel, p []byte) (n int, err error)
+}
+
 type levelWriterAdapter struct {
 	io.Writer
 }
@@ -20,6 +45,21 @@ func (lw levelWriterAdapter) WriteLevel(l LogLevel, p []byte) (n int, err error)
 	return lw.Write(p)
 }
 
+// Flush implements the Flusher interface.
+func (lw levelWriterAdapter) Flush() error {
+	return flush(lw.Writer)
+}
+
+// CloseContext implements the ContextCloser interface.
+func (lw levelWriterAdapter) CloseContext(ctx context.Context) error {
+	return CloseWriter(ctx, lw.Writer)
+}
+
+// CheckHealth implements the HealthChecker interface.
+func (lw levelWriterAdapter) CheckHealth() error {
+	return CheckHealth(lw.Writer)
+}
+
 type syncWriter struct {
 	mu sync.Mutex
 	lw LevelWriter
@@ -52,10 +92,46 @@ func (s *syncWriter) WriteLevel(l LogLevel, p []byte) (n int, err error) {
 	return s.lw.WriteLevel(l, p)
 }
 
+// WriteContext implements the ContextWriter interface.
+func (s *syncWriter) WriteContext(ctx context.Context, l LogLevel, p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeContext(ctx, s.lw, l, p)
+}
+
+// CloseContext implements the ContextCloser interface.
+func (s *syncWriter) CloseContext(ctx context.Context) error {
+	return CloseWriter(ctx, s.lw)
+}
+
+// Flush implements the Flusher interface.
+func (s *syncWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return flush(s.lw)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (s *syncWriter) DescribeConfig() string {
+	return "sync(" + describe(s.lw) + ")"
+}
+
 type multiLevelWriter struct {
 	writers []LevelWriter
 }
 
+// DescribeConfig implements the ConfigDescriber interface.
+func (t multiLevelWriter) DescribeConfig() string {
+	ret := "multi("
+	for i, w := range t.writers {
+		if i != 0 {
+			ret += ", "
+		}
+		ret += describe(w)
+	}
+	return ret + ")"
+}
+
 func (t multiLevelWriter) Write(p []byte) (n int, err error) {
 	for _, w := range t.writers {
 		n, err = w.Write(p)
@@ -71,8 +147,26 @@ func (t multiLevelWriter) Write(p []byte) (n int, err error) {
 }
 
 func (t multiLevelWriter) WriteLevel(l LogLevel, p []byte) (n int, err error) {
+	return t.write(context.Background(), l, p)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (t multiLevelWriter) WriteContext(ctx context.Context, l LogLevel, p []byte) (n int, err error) {
+	return t.write(ctx, l, p)
+}
+
+func (t multiLevelWriter) write(ctx context.Context, l LogLevel, p []byte) (n int, err error) {
+	var shared []byte
 	for _, w := range t.writers {
-		n, err = w.WriteLevel(l, p)
+		if sw, ok := w.(SharedWriter); ok {
+			// the event is copied once for all the writers retaining it
+			if shared == nil {
+				shared = append(make([]byte, 0, len(p)), p...)
+			}
+			n, err = sw.WriteShared(ctx, l, shared)
+		} else {
+			n, err = writeContext(ctx, w, l, p)
+		}
 		if err != nil {
 			return
 		}
@@ -84,9 +178,44 @@ func (t multiLevelWriter) WriteLevel(l LogLevel, p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// CloseContext implements the ContextCloser interface. All the writers share the same
+// deadline.
+func (t multiLevelWriter) CloseContext(ctx context.Context) (err error) {
+	for _, w := range t.writers {
+		if e := CloseWriter(ctx, w); e != nil && err == nil {
+			err = e
+		}
+	}
+	return
+}
+
+// CheckHealth implements the HealthChecker interface: it returns the first error of the writers.
+func (t multiLevelWriter) CheckHealth() error {
+	for _, w := range t.writers {
+		if err := CheckHealth(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush implements the Flusher interface.
+func (t multiLevelWriter) Flush() (err error) {
+	for _, w := range t.writers {
+		if e := flush(w); e != nil && err == nil {
+			err = e
+		}
+	}
+	return
+}
+
 // MultiLevelWriter creates a writer that duplicates its writes to all the
 // provided writers, similar to the Unix tee(1) command. If some writers
 // implement LevelWriter, their WriteLevel method will be used instead of Write.
+//
+// Events are encoded only once. Writers implementing SharedWriter receive a single
+// copy of each event shared between them. Wrap the writers with MinLevelWriter to give
+// each one its own minimum level.
 func MultiLevelWriter(writers ...io.Writer) LevelWriter {
 	lwriters := make([]LevelWriter, 0, len(writers))
 	for _, w := range writers {
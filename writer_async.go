@@ -0,0 +1,314 @@
+package rz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrWriterClosed is returned by the writes of a closed AsyncWriter.
+var ErrWriterClosed = errors.New("rz: writer is closed")
+
+// DropPolicy is the event dropped by an AsyncWriter when its buffer is full.
+type DropPolicy uint8
+
+const (
+	// DropOldest drops the oldest buffered event to buffer the new one.
+	DropOldest DropPolicy = iota
+	// DropNewest drops the new event.
+	DropNewest
+)
+
+// String returns the name of the policy.
+func (p DropPolicy) String() string {
+	if p == DropNewest {
+		return "drop_newest"
+	}
+	return "drop_oldest"
+}
+
+type asyncEvent struct {
+	level LogLevel
+	p     []byte
+	// shared is true if p was received by WriteShared: it's not reused for other events
+	shared bool
+}
+
+// asyncFlush is a pending Flush, done once the events before position are out of the buffer.
+type asyncFlush struct {
+	position uint64
+	done     chan error
+}
+
+// AsyncWriter buffers events in a bounded ring buffer, drained to the underlying writer by a
+// background goroutine, so a slow destination never blocks the logging goroutines: when the
+// buffer is full, an event is dropped according to the DropPolicy. The number of events dropped
+// since the previous report is passed to the drop callback by the background goroutine. It's safe
+// for concurrent use, and the underlying writer is never written concurrently.
+//
+// Write errors of the underlying writer are passed to ErrorHandler. Flush waits for the buffered
+// events to be written; Close writes them and closes the underlying writer.
+type AsyncWriter struct {
+	lw     LevelWriter
+	out    io.Writer
+	policy DropPolicy
+	onDrop func(dropped uint64)
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	events []asyncEvent
+	head   int
+	size   int
+	// dequeued is the number of events taken out of the buffer, written or dropped: the position
+	// of the head of the buffer since the creation of the writer
+	dequeued uint64
+	pending  uint64 // dropped events not reported yet
+	flushes  []asyncFlush
+	closed  bool
+	stopped chan struct{}
+	dropped uint64
+}
+
+// AsyncWriterOption is used to configure an AsyncWriter.
+type AsyncWriterOption func(w *AsyncWriter)
+
+// AsyncBufferSize updates the maximum number of buffered events. Default: 1000.
+func AsyncBufferSize(size int) AsyncWriterOption {
+	return func(w *AsyncWriter) {
+		if size > 0 {
+			w.events = make([]asyncEvent, size)
+		}
+	}
+}
+
+// AsyncDropPolicy updates the policy used when the buffer is full. Default: DropOldest.
+func AsyncDropPolicy(policy DropPolicy) AsyncWriterOption {
+	return func(w *AsyncWriter) {
+		w.policy = policy
+	}
+}
+
+// AsyncOnDrop updates the function called with the number of events dropped since its previous
+// call. Default: the drops are reported to ErrorHandler.
+func AsyncOnDrop(onDrop func(dropped uint64)) AsyncWriterOption {
+	return func(w *AsyncWriter) {
+		w.onDrop = onDrop
+	}
+}
+
+// NewAsyncWriter creates an AsyncWriter writing to w and starts its background goroutine.
+func NewAsyncWriter(w io.Writer, options ...AsyncWriterOption) *AsyncWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	aw := &AsyncWriter{
+		lw:      lw,
+		out:     w,
+		events:  make([]asyncEvent, 1000),
+		stopped: make(chan struct{}),
+	}
+	aw.cond = sync.NewCond(&aw.mu)
+	for _, option := range options {
+		option(aw)
+	}
+	go aw.run()
+	return aw
+}
+
+// Write implements the io.Writer interface.
+func (w *AsyncWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. It never blocks on the underlying writer.
+func (w *AsyncWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	return w.write(level, p, false)
+}
+
+// WriteShared implements the SharedWriter interface: the event is buffered without being copied.
+// As the write never blocks, ctx is ignored.
+func (w *AsyncWriter) WriteShared(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	return w.write(level, p, true)
+}
+
+func (w *AsyncWriter) write(level LogLevel, p []byte, shared bool) (n int, err error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, ErrWriterClosed
+	}
+	slot := (w.head + w.size) % len(w.events)
+	if w.size == len(w.events) {
+		w.pending++
+		atomic.AddUint64(&w.dropped, 1)
+		if w.policy == DropNewest {
+			w.mu.Unlock()
+			w.cond.Signal()
+			return len(p), nil
+		}
+		w.head = (w.head + 1) % len(w.events)
+		w.dequeued++
+	} else {
+		w.size++
+	}
+	event := &w.events[slot]
+	event.level = level
+	switch {
+	case shared:
+		event.p, event.shared = p, true
+	case event.shared:
+		event.p, event.shared = append([]byte(nil), p...), false
+	default:
+		event.p = append(event.p[:0], p...)
+	}
+	w.mu.Unlock()
+	w.cond.Signal()
+	return len(p), nil
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.stopped)
+	// spare is the buffer of the last written event, swapped with the one of the next event so
+	// the buffers are reused
+	var spare []byte
+	for {
+		w.mu.Lock()
+		for w.size == 0 && w.pending == 0 && !w.flushReady() && !w.closed {
+			w.cond.Wait()
+		}
+		dropped := w.pending
+		w.pending = 0
+		// the previous event is written: the flushes of the events before the head are done,
+		// even if the buffer is never empty under sustained load
+		var flushes []asyncFlush
+		for w.flushReady() {
+			flushes = append(flushes, w.flushes[0])
+			w.flushes = w.flushes[1:]
+		}
+		var event asyncEvent
+		write := w.size > 0
+		if write {
+			event = w.events[w.head]
+			w.events[w.head].p, w.events[w.head].shared = spare[:0], false
+			w.head = (w.head + 1) % len(w.events)
+			w.size--
+			w.dequeued++
+		}
+		closed := w.closed
+		w.mu.Unlock()
+
+		w.reportDrops(dropped)
+		if len(flushes) > 0 {
+			err := flush(w.lw)
+			for _, f := range flushes {
+				f.done <- err
+			}
+		}
+		if write {
+			if _, err := w.lw.WriteLevel(event.level, event.p); err != nil {
+				handleWriterError(err)
+			}
+			// the buffer of a shared event is not reused, and spare is now the one of the head
+			spare = nil
+			if !event.shared {
+				spare = event.p
+			}
+			continue
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// flushReady returns true if the first pending flush can be done. w.mu must be held.
+func (w *AsyncWriter) flushReady() bool {
+	return len(w.flushes) > 0 && w.flushes[0].position <= w.dequeued
+}
+
+func (w *AsyncWriter) reportDrops(dropped uint64) {
+	if dropped == 0 {
+		return
+	}
+	if w.onDrop != nil {
+		w.onDrop(dropped)
+		return
+	}
+	err := fmt.Errorf("rz: async writer dropped %d events", dropped)
+	if ErrorHandler != nil {
+		ErrorHandler(err)
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// Flush implements the Flusher interface: it waits for the events buffered before the call to be
+// written (or dropped), and flushes the underlying writer. The events buffered after the call
+// are not waited for, so Flush returns under sustained load.
+func (w *AsyncWriter) Flush() error {
+	done := make(chan error, 1)
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.flushes = append(w.flushes, asyncFlush{position: w.dequeued + uint64(w.size), done: done})
+	w.mu.Unlock()
+	w.cond.Signal()
+	return <-done
+}
+
+// CheckHealth implements the HealthChecker interface, with the health of the underlying writer.
+func (w *AsyncWriter) CheckHealth() error {
+	return CheckHealth(w.out)
+}
+
+// Dropped returns the total number of dropped events.
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close writes the buffered events, stops the background goroutine and closes the underlying
+// writer if it implements io.Closer.
+func (w *AsyncWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext implements the ContextCloser interface.
+func (w *AsyncWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	w.cond.Signal()
+
+	select {
+	case <-w.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return CloseWriter(ctx, w.out)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *AsyncWriter) DescribeConfig() string {
+	return "async(" + describe(w.lw) + ", " + strconv.Itoa(len(w.events)) + ", " + w.policy.String() + ")"
+}
+
+// handleWriterError passes err to ErrorHandler, or prints it on stderr.
+func handleWriterError(err error) {
+	if ErrorHandler != nil {
+		ErrorHandler(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "rz: could not write event: %v\n", err)
+	}
+}
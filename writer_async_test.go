@@ -0,0 +1,235 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks the writes until unblock is closed.
+type gatedWriter struct {
+	unblock chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	closed  bool
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	out := &gatedWriter{unblock: make(chan struct{})}
+	w := NewAsyncWriter(out)
+	log := New(Writer(w), Fields(Timestamp(false)))
+
+	done := make(chan struct{})
+	go func() {
+		log.Info("first")
+		log.Info("second")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writes are blocked by the underlying writer")
+	}
+
+	close(out.unblock)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"info","message":"first"}` + "\n" + `{"level":"info","message":"second"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	log.Info("third")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), `"third"}`+"\n") || !out.closed {
+		t.Errorf("buffered events are not written and the writer is not closed on close: %q", out.String())
+	}
+	if _, err := w.Write([]byte("{}\n")); err != ErrWriterClosed {
+		t.Errorf("invalid error after close: %v", err)
+	}
+}
+
+func TestAsyncWriterDropPolicy(t *testing.T) {
+	for _, policy := range []DropPolicy{DropOldest, DropNewest} {
+		out := &gatedWriter{unblock: make(chan struct{})}
+		var mu sync.Mutex
+		var reported uint64
+		w := NewAsyncWriter(out, AsyncBufferSize(2), AsyncDropPolicy(policy), AsyncOnDrop(func(dropped uint64) {
+			mu.Lock()
+			reported += dropped
+			mu.Unlock()
+		}))
+
+		// wait for the first event to be taken by the background goroutine, blocked on the
+		// underlying writer
+		w.Write([]byte("0\n"))
+		for taken := false; !taken; {
+			w.mu.Lock()
+			taken = w.size == 0
+			w.mu.Unlock()
+			time.Sleep(time.Millisecond)
+		}
+		for _, event := range []string{"1\n", "2\n", "3\n", "4\n"} {
+			w.Write([]byte(event))
+		}
+		close(out.unblock)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "0\n3\n4\n"
+		if policy == DropNewest {
+			want = "0\n1\n2\n"
+		}
+		if got := out.String(); got != want {
+			t.Errorf("%s: invalid output: %q, want %q", policy, got, want)
+		}
+		mu.Lock()
+		if reported != 2 || w.Dropped() != 2 {
+			t.Errorf("%s: invalid dropped events: reported %d, total %d", policy, reported, w.Dropped())
+		}
+		mu.Unlock()
+	}
+}
+
+func TestAsyncWriterCloseContext(t *testing.T) {
+	out := &gatedWriter{unblock: make(chan struct{})}
+	w := NewAsyncWriter(out)
+	w.Write([]byte("{}\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.CloseContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("invalid error: %v", err)
+	}
+	close(out.unblock)
+	if got, want := w.DescribeConfig(), "async(*rz.gatedWriter, 1000, drop_oldest)"; got != want {
+		t.Errorf("invalid config: %s, want %s", got, want)
+	}
+}
+
+// slowWriter sleeps before each write.
+type slowWriter struct {
+	syncBuffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(100 * time.Microsecond)
+	return w.syncBuffer.Write(p)
+}
+
+func TestAsyncWriterFlushUnderLoad(t *testing.T) {
+	out := &slowWriter{}
+	w := NewAsyncWriter(out, AsyncBufferSize(10), AsyncDropPolicy(DropNewest),
+		AsyncOnDrop(func(uint64) {}))
+	defer w.Close()
+	w.Write([]byte(`{"message":"flushed"}` + "\n"))
+
+	// the buffer is never empty while the producer runs
+	stop := make(chan struct{})
+	produced := make(chan struct{})
+	go func() {
+		defer close(produced)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				w.Write([]byte("{}\n"))
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-produced
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Flush()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), `"flushed"`) {
+			t.Error("the event buffered before Flush is not written")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Flush is blocked under sustained load")
+	}
+}
+
+type sharedWriter struct {
+	events [][]byte
+	ctxs   []context.Context
+}
+
+func (w *sharedWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+func (w *sharedWriter) WriteLevel(level LogLevel, p []byte) (int, error) {
+	w.events = append(w.events, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (w *sharedWriter) WriteShared(ctx context.Context, level LogLevel, p []byte) (int, error) {
+	w.events = append(w.events, p)
+	w.ctxs = append(w.ctxs, ctx)
+	return len(p), nil
+}
+
+func TestAsyncWriterShared(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, AsyncBufferSize(2))
+	sw := &sharedWriter{}
+	log := New(Writer(MultiLevelWriter(w, sw)), Fields(Timestamp(false)))
+	for _, message := range []string{"1", "2", "3", "4"} {
+		log.Info(message)
+		w.Flush()
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := ""
+	for i, message := range []string{"1", "2", "3", "4"} {
+		event := `{"level":"info","message":"` + message + `"}` + "\n"
+		// the shared events are retained unmodified
+		if got := string(sw.events[i]); got != event {
+			t.Errorf("shared event %d modified: %q", i, got)
+		}
+		want += event
+	}
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
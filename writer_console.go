@@ -0,0 +1,246 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsoleFormatter formats a part or a field of a ConsoleWriter line. value is the decoded JSON
+// value (json.Number for numbers), nil if the event has no such part.
+type ConsoleFormatter func(value interface{}) string
+
+// ConsoleWriter is a LevelWriter decoding the JSON events and writing them as colorized,
+// human-readable lines: the parts (timestamp, level, caller and message by default) followed by
+// the other fields, sorted with errors first. It's intended for development use, as it's much
+// slower than writing JSON.
+//
+// The zero value writes to os.Stdout with colors; each field can be set to customize the lines:
+//
+//     log := rz.New(rz.Writer(&rz.ConsoleWriter{NoColor: true, TimeFormat: time.Kitchen}))
+type ConsoleWriter struct {
+	// Out is the destination of the lines. Default: os.Stdout.
+	Out io.Writer
+	// NoColor disables the colors.
+	NoColor bool
+	// TimeFormat reformats the RFC 3339 timestamps with this layout. Default: timestamps are
+	// written as is.
+	TimeFormat string
+	// PartsOrder is the list of the fields written first, in this order. Default: timestamp,
+	// level, caller and message, with rz's default field names.
+	PartsOrder []string
+	// FieldsExclude is the list of the fields not written.
+	FieldsExclude []string
+	// ErrorFieldName is the name of the error field, written first and in red. Default:
+	// DefaultErrorFieldName.
+	ErrorFieldName string
+
+	FormatTimestamp     ConsoleFormatter
+	FormatLevel         ConsoleFormatter
+	FormatCaller        ConsoleFormatter
+	FormatMessage       ConsoleFormatter
+	FormatFieldName     ConsoleFormatter
+	FormatFieldValue    ConsoleFormatter
+	FormatErrFieldName  ConsoleFormatter
+	FormatErrFieldValue ConsoleFormatter
+}
+
+// Write implements the io.Writer interface.
+func (w *ConsoleWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *ConsoleWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	var event map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err = d.Decode(&event); err != nil {
+		return 0, fmt.Errorf("rz: cannot decode event: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	parts := w.PartsOrder
+	if parts == nil {
+		parts = []string{DefaultTimestampFieldName, DefaultLevelFieldName, DefaultCallerFieldName, DefaultMessageFieldName}
+	}
+	for _, part := range parts {
+		s := w.formatPart(part, event[part])
+		if s == "" {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(s)
+	}
+
+	errorField := w.ErrorFieldName
+	if errorField == "" {
+		errorField = DefaultErrorFieldName
+	}
+	fields := make([]string, 0, len(event))
+	for field := range event {
+		if !containsString(parts, field) && !containsString(w.FieldsExclude, field) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if (fields[i] == errorField) != (fields[j] == errorField) {
+			return fields[i] == errorField
+		}
+		return fields[i] < fields[j]
+	})
+	for _, field := range fields {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		if field == errorField {
+			buf.WriteString(w.formatter(w.FormatErrFieldName, w.defaultErrFieldName)(field))
+			buf.WriteString(w.formatter(w.FormatErrFieldValue, w.defaultErrFieldValue)(event[field]))
+		} else {
+			buf.WriteString(w.formatter(w.FormatFieldName, w.defaultFieldName)(field))
+			buf.WriteString(w.formatter(w.FormatFieldValue, consoleValue)(event[field]))
+		}
+	}
+	buf.WriteByte('\n')
+
+	out := w.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if _, err = out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *ConsoleWriter) formatPart(part string, value interface{}) string {
+	switch part {
+	case DefaultTimestampFieldName:
+		return w.formatter(w.FormatTimestamp, w.defaultTimestamp)(value)
+	case DefaultLevelFieldName:
+		return w.formatter(w.FormatLevel, w.defaultLevel)(value)
+	case DefaultCallerFieldName:
+		return w.formatter(w.FormatCaller, w.defaultCaller)(value)
+	case DefaultMessageFieldName:
+		return w.formatter(w.FormatMessage, consoleString)(value)
+	}
+	if value == nil {
+		return ""
+	}
+	return w.formatter(w.FormatFieldName, w.defaultFieldName)(part) + w.formatter(w.FormatFieldValue, consoleValue)(value)
+}
+
+func (w *ConsoleWriter) formatter(custom, fallback ConsoleFormatter) ConsoleFormatter {
+	if custom != nil {
+		return custom
+	}
+	return fallback
+}
+
+func (w *ConsoleWriter) paint(s string, color int) string {
+	if w.NoColor || s == "" {
+		return s
+	}
+	return colorize(s, color)
+}
+
+func (w *ConsoleWriter) defaultTimestamp(value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		return w.paint(consoleString(value), cDarkGray)
+	}
+	if w.TimeFormat != "" {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			s = t.Format(w.TimeFormat)
+		}
+	}
+	return w.paint(s, cDarkGray)
+}
+
+func (w *ConsoleWriter) defaultLevel(value interface{}) string {
+	level, ok := value.(string)
+	if !ok {
+		return "???"
+	}
+	name := strings.ToUpper(level)
+	if len(name) > 3 {
+		name = name[:3]
+	}
+	return w.paint(name, levelColor(level))
+}
+
+func (w *ConsoleWriter) defaultCaller(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return w.paint(consoleString(value), cBold) + w.paint(" >", cCyan)
+}
+
+func (w *ConsoleWriter) defaultFieldName(value interface{}) string {
+	return w.paint(consoleString(value)+"=", cCyan)
+}
+
+func (w *ConsoleWriter) defaultErrFieldName(value interface{}) string {
+	return w.paint(consoleString(value)+"=", cRed)
+}
+
+func (w *ConsoleWriter) defaultErrFieldValue(value interface{}) string {
+	return w.paint(consoleValue(value), cRed)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *ConsoleWriter) DescribeConfig() string {
+	out := w.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	return "console(" + describe(out) + ")"
+}
+
+// consoleString formats value without quotes, nil as an empty string.
+func consoleString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return string(value)
+	}
+	return consoleValue(value)
+}
+
+// consoleValue formats a field value, quoting the strings which need it.
+func consoleValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		if value == "" || needsQuote(value) {
+			return strconv.Quote(value)
+		}
+		return value
+	case json.Number:
+		return string(value)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
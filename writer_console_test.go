@@ -0,0 +1,67 @@
+package rz
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	ts := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	w := &ConsoleWriter{Out: out, NoColor: true, TimeFormat: time.Kitchen}
+	log := New(Writer(w), TimestampFunc(func() time.Time { return ts }))
+	log.Warn("disk almost full", String("path", "/var/lib"), Int("usage", 93), Err(errors.New("no space")), String("empty", ""))
+	log.Info("hello world", Strings("tags", []string{"a b"}))
+
+	want := `3:04PM WAR disk almost full error="no space" empty="" path=/var/lib usage=93` + "\n" +
+		`3:04PM INF hello world tags=["a b"]` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestConsoleWriterColors(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(&ConsoleWriter{Out: out}), Fields(Timestamp(false)))
+	log.Error("failed", String("foo", "bar"))
+
+	want := colorize("ERR", cRed) + " failed " + colorize("foo=", cCyan) + "bar\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestConsoleWriterCustomization(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &ConsoleWriter{
+		Out:           out,
+		NoColor:       true,
+		PartsOrder:    []string{DefaultLevelFieldName, "component", DefaultMessageFieldName},
+		FieldsExclude: []string{"secret"},
+		FormatLevel: func(value interface{}) string {
+			return "[" + strings.ToUpper(consoleString(value)) + "]"
+		},
+		FormatFieldName: func(value interface{}) string {
+			return consoleString(value) + ":"
+		},
+		FormatFieldValue: func(value interface{}) string {
+			return "<" + consoleString(value) + ">"
+		},
+	}
+	log := New(Writer(w), Fields(Timestamp(false)))
+	log.Info("started", String("component", "api"), String("secret", "hunter2"), Int("port", 8080))
+	log.Info("no component")
+
+	want := "[INFO] component:<api> started port:<8080>\n" +
+		"[INFO] no component\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if _, err := w.Write([]byte("not json")); err == nil {
+		t.Error("invalid event is written")
+	}
+}
@@ -0,0 +1,53 @@
+package rz
+
+import (
+	"context"
+	"io"
+)
+
+// ContextWriter defines an interface a writer may implement in order to bound writes with
+// the context of the event (see the Ctx field). Writers shipping events to a remote
+// endpoint should stop as soon as the context is done.
+type ContextWriter interface {
+	WriteContext(ctx context.Context, level LogLevel, p []byte) (n int, err error)
+}
+
+// ContextCloser defines an interface a writer buffering events may implement in order to
+// bound the final drain of the buffered events with a context, so shutdown sequences with
+// a global deadline don't hang on a dead endpoint.
+type ContextCloser interface {
+	// CloseContext writes the buffered events and closes the writer. If ctx is done before the
+	// buffered events are written, the remaining events are dropped and ctx.Err() is returned.
+	CloseContext(ctx context.Context) error
+}
+
+// CloseWriter closes w, bounded by ctx. If w implements ContextCloser, CloseContext is called.
+// Otherwise, if w implements io.Closer, Close is called and CloseWriter returns ctx.Err()
+// if ctx is done before Close returns.
+func CloseWriter(ctx context.Context, w io.Writer) error {
+	switch c := w.(type) {
+	case ContextCloser:
+		return c.CloseContext(ctx)
+	case io.Closer:
+		done := make(chan error, 1)
+		go func() {
+			done <- c.Close()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// writeContext writes p to w, using WriteContext if w implements ContextWriter. Other writers
+// ignore ctx.
+func writeContext(ctx context.Context, w LevelWriter, level LogLevel, p []byte) (n int, err error) {
+	if cw, ok := w.(ContextWriter); ok {
+		return cw.WriteContext(ctx, level, p)
+	}
+	return w.WriteLevel(level, p)
+}
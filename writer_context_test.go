@@ -0,0 +1,47 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// ctxWriter is a ContextWriter dropping the events whose context is done.
+type ctxWriter struct {
+	syncBuffer
+}
+
+func (w *ctxWriter) WriteLevel(level LogLevel, p []byte) (int, error) {
+	return w.Write(p)
+}
+
+func (w *ctxWriter) WriteContext(ctx context.Context, level LogLevel, p []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.Write(p)
+}
+
+func TestWriteContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// plain writers ignore the context
+	out := &bytes.Buffer{}
+	log := New(Writer(out), Fields(Timestamp(false)))
+	if err := log.InfoE("done", Ctx(ctx)); err != nil {
+		t.Errorf("InfoE err = %v, want nil", err)
+	}
+	if got, want := out.String(), `{"level":"info","message":"done"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	cw := &ctxWriter{}
+	log = New(Writer(cw), Fields(Timestamp(false)))
+	if err := log.InfoE("done", Ctx(ctx)); err != context.Canceled {
+		t.Errorf("InfoE err = %v, want %v", err, context.Canceled)
+	}
+	if got := cw.String(); got != "" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
@@ -0,0 +1,195 @@
+package rz
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileWriter writes events to files whose path is a template expanded with the time of the
+// write, e.g. "/var/log/app/%Y/%m/%d/app-%H.log", giving date partitioned layouts without a
+// rotation daemon. Missing directories are created.
+// A RetentionPolicy can be enforced on the files matching the template.
+//
+// The supported verbs are %Y (year), %m (month), %d (day), %H (hour), %M (minute),
+// %S (second), %j (day of year) and %% (a percent sign).
+type FileWriter struct {
+	template string
+	perm     os.FileMode
+	dirPerm  os.FileMode
+	now      func() time.Time
+	policy   *RetentionPolicy
+	sync     SyncPolicy
+	// maxEventSize is set by FileAtomicAppend
+	maxEventSize int
+
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	unsynced int
+	lastSync time.Time
+}
+
+// FileWriterOption is used to configure a FileWriter.
+type FileWriterOption func(w *FileWriter)
+
+// FilePerm updates the permissions of the created files and directories.
+// Default: 0644 and 0755.
+func FilePerm(perm, dirPerm os.FileMode) FileWriterOption {
+	return func(w *FileWriter) {
+		w.perm = perm
+		w.dirPerm = dirPerm
+	}
+}
+
+// FileTimeFunc updates the function returning the time used to expand the path template.
+// Default: DefaultTimestampFunc (UTC).
+func FileTimeFunc(now func() time.Time) FileWriterOption {
+	return func(w *FileWriter) {
+		w.now = now
+	}
+}
+
+// Retention enforces policy on the files of the writer each time a new file is opened.
+func Retention(policy RetentionPolicy) FileWriterOption {
+	return func(w *FileWriter) {
+		w.policy = &policy
+	}
+}
+
+// NewFileWriter creates a FileWriter and opens the current file.
+func NewFileWriter(pathTemplate string, options ...FileWriterOption) (*FileWriter, error) {
+	w := &FileWriter{
+		template: pathTemplate,
+		perm:     0o644,
+		dirPerm:  0o755,
+		now:      DefaultTimestampFunc,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if err := w.open(w.now()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// expandPathTemplate expands the time verbs of template with t.
+func expandPathTemplate(template string, t time.Time) string {
+	if !strings.Contains(template, "%") {
+		return template
+	}
+	pad := func(b *strings.Builder, n, width int) {
+		s := strconv.Itoa(n)
+		for i := len(s); i < width; i++ {
+			b.WriteByte('0')
+		}
+		b.WriteString(s)
+	}
+	b := strings.Builder{}
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' || i == len(template)-1 {
+			b.WriteByte(template[i])
+			continue
+		}
+		i++
+		switch template[i] {
+		case 'Y':
+			pad(&b, t.Year(), 4)
+		case 'm':
+			pad(&b, int(t.Month()), 2)
+		case 'd':
+			pad(&b, t.Day(), 2)
+		case 'H':
+			pad(&b, t.Hour(), 2)
+		case 'M':
+			pad(&b, t.Minute(), 2)
+		case 'S':
+			pad(&b, t.Second(), 2)
+		case 'j':
+			pad(&b, t.YearDay(), 3)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(template[i])
+		}
+	}
+	return b.String()
+}
+
+// open opens the file of the time t if it's not the current one. w.mu must be held.
+func (w *FileWriter) open(t time.Time) error {
+	path := expandPathTemplate(w.template, t)
+	if w.file != nil && path == w.path {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), w.dirPerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.perm)
+	if err != nil {
+		return err
+	}
+	if w.file != nil {
+		w.file.Close()
+	}
+	w.file, w.path = file, path
+	w.unsynced, w.lastSync = 0, time.Now()
+	if w.policy != nil {
+		if _, err := w.enforceRetention(); err != nil {
+			handleWriterError(err)
+		}
+	}
+	return nil
+}
+
+// Write implements the io.Writer interface.
+func (w *FileWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *FileWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err = w.open(w.now()); err != nil {
+		return 0, err
+	}
+	if w.maxEventSize > 0 {
+		n, err = w.writeAtomic(p)
+	} else {
+		n, err = w.file.Write(p)
+	}
+	if err != nil {
+		return
+	}
+	return n, w.syncAfterWrite(level)
+}
+
+// Path returns the path of the current file.
+func (w *FileWriter) Path() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.path
+}
+
+// Close closes the current file.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *FileWriter) DescribeConfig() string {
+	return "file(" + strconv.Quote(w.template) + ")"
+}
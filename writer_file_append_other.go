@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package rz
+
+import (
+	"syscall"
+)
+
+func writeFd(fd uintptr, p []byte) (int, error) {
+	for {
+		n, err := syscall.Write(int(fd), p)
+		if err != syscall.EINTR {
+			if n < 0 {
+				n = 0
+			}
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package rz
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileWriterAtomicAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	const writers, events = 4, 200
+	wg := sync.WaitGroup{}
+	for i := 0; i < writers; i++ {
+		w, err := NewFileWriter(path, FileAtomicAppend(4096))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Close()
+		wg.Add(1)
+		go func(w *FileWriter) {
+			defer wg.Done()
+			padding := strings.Repeat("x", 1000)
+			for j := 0; j < events; j++ {
+				if _, err := w.Write([]byte(`{"padding":"` + padding + `"}` + "\n")); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		if !json.Valid(scanner.Bytes()) {
+			t.Fatalf("interleaved event: %q", scanner.Text())
+		}
+	}
+	if lines != writers*events {
+		t.Errorf("invalid number of events: got %d, want %d", lines, writers*events)
+	}
+}
+
+func TestFileWriterEventTooLarge(t *testing.T) {
+	w, err := NewFileWriter(filepath.Join(t.TempDir(), "app.log"), FileAtomicAppend(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err = w.Write([]byte(`{"message":"too large"}` + "\n")); err != ErrEventTooLarge {
+		t.Errorf("invalid error: got %v, want %v", err, ErrEventTooLarge)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package rz
+
+import (
+	"syscall"
+)
+
+func writeFd(fd uintptr, p []byte) (int, error) {
+	return syscall.Write(syscall.Handle(fd), p)
+}
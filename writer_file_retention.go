@@ -0,0 +1,110 @@
+package rz
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy limits the disk usage of the files of a FileWriter: the current file, the
+// files of previous time slices, and their rotated or compressed copies (any file matching the
+// path template followed by a suffix, e.g. "app-05.log.gz"). The oldest files are deleted first
+// and the current file is never deleted.
+type RetentionPolicy struct {
+	// MaxSize is the maximum total size in bytes of the files. Ignored if <= 0.
+	MaxSize int64
+	// MaxAge is the maximum age of the files, based on their modification time. Ignored if <= 0.
+	MaxAge time.Duration
+	// MaxFiles is the maximum number of files besides the current one. Ignored if <= 0.
+	MaxFiles int
+}
+
+// retentionGlob returns the glob pattern matching the files of template.
+func retentionGlob(template string) string {
+	b := strings.Builder{}
+	for i := 0; i < len(template); i++ {
+		switch c := template[i]; {
+		case c == '%' && i < len(template)-1 && template[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case c == '%' && i < len(template)-1 && strings.IndexByte("YmdHMSj", template[i+1]) >= 0:
+			b.WriteByte('*')
+			i++
+		case c == '*' || c == '?' || c == '[' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('*')
+	return b.String()
+}
+
+// EnforceRetention deletes the files exceeding the retention policy of the writer, and returns
+// their paths. It's a no-op if the writer has no policy.
+// It's called each time a new file is opened, passing errors to ErrorHandler, and may be called
+// periodically to enforce MaxAge.
+func (w *FileWriter) EnforceRetention() (removed []string, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.policy == nil {
+		return nil, nil
+	}
+	return w.enforceRetention()
+}
+
+// enforceRetention implements EnforceRetention. w.mu must be held.
+func (w *FileWriter) enforceRetention() (removed []string, err error) {
+	return w.policy.enforce(retentionGlob(w.template), w.path)
+}
+
+// enforce deletes the files matching the glob pattern and exceeding the policy, except current,
+// and returns their paths.
+func (policy RetentionPolicy) enforce(pattern, current string) (removed []string, err error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]file, 0, len(paths))
+	var total int64
+	for _, path := range paths {
+		info, statErr := os.Stat(path)
+		if statErr != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		total += info.Size()
+		if path != current {
+			files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	now := time.Now()
+	count := len(files)
+	for _, f := range files {
+		expired := policy.MaxAge > 0 && now.Sub(f.modTime) > policy.MaxAge
+		oversized := policy.MaxSize > 0 && total > policy.MaxSize
+		overcount := policy.MaxFiles > 0 && count > policy.MaxFiles
+		if !expired && !oversized && !overcount {
+			continue
+		}
+		if removeErr := os.Remove(f.path); removeErr != nil {
+			err = removeErr
+			continue
+		}
+		total -= f.size
+		count--
+		removed = append(removed, f.path)
+	}
+	return removed, err
+}
@@ -0,0 +1,83 @@
+package rz
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionGlob(t *testing.T) {
+	if got, want := retentionGlob("/var/log/[app]/%Y/%m/app-%H-100%%.log"), `/var/log/\[app]/*/*/app-*-100%.log*`; got != want {
+		t.Errorf("invalid glob: got %q, want %q", got, want)
+	}
+}
+
+func TestFileWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int, age time.Duration) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("a", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app-01.log.gz", 10, 50*time.Hour)
+	write("app-02.log.gz", 10, 3*time.Hour)
+	write("app-03.log", 10, 2*time.Hour)
+	write("app-04.log", 10, time.Hour)
+	write("other.log", 100, 100*time.Hour)
+
+	now := time.Date(2020, 1, 1, 5, 0, 0, 0, time.UTC)
+	w, err := NewFileWriter(filepath.Join(dir, "app-%H.log"),
+		FileTimeFunc(func() time.Time { return now }),
+		Retention(RetentionPolicy{MaxSize: 15, MaxAge: 48 * time.Hour}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("01234")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	entries, _ := os.ReadDir(dir)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if got, want := strings.Join(names, ","), "app-04.log,app-05.log,other.log"; got != want {
+		t.Errorf("invalid retained files: got %s, want %s", got, want)
+	}
+}
+
+func TestRetentionPolicyMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	for i, name := range []string{"app-01.log", "app-02.log", "app-03.log", "app-04.log"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().Add(time.Duration(i-4) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := RetentionPolicy{MaxFiles: 2}.enforce(filepath.Join(dir, "app-*.log"), filepath.Join(dir, "app-04.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range removed {
+		removed[i] = filepath.Base(removed[i])
+	}
+	if got, want := strings.Join(removed, ","), "app-01.log"; got != want {
+		t.Errorf("invalid removed files: got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,371 @@
+package rz
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat is the format of the time in the names of the rotated files.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileWriter writes events to a file with a FileWriter, renaming it to a backup when it
+// exceeds a maximum size or when a new time interval starts, e.g. "app.log" to
+// "app-2006-01-02T15-04-05.000.log", with the time of the rotation. The backups can be compressed
+// with gzip, and a RetentionPolicy can be enforced on them. Missing directories are created.
+//
+// Compression, indexing and retention of the backups are done in a background goroutine, so no
+// write waits for them; Close waits for them to complete.
+type RotatingFileWriter struct {
+	path     string
+	maxSize  int64
+	interval time.Duration
+	policy   *RetentionPolicy
+	compress bool
+	indexer  *Indexer
+	now      func() time.Time
+	options  []FileWriterOption
+
+	mu       sync.Mutex
+	file     *FileWriter
+	closed   bool
+	size     int64
+	openedAt time.Time
+
+	millMu sync.Mutex
+	millWg sync.WaitGroup
+}
+
+// RotatingFileWriterOption is used to configure a RotatingFileWriter.
+type RotatingFileWriterOption func(w *RotatingFileWriter)
+
+// RotateSize rotates the file before it exceeds maxSize bytes. Ignored if <= 0. Default: 100 MiB.
+func RotateSize(maxSize int64) RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.maxSize = maxSize
+	}
+}
+
+// RotateInterval rotates the file at the start of each interval (e.g. 24 * time.Hour for a daily
+// rotation at midnight UTC), at the first write of the interval. Ignored if <= 0.
+func RotateInterval(interval time.Duration) RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.interval = interval
+	}
+}
+
+// RotateRetention enforces policy on the backups after each rotation.
+func RotateRetention(policy RetentionPolicy) RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.policy = &policy
+	}
+}
+
+// CompressBackups compresses the backups with gzip (adding a ".gz" suffix).
+func CompressBackups() RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.compress = true
+	}
+}
+
+// RotateIndex adds the events written to indexer, and writes the ObjectIndex of each backup next
+// to it when it's rotated, as JSON with the ".index.json" extension in place of the one of the
+// file, e.g. "app-2006-01-02T15-04-05.000.index.json". The retention policy removes the indexes
+// with their backups. The events of the file written before the writer was created are not
+// indexed.
+func RotateIndex(indexer *Indexer) RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.indexer = indexer
+	}
+}
+
+// RotateTimeFunc updates the function returning the time used for the rotations.
+// Default: DefaultTimestampFunc (UTC).
+func RotateTimeFunc(now func() time.Time) RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.now = now
+	}
+}
+
+// RotateFileOptions configures the FileWriter of the current file, e.g. with FilePerm or
+// FileSync. Retention applies to the current file only: use RotateRetention instead.
+func RotateFileOptions(options ...FileWriterOption) RotatingFileWriterOption {
+	return func(w *RotatingFileWriter) {
+		w.options = append(w.options, options...)
+	}
+}
+
+// NewRotatingFileWriter creates a RotatingFileWriter writing to path, and opens it. An existing
+// file is appended to.
+func NewRotatingFileWriter(path string, options ...RotatingFileWriterOption) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		path:    path,
+		maxSize: 100 << 20,
+		now:     DefaultTimestampFunc,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	// the path is not a template: its percent signs are escaped
+	file, err := NewFileWriter(strings.ReplaceAll(path, "%", "%%"), w.options...)
+	if err != nil {
+		return nil, err
+	}
+	w.file = file
+	if err = w.stat(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// stat reads the size and opening time of the current file. w.mu must be held.
+func (w *RotatingFileWriter) stat() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+	w.size = info.Size()
+	w.openedAt = w.now()
+	if w.size > 0 {
+		w.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// Write implements the io.Writer interface.
+func (w *RotatingFileWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RotatingFileWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		// the file is reopened by FileWriter: it may have been rotated or written to meanwhile
+		if err = w.stat(); os.IsNotExist(err) {
+			w.size, w.openedAt = 0, w.now()
+		} else if err != nil {
+			return 0, err
+		}
+		w.closed = false
+	}
+	oversized := w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize
+	if oversized || w.newInterval() {
+		if err = w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = w.file.WriteLevel(level, p)
+	w.size += int64(n)
+	if err == nil && w.indexer != nil {
+		w.indexer.Add(p)
+	}
+	return n, err
+}
+
+// newInterval returns true if the file was opened in a previous rotation interval.
+func (w *RotatingFileWriter) newInterval() bool {
+	if w.interval <= 0 || w.size == 0 {
+		return false
+	}
+	return !w.now().Truncate(w.interval).Equal(w.openedAt.Truncate(w.interval))
+}
+
+// Rotate renames the current file to a backup. A new file is opened by the next write.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// rotate implements Rotate. w.mu must be held.
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	now := w.now()
+	ext := filepath.Ext(w.path)
+	var backup string
+	// the time is shifted if a backup of the same millisecond exists, so it's not overwritten
+	for t := now.UTC(); ; t = t.Add(time.Millisecond) {
+		backup = strings.TrimSuffix(w.path, ext) + "-" + t.Format(rotatedTimeFormat) + ext
+		if !fileExists(backup) && !fileExists(backup+".gz") {
+			break
+		}
+	}
+	var index *ObjectIndex
+	if err := os.Rename(w.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	} else if err == nil && w.indexer != nil {
+		index = w.indexer.Rotate()
+	}
+	w.size, w.openedAt, w.closed = 0, now, false
+	w.millWg.Add(1)
+	go w.mill(backup, index)
+	return nil
+}
+
+// backupsGlob returns the glob pattern matching the backups of the file, compressed or not.
+func (w *RotatingFileWriter) backupsGlob() string {
+	ext := filepath.Ext(w.path)
+	stamp := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '?'
+		}
+		return r
+	}, rotatedTimeFormat)
+	return escapeGlob(strings.TrimSuffix(w.path, ext)+"-") + stamp + escapeGlob(ext) + "*"
+}
+
+// indexPath returns the path of the index of the backup, compressed or not.
+func (w *RotatingFileWriter) indexPath(backup string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(backup, ".gz"), filepath.Ext(w.path)) + ".index.json"
+}
+
+// escapeGlob escapes the special characters of the glob patterns in s.
+func escapeGlob(s string) string {
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '*' || c == '?' || c == '[' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// mill compresses the backup, writes its index if it's not nil, and enforces the retention policy
+// on the backups. Errors are passed to ErrorHandler.
+func (w *RotatingFileWriter) mill(backup string, index *ObjectIndex) {
+	defer w.millWg.Done()
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	if w.compress && fileExists(backup) {
+		if err := gzipFile(backup); err != nil {
+			handleWriterError(err)
+		}
+	}
+	if index != nil {
+		if err := writeIndex(w.indexPath(backup), index); err != nil {
+			handleWriterError(err)
+		}
+	}
+	if w.policy != nil {
+		removed, err := w.policy.enforce(w.backupsGlob(), "")
+		if err != nil {
+			handleWriterError(err)
+		}
+		if w.indexer != nil {
+			for _, path := range removed {
+				if err := os.Remove(w.indexPath(path)); err != nil && !os.IsNotExist(err) {
+					handleWriterError(err)
+				}
+			}
+		}
+	}
+}
+
+// writeIndex writes index to path as JSON.
+func writeIndex(path string, index *ObjectIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// gzipFile compresses the file at path to path.gz and removes it.
+func gzipFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(path + ".gz")
+		}
+	}()
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	// the modification time is kept, as the retention policies delete the oldest files first
+	if err = os.Chtimes(path+".gz", info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// Flush implements the Flusher interface: it syncs the file to disk.
+func (w *RotatingFileWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Flush()
+}
+
+// Close closes the file, and waits for the compression and retention of the backups. The file is
+// reopened by the next write.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	err := w.file.Close()
+	w.closed = true
+	w.mu.Unlock()
+	w.millWg.Wait()
+	return err
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *RotatingFileWriter) DescribeConfig() string {
+	config := "rotating_file(" + strconv.Quote(w.path) + ", size=" + strconv.FormatInt(w.maxSize, 10)
+	if w.interval > 0 {
+		config += ", interval=" + w.interval.String()
+	}
+	if w.policy != nil {
+		if w.policy.MaxFiles > 0 {
+			config += ", max_files=" + strconv.Itoa(w.policy.MaxFiles)
+		}
+		if w.policy.MaxSize > 0 {
+			config += ", max_size=" + strconv.FormatInt(w.policy.MaxSize, 10)
+		}
+		if w.policy.MaxAge > 0 {
+			config += ", max_age=" + w.policy.MaxAge.String()
+		}
+	}
+	if w.compress {
+		config += ", gzip"
+	}
+	if w.indexer != nil {
+		config += ", index"
+	}
+	return config + ")"
+}
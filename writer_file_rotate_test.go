@@ -0,0 +1,233 @@
+package rz
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rotatedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.Name() != "app.log" {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func TestRotatingFileWriterSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "app.log")
+	w, err := NewRotatingFileWriter(path, RotateSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"event 1\n", "event 2\n", "event 3\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := len(rotatedFiles(t, filepath.Dir(path))), 2; got != want {
+		t.Errorf("backups: got %d, want %d", got, want)
+	}
+	content, _ := os.ReadFile(path)
+	if got, want := string(content), "event 3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRotatingFileWriterInterval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 1, 1, 23, 59, 0, 0, time.UTC)
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"),
+		RotateInterval(24*time.Hour), RotateTimeFunc(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("event 1\n"))
+	now = now.Add(30 * time.Second)
+	w.Write([]byte("event 2\n"))
+	if got := rotatedFiles(t, dir); len(got) != 0 {
+		t.Errorf("unexpected backups: %v", got)
+	}
+	now = now.Add(time.Minute)
+	w.Write([]byte("event 3\n"))
+	got := rotatedFiles(t, dir)
+	if want := []string{"app-2020-01-02T00-00-30.000.log"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// rotateAt writes an event to w, sets the modification time of the file to now, and rotates it.
+func rotateAt(t *testing.T, w *RotatingFileWriter, now time.Time) {
+	t.Helper()
+	if _, err := w.Write([]byte("event\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(w.path, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	w.millWg.Wait()
+}
+
+func TestRotatingFileWriterBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), RotateRetention(RetentionPolicy{MaxFiles: 2}),
+		CompressBackups(), RotateTimeFunc(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		rotateAt(t, w, now)
+		now = now.Add(time.Hour)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := rotatedFiles(t, dir)
+	want := []string{"app-2020-01-01T02-00-00.000.log.gz", "app-2020-01-01T03-00-00.000.log.gz"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	file, err := os.Open(filepath.Join(dir, got[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(gz)
+	if got, want := string(content), "event\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRotatingFileWriterIndex(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), RotateRetention(RetentionPolicy{MaxFiles: 1}),
+		CompressBackups(), RotateIndex(NewIndexer(100, 0.01, "trace_id")), RotateTimeFunc(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, traceID := range []string{"a1", "b2"} {
+		if _, err := w.Write([]byte(`{"trace_id":"` + traceID + `"}` + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		rotateAt(t, w, now)
+		now = now.Add(time.Hour)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := rotatedFiles(t, dir)
+	want := []string{"app-2020-01-01T01-00-00.000.index.json", "app-2020-01-01T01-00-00.000.log.gz"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, got[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index ObjectIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	if index.Events != 2 {
+		t.Errorf("events: got %d, want 2", index.Events)
+	}
+	if !index.MayContain("trace_id", "b2") || index.MayContain("trace_id", "a1") {
+		t.Errorf("invalid index: %s", data)
+	}
+}
+
+func TestRotatingFileWriterMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second).Add(-3 * time.Hour)
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), RotateRetention(RetentionPolicy{MaxAge: 90 * time.Minute}),
+		RotateTimeFunc(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Hour)
+		rotateAt(t, w, now)
+	}
+	w.Close()
+
+	got := rotatedFiles(t, dir)
+	want := []string{
+		"app-" + now.Add(-time.Hour).Format(rotatedTimeFormat) + ".log",
+		"app-" + now.Format(rotatedTimeFormat) + ".log",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRotatingFileWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "100%.log")
+	w, err := NewRotatingFileWriter(path, RotateSize(10), RotateFileOptions(FilePerm(0o600, 0o700)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("event 1\n"))
+	w.Close()
+	w.Write([]byte("event 2\n"))
+	w.Close()
+
+	if got, want := len(rotatedFiles(t, dir)), 2; got != want {
+		t.Errorf("files: got %d, want %d", got, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Mode().Perm(), os.FileMode(0o600); got != want {
+		t.Errorf("invalid permissions: got %v, want %v", got, want)
+	}
+	content, _ := os.ReadFile(path)
+	if got, want := string(content), "event 2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRotatingFileWriterDescribeConfig(t *testing.T) {
+	w, err := NewRotatingFileWriter(filepath.Join(t.TempDir(), "app.log"), RotateSize(1024),
+		RotateRetention(RetentionPolicy{MaxFiles: 3}), CompressBackups())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	got := w.DescribeConfig()
+	if !strings.HasPrefix(got, `rotating_file("`) || !strings.HasSuffix(got, `app.log", size=1024, max_files=3, gzip)`) {
+		t.Errorf("unexpected config: %s", got)
+	}
+}
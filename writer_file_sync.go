@@ -0,0 +1,94 @@
+package rz
+
+import (
+	"time"
+)
+
+// SyncPolicy decides when a FileWriter syncs its file to stable storage (fsync), trading
+// throughput for durability.
+type SyncPolicy interface {
+	// ShouldSync is called after each write with the level of the event, the number of events
+	// written since the last sync (including this one) and the time of the last sync.
+	ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool
+}
+
+// SyncNever never syncs: the operating system decides when the data is written. It's the default.
+type SyncNever struct{}
+
+// ShouldSync implements the SyncPolicy interface.
+func (SyncNever) ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool {
+	return false
+}
+
+// SyncEvery syncs every N events.
+type SyncEvery uint32
+
+// ShouldSync implements the SyncPolicy interface.
+func (s SyncEvery) ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool {
+	return s > 0 && unsynced >= int(s)
+}
+
+// SyncInterval syncs when the last sync is older than the interval. It's checked on writes:
+// use FileWriter.Flush to sync the last events.
+type SyncInterval time.Duration
+
+// ShouldSync implements the SyncPolicy interface.
+func (s SyncInterval) ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool {
+	return time.Since(lastSync) >= time.Duration(s)
+}
+
+// SyncLevel syncs after each event with a level greater than or equal to it (e.g. ErrorLevel),
+// NoLevel events excepted.
+type SyncLevel LogLevel
+
+// ShouldSync implements the SyncPolicy interface.
+func (s SyncLevel) ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool {
+	return level != NoLevel && level >= LogLevel(s)
+}
+
+// SyncAny syncs when any of its policies decides to.
+type SyncAny []SyncPolicy
+
+// ShouldSync implements the SyncPolicy interface.
+func (s SyncAny) ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool {
+	for _, policy := range s {
+		if policy.ShouldSync(level, unsynced, lastSync) {
+			return true
+		}
+	}
+	return false
+}
+
+// FileSync sets the sync policy of a FileWriter.
+func FileSync(policy SyncPolicy) FileWriterOption {
+	return func(w *FileWriter) {
+		w.sync = policy
+	}
+}
+
+// syncAfterWrite syncs the current file if the policy decides to. w.mu must be held.
+func (w *FileWriter) syncAfterWrite(level LogLevel) error {
+	w.unsynced++
+	if w.sync == nil || !w.sync.ShouldSync(level, w.unsynced, w.lastSync) {
+		return nil
+	}
+	return w.syncFile()
+}
+
+// syncFile syncs the current file. w.mu must be held.
+func (w *FileWriter) syncFile() error {
+	w.unsynced = 0
+	w.lastSync = time.Now()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Flush implements the Flusher interface: the current file is synced to stable storage,
+// whatever the sync policy.
+func (w *FileWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.syncFile()
+}
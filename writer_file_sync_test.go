@@ -0,0 +1,69 @@
+package rz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncPolicies(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		policy   SyncPolicy
+		level    LogLevel
+		unsynced int
+		lastSync time.Time
+		want     bool
+	}{
+		{"Never", SyncNever{}, ErrorLevel, 100, now.Add(-time.Hour), false},
+		{"Every/below", SyncEvery(10), InfoLevel, 9, now, false},
+		{"Every/reached", SyncEvery(10), InfoLevel, 10, now, true},
+		{"Interval/recent", SyncInterval(time.Second), InfoLevel, 1, now, false},
+		{"Interval/old", SyncInterval(time.Second), InfoLevel, 1, now.Add(-2 * time.Second), true},
+		{"Level/below", SyncLevel(ErrorLevel), WarnLevel, 1, now, false},
+		{"Level/above", SyncLevel(ErrorLevel), FatalLevel, 1, now, true},
+		{"Level/none", SyncLevel(ErrorLevel), NoLevel, 1, now, false},
+		{"Any", SyncAny{SyncEvery(10), SyncLevel(ErrorLevel)}, ErrorLevel, 1, now, true},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.ShouldSync(tt.level, tt.unsynced, tt.lastSync); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type countingSyncPolicy struct {
+	SyncPolicy
+	calls []int
+}
+
+func (p *countingSyncPolicy) ShouldSync(level LogLevel, unsynced int, lastSync time.Time) bool {
+	p.calls = append(p.calls, unsynced)
+	return p.SyncPolicy.ShouldSync(level, unsynced, lastSync)
+}
+
+func TestFileWriterSync(t *testing.T) {
+	policy := &countingSyncPolicy{SyncPolicy: SyncAny{SyncEvery(3), SyncLevel(ErrorLevel)}}
+	w, err := NewFileWriter(filepath.Join(t.TempDir(), "app.log"), FileSync(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := New(Writer(w))
+	log.Info("1")
+	log.Error("2")
+	log.Info("3")
+	log.Info("4")
+	log.Info("5")
+	log.Info("6")
+	if err = w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("7")
+
+	if got, want := fmt.Sprint(policy.calls), "[1 2 1 2 3 1 1]"; got != want {
+		t.Errorf("invalid unsynced counts: got %v, want %v", got, want)
+	}
+}
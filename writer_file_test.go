@@ -0,0 +1,59 @@
+package rz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpandPathTemplate(t *testing.T) {
+	now := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"/var/log/app.log", "/var/log/app.log"},
+		{"/var/log/app/%Y/%m/%d/app-%H.log", "/var/log/app/2020/03/04/app-05.log"},
+		{"%Y%m%dT%H%M%S-%j", "20200304T050607-064"},
+		{"100%%-%x-%", "100%-%x-%"},
+	}
+	for _, tt := range tests {
+		if got := expandPathTemplate(tt.template, now); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 3, 4, 5, 59, 0, 0, time.UTC)
+	w, err := NewFileWriter(filepath.Join(dir, "%Y", "%m", "%d", "app-%H.log"), FileTimeFunc(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := New(Writer(w), Fields(Timestamp(false)))
+	log.Info("first")
+	now = now.Add(time.Minute)
+	log.Info("second")
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		filepath.Join(dir, "2020", "03", "04", "app-05.log"): `{"level":"info","message":"first"}` + "\n",
+		filepath.Join(dir, "2020", "03", "04", "app-06.log"): `{"level":"info","message":"second"}` + "\n",
+	}
+	for path, want := range files {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("invalid content of %s:\ngot:  %v\nwant: %v", path, string(got), want)
+		}
+	}
+	if got, want := w.Path(), filepath.Join(dir, "2020", "03", "04", "app-06.log"); got != want {
+		t.Errorf("invalid path: got %s, want %s", got, want)
+	}
+}
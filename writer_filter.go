@@ -0,0 +1,129 @@
+package rz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// EventFilter is a predicate of FilterWriter over a decoded event: it returns false to drop
+// the event.
+type EventFilter func(e DecodedEvent) bool
+
+// DropQuery returns an EventFilter dropping the events matching query, e.g.
+//
+//     rz.DropQuery(rz.MustParseQuery(`fields.component=healthcheck AND level<warn`))
+func DropQuery(query *Query) EventFilter {
+	return func(e DecodedEvent) bool {
+		return !query.Match(e)
+	}
+}
+
+// KeepQuery returns an EventFilter dropping the events not matching query.
+func KeepQuery(query *Query) EventFilter {
+	return func(e DecodedEvent) bool {
+		return query.Match(e)
+	}
+}
+
+// DropField returns an EventFilter dropping the events whose field has one of values. Values are
+// compared with the string representation of the field (e.g. "42" for a number).
+func DropField(field string, values ...string) EventFilter {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return func(e DecodedEvent) bool {
+		value, ok := e.Fields[field]
+		if !ok {
+			return true
+		}
+		switch value := value.(type) {
+		case string:
+			return !set[value]
+		case bool:
+			return !set[strconv.FormatBool(value)]
+		case nil:
+			return !set["null"]
+		}
+		return !set[fmt.Sprint(value)]
+	}
+}
+
+type filterWriter struct {
+	lw      LevelWriter
+	filters []EventFilter
+}
+
+// FilterWriter wraps w so that the events are written only if all the filters return true,
+// e.g. to silence a noisy subsystem without touching its call sites:
+//
+//     rz.FilterWriter(os.Stderr, rz.DropField("component", "healthcheck"))
+//
+// Events are decoded once for all the filters; they must be JSON encoded (no formatter), as the
+// events which can't be decoded are written unfiltered.
+func FilterWriter(w io.Writer, filters ...EventFilter) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return filterWriter{lw: lw, filters: filters}
+}
+
+func (w filterWriter) keep(p []byte) bool {
+	if len(w.filters) == 0 {
+		return true
+	}
+	event, err := DecodeEvent(p)
+	if err != nil {
+		return true
+	}
+	for _, filter := range w.filters {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// Write implements the io.Writer interface.
+func (w filterWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The dropped events are reported as written.
+func (w filterWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	if !w.keep(p) {
+		return len(p), nil
+	}
+	return w.lw.WriteLevel(level, p)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (w filterWriter) WriteContext(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	if !w.keep(p) {
+		return len(p), nil
+	}
+	return writeContext(ctx, w.lw, level, p)
+}
+
+// Flush implements the Flusher interface.
+func (w filterWriter) Flush() error {
+	return flush(w.lw)
+}
+
+// CloseContext implements the ContextCloser interface.
+func (w filterWriter) CloseContext(ctx context.Context) error {
+	return CloseWriter(ctx, w.lw)
+}
+
+// CheckHealth implements the HealthChecker interface.
+func (w filterWriter) CheckHealth() error {
+	return CheckHealth(w.lw)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w filterWriter) DescribeConfig() string {
+	return "filter(" + strconv.Itoa(len(w.filters)) + ", " + describe(w.lw) + ")"
+}
@@ -0,0 +1,39 @@
+package rz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFilterWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(FilterWriter(out,
+		DropField("component", "healthcheck"),
+		DropField("code", "404"),
+		DropQuery(MustParseQuery(`msg~"^debug"`)),
+	)), Fields(Timestamp(false)))
+	log.Info("ping", String("component", "healthcheck"))
+	log.Info("request", String("component", "api"), Int("code", 200))
+	log.Info("request", String("component", "api"), Int("code", 404))
+	log.Info("debug dump")
+	log.Warn("no component")
+
+	want := `{"level":"info","component":"api","code":200,"message":"request"}` + "\n" +
+		`{"level":"warning","message":"no component"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	w := FilterWriter(out, KeepQuery(MustParseQuery(`level>=error`)))
+	w.Write([]byte("not json\n"))
+	w.WriteLevel(InfoLevel, []byte(`{"level":"info"}`+"\n"))
+	w.WriteLevel(ErrorLevel, []byte(`{"level":"error"}`+"\n"))
+	if got, want := out.String(), "not json\n"+`{"level":"error"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := describe(w); !strings.HasPrefix(got, "filter(1, ") {
+		t.Errorf("DescribeConfig() = %q", got)
+	}
+}
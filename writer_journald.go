@@ -0,0 +1,201 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// DefaultJournaldSocket is the socket of the native protocol of systemd-journald.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldWriter writes events to systemd-journald with its native protocol: the PRIORITY of
+// the entries is the syslog severity of the level of the events (see SyslogSeverity), the message
+// field becomes MESSAGE and each other top-level field becomes a journal field of the same name,
+// converted to upper case (e.g. "user_id" to USER_ID), with its string value or its JSON value
+// for the other types. The events which are not JSON objects (e.g. formatted events) are written
+// as MESSAGE only. SYSLOG_IDENTIFIER is set to the name of the executable.
+//
+// The entries too large for a datagram are passed to journald in an unlinked temporary file, on
+// the unix platforms.
+type JournaldWriter struct {
+	socket       string
+	identifier   string
+	messageField string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+	buf  []byte
+}
+
+// JournaldWriterOption is used to configure a JournaldWriter.
+type JournaldWriterOption func(w *JournaldWriter)
+
+// JournaldSocket updates the path of the journald socket. Default: DefaultJournaldSocket.
+func JournaldSocket(socket string) JournaldWriterOption {
+	return func(w *JournaldWriter) {
+		w.socket = socket
+	}
+}
+
+// JournaldIdentifier updates the SYSLOG_IDENTIFIER of the entries. Default: the name of the
+// executable.
+func JournaldIdentifier(identifier string) JournaldWriterOption {
+	return func(w *JournaldWriter) {
+		w.identifier = identifier
+	}
+}
+
+// JournaldMessageFieldName updates the field written as MESSAGE, which should be the message
+// field name of the logger. Default: DefaultMessageFieldName.
+func JournaldMessageFieldName(messageField string) JournaldWriterOption {
+	return func(w *JournaldWriter) {
+		w.messageField = messageField
+	}
+}
+
+// NewJournaldWriter creates a JournaldWriter and connects it to the journald socket.
+func NewJournaldWriter(options ...JournaldWriterOption) (*JournaldWriter, error) {
+	w := &JournaldWriter{
+		socket:       DefaultJournaldSocket,
+		identifier:   filepath.Base(os.Args[0]),
+		messageField: DefaultMessageFieldName,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.socket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// Write implements the io.Writer interface.
+func (w *JournaldWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *JournaldWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = appendJournalField(w.buf[:0], "PRIORITY", []byte(strconv.Itoa(SyslogSeverity(level))))
+	if w.identifier != "" {
+		w.buf = appendJournalField(w.buf, "SYSLOG_IDENTIFIER", []byte(w.identifier))
+	}
+	w.buf = w.appendEvent(w.buf, p)
+
+	if _, err = w.conn.Write(w.buf); err != nil {
+		if !isMessageTooLarge(err) {
+			return 0, err
+		}
+		if err = sendJournalFile(w.conn, w.buf); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// appendEvent appends the fields of the JSON event p, or p as MESSAGE if it's not a JSON object.
+func (w *JournaldWriter) appendEvent(dst, p []byte) []byte {
+	event := bytes.TrimRight(p, "\n")
+	start := len(dst)
+	dec := json.NewDecoder(bytes.NewReader(event))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return appendJournalField(dst, "MESSAGE", event)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return appendJournalField(dst[:start], "MESSAGE", event)
+		}
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return appendJournalField(dst[:start], "MESSAGE", event)
+		}
+		key, _ := tok.(string)
+		name := "MESSAGE"
+		if key != w.messageField {
+			if name = journalFieldName(key); name == "" || name == "MESSAGE" || name == "PRIORITY" {
+				continue
+			}
+		}
+		if len(value) > 0 && value[0] == '"' {
+			var s string
+			if json.Unmarshal(value, &s) == nil {
+				dst = appendJournalField(dst, name, []byte(s))
+				continue
+			}
+		}
+		dst = appendJournalField(dst, name, value)
+	}
+	return dst
+}
+
+// journalFieldName converts key to a valid journal field name: upper case letters, digits and
+// underscores, starting with a letter, at most 64 characters. It returns "" if there is none.
+func journalFieldName(key string) string {
+	name := make([]byte, 0, len(key))
+	for i := 0; i < len(key) && len(name) < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9', c == '_':
+			if len(name) == 0 {
+				continue
+			}
+		default:
+			if len(name) == 0 {
+				continue
+			}
+			c = '_'
+		}
+		name = append(name, c)
+	}
+	return string(name)
+}
+
+// appendJournalField appends a field in the native protocol: "NAME=value\n", or the binary safe
+// form if value contains a newline.
+func appendJournalField(dst []byte, name string, value []byte) []byte {
+	dst = append(dst, name...)
+	if bytes.IndexByte(value, '\n') < 0 {
+		dst = append(dst, '=')
+		dst = append(dst, value...)
+		return append(dst, '\n')
+	}
+	dst = append(dst, '\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	dst = append(dst, size[:]...)
+	dst = append(dst, value...)
+	return append(dst, '\n')
+}
+
+// Close closes the connection to journald.
+func (w *JournaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.Close()
+}
+
+// CloseContext implements the ContextCloser interface.
+func (w *JournaldWriter) CloseContext(ctx context.Context) error {
+	return w.Close()
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *JournaldWriter) DescribeConfig() string {
+	return "journald(" + strconv.Quote(w.socket) + ")"
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package rz
+
+import (
+	"net"
+)
+
+// isMessageTooLarge returns false: the entries can't be passed in a file on this platform.
+func isMessageTooLarge(err error) bool {
+	return false
+}
+
+func sendJournalFile(conn *net.UnixConn, entry []byte) error {
+	return nil
+}
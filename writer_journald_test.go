@@ -0,0 +1,70 @@
+package rz
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestJournaldWriter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix datagram sockets are not supported")
+	}
+	dir, err := os.MkdirTemp("", "rz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "journal")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := NewJournaldWriter(JournaldSocket(socket), JournaldIdentifier("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := New(Writer(w), Fields(Timestamp(false)))
+
+	log.Error("failed\nto connect", String("user-id", "42"), Int("attempts", 3), Strings("hosts", []string{"a"}),
+		String("_private", "x"))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "PRIORITY=3\nSYSLOG_IDENTIFIER=app\nLEVEL=error\nUSER_ID=42\nATTEMPTS=3\nHOSTS=[\"a\"]\nPRIVATE=x\n" +
+		"MESSAGE\n\x11\x00\x00\x00\x00\x00\x00\x00failed\nto connect\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	w.Write([]byte("not json\n"))
+	n, err = conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(buf[:n]), "PRIORITY=5\nSYSLOG_IDENTIFIER=app\nMESSAGE=not json\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":    "USER_ID",
+		"http.route": "HTTP_ROUTE",
+		"_internal":  "INTERNAL",
+		"1st":        "ST",
+		"---":        "",
+	}
+	for key, want := range tests {
+		if got := journalFieldName(key); got != want {
+			t.Errorf("journalFieldName(%q): got %q, want %q", key, got, want)
+		}
+	}
+}
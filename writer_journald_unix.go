@@ -0,0 +1,38 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package rz
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// isMessageTooLarge returns true if err is returned by the write of a too large datagram.
+func isMessageTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendJournalFile writes the entry to an unlinked temporary file, and passes its descriptor to
+// journald.
+func sendJournalFile(conn *net.UnixConn, entry []byte) error {
+	dir := "/dev/shm"
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = ""
+	}
+	file, err := os.CreateTemp(dir, "rz-journal-")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = os.Remove(file.Name()); err != nil {
+		return err
+	}
+	if _, err = file.Write(entry); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), nil)
+	return err
+}
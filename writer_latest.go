@@ -0,0 +1,153 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// LatestWriter passes events through to a writer, and keeps in memory the latest event for
+// each value of a key field, so the freshest state of an entity can be queried without
+// searching the logs. Events must be JSON encoded (no formatter), events without the key field
+// are only passed through.
+//
+// LatestWriter implements http.Handler: GET returns all the latest events as a JSON object
+// indexed by key value, and GET ?key=<value> returns the latest event for value.
+type LatestWriter struct {
+	w        LevelWriter
+	keyField string
+	maxKeys  int
+
+	mu     sync.RWMutex
+	latest map[string]json.RawMessage
+}
+
+// NewLatestWriter creates a LatestWriter indexing events by the keyField field.
+// If maxKeys is > 0, events with a new key value are not indexed once maxKeys values are known.
+func NewLatestWriter(w io.Writer, keyField string, maxKeys int) *LatestWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return &LatestWriter{
+		w:        lw,
+		keyField: keyField,
+		maxKeys:  maxKeys,
+		latest:   map[string]json.RawMessage{},
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *LatestWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *LatestWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.index(p)
+	return w.w.WriteLevel(level, p)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (w *LatestWriter) WriteContext(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	w.index(p)
+	return writeContext(ctx, w.w, level, p)
+}
+
+func (w *LatestWriter) index(p []byte) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return
+	}
+	raw, ok := fields[w.keyField]
+	if !ok {
+		return
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		key = string(raw)
+	}
+	event := json.RawMessage(bytes.TrimSpace(append([]byte(nil), p...)))
+
+	w.mu.Lock()
+	if _, exists := w.latest[key]; exists || w.maxKeys <= 0 || len(w.latest) < w.maxKeys {
+		w.latest[key] = event
+	}
+	w.mu.Unlock()
+}
+
+// Get returns the latest event for the key value. It must not be modified.
+func (w *LatestWriter) Get(key string) (event json.RawMessage, ok bool) {
+	w.mu.RLock()
+	event, ok = w.latest[key]
+	w.mu.RUnlock()
+	return
+}
+
+// Keys returns the sorted key values indexed by the writer.
+func (w *LatestWriter) Keys() []string {
+	w.mu.RLock()
+	keys := make([]string, 0, len(w.latest))
+	for key := range w.latest {
+		keys = append(keys, key)
+	}
+	w.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// Snapshot returns a copy of the latest events, indexed by key value.
+func (w *LatestWriter) Snapshot() map[string]json.RawMessage {
+	w.mu.RLock()
+	ret := make(map[string]json.RawMessage, len(w.latest))
+	for key, event := range w.latest {
+		ret[key] = event
+	}
+	w.mu.RUnlock()
+	return ret
+}
+
+// Reset forgets all the indexed events.
+func (w *LatestWriter) Reset() {
+	w.mu.Lock()
+	w.latest = map[string]json.RawMessage{}
+	w.mu.Unlock()
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (w *LatestWriter) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		res.Header().Set("Allow", "GET, HEAD")
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	var body []byte
+	if query := req.URL.Query(); query.Has("key") {
+		event, ok := w.Get(query.Get("key"))
+		if !ok {
+			http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		body = event
+	} else {
+		body, _ = json.Marshal(w.Snapshot())
+	}
+	res.Header().Set("Content-Type", "application/json")
+	// body may be the stored event: it's not appended to, as it's shared by the requests
+	res.Write(body)
+	res.Write([]byte{'\n'})
+}
+
+// Flush implements the Flusher interface.
+func (w *LatestWriter) Flush() error {
+	return flush(w.w)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *LatestWriter) DescribeConfig() string {
+	return "latest(" + w.keyField + ", " + describe(w.w) + ")"
+}
@@ -0,0 +1,68 @@
+package rz
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLatestWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	latest := NewLatestWriter(out, "user", 2)
+	log := New(Writer(latest), Fields(Timestamp(false)))
+	log.Info("login", String("user", "alice"))
+	log.Info("login", String("user", "bob"))
+	log.Info("logout", String("user", "alice"))
+	log.Info("login", String("user", "carol"))
+	log.Info("no key")
+
+	if got, want := len(bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))), 5; got != want {
+		t.Errorf("events not passed through: got %d, want %d", got, want)
+	}
+	if got, want := latest.Keys(), []string{"alice", "bob"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("invalid keys: got %v, want %v", got, want)
+	}
+	event, ok := latest.Get("alice")
+	if got, want := string(event), `{"level":"info","user":"alice","message":"logout"}`; !ok || got != want {
+		t.Errorf("invalid latest event:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	tests := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/?key=bob", http.StatusOK, `{"level":"info","user":"bob","message":"login"}` + "\n"},
+		{"/?key=carol", http.StatusNotFound, "Not Found\n"},
+		{"/", http.StatusOK, `{"alice":{"level":"info","user":"alice","message":"logout"},"bob":{"level":"info","user":"bob","message":"login"}}` + "\n"},
+	}
+	for _, tt := range tests {
+		res := httptest.NewRecorder()
+		latest.ServeHTTP(res, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if res.Code != tt.status || res.Body.String() != tt.body {
+			t.Errorf("%s: invalid response %d:\ngot:  %v\nwant: %v", tt.url, res.Code, res.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestLatestWriterConcurrentRequests(t *testing.T) {
+	latest := NewLatestWriter(&bytes.Buffer{}, "user", 0)
+	log := New(Writer(latest), Fields(Timestamp(false)))
+	log.Info("login", String("user", "alice"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := httptest.NewRecorder()
+			latest.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/?key=alice", nil))
+		}()
+	}
+	wg.Wait()
+	if event, _ := latest.Get("alice"); bytes.HasSuffix(event, []byte("\n")) {
+		t.Errorf("the stored event was modified: %q", event)
+	}
+}
@@ -0,0 +1,77 @@
+package rz
+
+import (
+	"context"
+	"io"
+)
+
+type minLevelWriter struct {
+	min LogLevel
+	lw  LevelWriter
+}
+
+// MinLevelWriter wraps w so that only the events of level min or above are written to it, the
+// others being discarded. Events without level are written only if min is DebugLevel.
+//
+// Combined with MultiLevelWriter, each destination gets its own minimum level:
+//
+//     writer := rz.MultiLevelWriter(
+//         file,
+//         rz.MinLevelWriter(rz.ErrorLevel, os.Stderr),
+//         rz.MinLevelWriter(rz.WarnLevel, network),
+//     )
+func MinLevelWriter(min LogLevel, w io.Writer) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return minLevelWriter{min: min, lw: lw}
+}
+
+func (w minLevelWriter) enabled(level LogLevel) bool {
+	if level == NoLevel {
+		return w.min == DebugLevel
+	}
+	return level >= w.min
+}
+
+// Write implements the io.Writer interface.
+func (w minLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The discarded events are reported as written.
+func (w minLevelWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	if !w.enabled(level) {
+		return len(p), nil
+	}
+	return w.lw.WriteLevel(level, p)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (w minLevelWriter) WriteContext(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	if !w.enabled(level) {
+		return len(p), nil
+	}
+	return writeContext(ctx, w.lw, level, p)
+}
+
+// Flush implements the Flusher interface.
+func (w minLevelWriter) Flush() error {
+	return flush(w.lw)
+}
+
+// CloseContext implements the ContextCloser interface.
+func (w minLevelWriter) CloseContext(ctx context.Context) error {
+	return CloseWriter(ctx, w.lw)
+}
+
+// CheckHealth implements the HealthChecker interface.
+func (w minLevelWriter) CheckHealth() error {
+	return CheckHealth(w.lw)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w minLevelWriter) DescribeConfig() string {
+	return "min_level(" + w.min.String() + ", " + describe(w.lw) + ")"
+}
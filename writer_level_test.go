@@ -0,0 +1,41 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMinLevelWriter(t *testing.T) {
+	all, errors, warnings := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Writer(MultiLevelWriter(
+		all,
+		MinLevelWriter(ErrorLevel, errors),
+		MinLevelWriter(WarnLevel, warnings),
+	)), Fields(Timestamp(false)))
+	log.Debug("debug")
+	log.Warn("warn")
+	log.Error("error")
+	log.Log("none")
+
+	if got, want := all.String(), `{"level":"debug","message":"debug"}`+"\n"+`{"level":"warning","message":"warn"}`+"\n"+
+		`{"level":"error","message":"error"}`+"\n"+`{"message":"none"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := errors.String(), `{"level":"error","message":"error"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := warnings.String(), `{"level":"warning","message":"warn"}`+"\n"+`{"level":"error","message":"error"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	debug := &bytes.Buffer{}
+	log = New(Writer(MinLevelWriter(DebugLevel, debug)), Fields(Timestamp(false)))
+	log.Log("none")
+	if got, want := debug.String(), `{"message":"none"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if got, want := describe(MinLevelWriter(WarnLevel, MultiLevelWriter())), "min_level(warning, multi())"; got != want {
+		t.Errorf("DescribeConfig() = %q, want %q", got, want)
+	}
+}
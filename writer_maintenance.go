@@ -0,0 +1,140 @@
+package rz
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// MaintenanceWindow is a period during which alerts are not wanted.
+type MaintenanceWindow struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+	// Every, if > 0, repeats the window every period, e.g. 24 * time.Hour for a daily window.
+	Every time.Duration
+}
+
+// active returns the start of the occurrence of the window containing t, if any.
+func (w MaintenanceWindow) active(t time.Time) (start time.Time, ok bool) {
+	if t.Before(w.Start) {
+		return
+	}
+	start = w.Start
+	if w.Every > 0 {
+		start = w.Start.Add(t.Sub(w.Start) / w.Every * w.Every)
+	}
+	return start, t.Before(start.Add(w.End.Sub(w.Start)))
+}
+
+// MaintenanceWriter suppresses or downgrades the events of a writer during maintenance windows,
+// e.g. to silence a writer sending alerts while the rest of the logs are kept. When a window
+// ends, a "maintenance window summary" event with the number of affected events is written
+// before the next event, or when the writer is flushed.
+type MaintenanceWriter struct {
+	w         LevelWriter
+	minLevel  LogLevel
+	downgrade LogLevel
+	windows   []MaintenanceWindow
+	summary   Logger
+	now       func() time.Time
+
+	mu         sync.Mutex
+	current    *maintenanceOccurrence
+	suppressed uint64
+	downgraded uint64
+}
+
+type maintenanceOccurrence struct {
+	window int
+	start  time.Time
+}
+
+// NewMaintenanceWriter creates a MaintenanceWriter. During windows, the events with a level
+// >= minLevel are written with the downgrade level (which may be used by level aware writers;
+// the payload is not modified), or suppressed if downgrade is Disabled.
+func NewMaintenanceWriter(w io.Writer, minLevel, downgrade LogLevel, windows ...MaintenanceWindow) *MaintenanceWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return &MaintenanceWriter{
+		w:         lw,
+		minLevel:  minLevel,
+		downgrade: downgrade,
+		windows:   windows,
+		summary:   New(Writer(lw)),
+		now:       time.Now,
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *MaintenanceWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *MaintenanceWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.Lock()
+	if w.update(w.now()) && level >= w.minLevel && level != NoLevel {
+		if w.downgrade == Disabled {
+			w.suppressed++
+			w.mu.Unlock()
+			return len(p), nil
+		}
+		w.downgraded++
+		level = w.downgrade
+	}
+	w.mu.Unlock()
+	return w.w.WriteLevel(level, p)
+}
+
+// update updates the current window occurrence, logging the summary of the previous one
+// if it has ended, and returns true if a window is active. w.mu must be held.
+func (w *MaintenanceWriter) update(now time.Time) bool {
+	var current *maintenanceOccurrence
+	for i, window := range w.windows {
+		if start, ok := window.active(now); ok {
+			current = &maintenanceOccurrence{window: i, start: start}
+			break
+		}
+	}
+	if w.current != nil && (current == nil || *current != *w.current) {
+		w.logSummary()
+	}
+	if w.current == nil {
+		w.current = current
+	}
+	return current != nil
+}
+
+// logSummary logs the summary of the current window occurrence and resets it. w.mu must be held.
+func (w *MaintenanceWriter) logSummary() {
+	occurrence := w.current
+	window := w.windows[occurrence.window]
+	suppressed, downgraded := w.suppressed, w.downgraded
+	w.current = nil
+	w.suppressed, w.downgraded = 0, 0
+	w.summary.Info("maintenance window summary", func(e *Event) {
+		e.buf = e.encoder.AppendBeginMarker(e.encoder.AppendKey(e.buf, "maintenance"))
+		e.string("window", window.Name)
+		e.time("start", occurrence.start)
+		e.time("end", occurrence.start.Add(window.End.Sub(window.Start)))
+		e.uint64("suppressed", suppressed)
+		e.uint64("downgraded", downgraded)
+		e.buf = e.encoder.AppendEndMarker(e.buf)
+	})
+}
+
+// Flush implements the Flusher interface. The summary of a window which has ended is written first.
+func (w *MaintenanceWriter) Flush() error {
+	w.mu.Lock()
+	w.update(w.now())
+	w.mu.Unlock()
+	return flush(w.w)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *MaintenanceWriter) DescribeConfig() string {
+	return "maintenance(" + describe(w.w) + ")"
+}
@@ -0,0 +1,84 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	start := time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)
+	now := start.Add(-time.Minute)
+	mw := NewMaintenanceWriter(out, WarnLevel, Disabled, MaintenanceWindow{
+		Name:  "nightly",
+		Start: start,
+		End:   start.Add(time.Hour),
+		Every: 24 * time.Hour,
+	})
+	mw.now = func() time.Time { return now }
+	log := New(Writer(mw), Fields(Timestamp(false)))
+
+	log.Error("before")
+	now = start.Add(24*time.Hour + time.Minute)
+	log.Error("during")
+	log.Warn("during")
+	log.Info("kept")
+	now = start.Add(25*time.Hour + time.Minute)
+	log.Error("after")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("invalid number of events: %d\n%s", len(lines), out.String())
+	}
+	if lines[0] != `{"level":"error","message":"before"}` || lines[1] != `{"level":"info","message":"kept"}` ||
+		lines[3] != `{"level":"error","message":"after"}` {
+		t.Errorf("invalid events:\n%s", out.String())
+	}
+	var summary struct {
+		Message     string
+		Maintenance struct {
+			Window     string
+			Start      time.Time
+			End        time.Time
+			Suppressed int
+			Downgraded int
+		}
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	m := summary.Maintenance
+	if summary.Message != "maintenance window summary" || m.Window != "nightly" || m.Suppressed != 2 || m.Downgraded != 0 ||
+		!m.Start.Equal(start.Add(24*time.Hour)) || !m.End.Equal(start.Add(25*time.Hour)) {
+		t.Errorf("invalid summary: %s", lines[2])
+	}
+}
+
+func TestMaintenanceWriterDowngrade(t *testing.T) {
+	lw := &levelRecorder{}
+	start := time.Now().Add(-time.Minute)
+	mw := NewMaintenanceWriter(lw, ErrorLevel, InfoLevel, MaintenanceWindow{Start: start, End: start.Add(time.Hour)})
+	log := New(Writer(mw))
+	log.Error("alert")
+	log.Warn("warning")
+
+	if levels := lw.levels; len(levels) != 2 || levels[0] != InfoLevel || levels[1] != WarnLevel {
+		t.Errorf("invalid levels: %v", levels)
+	}
+}
+
+type levelRecorder struct {
+	levels []LogLevel
+}
+
+func (w *levelRecorder) Write(p []byte) (int, error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+func (w *levelRecorder) WriteLevel(level LogLevel, p []byte) (int, error) {
+	w.levels = append(w.levels, level)
+	return len(p), nil
+}
@@ -0,0 +1,362 @@
+package rz
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NetworkWriterStats holds the metrics of a NetworkWriter.
+type NetworkWriterStats struct {
+	// Written is the number of events written to the connection.
+	Written uint64
+	// Buffered is the number of events currently buffered, waiting for a connection.
+	Buffered int
+	// Dropped is the number of events dropped because the buffer was full.
+	Dropped uint64
+	// Reconnects is the number of connections established after a disconnection.
+	Reconnects uint64
+}
+
+// NetworkWriter writes events to a network address ("tcp", "udp", "unix"...), e.g. of a log
+// collector, optionally with TLS. Over stream connections, events are written as JSON lines;
+// over packet connections, each event is a datagram.
+//
+// The events are buffered, up to a maximum number of events beyond which the oldest ones are
+// dropped, and written by a background goroutine, so the writes never wait for the connection.
+// The connection is reopened after a write error with an exponential backoff. If an event was
+// partially written to a stream connection, only its unwritten bytes are written to the new
+// connection. It's safe for concurrent use.
+type NetworkWriter struct {
+	network     string
+	addr        string
+	tlsConfig   *tls.Config
+	timeout     time.Duration
+	bufferSize  int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	ctx         context.Context // canceled to stop the background goroutine
+	cancel      context.CancelFunc
+	closing     chan struct{} // closed by CloseContext
+	stopped     chan struct{} // closed when the background goroutine returns
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	conn      net.Conn
+	buffer    [][]byte
+	connected bool // a connection was established once
+	closed    bool
+	lastErr   error
+	stats     NetworkWriterStats
+}
+
+// NetworkWriterOption is used to configure a NetworkWriter.
+type NetworkWriterOption func(w *NetworkWriter)
+
+// NetworkTLS enables TLS with config, for stream networks.
+func NetworkTLS(config *tls.Config) NetworkWriterOption {
+	return func(w *NetworkWriter) {
+		w.tlsConfig = config
+	}
+}
+
+// NetworkTimeout updates the timeout of the connections and of the writes. Default: 10 seconds.
+func NetworkTimeout(timeout time.Duration) NetworkWriterOption {
+	return func(w *NetworkWriter) {
+		w.timeout = timeout
+	}
+}
+
+// NetworkBufferSize updates the maximum number of events buffered while disconnected.
+// Default: 1000.
+func NetworkBufferSize(size int) NetworkWriterOption {
+	return func(w *NetworkWriter) {
+		if size > 0 {
+			w.bufferSize = size
+		}
+	}
+}
+
+// NetworkBackoff updates the bounds of the delay between connection attempts, doubled after each
+// failed attempt. Default: from 100ms to 30s.
+func NetworkBackoff(min, max time.Duration) NetworkWriterOption {
+	return func(w *NetworkWriter) {
+		w.minBackoff = min
+		w.maxBackoff = max
+	}
+}
+
+// NewNetworkWriter creates a NetworkWriter writing to addr on network, and starts connecting.
+func NewNetworkWriter(network, addr string, options ...NetworkWriterOption) *NetworkWriter {
+	w := &NetworkWriter{
+		network:    network,
+		addr:       addr,
+		timeout:    10 * time.Second,
+		bufferSize: 1000,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.dialContext == nil {
+		dialer := &net.Dialer{Timeout: w.timeout}
+		w.dialContext = dialer.DialContext
+		if w.tlsConfig != nil && !w.isPacket() {
+			tlsDialer := &tls.Dialer{NetDialer: dialer, Config: w.tlsConfig}
+			w.dialContext = tlsDialer.DialContext
+		}
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.closing = make(chan struct{})
+	w.stopped = make(chan struct{})
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+func (w *NetworkWriter) isPacket() bool {
+	return strings.HasPrefix(w.network, "udp") || strings.HasPrefix(w.network, "ip") || w.network == "unixgram"
+}
+
+// Write implements the io.Writer interface.
+func (w *NetworkWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface: it buffers the event. It only returns an error
+// if the writer is closed.
+func (w *NetworkWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, ErrWriterClosed
+	}
+	w.bufferEvent(p)
+	w.mu.Unlock()
+	w.cond.Signal()
+	return len(p), nil
+}
+
+// bufferEvent buffers a copy of p, dropping the oldest event if the buffer is full. w.mu must be
+// held.
+func (w *NetworkWriter) bufferEvent(p []byte) {
+	var event []byte
+	if len(w.buffer) >= w.bufferSize {
+		// reuse the buffer of the dropped event
+		event = w.buffer[0][:0]
+		w.buffer = w.buffer[1:]
+		w.stats.Dropped++
+	}
+	w.buffer = append(w.buffer, append(event, p...))
+}
+
+// run connects and writes the buffered events, until the writer is closed and its buffer is
+// drained, or w.ctx is canceled. The network I/O is done without holding w.mu.
+func (w *NetworkWriter) run() {
+	defer close(w.stopped)
+	var conn net.Conn
+	// pending holds the unwritten bytes of the event being written
+	var pending []byte
+	backoff := w.minBackoff
+	for {
+		if conn == nil {
+			var err error
+			if conn, err = w.dial(); err != nil {
+				if !w.sleep(backoff, pending == nil) {
+					break
+				}
+				if backoff *= 2; backoff > w.maxBackoff {
+					backoff = w.maxBackoff
+				}
+				continue
+			}
+			backoff = w.minBackoff
+		}
+		if pending == nil {
+			var ok bool
+			if pending, ok = w.next(); !ok {
+				break
+			}
+		}
+
+		if w.timeout > 0 {
+			conn.SetWriteDeadline(time.Now().Add(w.timeout))
+		}
+		n, err := conn.Write(pending)
+		w.mu.Lock()
+		if err != nil {
+			w.lastErr = err
+			w.conn = nil
+		} else {
+			w.stats.Written++
+		}
+		w.mu.Unlock()
+		if err != nil {
+			conn.Close()
+			conn = nil
+			if !w.isPacket() {
+				pending = pending[n:]
+			}
+			continue
+		}
+		pending = nil
+	}
+
+	if conn != nil {
+		conn.Close()
+	}
+	w.mu.Lock()
+	w.conn = nil
+	if pending != nil {
+		w.stats.Dropped++
+	}
+	w.mu.Unlock()
+}
+
+// dial opens a connection.
+func (w *NetworkWriter) dial() (net.Conn, error) {
+	conn, err := w.dialContext(w.ctx, w.network, w.addr)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.lastErr = err
+		return nil, err
+	}
+	if w.ctx.Err() != nil {
+		conn.Close()
+		return nil, w.ctx.Err()
+	}
+	if w.connected {
+		w.stats.Reconnects++
+	}
+	w.conn, w.connected, w.lastErr = conn, true, nil
+	return conn, nil
+}
+
+// sleep waits for d, and returns false if the background goroutine must stop: if w.ctx is
+// canceled, or if the writer is closed with nothing to write (idle).
+func (w *NetworkWriter) sleep(d time.Duration, idle bool) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	closing := w.closing
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case <-w.ctx.Done():
+			return false
+		case <-closing:
+			w.mu.Lock()
+			empty := len(w.buffer) == 0
+			w.mu.Unlock()
+			if idle && empty {
+				return false
+			}
+			closing = nil
+		}
+	}
+}
+
+// next waits for a buffered event and removes it from the buffer. It returns false if the writer
+// is closed and its buffer is drained, or if w.ctx is canceled.
+func (w *NetworkWriter) next() ([]byte, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.buffer) == 0 || w.ctx.Err() != nil {
+		if w.closed || w.ctx.Err() != nil {
+			return nil, false
+		}
+		w.cond.Wait()
+	}
+	event := w.buffer[0]
+	w.buffer[0] = nil
+	w.buffer = w.buffer[1:]
+	return event, true
+}
+
+// Stats returns the metrics of the writer.
+func (w *NetworkWriter) Stats() NetworkWriterStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stats := w.stats
+	stats.Buffered = len(w.buffer)
+	return stats
+}
+
+// CheckHealth implements the HealthChecker interface: it returns the last connection or write
+// error while disconnected.
+func (w *NetworkWriter) CheckHealth() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil || w.closed {
+		return nil
+	}
+	if w.lastErr != nil {
+		return w.lastErr
+	}
+	return errors.New("rz: network writer is not connected")
+}
+
+// Close stops the writer and closes the connection without waiting: the buffered events are
+// dropped. Use CloseContext to write them.
+func (w *NetworkWriter) Close() error {
+	w.abort()
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext implements the ContextCloser interface: it writes the buffered events, reconnecting
+// if needed, and closes the connection. If ctx is done first, the remaining events are dropped.
+func (w *NetworkWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.closing)
+	w.mu.Unlock()
+	w.cond.Broadcast()
+
+	var err error
+	select {
+	case <-w.stopped:
+	case <-ctx.Done():
+		err = ctx.Err()
+		w.abort()
+		<-w.stopped
+	}
+	w.cancel()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stats.Dropped += uint64(len(w.buffer))
+	w.buffer = nil
+	return err
+}
+
+// abort stops the background goroutine, interrupting its current write.
+func (w *NetworkWriter) abort() {
+	w.mu.Lock()
+	w.cancel()
+	if w.conn != nil {
+		w.conn.Close()
+	}
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *NetworkWriter) DescribeConfig() string {
+	config := "network(" + w.network + "://" + w.addr
+	if w.tlsConfig != nil && !w.isPacket() {
+		config += ", tls"
+	}
+	return config + ", " + strconv.Itoa(w.bufferSize) + ")"
+}
@@ -0,0 +1,217 @@
+package rz
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNetworkWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := NewNetworkWriter("tcp", addr, NetworkBufferSize(2), NetworkBackoff(10*time.Millisecond, 20*time.Millisecond))
+	defer w.Close()
+	log := New(Writer(w), Fields(Timestamp(false)))
+	log.Info("1")
+	log.Info("2")
+	log.Info("3")
+	if stats := w.Stats(); stats.Buffered != 2 || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want 2 buffered and 1 dropped", stats)
+	}
+	if w.CheckHealth() == nil {
+		t.Error("CheckHealth() = nil while disconnected")
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not listen again on %s: %v", addr, err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for _, want := range []string{`{"level":"info","message":"2"}` + "\n", `{"level":"info","message":"3"}` + "\n"} {
+		if got, err := reader.ReadString('\n'); err != nil || got != want {
+			t.Fatalf("read %q, %v, want %q", got, err, want)
+		}
+	}
+
+	log.Info("4")
+	if got, err := reader.ReadString('\n'); err != nil || got != `{"level":"info","message":"4"}`+"\n" {
+		t.Errorf("read %q, %v", got, err)
+	}
+	if stats := w.Stats(); stats.Written != 3 || stats.Buffered != 0 || stats.Reconnects != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if err := w.CheckHealth(); err != nil {
+		t.Errorf("CheckHealth() = %v", err)
+	}
+}
+
+func TestNetworkWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	w := NewNetworkWriter("tcp", ln.Addr().String(), NetworkBackoff(10*time.Millisecond, 20*time.Millisecond))
+	defer w.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	// writes to a connection closed by the peer fail after a few attempts
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Stats().Reconnects == 0 && time.Now().Before(deadline) {
+		w.Write([]byte("lost\n"))
+		time.Sleep(5 * time.Millisecond)
+	}
+	w.Write([]byte("kept\n"))
+
+	conn, err = ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "kept\n" {
+			break
+		}
+	}
+	if stats := w.Stats(); stats.Reconnects != 1 {
+		t.Errorf("stats = %+v, want 1 reconnect", stats)
+	}
+}
+
+func TestNetworkWriterClosed(t *testing.T) {
+	w := NewNetworkWriter("tcp", "127.0.0.1:1", NetworkBackoff(time.Hour, time.Hour))
+	w.Write([]byte("event\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("event\n")); err != ErrWriterClosed {
+		t.Errorf("Write() = %v, want ErrWriterClosed", err)
+	}
+	if stats := w.Stats(); stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want 1 dropped", stats)
+	}
+	if got, want := w.DescribeConfig(), "network(tcp://127.0.0.1:1, 1000)"; got != want {
+		t.Errorf("DescribeConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestNetworkWriterCloseContext(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	w := NewNetworkWriter("tcp", ln.Addr().String())
+	for i := 0; i < 100; i++ {
+		w.Write([]byte("event\n"))
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := w.CloseContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(data); got != 100*len("event\n") {
+		t.Errorf("read %d bytes, want all the buffered events", got)
+	}
+	if stats := w.Stats(); stats.Written != 100 || stats.Dropped != 0 {
+		t.Errorf("stats = %+v, want 100 written", stats)
+	}
+
+	// the drain is bounded by ctx while disconnected
+	w = NewNetworkWriter("tcp", "127.0.0.1:1", NetworkBackoff(time.Hour, time.Hour))
+	w.Write([]byte("event\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.CloseContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("CloseContext() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if stats := w.Stats(); stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want 1 dropped", stats)
+	}
+}
+
+// partialConn writes at most limit bytes to out, then fails.
+type partialConn struct {
+	net.Conn
+	mu    *sync.Mutex
+	out   *[]byte
+	limit int
+}
+
+func (c *partialConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limit >= 0 && len(p) > c.limit {
+		*c.out = append(*c.out, p[:c.limit]...)
+		return c.limit, errors.New("connection reset")
+	}
+	*c.out = append(*c.out, p...)
+	return len(p), nil
+}
+
+func (c *partialConn) SetWriteDeadline(t time.Time) error { return nil }
+func (c *partialConn) Close() error                       { return nil }
+
+func TestNetworkWriterPartialWrite(t *testing.T) {
+	var mu sync.Mutex
+	var out []byte
+	dials := 0
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		limit := -1
+		if dials == 1 {
+			limit = 3
+		}
+		return &partialConn{mu: &mu, out: &out, limit: limit}, nil
+	}
+	w := NewNetworkWriter("tcp", "collector:514", func(w *NetworkWriter) { w.dialContext = dial })
+	w.Write([]byte("event 1\n"))
+	w.Write([]byte("event 2\n"))
+	if err := w.CloseContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := string(out), "event 1\nevent 2\n"; got != want {
+		t.Errorf("written %q, want %q", got, want)
+	}
+	if stats := w.Stats(); stats.Written != 2 || stats.Reconnects != 1 {
+		t.Errorf("stats = %+v, want 2 written and 1 reconnect", stats)
+	}
+}
@@ -0,0 +1,56 @@
+package rz
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// The layout of a ring file is a header of ringHeaderSize bytes: the magic, the capacity of the
+// data region, the total number of bytes ever written and the position of the oldest complete
+// event in this stream of bytes (uint64 little endian), followed by the data region.
+const (
+	ringMagic      = "RZRING1\n"
+	ringHeaderSize = 32
+)
+
+// ErrInvalidRingFile is returned when reading a file which is not a ring file.
+var ErrInvalidRingFile = errors.New("rz: invalid ring file")
+
+// ReadRingFile returns the events recorded in the ring file at path by a RingFileWriter, oldest
+// first, e.g. to recover the last events of a crashed process.
+func ReadRingFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < ringHeaderSize || string(data[:len(ringMagic)]) != ringMagic {
+		return nil, ErrInvalidRingFile
+	}
+	capacity := binary.LittleEndian.Uint64(data[8:16])
+	written := binary.LittleEndian.Uint64(data[16:24])
+	ring := data[ringHeaderSize:]
+	if uint64(len(ring)) != capacity {
+		return nil, ErrInvalidRingFile
+	}
+	oldest := binary.LittleEndian.Uint64(data[24:32])
+	if oldest > written || written-oldest > capacity {
+		return nil, ErrInvalidRingFile
+	}
+	start, size := oldest%capacity, written-oldest
+	if start+size <= capacity {
+		return append([]byte(nil), ring[start:start+size]...), nil
+	}
+	return append(append([]byte(nil), ring[start:]...), ring[:start+size-capacity]...), nil
+}
+
+// DumpRingFile writes the events recorded in the ring file at path to w.
+func DumpRingFile(path string, w io.Writer) error {
+	events, err := ReadRingFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(events)
+	return err
+}
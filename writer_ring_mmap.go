@@ -0,0 +1,134 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package rz
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// RingFileWriter keeps the most recent events in a fixed size memory mapped file, which
+// survives crashes of the process, as a "black box recorder": the file can be read with
+// ReadRingFile or DumpRingFile after a crash. Writes are memory copies, with no system call.
+type RingFileWriter struct {
+	mu       sync.Mutex
+	file     *os.File
+	data     []byte
+	ring     []byte
+	capacity uint64
+	written  uint64
+	oldest   uint64
+}
+
+// NewRingFileWriter opens or creates the ring file at path, with a data region of size bytes.
+// An existing ring file of the same size is continued, otherwise the file is reset.
+func NewRingFileWriter(path string, size int) (*RingFileWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err = file.Truncate(int64(ringHeaderSize + size)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, ringHeaderSize+size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	w := &RingFileWriter{
+		file:     file,
+		data:     data,
+		ring:     data[ringHeaderSize:],
+		capacity: uint64(size),
+	}
+	if string(data[:len(ringMagic)]) == ringMagic && binary.LittleEndian.Uint64(data[8:16]) == w.capacity {
+		w.written = binary.LittleEndian.Uint64(data[16:24])
+		w.oldest = binary.LittleEndian.Uint64(data[24:32])
+	} else {
+		copy(data, ringMagic)
+		binary.LittleEndian.PutUint64(data[8:16], w.capacity)
+		binary.LittleEndian.PutUint64(data[16:24], 0)
+		binary.LittleEndian.PutUint64(data[24:32], 0)
+	}
+	return w, nil
+}
+
+// Write implements the io.Writer interface.
+func (w *RingFileWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. Events larger than the ring are rejected
+// with ErrEventTooLarge.
+func (w *RingFileWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	if uint64(len(p)) > w.capacity {
+		return 0, ErrEventTooLarge
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data == nil {
+		return 0, os.ErrClosed
+	}
+	end := w.written + uint64(len(p))
+	oldest := w.oldest
+	if end > w.capacity && end-w.capacity > oldest {
+		// the oldest event is overwritten: the new oldest one starts after the first line
+		// break of the remaining old bytes, or right after the overwritten ones
+		cut := end - w.capacity
+		oldest = cut
+		if w.ring[(cut-1)%w.capacity] != '\n' {
+			oldest = w.written
+			for i := cut; i < w.written; i++ {
+				if w.ring[i%w.capacity] == '\n' {
+					oldest = i + 1
+					break
+				}
+			}
+		}
+		// the header is updated before the old bytes are overwritten, so a crash never
+		// exposes a partial event
+		w.oldest = oldest
+		binary.LittleEndian.PutUint64(w.data[24:32], w.oldest)
+	}
+	start := w.written % w.capacity
+	n = copy(w.ring[start:], p)
+	copy(w.ring, p[n:])
+	w.written = end
+	binary.LittleEndian.PutUint64(w.data[16:24], w.written)
+	return len(p), nil
+}
+
+// Flush implements the Flusher interface: the ring is synced to stable storage, which is only
+// needed to survive a crash of the system.
+func (w *RingFileWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data == nil {
+		return os.ErrClosed
+	}
+	return w.file.Sync()
+}
+
+// Close unmaps and closes the ring file.
+func (w *RingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(w.data)
+	w.data, w.ring = nil, nil
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *RingFileWriter) DescribeConfig() string {
+	return "ring(" + w.file.Name() + ")"
+}
@@ -0,0 +1,61 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package rz
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRingFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "black_box")
+	w, err := NewRingFileWriter(path, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := New(Writer(w), Fields(Timestamp(false)))
+	log.Info("1")
+	log.Info("2")
+
+	// the file is readable while the writer is open, as after a crash
+	events, err := ReadRingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(events), `{"level":"info","message":"1"}`+"\n"+`{"level":"info","message":"2"}`+"\n"; got != want {
+		t.Errorf("invalid events:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	log.Info("3")
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	events, err = ReadRingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(events), `{"level":"info","message":"2"}`+"\n"+`{"level":"info","message":"3"}`+"\n"; got != want {
+		t.Errorf("invalid events after wrap:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	// reopening continues the ring
+	w, err = NewRingFileWriter(path, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Write([]byte("4\n"))
+	events, _ = ReadRingFile(path)
+	if got, want := string(events), `{"level":"info","message":"2"}`+"\n"+`{"level":"info","message":"3"}`+"\n4\n"; got != want {
+		t.Errorf("invalid events after reopen:\ngot:  %v\nwant: %v", got, want)
+	}
+	w.Write([]byte("5\n"))
+	events, _ = ReadRingFile(path)
+	if got, want := string(events), `{"level":"info","message":"3"}`+"\n4\n5\n"; got != want {
+		t.Errorf("invalid events after reopen:\ngot:  %v\nwant: %v", got, want)
+	}
+	if _, err = w.Write(make([]byte, 65)); err != ErrEventTooLarge {
+		t.Errorf("invalid error: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package rz
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+var sanitizeBufferPool = &sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 500)
+		return &buf
+	},
+}
+
+type sanitizeWriter struct {
+	lw LevelWriter
+}
+
+// SanitizeWriter wraps w so that ANSI escape sequences and control characters (except tabulations
+// and line breaks) are removed from the whole output stream, including the ones emitted by
+// formatters (e.g. colors).
+func SanitizeWriter(w io.Writer) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return sanitizeWriter{lw: lw}
+}
+
+// TerminalSafeWriter wraps w with SanitizeWriter if w is a terminal, and returns w as
+// is otherwise. It's intended for CLI tools echoing user data.
+func TerminalSafeWriter(w io.Writer) io.Writer {
+	if IsTerminal(w) {
+		return SanitizeWriter(w)
+	}
+	return w
+}
+
+// IsTerminal returns true if w is an *os.File connected to a terminal.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Write implements the io.Writer interface.
+func (w sanitizeWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The returned count is len(p) if the
+// sanitized event is successfully written.
+func (w sanitizeWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	bufp := sanitizeBufferPool.Get().(*[]byte)
+	buf := appendStripANSI((*bufp)[:0], p, true)
+	_, err = w.lw.WriteLevel(level, buf)
+	if cap(buf) <= 1<<16 {
+		*bufp = buf
+		sanitizeBufferPool.Put(bufp)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush implements the Flusher interface.
+func (w sanitizeWriter) Flush() error {
+	return flush(w.lw)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w sanitizeWriter) DescribeConfig() string {
+	return "sanitize(" + describe(w.lw) + ")"
+}
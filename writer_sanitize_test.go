@@ -0,0 +1,22 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSanitizeWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(Writer(SanitizeWriter(out)), Fields(Timestamp(false)), Formatter(FormatterConsole()))
+	log.Info("hello\x1b[2J", String("user", "\x1b]0;pwned\x07bob"))
+	if got, want := out.String(), "                     |INFO| hello user=\"\\x1b]0;pwned\\abob\"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if IsTerminal(out) {
+		t.Error("IsTerminal(*bytes.Buffer) = true")
+	}
+	if w := TerminalSafeWriter(out); w != out {
+		t.Error("TerminalSafeWriter wrapped a non-terminal writer")
+	}
+}
@@ -0,0 +1,120 @@
+package rz
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ShadowWriter writes events to a primary writer, and a sampled copy of them to a shadow
+// writer, recording comparison metrics. It's intended to de-risk the migration from one log
+// backend to another: errors of the shadow writer are never returned to the logger.
+//
+// The shadow writer is called synchronously after the primary writer, so a slow shadow
+// writer should be wrapped with TimeoutWriter.
+type ShadowWriter struct {
+	primary LevelWriter
+	shadow  LevelWriter
+	sampler LogSampler
+
+	primaryStats shadowCounters
+	shadowStats  shadowCounters
+	skipped      uint64
+}
+
+type shadowCounters struct {
+	writes  uint64
+	errors  uint64
+	latency int64
+}
+
+func (c *shadowCounters) record(start time.Time, err error) {
+	atomic.AddInt64(&c.latency, int64(time.Since(start)))
+	atomic.AddUint64(&c.writes, 1)
+	if err != nil {
+		atomic.AddUint64(&c.errors, 1)
+	}
+}
+
+func (c *shadowCounters) stats() ShadowWriterSinkStats {
+	stats := ShadowWriterSinkStats{
+		Writes:       atomic.LoadUint64(&c.writes),
+		Errors:       atomic.LoadUint64(&c.errors),
+		TotalLatency: time.Duration(atomic.LoadInt64(&c.latency)),
+	}
+	if stats.Writes > 0 {
+		stats.AverageLatency = stats.TotalLatency / time.Duration(stats.Writes)
+	}
+	return stats
+}
+
+// ShadowWriterSinkStats holds the metrics of one of the writers of a ShadowWriter.
+type ShadowWriterSinkStats struct {
+	Writes         uint64
+	Errors         uint64
+	TotalLatency   time.Duration
+	AverageLatency time.Duration
+}
+
+// ShadowWriterStats holds the comparison metrics of a ShadowWriter.
+type ShadowWriterStats struct {
+	Primary ShadowWriterSinkStats
+	Shadow  ShadowWriterSinkStats
+	// Skipped is the number of events not sent to the shadow writer by the sampler.
+	Skipped uint64
+}
+
+// NewShadowWriter creates a ShadowWriter. If sampler is nil, all the events are sent to
+// the shadow writer.
+func NewShadowWriter(primary, shadow io.Writer, sampler LogSampler) *ShadowWriter {
+	w := &ShadowWriter{sampler: sampler}
+	var ok bool
+	if w.primary, ok = primary.(LevelWriter); !ok {
+		w.primary = levelWriterAdapter{primary}
+	}
+	if w.shadow, ok = shadow.(LevelWriter); !ok {
+		w.shadow = levelWriterAdapter{shadow}
+	}
+	return w
+}
+
+// Write implements the io.Writer interface.
+func (w *ShadowWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *ShadowWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = w.primary.WriteLevel(level, p)
+	w.primaryStats.record(start, err)
+
+	if w.sampler != nil && !w.sampler.Sample(level) {
+		atomic.AddUint64(&w.skipped, 1)
+		return
+	}
+	start = time.Now()
+	_, shadowErr := w.shadow.WriteLevel(level, p)
+	w.shadowStats.record(start, shadowErr)
+	return
+}
+
+// Flush implements the Flusher interface. Only the error of the primary writer is returned.
+func (w *ShadowWriter) Flush() error {
+	flush(w.shadow)
+	return flush(w.primary)
+}
+
+// Stats returns the comparison metrics of the writer.
+func (w *ShadowWriter) Stats() ShadowWriterStats {
+	return ShadowWriterStats{
+		Primary: w.primaryStats.stats(),
+		Shadow:  w.shadowStats.stats(),
+		Skipped: atomic.LoadUint64(&w.skipped),
+	}
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *ShadowWriter) DescribeConfig() string {
+	return "shadow(" + describe(w.primary) + ", " + describe(w.shadow) + ")"
+}
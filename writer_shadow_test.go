@@ -0,0 +1,31 @@
+package rz
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestShadowWriter(t *testing.T) {
+	primary := &bytes.Buffer{}
+	shadow := errWriter{errors.New("shadow error")}
+	w := NewShadowWriter(primary, shadow, &SamplerBasic{N: 2})
+	log := New(Writer(w), Fields(Timestamp(false)))
+
+	for i := 0; i < 4; i++ {
+		if err := log.InfoE("hello"); err != nil {
+			t.Errorf("InfoE err = %v, want nil", err)
+		}
+	}
+
+	stats := w.Stats()
+	if stats.Primary.Writes != 4 || stats.Primary.Errors != 0 {
+		t.Errorf("invalid primary stats: %+v", stats.Primary)
+	}
+	if stats.Shadow.Writes != 2 || stats.Shadow.Errors != 2 || stats.Skipped != 2 {
+		t.Errorf("invalid shadow stats: %+v, skipped: %d", stats.Shadow, stats.Skipped)
+	}
+	if got, want := primary.Len(), 4*len(`{"level":"info","message":"hello"}`+"\n"); got != want {
+		t.Errorf("invalid primary output length: got %d, want %d", got, want)
+	}
+}
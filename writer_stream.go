@@ -0,0 +1,208 @@
+package rz
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DecodedEvent is an event decoded by a StreamWriter. It's decoded with the default field names.
+type DecodedEvent struct {
+	Level   LogLevel
+	Message string
+	// Time is the zero time if the event has no timestamp in the RFC 3339 format.
+	Time time.Time
+	// Fields holds the other fields, numbers decoded as json.Number.
+	Fields map[string]interface{}
+	// Raw is the encoded event.
+	Raw []byte
+}
+
+// DecodeEvent decodes a JSON encoded event with the default field names. The event
+// retains p as Raw.
+func DecodeEvent(p []byte) (DecodedEvent, error) {
+	event := DecodedEvent{Level: NoLevel, Raw: p}
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	if err := decoder.Decode(&event.Fields); err != nil {
+		return event, err
+	}
+	if level, ok := event.Fields[DefaultLevelFieldName].(string); ok {
+		if l, err := ParseLevel(level); err == nil {
+			event.Level = l
+			delete(event.Fields, DefaultLevelFieldName)
+		}
+	}
+	if message, ok := event.Fields[DefaultMessageFieldName].(string); ok {
+		event.Message = message
+		delete(event.Fields, DefaultMessageFieldName)
+	}
+	if timestamp, ok := event.Fields[DefaultTimestampFieldName].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			event.Time = t
+			delete(event.Fields, DefaultTimestampFieldName)
+		}
+	}
+	return event, nil
+}
+
+// StreamWriter keeps the most recent events in memory and delivers the decoded events to
+// subscribers over channels, e.g. to embed a live log pane in an admin TUI. Use it with
+// MultiLevelWriter to keep the regular output. Events must be JSON encoded (no formatter).
+type StreamWriter struct {
+	mu     sync.Mutex
+	ring   []DecodedEvent
+	next   int
+	count  int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription delivers the events of a StreamWriter.
+type Subscription struct {
+	// C delivers the events. It's closed when the subscription or the writer is closed.
+	C <-chan DecodedEvent
+
+	c       chan DecodedEvent
+	w       *StreamWriter
+	query   *Query
+	dropped uint64
+}
+
+// NewStreamWriter creates a StreamWriter keeping the last size events.
+func NewStreamWriter(size int) *StreamWriter {
+	if size < 0 {
+		size = 0
+	}
+	return &StreamWriter{
+		ring: make([]DecodedEvent, size),
+		subs: map[*Subscription]struct{}{},
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *StreamWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. Events which can't be decoded are ignored.
+func (w *StreamWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	event, err := DecodeEvent(append([]byte(nil), p...))
+	if err != nil {
+		return len(p), nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.ring) > 0 {
+		w.ring[w.next] = event
+		w.next = (w.next + 1) % len(w.ring)
+		if w.count < len(w.ring) {
+			w.count++
+		}
+	}
+	for sub := range w.subs {
+		sub.send(event)
+	}
+	return len(p), nil
+}
+
+// recent returns the last n events, oldest first. w.mu must be held.
+func (w *StreamWriter) recent(n int) []DecodedEvent {
+	if n > w.count || n < 0 {
+		n = w.count
+	}
+	events := make([]DecodedEvent, 0, n)
+	for i := w.next - n; i < w.next; i++ {
+		events = append(events, w.ring[(i+len(w.ring))%len(w.ring)])
+	}
+	return events
+}
+
+// Recent returns the last n (all if < 0) kept events, oldest first.
+func (w *StreamWriter) Recent(n int) []DecodedEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.recent(n)
+}
+
+// Subscribe returns a subscription delivering the last backfill kept events (all if < 0), then
+// the new ones. The channel buffers buffer events in addition to the backfill; when it's full,
+// the new events are dropped for this subscription, so a slow subscriber never blocks the logger.
+func (w *StreamWriter) Subscribe(backfill, buffer int) *Subscription {
+	return w.SubscribeQuery(nil, backfill, buffer)
+}
+
+// SubscribeQuery is like Subscribe, but only the events matching query are delivered
+// (backfill included). A nil query matches all the events.
+func (w *StreamWriter) SubscribeQuery(query *Query, backfill, buffer int) *Subscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := w.recent(-1)
+	if query != nil {
+		matching := events[:0]
+		for _, event := range events {
+			if query.Match(event) {
+				matching = append(matching, event)
+			}
+		}
+		events = matching
+	}
+	if backfill >= 0 && len(events) > backfill {
+		events = events[len(events)-backfill:]
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	c := make(chan DecodedEvent, len(events)+buffer)
+	sub := &Subscription{C: c, c: c, w: w, query: query}
+	for _, event := range events {
+		c <- event
+	}
+	if w.closed {
+		close(c)
+	} else {
+		w.subs[sub] = struct{}{}
+	}
+	return sub
+}
+
+// send delivers event without blocking. w.mu must be held.
+func (sub *Subscription) send(event DecodedEvent) {
+	if sub.query != nil && !sub.query.Match(event) {
+		return
+	}
+	select {
+	case sub.c <- event:
+	default:
+		atomic.AddUint64(&sub.dropped, 1)
+	}
+}
+
+// Dropped returns the number of events dropped because the channel was full.
+func (sub *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&sub.dropped)
+}
+
+// Close ends the subscription and closes its channel.
+func (sub *Subscription) Close() {
+	sub.w.mu.Lock()
+	defer sub.w.mu.Unlock()
+	if _, ok := sub.w.subs[sub]; ok {
+		delete(sub.w.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Close ends all the subscriptions. The writer keeps the events written after it's closed.
+func (w *StreamWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	for sub := range w.subs {
+		delete(w.subs, sub)
+		close(sub.c)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package rz
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStreamWriter(t *testing.T) {
+	stream := NewStreamWriter(2)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := New(Writer(stream), TimestampFunc(func() time.Time { return now }), TimeFieldFormat(time.RFC3339))
+	log.Info("1")
+	log.Warn("2", Int("n", 2))
+	log.Error("3")
+
+	sub := stream.Subscribe(-1, 1)
+	log.Info("4")
+	log.Info("5")
+
+	want := []string{"2", "3", "4"}
+	for _, message := range want {
+		event := <-sub.C
+		if event.Message != message || event.Time != now {
+			t.Errorf("invalid event: %+v", event)
+		}
+	}
+	if sub.Dropped() != 1 {
+		t.Errorf("invalid dropped count: %d", sub.Dropped())
+	}
+
+	recent := stream.Recent(-1)
+	if len(recent) != 2 || recent[0].Message != "4" || recent[1].Message != "5" {
+		t.Errorf("invalid recent events: %+v", recent)
+	}
+
+	sub.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("channel not closed")
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	event, err := DecodeEvent([]byte(`{"level":"warning","n":2,"message":"hello","timestamp":"2020-01-01T00:00:00Z"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Level != WarnLevel || event.Message != "hello" || event.Time.IsZero() || len(event.Fields) != 1 || event.Fields["n"] != json.Number("2") {
+		t.Errorf("invalid decoded event: %+v", event)
+	}
+}
@@ -0,0 +1,100 @@
+package rz
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// SwapWriter passes events through to a writer which can be replaced while the logger is
+// running, e.g. to migrate to another log backend without restarting. As the loggers derived
+// with With share their writer, swapping the writer of a root logger swaps it for all of them.
+//
+// Swap drains the events buffered by the old writer (e.g. an AsyncWriter or a batching writer)
+// before the new writer receives any event, so no event is lost or reordered by the migration.
+type SwapWriter struct {
+	mu  sync.RWMutex
+	out io.Writer
+	lw  LevelWriter
+}
+
+// NewSwapWriter creates a SwapWriter writing to w.
+func NewSwapWriter(w io.Writer) *SwapWriter {
+	sw := &SwapWriter{}
+	sw.set(w)
+	return sw
+}
+
+func (w *SwapWriter) set(out io.Writer) {
+	lw, ok := out.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{out}
+	}
+	w.out, w.lw = out, lw
+}
+
+// Write implements the io.Writer interface.
+func (w *SwapWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *SwapWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lw.WriteLevel(level, p)
+}
+
+// WriteContext implements the ContextWriter interface.
+func (w *SwapWriter) WriteContext(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return writeContext(ctx, w.lw, level, p)
+}
+
+// Swap replaces the writer with newWriter, and returns the old writer so it can be closed with
+// CloseWriter. It waits for the in-flight writes to complete and flushes the old writer, blocking
+// the new writes until then; the flush error is returned, newWriter being used in any case.
+func (w *SwapWriter) Swap(newWriter io.Writer) (old io.Writer, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	old = w.out
+	err = flush(w.lw)
+	w.set(newWriter)
+	return old, err
+}
+
+// Writer returns the current writer.
+func (w *SwapWriter) Writer() io.Writer {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.out
+}
+
+// Flush implements the Flusher interface.
+func (w *SwapWriter) Flush() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return flush(w.lw)
+}
+
+// CloseContext implements the ContextCloser interface: it closes the current writer.
+func (w *SwapWriter) CloseContext(ctx context.Context) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return CloseWriter(ctx, w.out)
+}
+
+// CheckHealth implements the HealthChecker interface, with the health of the current writer.
+func (w *SwapWriter) CheckHealth() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return CheckHealth(w.out)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *SwapWriter) DescribeConfig() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return "swap(" + describe(w.lw) + ")"
+}
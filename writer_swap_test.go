@@ -0,0 +1,56 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestSwapWriter(t *testing.T) {
+	old := &gatedWriter{unblock: make(chan struct{})}
+	async := NewAsyncWriter(old)
+	w := NewSwapWriter(async)
+	log := New(Writer(w), Fields(Timestamp(false)))
+	child := log.With(Fields(String("component", "child")))
+
+	log.Info("before")
+	child.Info("before")
+
+	swapped := make(chan struct{})
+	newWriter := &bytes.Buffer{}
+	go func() {
+		previous, err := w.Swap(newWriter)
+		if err != nil {
+			t.Error(err)
+		}
+		if previous != async {
+			t.Error("Swap must return the old writer")
+		}
+		close(swapped)
+	}()
+	close(old.unblock)
+	<-swapped
+
+	log.Info("after")
+	child.Info("after")
+	if err := CloseWriter(context.Background(), async); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := old.String(), `{"level":"info","message":"before"}
+{"level":"info","component":"child","message":"before"}
+`; got != want {
+		t.Errorf("old writer: got %q, want %q", got, want)
+	}
+	if got, want := newWriter.String(), `{"level":"info","message":"after"}
+{"level":"info","component":"child","message":"after"}
+`; got != want {
+		t.Errorf("new writer: got %q, want %q", got, want)
+	}
+	if !old.closed {
+		t.Error("the old writer must be closed")
+	}
+	if got, want := w.DescribeConfig(), "swap(*bytes.Buffer)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
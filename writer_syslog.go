@@ -0,0 +1,254 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Syslog severities (RFC 5424).
+const (
+	SyslogEmergency = iota
+	SyslogAlert
+	SyslogCritical
+	SyslogError
+	SyslogWarning
+	SyslogNotice
+	SyslogInfo
+	SyslogDebug
+)
+
+// SyslogSeverity returns the syslog severity of level: debug, info, warning and error map to the
+// severities of the same name, fatal to critical, panic to alert, and the events without level to
+// notice.
+func SyslogSeverity(level LogLevel) int {
+	switch level {
+	case DebugLevel:
+		return SyslogDebug
+	case InfoLevel:
+		return SyslogInfo
+	case WarnLevel:
+		return SyslogWarning
+	case ErrorLevel:
+		return SyslogError
+	case FatalLevel:
+		return SyslogCritical
+	case PanicLevel:
+		return SyslogAlert
+	}
+	return SyslogNotice
+}
+
+// SyslogWriter is the interface of the writers of the log/syslog package, implemented by
+// *syslog.Writer.
+type SyslogWriter interface {
+	io.Writer
+	Debug(m string) error
+	Info(m string) error
+	Notice(m string) error
+	Warning(m string) error
+	Err(m string) error
+	Crit(m string) error
+	Alert(m string) error
+}
+
+type syslogLevelWriter struct {
+	w SyslogWriter
+}
+
+// SyslogLevelWriter wraps a SyslogWriter (e.g. created with syslog.New or syslog.Dial) so the
+// events are written with the syslog severity of their level (see SyslogSeverity), instead of
+// the default priority of the writer.
+func SyslogLevelWriter(w SyslogWriter) LevelWriter {
+	return syslogLevelWriter{w}
+}
+
+// Write implements the io.Writer interface.
+func (sw syslogLevelWriter) Write(p []byte) (n int, err error) {
+	return sw.w.Write(p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (sw syslogLevelWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	m := string(p)
+	switch SyslogSeverity(level) {
+	case SyslogDebug:
+		err = sw.w.Debug(m)
+	case SyslogInfo:
+		err = sw.w.Info(m)
+	case SyslogWarning:
+		err = sw.w.Warning(m)
+	case SyslogError:
+		err = sw.w.Err(m)
+	case SyslogCritical:
+		err = sw.w.Crit(m)
+	case SyslogAlert:
+		err = sw.w.Alert(m)
+	default:
+		err = sw.w.Notice(m)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CloseContext implements the ContextCloser interface.
+func (sw syslogLevelWriter) CloseContext(ctx context.Context) error {
+	return CloseWriter(ctx, sw.w)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (sw syslogLevelWriter) DescribeConfig() string {
+	return "syslog"
+}
+
+// RFC5424Writer writes events as RFC 5424 syslog messages to a writer, e.g. a connection to a
+// remote syslog server, with the priority of their level (see SyslogSeverity) and the event as
+// message:
+//
+//     <11>1 2019-02-07T09:45:33.123456Z host app 1234 - - {"level":"error",...}
+//
+// Each message is written with a single Write call. Over a stream transport (TCP, TLS), messages
+// should be framed with octet counting (RFC 6587) using the SyslogOctetCounting option.
+type RFC5424Writer struct {
+	out           io.Writer
+	facility      int
+	hostname      string
+	appName       string
+	procID        string
+	octetCounting bool
+	now           func() time.Time
+
+	mu    sync.Mutex
+	buf   []byte
+	frame []byte
+}
+
+// RFC5424WriterOption is used to configure a RFC5424Writer.
+type RFC5424WriterOption func(w *RFC5424Writer)
+
+// SyslogFacility updates the facility of the messages (0 to 23). Default: 1 (user-level).
+func SyslogFacility(facility int) RFC5424WriterOption {
+	return func(w *RFC5424Writer) {
+		if facility >= 0 && facility < 24 {
+			w.facility = facility
+		}
+	}
+}
+
+// SyslogHostname updates the HOSTNAME of the messages. Default: os.Hostname().
+func SyslogHostname(hostname string) RFC5424WriterOption {
+	return func(w *RFC5424Writer) {
+		w.hostname = hostname
+	}
+}
+
+// SyslogAppName updates the APP-NAME of the messages. Default: the name of the executable.
+func SyslogAppName(appName string) RFC5424WriterOption {
+	return func(w *RFC5424Writer) {
+		w.appName = appName
+	}
+}
+
+// SyslogOctetCounting prefixes each message with its length (RFC 6587), to frame the messages
+// sent over a stream transport.
+func SyslogOctetCounting() RFC5424WriterOption {
+	return func(w *RFC5424Writer) {
+		w.octetCounting = true
+	}
+}
+
+// SyslogTimeFunc updates the function returning the TIMESTAMP of the messages.
+// Default: DefaultTimestampFunc.
+func SyslogTimeFunc(now func() time.Time) RFC5424WriterOption {
+	return func(w *RFC5424Writer) {
+		w.now = now
+	}
+}
+
+// NewRFC5424Writer creates a RFC5424Writer writing to w.
+func NewRFC5424Writer(w io.Writer, options ...RFC5424WriterOption) *RFC5424Writer {
+	hostname, _ := os.Hostname()
+	sw := &RFC5424Writer{
+		out:      w,
+		facility: 1,
+		hostname: hostname,
+		appName:  filepath.Base(os.Args[0]),
+		procID:   strconv.Itoa(os.Getpid()),
+		now:      DefaultTimestampFunc,
+	}
+	for _, option := range options {
+		option(sw)
+	}
+	return sw
+}
+
+// Write implements the io.Writer interface.
+func (w *RFC5424Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RFC5424Writer) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	msg := w.buf[:0]
+	msg = append(msg, '<')
+	msg = strconv.AppendInt(msg, int64(w.facility*8+SyslogSeverity(level)), 10)
+	msg = append(msg, ">1 "...)
+	msg = w.now().UTC().AppendFormat(msg, "2006-01-02T15:04:05.000000Z07:00")
+	msg = append(msg, ' ')
+	msg = appendSyslogHeader(msg, w.hostname, 255)
+	msg = append(msg, ' ')
+	msg = appendSyslogHeader(msg, w.appName, 48)
+	msg = append(msg, ' ')
+	msg = appendSyslogHeader(msg, w.procID, 128)
+	msg = append(msg, " - - "...)
+	msg = append(msg, bytes.TrimRight(p, "\n")...)
+	w.buf = msg
+	if w.octetCounting {
+		w.frame = strconv.AppendInt(w.frame[:0], int64(len(msg)), 10)
+		msg = append(append(w.frame, ' '), msg...)
+		w.frame = msg
+	} else {
+		msg = append(msg, '\n')
+		w.buf = msg
+	}
+
+	if _, err = w.out.Write(msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendSyslogHeader appends the header field value, limited to printable US-ASCII characters and
+// maxLen bytes, or the nil value "-" if empty.
+func appendSyslogHeader(dst []byte, value string, maxLen int) []byte {
+	start := len(dst)
+	for i := 0; i < len(value) && len(dst)-start < maxLen; i++ {
+		if c := value[i]; c > ' ' && c < 0x7f {
+			dst = append(dst, c)
+		}
+	}
+	if len(dst) == start {
+		dst = append(dst, '-')
+	}
+	return dst
+}
+
+// CloseContext implements the ContextCloser interface.
+func (w *RFC5424Writer) CloseContext(ctx context.Context) error {
+	return CloseWriter(ctx, w.out)
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *RFC5424Writer) DescribeConfig() string {
+	return "rfc5424(" + describe(w.out) + ", facility=" + strconv.Itoa(w.facility) + ")"
+}
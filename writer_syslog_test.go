@@ -0,0 +1,72 @@
+package rz
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type syslogTestWriter struct {
+	bytes.Buffer
+}
+
+func (w *syslogTestWriter) record(severity, m string) error {
+	w.WriteString(severity + ": " + m)
+	return nil
+}
+
+func (w *syslogTestWriter) Debug(m string) error   { return w.record("debug", m) }
+func (w *syslogTestWriter) Info(m string) error    { return w.record("info", m) }
+func (w *syslogTestWriter) Notice(m string) error  { return w.record("notice", m) }
+func (w *syslogTestWriter) Warning(m string) error { return w.record("warning", m) }
+func (w *syslogTestWriter) Err(m string) error     { return w.record("err", m) }
+func (w *syslogTestWriter) Crit(m string) error    { return w.record("crit", m) }
+func (w *syslogTestWriter) Alert(m string) error   { return w.record("alert", m) }
+
+func TestSyslogLevelWriter(t *testing.T) {
+	sw := &syslogTestWriter{}
+	log := New(Writer(SyslogLevelWriter(sw)), Fields(Timestamp(false)))
+
+	log.Debug("a")
+	log.Info("b")
+	log.Warn("c")
+	log.Error("d")
+	log.Log("e")
+	want := `debug: {"level":"debug","message":"a"}
+info: {"level":"info","message":"b"}
+warning: {"level":"warning","message":"c"}
+err: {"level":"error","message":"d"}
+notice: {"message":"e"}
+`
+	if got := sw.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRFC5424Writer(t *testing.T) {
+	out := &bytes.Buffer{}
+	now := func() time.Time { return time.Date(2019, 2, 7, 9, 45, 33, 123456000, time.UTC) }
+	w := NewRFC5424Writer(out, SyslogHostname("my host"), SyslogAppName("app"), SyslogTimeFunc(now),
+		SyslogFacility(16))
+	w.procID = "42"
+	log := New(Writer(w), Fields(Timestamp(false)))
+
+	log.Error("failed")
+	log.Log("")
+	want := `<131>1 2019-02-07T09:45:33.123456Z myhost app 42 - - {"level":"error","message":"failed"}
+<133>1 2019-02-07T09:45:33.123456Z myhost app 42 - - {}
+`
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	out.Reset()
+	w = NewRFC5424Writer(out, SyslogHostname(""), SyslogAppName("app"), SyslogTimeFunc(now), SyslogOctetCounting())
+	w.procID = "42"
+	w.WriteLevel(FatalLevel, []byte("{}\n"))
+	w.WriteLevel(PanicLevel, []byte("{}\n"))
+	want = `49 <10>1 2019-02-07T09:45:33.123456Z - app 42 - - {}48 <9>1 2019-02-07T09:45:33.123456Z - app 42 - - {}`
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,7 +1,14 @@
+//go:build !windows
 // +build !windows
 
 package rz
 
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
 // func TestMultiSyslogWriter(t *testing.T) {
 // 	sw := &syslogTestWriter{}
 // 	log := New(MultiLevelWriter(SyslogLevelWriter(sw)))
@@ -21,3 +28,31 @@ package rz
 // 		t.Errorf("Invalid syslog message routing: want %v, got %v", want, got)
 // 	}
 // }
+
+func TestMultiLevelWriterShared(t *testing.T) {
+	w1, w2 := &sharedWriter{}, &sharedWriter{}
+	out := &bytes.Buffer{}
+	log := New(Writer(MultiLevelWriter(w1, out, w2)), Fields(Timestamp(false)))
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	log.Info("hello", Ctx(ctx))
+	log.Info("world")
+
+	if len(w1.events) != 2 || len(w2.events) != 2 {
+		t.Fatalf("invalid number of events: %d, %d", len(w1.events), len(w2.events))
+	}
+	for i := range w1.events {
+		if &w1.events[i][0] != &w2.events[i][0] {
+			t.Errorf("event %d is not shared between writers", i)
+		}
+	}
+	if w1.ctxs[0].Value(ctxKey{}) != "request" || w2.ctxs[0].Value(ctxKey{}) != "request" {
+		t.Error("the context of the event is not passed to the shared writers")
+	}
+	if got, want := string(w1.events[0]), `{"level":"info","message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := out.String(), `{"level":"info","message":"hello"}`+"\n"+`{"level":"info","message":"world"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package rz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriteTimeout is returned by the writer returned by TimeoutWriter when a write
+// did not complete before its deadline.
+var ErrWriteTimeout = errors.New("rz: write timeout")
+
+type deadlineWriter interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// states of a timeoutRequest
+const (
+	requestPending int32 = iota
+	requestWriting
+	requestCancelled
+)
+
+type timeoutRequest struct {
+	level    LogLevel
+	p        []byte
+	deadline time.Time // set on deadline writers
+	err      chan error
+	// state is claimed by the background goroutine before writing, or by the caller when
+	// the request expires, so an expired event is never written afterwards.
+	state *int32
+}
+
+func newTimeoutRequest(level LogLevel, p []byte, deadline time.Time) timeoutRequest {
+	return timeoutRequest{level: level, p: p, deadline: deadline, err: make(chan error, 1), state: new(int32)}
+}
+
+type timeoutWriter struct {
+	lw        LevelWriter
+	dw        deadlineWriter
+	timeout   time.Duration
+	onTimeout func(p []byte)
+	requests  chan timeoutRequest
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// TimeoutWriter wraps w so that each call to Write (and Flush) is bounded by timeout,
+// preventing a hung sink (e.g. a network connection) from stalling the application.
+// When a write expires, ErrWriteTimeout is returned, which rz then passes to rz.ErrorHandler,
+// and onTimeout (if not nil) is called with the dropped event. onTimeout is also called when the
+// deadline of the context of the event (see ContextWriter) expires, but not when the context is
+// canceled or the writer closed. An event that w had already started writing is not dropped:
+// it is written once w unblocks, so onTimeout is not called.
+//
+// The writes are issued by a background goroutine, which requires a copy of each event, so w is
+// never written concurrently. If w implements SetWriteDeadline (like net.Conn), the deadline is
+// also set on w, so a hung write is interrupted and its event dropped. Close stops the background
+// goroutine.
+func TimeoutWriter(w io.Writer, timeout time.Duration, onTimeout func(p []byte)) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	tw := &timeoutWriter{
+		lw:        lw,
+		timeout:   timeout,
+		onTimeout: onTimeout,
+		requests:  make(chan timeoutRequest),
+		done:      make(chan struct{}),
+	}
+	tw.dw, _ = w.(deadlineWriter)
+	go tw.run()
+	return tw
+}
+
+func (w *timeoutWriter) run() {
+	for {
+		var req timeoutRequest
+		select {
+		case req = <-w.requests:
+		case <-w.done:
+			return
+		}
+		if !atomic.CompareAndSwapInt32(req.state, requestPending, requestWriting) {
+			continue
+		}
+		var err error
+		if req.p == nil {
+			err = flush(w.lw)
+		} else {
+			if w.dw != nil {
+				w.dw.SetWriteDeadline(req.deadline)
+			}
+			_, err = w.lw.WriteLevel(req.level, req.p)
+			if w.dw != nil {
+				w.dw.SetWriteDeadline(time.Time{})
+				if isTimeout(err) {
+					err = ErrWriteTimeout
+				}
+			}
+		}
+		req.err <- err
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *timeoutWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *timeoutWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	return w.WriteContext(context.Background(), level, p)
+}
+
+// WriteContext implements the ContextWriter interface. The write is bounded by
+// the earliest of the timeout and the deadline of ctx.
+func (w *timeoutWriter) WriteContext(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	return w.write(ctx, level, append(make([]byte, 0, len(p)), p...))
+}
+
+// WriteShared implements the SharedWriter interface.
+func (w *timeoutWriter) WriteShared(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	return w.write(ctx, level, p)
+}
+
+func (w *timeoutWriter) write(ctx context.Context, level LogLevel, p []byte) (n int, err error) {
+	dropped, err := w.do(ctx, newTimeoutRequest(level, p, w.deadline(ctx)))
+	if dropped {
+		if err == ErrWriteTimeout || err == context.DeadlineExceeded {
+			w.timedOut(p)
+		}
+		return 0, err
+	}
+	return len(p), err
+}
+
+// deadline returns the earliest of the timeout and the deadline of ctx.
+func (w *timeoutWriter) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(w.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	return deadline
+}
+
+// Flush implements the Flusher interface.
+func (w *timeoutWriter) Flush() error {
+	_, err := w.do(context.Background(), newTimeoutRequest(NoLevel, nil, time.Time{}))
+	return err
+}
+
+// Close stops the background goroutine. Writes after Close return ErrWriterClosed.
+// The underlying writer is not closed.
+func (w *timeoutWriter) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	return nil
+}
+
+// DescribeConfig implements the ConfigDescriber interface.
+func (w *timeoutWriter) DescribeConfig() string {
+	return "timeout(" + describe(w.lw) + ", " + w.timeout.String() + ")"
+}
+
+// do sends req to the background goroutine and waits for its result. dropped is true if
+// the request expired, ctx is done or the writer is closed before the event was written.
+func (w *timeoutWriter) do(ctx context.Context, req timeoutRequest) (dropped bool, err error) {
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+
+	select {
+	case w.requests <- req:
+	case <-timer.C:
+		return true, ErrWriteTimeout
+	case <-ctx.Done():
+		return true, ctx.Err()
+	case <-w.done:
+		return true, ErrWriterClosed
+	}
+	select {
+	case err = <-req.err:
+		return false, err
+	case <-timer.C:
+		err = ErrWriteTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-w.done:
+		err = ErrWriterClosed
+	}
+	if atomic.CompareAndSwapInt32(req.state, requestPending, requestCancelled) {
+		return true, err
+	}
+	if w.dw != nil && req.p != nil {
+		// the write deadline interrupts the write already started
+		if err = <-req.err; err == ErrWriteTimeout {
+			return true, err
+		}
+		return false, err
+	}
+	// the event is still written as the background goroutine already started writing it
+	return false, err
+}
+
+func (w *timeoutWriter) timedOut(p []byte) {
+	if w.onTimeout != nil {
+		w.onTimeout(p)
+	}
+}
+
+func isTimeout(err error) bool {
+	t, ok := err.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
@@ -0,0 +1,203 @@
+package rz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingWriter struct {
+	release chan struct{}
+	bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.Buffer.Write(p)
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	var dropped int32
+	w := TimeoutWriter(out, 10*time.Millisecond, func(p []byte) {
+		atomic.AddInt32(&dropped, 1)
+	})
+	defer w.(*timeoutWriter).Close()
+
+	start := time.Now()
+	if _, err := w.Write([]byte("hung")); err != ErrWriteTimeout {
+		t.Errorf("Write err = %v, want %v", err, ErrWriteTimeout)
+	}
+	// the first write is still hung
+	if _, err := w.Write([]byte("queued")); err != ErrWriteTimeout {
+		t.Errorf("Write err = %v, want %v", err, ErrWriteTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("writes were not bounded: %v", elapsed)
+	}
+	// only the queued event was dropped, the hung one is written once the sink unblocks
+	if got := atomic.LoadInt32(&dropped); got != 1 {
+		t.Errorf("dropped = %d, want 1", got)
+	}
+
+	close(out.release)
+	if n, err := w.Write([]byte("ok")); err != nil || n != 2 {
+		t.Errorf("Write = %d, %v, want 2, nil", n, err)
+	}
+	if got, want := out.String(), "hungok"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestTimeoutWriterClose(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	defer close(out.release)
+	w := TimeoutWriter(out, time.Hour, nil)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("hung"))
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// a concurrent write is blocked sending to the background goroutine
+	go w.Write([]byte("queued"))
+	time.Sleep(10 * time.Millisecond)
+
+	w.(*timeoutWriter).Close()
+	if err := <-errs; err != ErrWriterClosed {
+		t.Errorf("Write err = %v, want %v", err, ErrWriterClosed)
+	}
+	if _, err := w.Write([]byte("closed")); err != ErrWriterClosed {
+		t.Errorf("Write err = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func TestTimeoutWriterDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	dropped := 0
+	w := TimeoutWriter(client, 10*time.Millisecond, func(p []byte) {
+		dropped++
+	})
+	if _, err := w.Write([]byte("hung")); err != ErrWriteTimeout {
+		t.Errorf("Write err = %v, want %v", err, ErrWriteTimeout)
+	}
+	if dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+}
+
+func TestTimeoutWriterContext(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	defer close(out.release)
+	log := New(Writer(TimeoutWriter(out, time.Hour, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := log.InfoE("hung", Ctx(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("InfoE err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("write was not bounded by the context: %v", elapsed)
+	}
+}
+
+func TestTimeoutWriterOnTimeout(t *testing.T) {
+	out := &blockingWriter{release: make(chan struct{})}
+	defer close(out.release)
+	var timeouts int32
+	w := TimeoutWriter(out, time.Hour, func(p []byte) {
+		atomic.AddInt32(&timeouts, 1)
+	})
+	// the first write is hung, so the next ones are dropped before being written
+	go w.Write([]byte("hung"))
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := w.(ContextWriter).WriteContext(ctx, NoLevel, []byte("canceled")); err != context.Canceled {
+		t.Errorf("WriteContext err = %v, want %v", err, context.Canceled)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := w.(ContextWriter).WriteContext(ctx, NoLevel, []byte("expired")); err != context.DeadlineExceeded {
+		t.Errorf("WriteContext err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	w.(*timeoutWriter).Close()
+	if _, err := w.Write([]byte("closed")); err != ErrWriterClosed {
+		t.Errorf("Write err = %v, want %v", err, ErrWriterClosed)
+	}
+	if got := atomic.LoadInt32(&timeouts); got != 1 {
+		t.Errorf("onTimeout called %d times, want 1 (the expired write only)", got)
+	}
+}
+
+// deadlineBuffer is a deadline writer detecting concurrent writes.
+type deadlineBuffer struct {
+	writing int32
+	bytes.Buffer
+}
+
+func (w *deadlineBuffer) SetWriteDeadline(t time.Time) error { return nil }
+
+func (w *deadlineBuffer) Write(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&w.writing, 0, 1) {
+		return 0, errors.New("concurrent write")
+	}
+	defer atomic.StoreInt32(&w.writing, 0)
+	time.Sleep(time.Millisecond)
+	return w.Buffer.Write(p)
+}
+
+func TestTimeoutWriterDeadlineSerialized(t *testing.T) {
+	out := &deadlineBuffer{}
+	w := TimeoutWriter(out, time.Second, nil)
+	defer w.(*timeoutWriter).Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := w.Write([]byte("event\n")); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := strings.Count(out.String(), "event\n"); got != 4 {
+		t.Errorf("%d events written, want 4", got)
+	}
+}
+
+type slowCloser struct {
+	bytes.Buffer
+	delay time.Duration
+}
+
+func (w *slowCloser) Close() error {
+	time.Sleep(w.delay)
+	return nil
+}
+
+func TestCloseWriter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	w := MultiLevelWriter(&slowCloser{delay: time.Second}, &bytes.Buffer{})
+	if err := CloseWriter(ctx, w); err != context.DeadlineExceeded {
+		t.Errorf("CloseWriter err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := CloseWriter(context.Background(), &slowCloser{}); err != nil {
+		t.Errorf("CloseWriter err = %v, want nil", err)
+	}
+}